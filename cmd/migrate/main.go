@@ -9,6 +9,7 @@ import (
 	"ironnode/pkg/database"
 	"ironnode/pkg/models"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -63,24 +64,109 @@ func runMigrations(db *gorm.DB) error {
 		return err
 	}
 
+	// Replace plaintext API keys with their hashes before the key column goes away
+	if err := hashAPIKeys(db); err != nil {
+		return err
+	}
+
 	// Auto-migrate all models
-	return db.AutoMigrate(
+	err := db.AutoMigrate(
 		&models.User{},
 		&models.APIKey{},
 		&models.BlockchainNode{},
 		&models.RequestLog{},
+		&models.UsageRollup{},
+		&models.RollupCursor{},
+		&models.RetentionCursor{},
 		&models.Subscription{},
+		&models.BillingPeriod{},
+		&models.ChainUsage{},
+		&models.KeyUsage{},
+		&models.UsageIncrement{},
+		&models.Invoice{},
+		&models.InvoiceLineItem{},
+		&models.PaymentCustomer{},
+		&models.Payment{},
 		&models.Wallet{},
+		&models.DepositWallet{},
+		&models.CryptoDeposit{},
+		&models.PrepaidBalance{},
+		&models.LedgerAccount{},
+		&models.LedgerTransaction{},
+		&models.LedgerEntry{},
 		&models.PasswordReset{},
 	)
+	if err != nil {
+		return err
+	}
+
+	// Partition request logs by day so expired days can be dropped whole,
+	// then recreate their indexes on the partitioned table
+	if err := database.PartitionByDay(db, "request_logs", "created_at", 7); err != nil {
+		return err
+	}
+	return db.AutoMigrate(&models.RequestLog{})
+}
+
+// hashAPIKeys stores the hash and prefix of every plaintext key in api_keys and
+// drops the plaintext key column. Keys keep working, as they are validated by hash.
+func hashAPIKeys(db *gorm.DB) error {
+	if !db.Migrator().HasColumn("api_keys", "key") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS key_hash text, ADD COLUMN IF NOT EXISTS key_prefix text`).Error
+		if err != nil {
+			return err
+		}
+
+		var keys []struct {
+			ID  uuid.UUID
+			Key string
+		}
+		err = tx.Raw(`SELECT id, key FROM api_keys WHERE key IS NOT NULL AND (key_hash IS NULL OR key_hash = '')`).
+			Scan(&keys).Error
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			err := tx.Exec(`UPDATE api_keys SET key_hash = ?, key_prefix = ? WHERE id = ?`,
+				models.HashAPIKey(key.Key), models.APIKeyPrefix(key.Key), key.ID).Error
+			if err != nil {
+				return err
+			}
+		}
+		fmt.Printf("Hashed %d API keys\n", len(keys))
+
+		return tx.Exec(`ALTER TABLE api_keys DROP COLUMN key`).Error
+	})
 }
 
 func rollbackMigrations(db *gorm.DB) error {
 	// Drop all tables (use with caution!)
 	return db.Migrator().DropTable(
 		&models.PasswordReset{},
+		&models.LedgerEntry{},
+		&models.LedgerTransaction{},
+		&models.LedgerAccount{},
+		&models.PrepaidBalance{},
+		&models.CryptoDeposit{},
+		&models.DepositWallet{},
 		&models.Wallet{},
+		&models.Payment{},
+		&models.PaymentCustomer{},
+		&models.InvoiceLineItem{},
+		&models.Invoice{},
+		&models.UsageIncrement{},
+		&models.KeyUsage{},
+		&models.ChainUsage{},
+		&models.BillingPeriod{},
 		&models.Subscription{},
+		&models.RetentionCursor{},
+		&models.RollupCursor{},
+		&models.UsageRollup{},
 		&models.RequestLog{},
 		&models.BlockchainNode{},
 		&models.APIKey{},
@@ -0,0 +1,296 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when every node's circuit is open
+var ErrCircuitOpen = errors.New("circuit open for all nodes")
+
+// CircuitState is the state of a node's circuit
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects requests until the cooldown expires
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe requests through
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig holds circuit breaker thresholds
+type CircuitBreakerConfig struct {
+	WindowSize         int           // number of recent calls evaluated per node
+	MinRequests        int           // calls required in the window before the circuit can open
+	ErrorRateThreshold float64       // failure ratio (0..1) that opens the circuit
+	LatencyThreshold   time.Duration // successful calls slower than this count as failures; 0 disables
+	Cooldown           time.Duration // time an open circuit waits before going half-open
+	HalfOpenRequests   int           // successful probes needed to close a half-open circuit
+}
+
+// DefaultCircuitBreakerConfig returns sensible defaults
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		WindowSize:         20,
+		MinRequests:        10,
+		ErrorRateThreshold: 0.5,
+		LatencyThreshold:   5 * time.Second,
+		Cooldown:           30 * time.Second,
+		HalfOpenRequests:   3,
+	}
+}
+
+// StateChange describes a circuit transition of a node
+type StateChange struct {
+	NodeURL   string
+	From      CircuitState
+	To        CircuitState
+	ErrorRate float64
+	At        time.Time
+}
+
+// StateChangeFunc is called on every circuit transition
+type StateChangeFunc func(change StateChange)
+
+// circuit tracks a single node
+type circuit struct {
+	state    CircuitState
+	outcomes []bool // ring buffer, true = failure
+	next     int
+	count    int
+	failures int
+	openedAt time.Time
+	probes   int // probes in flight while half-open
+	passed   int // successful probes while half-open
+}
+
+// CircuitBreaker keeps an independent circuit for every node URL
+type CircuitBreaker struct {
+	config        CircuitBreakerConfig
+	mu            sync.Mutex
+	circuits      map[string]*circuit
+	onStateChange StateChangeFunc
+}
+
+// NewCircuitBreaker creates a circuit breaker with the given thresholds
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	defaults := DefaultCircuitBreakerConfig()
+	if config.WindowSize <= 0 {
+		config.WindowSize = defaults.WindowSize
+	}
+	if config.MinRequests <= 0 || config.MinRequests > config.WindowSize {
+		config.MinRequests = config.WindowSize
+	}
+	if config.ErrorRateThreshold <= 0 || config.ErrorRateThreshold > 1 {
+		config.ErrorRateThreshold = defaults.ErrorRateThreshold
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = defaults.Cooldown
+	}
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = defaults.HalfOpenRequests
+	}
+
+	return &CircuitBreaker{
+		config:   config,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// OnStateChange registers a callback for circuit transitions.
+// The callback runs synchronously and must not call back into the breaker.
+func (cb *CircuitBreaker) OnStateChange(fn StateChangeFunc) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.onStateChange = fn
+}
+
+// Allow reports whether a request to the node may be sent.
+// An allowed request must be followed by a call to Record.
+func (cb *CircuitBreaker) Allow(nodeURL string) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c := cb.circuit(nodeURL)
+
+	switch c.state {
+	case CircuitOpen:
+		if time.Since(c.openedAt) < cb.config.Cooldown {
+			return false
+		}
+		cb.transition(nodeURL, c, CircuitHalfOpen)
+		c.probes++
+		return true
+
+	case CircuitHalfOpen:
+		if c.probes+c.passed >= cb.config.HalfOpenRequests {
+			return false
+		}
+		c.probes++
+		return true
+
+	default:
+		return true
+	}
+}
+
+// Record reports the outcome of a request allowed by Allow.
+// Requests cancelled by the caller are not counted against the node.
+func (cb *CircuitBreaker) Record(nodeURL string, latency time.Duration, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c := cb.circuit(nodeURL)
+
+	if errors.Is(err, context.Canceled) {
+		if c.state == CircuitHalfOpen && c.probes > 0 {
+			c.probes--
+		}
+		return
+	}
+
+	failed := err != nil || (cb.config.LatencyThreshold > 0 && latency > cb.config.LatencyThreshold)
+
+	switch c.state {
+	case CircuitHalfOpen:
+		if c.probes > 0 {
+			c.probes--
+		}
+		if failed {
+			cb.transition(nodeURL, c, CircuitOpen)
+			return
+		}
+		c.passed++
+		if c.passed >= cb.config.HalfOpenRequests {
+			cb.transition(nodeURL, c, CircuitClosed)
+		}
+
+	case CircuitClosed:
+		c.add(failed, cb.config.WindowSize)
+		if c.count >= cb.config.MinRequests && c.errorRate() >= cb.config.ErrorRateThreshold {
+			cb.transition(nodeURL, c, CircuitOpen)
+		}
+	}
+}
+
+// Filter returns the node URLs whose circuits allow a request, keeping their order.
+// Every returned URL counts as allowed and must be followed by Record.
+func (cb *CircuitBreaker) Filter(nodeURLs []string) []string {
+	allowed := make([]string, 0, len(nodeURLs))
+	for _, url := range nodeURLs {
+		if cb.Allow(url) {
+			allowed = append(allowed, url)
+		}
+	}
+	return allowed
+}
+
+// State returns the current circuit state of a node
+func (cb *CircuitBreaker) State(nodeURL string) CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c, exists := cb.circuits[nodeURL]
+	if !exists {
+		return CircuitClosed
+	}
+	return c.state
+}
+
+// States returns the circuit state of every known node
+func (cb *CircuitBreaker) States() map[string]CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	states := make(map[string]CircuitState, len(cb.circuits))
+	for url, c := range cb.circuits {
+		states[url] = c.state
+	}
+	return states
+}
+
+// circuit returns the node's circuit, creating it if needed. Must be called with cb.mu held.
+func (cb *CircuitBreaker) circuit(nodeURL string) *circuit {
+	c, exists := cb.circuits[nodeURL]
+	if !exists {
+		c = &circuit{outcomes: make([]bool, cb.config.WindowSize)}
+		cb.circuits[nodeURL] = c
+	}
+	return c
+}
+
+// transition moves a circuit to a new state. Must be called with cb.mu held.
+func (cb *CircuitBreaker) transition(nodeURL string, c *circuit, to CircuitState) {
+	change := StateChange{
+		NodeURL:   nodeURL,
+		From:      c.state,
+		To:        to,
+		ErrorRate: c.errorRate(),
+		At:        time.Now(),
+	}
+
+	c.state = to
+	c.probes = 0
+	c.passed = 0
+
+	switch to {
+	case CircuitOpen:
+		c.openedAt = change.At
+	case CircuitClosed:
+		c.reset()
+	}
+
+	if cb.onStateChange != nil {
+		cb.onStateChange(change)
+	}
+}
+
+// add records an outcome in the sliding window
+func (c *circuit) add(failed bool, windowSize int) {
+	if c.count == windowSize {
+		if c.outcomes[c.next] {
+			c.failures--
+		}
+	} else {
+		c.count++
+	}
+
+	c.outcomes[c.next] = failed
+	if failed {
+		c.failures++
+	}
+	c.next = (c.next + 1) % windowSize
+}
+
+func (c *circuit) errorRate() float64 {
+	if c.count == 0 {
+		return 0
+	}
+	return float64(c.failures) / float64(c.count)
+}
+
+func (c *circuit) reset() {
+	for i := range c.outcomes {
+		c.outcomes[i] = false
+	}
+	c.next = 0
+	c.count = 0
+	c.failures = 0
+}
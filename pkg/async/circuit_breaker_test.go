@@ -0,0 +1,189 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+const testNode = "http://node-1"
+
+var errNode = errors.New("node failed")
+
+func testBreaker() *CircuitBreaker {
+	return NewCircuitBreaker(CircuitBreakerConfig{
+		WindowSize:         4,
+		MinRequests:        4,
+		ErrorRateThreshold: 0.5,
+		LatencyThreshold:   time.Second,
+		Cooldown:           20 * time.Millisecond,
+		HalfOpenRequests:   2,
+	})
+}
+
+// record lets a request through and reports its outcome
+func record(t *testing.T, cb *CircuitBreaker, latency time.Duration, err error) {
+	t.Helper()
+	if !cb.Allow(testNode) {
+		t.Fatalf("request not allowed in state %s", cb.State(testNode))
+	}
+	cb.Record(testNode, latency, err)
+}
+
+// open trips the test node's circuit
+func open(t *testing.T, cb *CircuitBreaker) {
+	t.Helper()
+	for i := 0; i < 4; i++ {
+		record(t, cb, 0, errNode)
+	}
+	if state := cb.State(testNode); state != CircuitOpen {
+		t.Fatalf("state = %s, want open", state)
+	}
+}
+
+func TestCircuitBreakerOpens(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcomes []error
+		latency  time.Duration
+		want     CircuitState
+	}{
+		{"all successes", []error{nil, nil, nil, nil}, 0, CircuitClosed},
+		{"below min requests", []error{errNode, errNode, errNode}, 0, CircuitClosed},
+		{"below threshold", []error{errNode, nil, nil, nil}, 0, CircuitClosed},
+		{"at threshold", []error{errNode, nil, errNode, nil}, 0, CircuitOpen},
+		{"slow successes", []error{nil, nil, nil, nil}, 2 * time.Second, CircuitOpen},
+		{"one failure per window", []error{errNode, nil, nil, nil, errNode, nil, nil, nil, errNode}, 0, CircuitClosed},
+		{"cancelled calls ignored", []error{context.Canceled, context.Canceled, context.Canceled, context.Canceled}, 0, CircuitClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb := testBreaker()
+			for _, err := range tt.outcomes {
+				record(t, cb, tt.latency, err)
+			}
+			if state := cb.State(testNode); state != tt.want {
+				t.Errorf("state = %s, want %s", state, tt.want)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	cb := testBreaker()
+	open(t, cb)
+
+	if cb.Allow(testNode) {
+		t.Fatal("open circuit allowed a request before the cooldown")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	// Only HalfOpenRequests probes at a time
+	if !cb.Allow(testNode) || !cb.Allow(testNode) {
+		t.Fatal("half-open circuit rejected a probe")
+	}
+	if state := cb.State(testNode); state != CircuitHalfOpen {
+		t.Fatalf("state = %s, want half-open", state)
+	}
+	if cb.Allow(testNode) {
+		t.Fatal("half-open circuit allowed more probes than configured")
+	}
+
+	cb.Record(testNode, 0, nil)
+	if state := cb.State(testNode); state != CircuitHalfOpen {
+		t.Fatalf("state after one probe = %s, want half-open", state)
+	}
+	cb.Record(testNode, 0, nil)
+	if state := cb.State(testNode); state != CircuitClosed {
+		t.Fatalf("state after probes passed = %s, want closed", state)
+	}
+
+	// Closing resets the window, so old failures do not reopen it
+	record(t, cb, 0, errNode)
+	if state := cb.State(testNode); state != CircuitClosed {
+		t.Errorf("state = %s, want closed", state)
+	}
+}
+
+func TestCircuitBreakerFailedProbeReopens(t *testing.T) {
+	cb := testBreaker()
+	open(t, cb)
+	time.Sleep(30 * time.Millisecond)
+
+	record(t, cb, 0, errNode)
+	if state := cb.State(testNode); state != CircuitOpen {
+		t.Fatalf("state = %s, want open", state)
+	}
+	if cb.Allow(testNode) {
+		t.Error("reopened circuit allowed a request before a new cooldown")
+	}
+}
+
+func TestCircuitBreakerCancelledProbeReleased(t *testing.T) {
+	cb := testBreaker()
+	open(t, cb)
+	time.Sleep(30 * time.Millisecond)
+
+	if !cb.Allow(testNode) || !cb.Allow(testNode) {
+		t.Fatal("half-open circuit rejected a probe")
+	}
+	cb.Record(testNode, 0, context.Canceled)
+
+	if state := cb.State(testNode); state != CircuitHalfOpen {
+		t.Fatalf("state = %s, want half-open", state)
+	}
+	if !cb.Allow(testNode) {
+		t.Error("cancelled probe did not free its slot")
+	}
+}
+
+func TestCircuitBreakerStateChanges(t *testing.T) {
+	cb := testBreaker()
+
+	var changes []StateChange
+	cb.OnStateChange(func(change StateChange) {
+		changes = append(changes, change)
+	})
+
+	open(t, cb)
+	time.Sleep(30 * time.Millisecond)
+	record(t, cb, 0, nil)
+	record(t, cb, 0, nil)
+
+	want := []struct{ from, to CircuitState }{
+		{CircuitClosed, CircuitOpen},
+		{CircuitOpen, CircuitHalfOpen},
+		{CircuitHalfOpen, CircuitClosed},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d state changes, want %d", len(changes), len(want))
+	}
+	for i, change := range changes {
+		if change.NodeURL != testNode || change.From != want[i].from || change.To != want[i].to {
+			t.Errorf("change %d = %s %s -> %s, want %s -> %s",
+				i, change.NodeURL, change.From, change.To, want[i].from, want[i].to)
+		}
+	}
+	if changes[0].ErrorRate != 1 {
+		t.Errorf("error rate on opening = %v, want 1", changes[0].ErrorRate)
+	}
+}
+
+func TestCircuitBreakerFilter(t *testing.T) {
+	cb := testBreaker()
+	open(t, cb)
+
+	got := cb.Filter([]string{"http://node-0", testNode, "http://node-2"})
+	want := []string{"http://node-0", "http://node-2"}
+	if len(got) != len(want) {
+		t.Fatalf("Filter = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Filter = %v, want %v", got, want)
+		}
+	}
+}
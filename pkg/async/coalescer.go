@@ -0,0 +1,87 @@
+package async
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// CoalescerStats holds request coalescing statistics
+type CoalescerStats struct {
+	Calls     int64 `json:"calls"`     // calls that reached the coalescer
+	Executed  int64 `json:"executed"`  // calls that ran the function
+	Coalesced int64 `json:"coalesced"` // calls that waited for another call's result
+	InFlight  int   `json:"in_flight"` // distinct keys currently executing
+}
+
+// call is an in-flight or completed Do call
+type call struct {
+	wg   sync.WaitGroup
+	data []byte
+	err  error
+}
+
+// Coalescer collapses concurrent calls with the same key into one execution
+// and hands its result to every waiter (singleflight)
+type Coalescer struct {
+	mu    sync.Mutex
+	calls map[string]*call
+
+	total     int64
+	executed  int64
+	coalesced int64
+}
+
+// NewCoalescer creates a new request coalescer
+func NewCoalescer() *Coalescer {
+	return &Coalescer{
+		calls: make(map[string]*call),
+	}
+}
+
+// Do runs fn once for all concurrent callers with the same key.
+// shared is true when the result was produced by another caller's execution.
+// The returned data is shared between callers and must not be modified.
+func (c *Coalescer) Do(key string, fn func() ([]byte, error)) (data []byte, shared bool, err error) {
+	atomic.AddInt64(&c.total, 1)
+
+	c.mu.Lock()
+	if existing, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		atomic.AddInt64(&c.coalesced, 1)
+		existing.wg.Wait()
+		return existing.data, true, existing.err
+	}
+
+	// Waiters see this error if fn panics
+	cl := &call{err: errors.New("coalesced call did not complete")}
+	cl.wg.Add(1)
+	c.calls[key] = cl
+	c.mu.Unlock()
+
+	atomic.AddInt64(&c.executed, 1)
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+		cl.wg.Done()
+	}()
+
+	cl.data, cl.err = fn()
+	return cl.data, false, cl.err
+}
+
+// Stats returns current coalescing statistics
+func (c *Coalescer) Stats() CoalescerStats {
+	c.mu.Lock()
+	inFlight := len(c.calls)
+	c.mu.Unlock()
+
+	return CoalescerStats{
+		Calls:     atomic.LoadInt64(&c.total),
+		Executed:  atomic.LoadInt64(&c.executed),
+		Coalesced: atomic.LoadInt64(&c.coalesced),
+		InFlight:  inFlight,
+	}
+}
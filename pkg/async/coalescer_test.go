@@ -0,0 +1,138 @@
+package async
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startWaiters starts n callers of key and returns once the first one executes fn
+// and all of them have reached the coalescer
+func startWaiters(t *testing.T, c *Coalescer, key string, n int, fn func() ([]byte, error)) (results chan []byte, errs chan error, shared chan bool) {
+	t.Helper()
+
+	results = make(chan []byte, n)
+	errs = make(chan error, n)
+	shared = make(chan bool, n)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			data, isShared, err := c.Do(key, fn)
+			results <- data
+			errs <- err
+			shared <- isShared
+		}()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for c.Stats().Calls < int64(n) {
+		if time.Now().After(deadline) {
+			t.Fatal("callers did not reach the coalescer")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return results, errs, shared
+}
+
+func TestCoalescerShares(t *testing.T) {
+	c := NewCoalescer()
+	release := make(chan struct{})
+	var executions int32
+
+	results, errs, shared := startWaiters(t, c, "key", 5, func() ([]byte, error) {
+		atomic.AddInt32(&executions, 1)
+		<-release
+		return []byte("result"), nil
+	})
+
+	if stats := c.Stats(); stats.InFlight != 1 {
+		t.Errorf("in flight = %d, want 1", stats.InFlight)
+	}
+	close(release)
+
+	sharedCount := 0
+	for i := 0; i < 5; i++ {
+		if data := <-results; string(data) != "result" {
+			t.Errorf("result = %s, want result", data)
+		}
+		if err := <-errs; err != nil {
+			t.Errorf("err = %v", err)
+		}
+		if <-shared {
+			sharedCount++
+		}
+	}
+
+	if executions != 1 {
+		t.Errorf("fn executed %d times, want 1", executions)
+	}
+	if sharedCount != 4 {
+		t.Errorf("%d callers got a shared result, want 4", sharedCount)
+	}
+
+	stats := c.Stats()
+	want := CoalescerStats{Calls: 5, Executed: 1, Coalesced: 4, InFlight: 0}
+	if stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
+
+func TestCoalescerSharesErrors(t *testing.T) {
+	c := NewCoalescer()
+	release := make(chan struct{})
+	errCall := errors.New("upstream failed")
+
+	_, errs, _ := startWaiters(t, c, "key", 3, func() ([]byte, error) {
+		<-release
+		return nil, errCall
+	})
+	close(release)
+
+	for i := 0; i < 3; i++ {
+		if err := <-errs; !errors.Is(err, errCall) {
+			t.Errorf("err = %v, want %v", err, errCall)
+		}
+	}
+
+	// A finished call is not reused
+	data, shared, err := c.Do("key", func() ([]byte, error) { return []byte("retry"), nil })
+	if err != nil || shared || string(data) != "retry" {
+		t.Errorf("Do after failure = %s, %v, %v; want a new execution", data, shared, err)
+	}
+}
+
+func TestCoalescerKeys(t *testing.T) {
+	c := NewCoalescer()
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			data, shared, err := c.Do(key, func() ([]byte, error) {
+				<-release
+				return []byte(key), nil
+			})
+			if err != nil || shared || string(data) != key {
+				t.Errorf("Do(%s) = %s, %v, %v", key, data, shared, err)
+			}
+		}(key)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for c.Stats().InFlight < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("different keys were not executed separately")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if stats := c.Stats(); stats.Executed != 2 || stats.Coalesced != 0 {
+		t.Errorf("stats = %+v, want 2 executions and none coalesced", stats)
+	}
+}
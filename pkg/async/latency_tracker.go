@@ -0,0 +1,86 @@
+package async
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyTracker keeps a sliding window of recent latencies per method
+type LatencyTracker struct {
+	windowSize int
+	mu         sync.Mutex
+	samples    map[string]*latencyWindow
+}
+
+type latencyWindow struct {
+	values []time.Duration
+	next   int
+	full   bool
+}
+
+// NewLatencyTracker creates a tracker that keeps the last windowSize samples per method
+func NewLatencyTracker(windowSize int) *LatencyTracker {
+	if windowSize <= 0 {
+		windowSize = 100
+	}
+
+	return &LatencyTracker{
+		windowSize: windowSize,
+		samples:    make(map[string]*latencyWindow),
+	}
+}
+
+// Observe records a latency sample for a method
+func (t *LatencyTracker) Observe(method string, latency time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	w, exists := t.samples[method]
+	if !exists {
+		w = &latencyWindow{values: make([]time.Duration, t.windowSize)}
+		t.samples[method] = w
+	}
+
+	w.values[w.next] = latency
+	w.next = (w.next + 1) % t.windowSize
+	if w.next == 0 {
+		w.full = true
+	}
+}
+
+// Percentile returns the p-th percentile (0..1) of the method's latency.
+// It returns false until minSamples samples have been observed.
+func (t *LatencyTracker) Percentile(method string, p float64, minSamples int) (time.Duration, bool) {
+	t.mu.Lock()
+	w, exists := t.samples[method]
+	if !exists {
+		t.mu.Unlock()
+		return 0, false
+	}
+
+	count := w.next
+	if w.full {
+		count = t.windowSize
+	}
+	if count == 0 || count < minSamples {
+		t.mu.Unlock()
+		return 0, false
+	}
+
+	sorted := make([]time.Duration, count)
+	copy(sorted, w.values[:count])
+	t.mu.Unlock()
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	index := int(p*float64(count)+0.5) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= count {
+		index = count - 1
+	}
+
+	return sorted[index], true
+}
@@ -0,0 +1,74 @@
+package async
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyTrackerPercentile(t *testing.T) {
+	tracker := NewLatencyTracker(100)
+	for i := 100; i >= 1; i-- {
+		tracker.Observe("eth_call", time.Duration(i)*time.Millisecond)
+	}
+
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0, 1 * time.Millisecond},
+		{0.5, 50 * time.Millisecond},
+		{0.95, 95 * time.Millisecond},
+		{0.99, 99 * time.Millisecond},
+		{1, 100 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		got, ok := tracker.Percentile("eth_call", tt.p, 100)
+		if !ok {
+			t.Fatalf("Percentile(%v) not available with 100 samples", tt.p)
+		}
+		if got != tt.want {
+			t.Errorf("Percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestLatencyTrackerMinSamples(t *testing.T) {
+	tracker := NewLatencyTracker(100)
+
+	if _, ok := tracker.Percentile("eth_call", 0.95, 1); ok {
+		t.Error("percentile of an unknown method is available")
+	}
+
+	for i := 0; i < 9; i++ {
+		tracker.Observe("eth_call", time.Millisecond)
+	}
+	if _, ok := tracker.Percentile("eth_call", 0.95, 10); ok {
+		t.Error("percentile available below min samples")
+	}
+
+	tracker.Observe("eth_call", time.Millisecond)
+	if _, ok := tracker.Percentile("eth_call", 0.95, 10); !ok {
+		t.Error("percentile not available at min samples")
+	}
+	if _, ok := tracker.Percentile("eth_getBalance", 0.95, 1); ok {
+		t.Error("methods share samples")
+	}
+}
+
+func TestLatencyTrackerWindow(t *testing.T) {
+	tracker := NewLatencyTracker(10)
+
+	// Slow samples are pushed out of the window by fast ones
+	for i := 0; i < 10; i++ {
+		tracker.Observe("eth_call", time.Second)
+	}
+	for i := 0; i < 10; i++ {
+		tracker.Observe("eth_call", time.Millisecond)
+	}
+
+	got, ok := tracker.Percentile("eth_call", 1, 10)
+	if !ok || got != time.Millisecond {
+		t.Errorf("Percentile(1) = %v, %v; want %v, true", got, ok, time.Millisecond)
+	}
+}
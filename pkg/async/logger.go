@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"ironnode/pkg/models"
@@ -11,11 +12,17 @@ import (
 	"gorm.io/gorm"
 )
 
+// LogWriter stores one log entry, e.g. in the database or a remote service
+type LogWriter func(logEntry *models.RequestLog) error
+
 // AsyncLogger provides asynchronous logging functionality
 type AsyncLogger struct {
-	db          *gorm.DB
+	write       LogWriter
 	logChannel  chan *models.RequestLog
 	workerCount int
+	dropped     int64        // entries not queued because the queue was full or closed
+	mu          sync.RWMutex // held for writing only to close logChannel
+	closed      bool
 	wg          sync.WaitGroup
 	ctx         context.Context
 	cancel      context.CancelFunc
@@ -23,10 +30,17 @@ type AsyncLogger struct {
 
 // NewAsyncLogger creates a new async logger with specified buffer size and worker count
 func NewAsyncLogger(db *gorm.DB, bufferSize, workerCount int) *AsyncLogger {
+	return NewAsyncLoggerWithWriter(func(logEntry *models.RequestLog) error {
+		return db.Create(logEntry).Error
+	}, bufferSize, workerCount)
+}
+
+// NewAsyncLoggerWithWriter creates an async logger that stores entries with write
+func NewAsyncLoggerWithWriter(write LogWriter, bufferSize, workerCount int) *AsyncLogger {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	logger := &AsyncLogger{
-		db:          db,
+		write:       write,
 		logChannel:  make(chan *models.RequestLog, bufferSize),
 		workerCount: workerCount,
 		ctx:         ctx,
@@ -68,13 +82,13 @@ func (l *AsyncLogger) worker(id int) {
 	}
 }
 
-// processLog saves log entry to database with retry logic
+// processLog stores log entry with retry logic
 func (l *AsyncLogger) processLog(logEntry *models.RequestLog) {
 	maxRetries := 3
 	var err error
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		err = l.db.Create(logEntry).Error
+		err = l.write(logEntry)
 		if err == nil {
 			return
 		}
@@ -89,33 +103,63 @@ func (l *AsyncLogger) processLog(logEntry *models.RequestLog) {
 	log.Printf("[AsyncLogger] Dropped log entry after %d attempts: %v", maxRetries, err)
 }
 
-// Log sends a log entry to the async queue
+// Log sends a log entry to the async queue without blocking the caller.
+// The entry is dropped if the queue is full or the logger is shut down.
 func (l *AsyncLogger) Log(logEntry *models.RequestLog) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.closed {
+		l.drop()
+		return
+	}
+
 	select {
 	case l.logChannel <- logEntry:
 		// Successfully queued
-	case <-time.After(1 * time.Second):
-		log.Printf("[AsyncLogger] Log queue full, dropping log entry")
+	default:
+		l.drop()
 	}
 }
 
 // LogWithTimeout sends a log entry with custom timeout
 func (l *AsyncLogger) LogWithTimeout(logEntry *models.RequestLog, timeout time.Duration) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.closed {
+		l.drop()
+		return false
+	}
+
 	select {
 	case l.logChannel <- logEntry:
 		return true
 	case <-time.After(timeout):
 		log.Printf("[AsyncLogger] Timeout waiting for log queue")
+		l.drop()
 		return false
 	}
 }
 
+// drop counts an entry that was not queued, logging the first and every 1000th
+func (l *AsyncLogger) drop() {
+	if dropped := atomic.AddInt64(&l.dropped, 1); dropped == 1 || dropped%1000 == 0 {
+		log.Printf("[AsyncLogger] Log queue full or closed, %d log entries dropped so far", dropped)
+	}
+}
+
 // Shutdown gracefully shuts down the async logger
 func (l *AsyncLogger) Shutdown(timeout time.Duration) error {
 	log.Printf("[AsyncLogger] Shutting down...")
 
-	// Stop accepting new logs
-	close(l.logChannel)
+	// Stop accepting new logs; senders hold the read lock, so none is mid-send
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.logChannel)
+	}
+	l.mu.Unlock()
 
 	// Wait for workers to finish with timeout
 	done := make(chan struct{})
@@ -141,5 +185,6 @@ func (l *AsyncLogger) Stats() map[string]interface{} {
 		"queue_length": len(l.logChannel),
 		"queue_capacity": cap(l.logChannel),
 		"worker_count": l.workerCount,
+		"dropped": atomic.LoadInt64(&l.dropped),
 	}
 }
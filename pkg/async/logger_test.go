@@ -0,0 +1,76 @@
+package async
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"ironnode/pkg/models"
+)
+
+// blockingWriter holds every write until release is closed
+func blockingWriter(release chan struct{}) LogWriter {
+	return func(logEntry *models.RequestLog) error {
+		<-release
+		return nil
+	}
+}
+
+func TestAsyncLoggerDropsWhenFull(t *testing.T) {
+	release := make(chan struct{})
+	logger := NewAsyncLoggerWithWriter(blockingWriter(release), 2, 1)
+	defer logger.Shutdown(time.Second)
+	defer close(release)
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		logger.Log(&models.RequestLog{})
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Log blocked for %v on a full queue", elapsed)
+	}
+
+	// The worker holds one entry and the queue two; the rest are dropped
+	if dropped := logger.Stats()["dropped"].(int64); dropped < 7 {
+		t.Errorf("dropped = %d, want at least 7", dropped)
+	}
+}
+
+func TestAsyncLoggerShutdown(t *testing.T) {
+	var mu sync.Mutex
+	written := 0
+	logger := NewAsyncLoggerWithWriter(func(logEntry *models.RequestLog) error {
+		mu.Lock()
+		written++
+		mu.Unlock()
+		return nil
+	}, 100, 2)
+
+	// Senders still running during and after shutdown must not panic
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				logger.Log(&models.RequestLog{})
+			}
+		}()
+	}
+
+	if err := logger.Shutdown(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	if logger.LogWithTimeout(&models.RequestLog{}, time.Millisecond) {
+		t.Error("entry queued after shutdown")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	dropped := logger.Stats()["dropped"].(int64)
+	if int64(written)+dropped != 801 {
+		t.Errorf("written %d + dropped %d, want every entry accounted for (801)", written, dropped)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -26,10 +28,58 @@ type NodeResponse struct {
 // RequestFunc defines the function signature for making requests
 type RequestFunc func(ctx context.Context, nodeURL string, method string, params []byte) ([]byte, int64, error)
 
+// ReplyError is a node reply with an error status, such as a JSON-RPC error sent
+// with HTTP 400. RequestFunc returns it so the status and body can reach the client.
+type ReplyError struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+func (e *ReplyError) Error() string {
+	return fmt.Sprintf("upstream returned status %d", e.StatusCode)
+}
+
+// NodeFault reports whether the status means the node is failing rather than
+// rejecting the request; only then is another node tried
+func (e *ReplyError) NodeFault() bool {
+	return e.StatusCode >= http.StatusInternalServerError || e.StatusCode == http.StatusTooManyRequests
+}
+
+// nodeFailure returns err unless it is a node's reply rejecting the request
+func nodeFailure(err error) error {
+	var reply *ReplyError
+	if errors.As(err, &reply) && !reply.NodeFault() {
+		return nil
+	}
+	return err
+}
+
+// HedgeConfig controls when RequestHedged sends a backup request
+type HedgeConfig struct {
+	Percentile   float64       // latency percentile of the method used as hedge delay (0..1)
+	MinSamples   int           // samples required before the percentile is trusted
+	DefaultDelay time.Duration // delay used until enough samples are collected
+	MinDelay     time.Duration // lower bound for the hedge delay
+}
+
+// DefaultHedgeConfig returns sensible defaults
+func DefaultHedgeConfig() HedgeConfig {
+	return HedgeConfig{
+		Percentile:   0.95,
+		MinSamples:   20,
+		DefaultDelay: 300 * time.Millisecond,
+		MinDelay:     10 * time.Millisecond,
+	}
+}
+
 // ParallelRequester makes parallel requests to multiple nodes with failover
 type ParallelRequester struct {
 	requestFunc RequestFunc
 	timeout     time.Duration
+	breaker     *CircuitBreaker
+	hedge       HedgeConfig
+	latencies   *LatencyTracker
 }
 
 // NewParallelRequester creates a new parallel requester
@@ -37,15 +87,100 @@ func NewParallelRequester(requestFunc RequestFunc, timeout time.Duration) *Paral
 	return &ParallelRequester{
 		requestFunc: requestFunc,
 		timeout:     timeout,
+		hedge:       DefaultHedgeConfig(),
+		latencies:   NewLatencyTracker(100),
+	}
+}
+
+// SetHedgeConfig changes the hedging settings; zero fields keep their defaults
+func (pr *ParallelRequester) SetHedgeConfig(config HedgeConfig) {
+	defaults := DefaultHedgeConfig()
+	if config.Percentile <= 0 || config.Percentile > 1 {
+		config.Percentile = defaults.Percentile
+	}
+	if config.MinSamples <= 0 {
+		config.MinSamples = defaults.MinSamples
+	}
+	if config.DefaultDelay <= 0 {
+		config.DefaultDelay = defaults.DefaultDelay
+	}
+	if config.MinDelay <= 0 {
+		config.MinDelay = defaults.MinDelay
+	}
+	pr.hedge = config
+}
+
+// SetCircuitBreaker makes failover and fastest requests skip nodes with an open circuit
+func (pr *ParallelRequester) SetCircuitBreaker(breaker *CircuitBreaker) {
+	pr.breaker = breaker
+}
+
+// CircuitBreaker returns the circuit breaker in use, or nil
+func (pr *ParallelRequester) CircuitBreaker() *CircuitBreaker {
+	return pr.breaker
+}
+
+// allowedNodes drops nodes whose circuit is open
+func (pr *ParallelRequester) allowedNodes(nodeURLs []string) ([]string, error) {
+	if pr.breaker == nil {
+		return nodeURLs, nil
+	}
+
+	allowed := pr.breaker.Filter(nodeURLs)
+	if len(allowed) == 0 {
+		return nil, ErrCircuitOpen
+	}
+
+	return allowed, nil
+}
+
+// request calls requestFunc, reports the outcome to the circuit breaker
+// and feeds successful latencies to the hedge delay estimate
+func (pr *ParallelRequester) request(ctx context.Context, nodeURL string, method string, params []byte) ([]byte, int64, error) {
+	start := time.Now()
+	data, responseTime, err := pr.requestFunc(ctx, nodeURL, method, params)
+	pr.record(nodeURL, method, time.Since(start), err)
+
+	return data, responseTime, err
+}
+
+// record reports a request's outcome to the circuit breaker and its latency, if the
+// node answered, to the hedge delay estimate
+func (pr *ParallelRequester) record(nodeURL string, method string, latency time.Duration, err error) {
+	// A node that rejects a request is healthy
+	err = nodeFailure(err)
+	if pr.breaker != nil {
+		pr.breaker.Record(nodeURL, latency, err)
+	}
+	if err == nil {
+		pr.latencies.Observe(method, latency)
 	}
 }
 
-// RequestWithFailover makes parallel requests to multiple nodes and returns the first successful response
+// hedgeDelay returns how long to wait for the first node before hedging
+func (pr *ParallelRequester) hedgeDelay(method string) time.Duration {
+	delay, ok := pr.latencies.Percentile(method, pr.hedge.Percentile, pr.hedge.MinSamples)
+	if !ok {
+		return pr.hedge.DefaultDelay
+	}
+	if delay < pr.hedge.MinDelay {
+		return pr.hedge.MinDelay
+	}
+	return delay
+}
+
+// RequestWithFailover makes parallel requests to multiple nodes and returns the first successful response.
+// Nodes with an open circuit are skipped.
 func (pr *ParallelRequester) RequestWithFailover(ctx context.Context, nodeURLs []string, method string, params []byte) (*NodeResponse, error) {
 	if len(nodeURLs) == 0 {
 		return nil, errors.New("no node URLs provided")
 	}
 
+	nodeURLs, err := pr.allowedNodes(nodeURLs)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create context with timeout
 	ctx, cancel := context.WithTimeout(ctx, pr.timeout)
 	defer cancel()
@@ -63,7 +198,7 @@ func (pr *ParallelRequester) RequestWithFailover(ctx context.Context, nodeURLs [
 			defer wg.Done()
 
 			// Make request
-			data, responseTime, err := pr.requestFunc(ctx, url, method, params)
+			data, responseTime, err := pr.request(ctx, url, method, params)
 
 			// Send response
 			select {
@@ -164,12 +299,18 @@ func (pr *ParallelRequester) RequestAll(ctx context.Context, nodeURLs []string,
 	return responses, nil
 }
 
-// RequestFastest makes parallel requests to all nodes and returns the fastest successful response
+// RequestFastest makes parallel requests to all nodes and returns the fastest successful response.
+// Nodes with an open circuit are skipped.
 func (pr *ParallelRequester) RequestFastest(ctx context.Context, nodeURLs []string, method string, params []byte) (*NodeResponse, error) {
 	if len(nodeURLs) == 0 {
 		return nil, errors.New("no node URLs provided")
 	}
 
+	nodeURLs, err := pr.allowedNodes(nodeURLs)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create context with timeout
 	ctx, cancel := context.WithTimeout(ctx, pr.timeout)
 	defer cancel()
@@ -188,7 +329,7 @@ func (pr *ParallelRequester) RequestFastest(ctx context.Context, nodeURLs []stri
 			defer wg.Done()
 
 			// Make request
-			data, responseTime, err := pr.requestFunc(ctx, url, method, params)
+			data, responseTime, err := pr.request(ctx, url, method, params)
 
 			if err != nil {
 				select {
@@ -230,6 +371,143 @@ func (pr *ParallelRequester) RequestFastest(ctx context.Context, nodeURLs []stri
 	}
 }
 
+// RequestHedged sends the request to the first node (nodes are expected best first)
+// and sends a single backup request to the next node only if no reply arrives within
+// the method's percentile latency. A failed request fails over to the next node at once.
+// The first successful reply wins and the other request is cancelled. A reply rejecting
+// the request also ends it and is returned as a *ReplyError. If every node fails, the
+// error wraps each node's error. Nodes with an open circuit are skipped.
+func (pr *ParallelRequester) RequestHedged(ctx context.Context, nodeURLs []string, method string, params []byte) (*NodeResponse, error) {
+	if len(nodeURLs) == 0 {
+		return nil, errors.New("no node URLs provided")
+	}
+
+	// Create context with timeout; cancelling it stops the losing request
+	ctx, cancel := context.WithTimeout(ctx, pr.timeout)
+	defer cancel()
+
+	// Buffered for every node so late replies never block
+	responseChan := make(chan *NodeResponse, len(nodeURLs))
+	next := 0
+	inFlight := 0
+
+	// Set once a reply won; requests finishing after that were cut short by the
+	// cancellation, so their outcome and latency say nothing about the node
+	var settled atomic.Bool
+
+	// launch starts a request to the next node whose circuit allows it
+	launch := func() bool {
+		for next < len(nodeURLs) {
+			url := nodeURLs[next]
+			next++
+
+			if pr.breaker != nil && !pr.breaker.Allow(url) {
+				continue
+			}
+
+			inFlight++
+			go func(url string) {
+				start := time.Now()
+				data, responseTime, err := pr.requestFunc(ctx, url, method, params)
+				if settled.Load() {
+					// Only release the node's half-open probe, if it used one
+					if pr.breaker != nil {
+						pr.breaker.Record(url, time.Since(start), context.Canceled)
+					}
+				} else {
+					pr.record(url, method, time.Since(start), err)
+				}
+
+				responseChan <- &NodeResponse{
+					Data:         data,
+					ResponseTime: responseTime,
+					NodeURL:      url,
+					Error:        err,
+				}
+			}(url)
+			return true
+		}
+		return false
+	}
+
+	if !launch() {
+		return nil, ErrCircuitOpen
+	}
+
+	hedgeTimer := time.NewTimer(pr.hedgeDelay(method))
+	defer hedgeTimer.Stop()
+
+	var failures []error
+	for inFlight > 0 {
+		select {
+		case response := <-responseChan:
+			inFlight--
+			if nodeFailure(response.Error) == nil {
+				settled.Store(true)
+				if response.Error != nil {
+					return nil, response.Error
+				}
+				return response, nil
+			}
+			failures = append(failures, fmt.Errorf("node %s: %w", response.NodeURL, response.Error))
+			// Fail over without waiting for the hedge delay
+			launch()
+
+		case <-hedgeTimer.C:
+			// First node is slow: send a backup request
+			launch()
+
+		case <-ctx.Done():
+			if len(failures) > 0 {
+				return nil, fmt.Errorf("request timed out: %w", errors.Join(failures...))
+			}
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, fmt.Errorf("all nodes failed: %w", errors.Join(failures...))
+}
+
+// RequestSequential sends the request to one node at a time (nodes are expected
+// best first) and moves to the next node only if the request fails, so a call that
+// changes state is never sent to two nodes at once. A reply rejecting the request is
+// returned as a *ReplyError without trying another node. Nodes with an open circuit are skipped.
+func (pr *ParallelRequester) RequestSequential(ctx context.Context, nodeURLs []string, method string, params []byte) (*NodeResponse, error) {
+	if len(nodeURLs) == 0 {
+		return nil, errors.New("no node URLs provided")
+	}
+
+	nodeURLs, err := pr.allowedNodes(nodeURLs)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pr.timeout)
+	defer cancel()
+
+	var failures []error
+	for _, nodeURL := range nodeURLs {
+		data, responseTime, err := pr.request(ctx, nodeURL, method, params)
+		if nodeFailure(err) != nil {
+			failures = append(failures, fmt.Errorf("node %s: %w", nodeURL, err))
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("request timed out: %w", errors.Join(failures...))
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &NodeResponse{
+			Data:         data,
+			ResponseTime: responseTime,
+			NodeURL:      nodeURL,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("all nodes failed: %w", errors.Join(failures...))
+}
+
 // RequestWithRetry makes a request with automatic retry on failure
 func (pr *ParallelRequester) RequestWithRetry(ctx context.Context, nodeURL string, method string, params []byte, maxRetries int) (*NodeResponse, error) {
 	var lastError error
@@ -268,7 +546,10 @@ func (pr *ParallelRequester) RequestWithRetry(ctx context.Context, nodeURL strin
 	return nil, fmt.Errorf("request failed after %d attempts: %w", maxRetries, lastError)
 }
 
-// BatchRequest makes parallel requests for multiple methods
+// BatchRequest makes parallel requests for multiple methods.
+// Responses are returned in the same order as requests; a request that did not
+// complete carries the error in its NodeResponse. Outcomes are reported to the
+// circuit breaker, but nodes are not filtered: the caller picks them.
 func (pr *ParallelRequester) BatchRequest(ctx context.Context, nodeURL string, requests []NodeRequest) ([]*NodeResponse, error) {
 	if len(requests) == 0 {
 		return nil, errors.New("no requests provided")
@@ -278,8 +559,8 @@ func (pr *ParallelRequester) BatchRequest(ctx context.Context, nodeURL string, r
 	ctx, cancel := context.WithTimeout(ctx, pr.timeout)
 	defer cancel()
 
-	// Channel to receive responses
-	responseChan := make(chan *NodeResponse, len(requests))
+	// Each goroutine writes only its own slot, so no locking is needed
+	responses := make([]*NodeResponse, len(requests))
 
 	// WaitGroup to track all goroutines
 	var wg sync.WaitGroup
@@ -290,34 +571,26 @@ func (pr *ParallelRequester) BatchRequest(ctx context.Context, nodeURL string, r
 		go func(index int, request NodeRequest) {
 			defer wg.Done()
 
+			// Requests without their own node use the batch node
+			url := request.NodeURL
+			if url == "" {
+				url = nodeURL
+			}
+
 			// Make request
-			data, responseTime, err := pr.requestFunc(ctx, request.NodeURL, request.Method, request.Params)
+			data, responseTime, err := pr.request(ctx, url, request.Method, request.Params)
 
-			// Send response with index to preserve order
-			select {
-			case responseChan <- &NodeResponse{
+			// Store response by index to preserve order
+			responses[index] = &NodeResponse{
 				Data:         data,
 				ResponseTime: responseTime,
-				NodeURL:      request.NodeURL,
+				NodeURL:      url,
 				Error:        err,
-			}:
-			case <-ctx.Done():
-				return
 			}
 		}(i, req)
 	}
 
-	// Close channel when all goroutines finish
-	go func() {
-		wg.Wait()
-		close(responseChan)
-	}()
-
-	// Collect all responses
-	var responses []*NodeResponse
-	for response := range responseChan {
-		responses = append(responses, response)
-	}
+	wg.Wait()
 
 	return responses, nil
 }
@@ -0,0 +1,286 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeNodes answers requests per node URL and records which nodes were called
+type fakeNodes struct {
+	mu      sync.Mutex
+	calls   []string
+	handler map[string]func(ctx context.Context) ([]byte, error)
+	done    chan string // receives a node URL when its request returns
+}
+
+func newFakeNodes(handler map[string]func(ctx context.Context) ([]byte, error)) *fakeNodes {
+	return &fakeNodes{handler: handler, done: make(chan string, len(handler))}
+}
+
+func (f *fakeNodes) request(ctx context.Context, nodeURL string, method string, params []byte) ([]byte, int64, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, nodeURL)
+	f.mu.Unlock()
+
+	data, err := f.handler[nodeURL](ctx)
+	f.done <- nodeURL
+	return data, 0, err
+}
+
+func (f *fakeNodes) called() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+// wait waits until every called node's request has returned
+func (f *fakeNodes) wait(t *testing.T) {
+	t.Helper()
+	for range f.called() {
+		select {
+		case <-f.done:
+		case <-time.After(time.Second):
+			t.Fatal("node request did not return")
+		}
+	}
+}
+
+func reply(data string) func(ctx context.Context) ([]byte, error) {
+	return func(ctx context.Context) ([]byte, error) {
+		return []byte(data), nil
+	}
+}
+
+func fail(ctx context.Context) ([]byte, error) {
+	return nil, errNode
+}
+
+// hang blocks until the request is cancelled and reports it like an HTTP client
+// that does not wrap the context error
+func hang(ctx context.Context) ([]byte, error) {
+	<-ctx.Done()
+	return nil, fmt.Errorf("post: %v", ctx.Err())
+}
+
+func newHedgedRequester(nodes *fakeNodes, delay time.Duration) *ParallelRequester {
+	pr := NewParallelRequester(nodes.request, time.Second)
+	pr.SetHedgeConfig(HedgeConfig{
+		Percentile:   0.95,
+		MinSamples:   1000,
+		DefaultDelay: delay,
+		MinDelay:     time.Millisecond,
+	})
+	return pr
+}
+
+func TestRequestHedgedFastNode(t *testing.T) {
+	nodes := newFakeNodes(map[string]func(ctx context.Context) ([]byte, error){
+		"node-1": reply("one"),
+		"node-2": reply("two"),
+	})
+	pr := newHedgedRequester(nodes, 50*time.Millisecond)
+
+	resp, err := pr.RequestHedged(context.Background(), []string{"node-1", "node-2"}, "eth_call", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.NodeURL != "node-1" || string(resp.Data) != "one" {
+		t.Errorf("reply from %s: %s, want node-1: one", resp.NodeURL, resp.Data)
+	}
+
+	time.Sleep(80 * time.Millisecond)
+	if calls := nodes.called(); len(calls) != 1 {
+		t.Errorf("called %v, want only node-1", calls)
+	}
+}
+
+func TestRequestHedgedSlowNode(t *testing.T) {
+	nodes := newFakeNodes(map[string]func(ctx context.Context) ([]byte, error){
+		"node-1": hang,
+		"node-2": reply("two"),
+	})
+	pr := newHedgedRequester(nodes, 20*time.Millisecond)
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{WindowSize: 1, MinRequests: 1, ErrorRateThreshold: 1})
+	pr.SetCircuitBreaker(breaker)
+
+	start := time.Now()
+	resp, err := pr.RequestHedged(context.Background(), []string{"node-1", "node-2"}, "eth_call", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.NodeURL != "node-2" {
+		t.Errorf("reply from %s, want the backup node-2", resp.NodeURL)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("backup sent after %v, before the hedge delay", elapsed)
+	}
+
+	// The losing request is cancelled and its outcome is not held against the node
+	nodes.wait(t)
+	if state := breaker.State("node-1"); state != CircuitClosed {
+		t.Errorf("cancelled node-1 circuit = %s, want closed", state)
+	}
+	if _, ok := pr.latencies.Percentile("eth_call", 1, 2); ok {
+		t.Error("latency of the cancelled request was recorded")
+	}
+	if _, ok := pr.latencies.Percentile("eth_call", 1, 1); !ok {
+		t.Error("latency of the winning request was not recorded")
+	}
+}
+
+func TestRequestHedgedFailover(t *testing.T) {
+	nodes := newFakeNodes(map[string]func(ctx context.Context) ([]byte, error){
+		"node-1": fail,
+		"node-2": reply("two"),
+	})
+	// A failure fails over at once instead of waiting for the hedge delay
+	pr := newHedgedRequester(nodes, time.Hour)
+
+	resp, err := pr.RequestHedged(context.Background(), []string{"node-1", "node-2"}, "eth_call", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.NodeURL != "node-2" {
+		t.Errorf("reply from %s, want node-2", resp.NodeURL)
+	}
+}
+
+func TestRequestHedgedAllFail(t *testing.T) {
+	nodes := newFakeNodes(map[string]func(ctx context.Context) ([]byte, error){
+		"node-1": fail,
+		"node-2": fail,
+	})
+	pr := newHedgedRequester(nodes, time.Hour)
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{WindowSize: 1, MinRequests: 1, ErrorRateThreshold: 1})
+	pr.SetCircuitBreaker(breaker)
+
+	_, err := pr.RequestHedged(context.Background(), []string{"node-1", "node-2"}, "eth_call", nil)
+	if err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("err = %v, want the node errors", err)
+	}
+	for _, url := range []string{"node-1", "node-2"} {
+		if state := breaker.State(url); state != CircuitOpen {
+			t.Errorf("failed %s circuit = %s, want open", url, state)
+		}
+	}
+
+	// Every circuit is open now
+	_, err = pr.RequestHedged(context.Background(), []string{"node-1", "node-2"}, "eth_call", nil)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("err = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestRequestSequentialWaitsForSlowNode(t *testing.T) {
+	nodes := newFakeNodes(map[string]func(ctx context.Context) ([]byte, error){
+		"node-1": func(ctx context.Context) ([]byte, error) {
+			time.Sleep(30 * time.Millisecond)
+			return []byte("one"), nil
+		},
+		"node-2": reply("two"),
+	})
+	// Far below node-1's latency: a hedged request would reach node-2
+	pr := newHedgedRequester(nodes, time.Millisecond)
+
+	resp, err := pr.RequestSequential(context.Background(), []string{"node-1", "node-2"}, "eth_sendRawTransaction", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.NodeURL != "node-1" || string(resp.Data) != "one" {
+		t.Errorf("reply from %s: %s, want node-1: one", resp.NodeURL, resp.Data)
+	}
+	if calls := nodes.called(); len(calls) != 1 {
+		t.Errorf("called %v, want only node-1", calls)
+	}
+}
+
+func TestRequestSequentialFailover(t *testing.T) {
+	nodes := newFakeNodes(map[string]func(ctx context.Context) ([]byte, error){
+		"node-1": fail,
+		"node-2": reply("two"),
+	})
+	pr := NewParallelRequester(nodes.request, time.Second)
+
+	resp, err := pr.RequestSequential(context.Background(), []string{"node-1", "node-2"}, "eth_sendRawTransaction", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.NodeURL != "node-2" {
+		t.Errorf("reply from %s, want node-2 after node-1 failed", resp.NodeURL)
+	}
+}
+
+func TestRequestHedgedReturnsRejection(t *testing.T) {
+	rejection := &ReplyError{StatusCode: 400, ContentType: "application/json", Body: []byte(`{"error":{}}`)}
+	nodes := newFakeNodes(map[string]func(ctx context.Context) ([]byte, error){
+		"node-1": func(ctx context.Context) ([]byte, error) { return nil, rejection },
+		"node-2": reply("two"),
+	})
+	pr := newHedgedRequester(nodes, 50*time.Millisecond)
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{WindowSize: 1, MinRequests: 1, ErrorRateThreshold: 1})
+	pr.SetCircuitBreaker(breaker)
+
+	_, err := pr.RequestHedged(context.Background(), []string{"node-1", "node-2"}, "eth_call", nil)
+	var reply *ReplyError
+	if !errors.As(err, &reply) || reply.StatusCode != 400 {
+		t.Fatalf("err = %v, want the node's 400 reply", err)
+	}
+	if calls := nodes.called(); len(calls) != 1 {
+		t.Errorf("called %v, want no failover after a rejection", calls)
+	}
+	if state := breaker.State("node-1"); state != CircuitClosed {
+		t.Errorf("node-1 circuit = %s, want closed", state)
+	}
+}
+
+func TestRequestSequentialNodeFaults(t *testing.T) {
+	unavailable := func(ctx context.Context) ([]byte, error) {
+		return nil, &ReplyError{StatusCode: 503, Body: []byte("busy")}
+	}
+	nodes := newFakeNodes(map[string]func(ctx context.Context) ([]byte, error){
+		"node-1": unavailable,
+		"node-2": unavailable,
+	})
+	pr := NewParallelRequester(nodes.request, time.Second)
+
+	_, err := pr.RequestSequential(context.Background(), []string{"node-1", "node-2"}, "eth_sendRawTransaction", nil)
+	var reply *ReplyError
+	if !errors.As(err, &reply) || reply.StatusCode != 503 {
+		t.Fatalf("err = %v, want the nodes' 503 replies", err)
+	}
+	if calls := nodes.called(); len(calls) != 2 {
+		t.Errorf("called %v, want both nodes", calls)
+	}
+}
+
+func TestHedgeDelay(t *testing.T) {
+	pr := NewParallelRequester(nil, time.Second)
+	pr.SetHedgeConfig(HedgeConfig{
+		Percentile:   0.5,
+		MinSamples:   4,
+		DefaultDelay: 300 * time.Millisecond,
+		MinDelay:     10 * time.Millisecond,
+	})
+
+	if delay := pr.hedgeDelay("eth_call"); delay != 300*time.Millisecond {
+		t.Errorf("delay without samples = %v, want the default", delay)
+	}
+
+	for _, ms := range []int{40, 10, 30, 20} {
+		pr.latencies.Observe("eth_call", time.Duration(ms)*time.Millisecond)
+	}
+	if delay := pr.hedgeDelay("eth_call"); delay != 20*time.Millisecond {
+		t.Errorf("delay = %v, want the median 20ms", delay)
+	}
+
+	for i := 0; i < 4; i++ {
+		pr.latencies.Observe("eth_chainId", time.Millisecond)
+	}
+	if delay := pr.hedgeDelay("eth_chainId"); delay != 10*time.Millisecond {
+		t.Errorf("delay = %v, want the minimum 10ms", delay)
+	}
+}
@@ -0,0 +1,240 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// quotaDirtyKey is the set of users with usage not yet flushed to the database
+	quotaDirtyKey = "quota:dirty"
+	// quotaGrace keeps a period counter readable for a while after the period ends
+	quotaGrace = 24 * time.Hour
+	// callWindowTTL keeps a per-second call counter just past its second
+	callWindowTTL = 2 * time.Second
+	// batchIDField holds the ID of a drained batch in its hash; usage fields never start with '#'
+	batchIDField = "#id"
+)
+
+// takeBatchScript returns the user's unacknowledged batch, or turns the pending
+// deltas into a new batch with the ID in ARGV[1]. KEYS: pending, batch.
+var takeBatchScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 0 then
+	if redis.call('EXISTS', KEYS[1]) == 0 then
+		return {}
+	end
+	redis.call('RENAME', KEYS[1], KEYS[2])
+	redis.call('HSET', KEYS[2], '` + batchIDField + `', ARGV[1])
+end
+return redis.call('HGETALL', KEYS[2])
+`)
+
+// ackBatchScript removes written fields (ARGV[3:]) from the batch with ID ARGV[1].
+// Once the batch is empty it is deleted, and the user (ARGV[2]) is marked dirty
+// again if usage was added meanwhile. KEYS: batch, pending, dirty set.
+var ackBatchScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], '` + batchIDField + `') ~= ARGV[1] then
+	return 0
+end
+for i = 3, #ARGV do
+	redis.call('HDEL', KEYS[1], ARGV[i])
+end
+if redis.call('HLEN', KEYS[1]) <= 1 then
+	redis.call('DEL', KEYS[1])
+	if redis.call('EXISTS', KEYS[2]) == 1 then
+		redis.call('SADD', KEYS[3], ARGV[2])
+	end
+end
+return 1
+`)
+
+// UsageBatch is a user's drained usage. It stays in Redis under the same ID until
+// every part of it is acknowledged, so each attempt to write a part sends the same
+// idempotency key; usage added meanwhile waits for the next batch.
+type UsageBatch struct {
+	ID      string
+	Credits map[string]int64 // by UsageField
+}
+
+// IdempotencyKey returns the key for writing the credits the batch spent with apiKeyID
+func (b *UsageBatch) IdempotencyKey(apiKeyID string) string {
+	return b.ID + "/" + apiKeyID
+}
+
+// QuotaCounter keeps per-user and per-API-key credit usage for the current billing period in Redis.
+// Every increment is also added to a pending delta per API key and blockchain that a
+// background job drains into the database, so the request path never writes to Postgres.
+type QuotaCounter struct {
+	redis *RedisClient
+}
+
+func NewQuotaCounter(redis *RedisClient) *QuotaCounter {
+	return &QuotaCounter{redis: redis}
+}
+
+func quotaUsedKey(userID string, periodStart time.Time) string {
+	return fmt.Sprintf("quota:used:%s:%d", userID, periodStart.Unix())
+}
+
+func quotaPendingKey(userID string) string {
+	return fmt.Sprintf("quota:pending:%s", userID)
+}
+
+func quotaBatchKey(userID string) string {
+	return fmt.Sprintf("quota:batch:%s", userID)
+}
+
+func keyUsedKey(apiKeyID string, periodStart time.Time) string {
+	return fmt.Sprintf("quota:key:%s:%d", apiKeyID, periodStart.Unix())
+}
+
+func keyCallsKey(apiKeyID string, second int64) string {
+	return fmt.Sprintf("quota:calls:%s:%d", apiKeyID, second)
+}
+
+// Used returns the credits the user has consumed in the period starting at periodStart
+func (q *QuotaCounter) Used(ctx context.Context, userID string, periodStart time.Time) (int64, error) {
+	used, err := q.redis.client.Get(ctx, quotaUsedKey(userID, periodStart)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return used, err
+}
+
+// KeyUsed returns the credits spent with an API key in the period starting at periodStart
+func (q *QuotaCounter) KeyUsed(ctx context.Context, apiKeyID string, periodStart time.Time) (int64, error) {
+	used, err := q.redis.client.Get(ctx, keyUsedKey(apiKeyID, periodStart)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return used, err
+}
+
+// CountCall counts a call made with an API key and returns the calls made with it
+// in now's second, this one included
+func (q *QuotaCounter) CountCall(ctx context.Context, apiKeyID string, now time.Time) (int64, error) {
+	key := keyCallsKey(apiKeyID, now.Unix())
+
+	var calls *redis.IntCmd
+	_, err := q.redis.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		calls = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, callWindowTTL)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return calls.Val(), nil
+}
+
+// UsageField names a pending delta: the blockchain, prefixed with the API key that spent the credits if any
+func UsageField(apiKeyID, blockchain string) string {
+	if apiKeyID == "" {
+		return blockchain
+	}
+	return apiKeyID + "/" + blockchain
+}
+
+// SplitUsageField returns the API key and blockchain of a pending delta
+func SplitUsageField(field string) (apiKeyID, blockchain string) {
+	if i := strings.LastIndex(field, "/"); i >= 0 {
+		return field[:i], field[i+1:]
+	}
+	return "", field
+}
+
+// Add charges credits spent with an API key on a blockchain to the user's and the
+// key's period counters and returns the user's new total. The counters expire a day after periodEnd.
+func (q *QuotaCounter) Add(ctx context.Context, userID, apiKeyID, blockchain string, periodStart, periodEnd time.Time, credits int64) (int64, error) {
+	usedKey := quotaUsedKey(userID, periodStart)
+
+	var used *redis.IntCmd
+	_, err := q.redis.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		used = pipe.IncrBy(ctx, usedKey, credits)
+		pipe.ExpireAt(ctx, usedKey, periodEnd.Add(quotaGrace))
+		if apiKeyID != "" {
+			keyKey := keyUsedKey(apiKeyID, periodStart)
+			pipe.IncrBy(ctx, keyKey, credits)
+			pipe.ExpireAt(ctx, keyKey, periodEnd.Add(quotaGrace))
+		}
+		pipe.HIncrBy(ctx, quotaPendingKey(userID), UsageField(apiKeyID, blockchain), credits)
+		pipe.SAdd(ctx, quotaDirtyKey, userID)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return used.Val(), nil
+}
+
+// Drain takes up to max users with pending usage and returns their batches.
+// A batch stays in Redis until Ack; call Restore if writing it fails.
+func (q *QuotaCounter) Drain(ctx context.Context, max int64) (map[string]*UsageBatch, error) {
+	userIDs, err := q.redis.client.SPopN(ctx, quotaDirtyKey, max).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	batches := make(map[string]*UsageBatch, len(userIDs))
+	for i, userID := range userIDs {
+		// A concurrent Add re-marks the user dirty, so nothing is lost between SPOP and taking the batch
+		values, err := takeBatchScript.Run(ctx, q.redis.client,
+			[]string{quotaPendingKey(userID), quotaBatchKey(userID)}, uuid.NewString()).StringSlice()
+		if err != nil {
+			// Leave the rest for the next drain
+			q.redis.client.SAdd(ctx, quotaDirtyKey, toMembers(userIDs[i:])...)
+			return batches, err
+		}
+
+		batch := &UsageBatch{Credits: make(map[string]int64, len(values)/2)}
+		for j := 0; j+1 < len(values); j += 2 {
+			field, value := values[j], values[j+1]
+			if field == batchIDField {
+				batch.ID = value
+				continue
+			}
+			if credits, err := strconv.ParseInt(value, 10, 64); err == nil && credits > 0 {
+				batch.Credits[field] = credits
+			}
+		}
+		if batch.ID != "" && len(batch.Credits) > 0 {
+			batches[userID] = batch
+		}
+	}
+
+	return batches, nil
+}
+
+// Ack removes the written fields from the user's batch
+func (q *QuotaCounter) Ack(ctx context.Context, userID string, batch *UsageBatch, fields []string) error {
+	args := make([]interface{}, 0, len(fields)+2)
+	args = append(args, batch.ID, userID)
+	for _, field := range fields {
+		args = append(args, field)
+	}
+
+	return ackBatchScript.Run(ctx, q.redis.client,
+		[]string{quotaBatchKey(userID), quotaPendingKey(userID), quotaDirtyKey}, args...).Err()
+}
+
+// Restore marks a user whose batch could not be fully written for another attempt.
+// The batch keeps its ID, so parts that did get written are not counted twice.
+func (q *QuotaCounter) Restore(ctx context.Context, userID string) error {
+	return q.redis.client.SAdd(ctx, quotaDirtyKey, userID).Err()
+}
+
+func toMembers(values []string) []interface{} {
+	members := make([]interface{}, len(values))
+	for i, value := range values {
+		members[i] = value
+	}
+	return members
+}
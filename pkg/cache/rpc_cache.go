@@ -0,0 +1,302 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ironnode/pkg/jsonrpc"
+)
+
+// cachePolicy describes when the result of a method may be cached
+type cachePolicy int
+
+const (
+	// immutable results never change (chain constants, hash-addressed data)
+	immutable cachePolicy = iota + 1
+	// finalized results never change once their block is FinalityDepth blocks deep
+	finalized
+	// headOnly results change with every new block
+	headOnly
+)
+
+// methodPolicies lists cacheable methods; anything else (eth_sendRawTransaction,
+// eth_call, eth_getBalance, ...) always goes upstream
+var methodPolicies = map[string]cachePolicy{
+	"eth_chainId":                           immutable,
+	"net_version":                           immutable,
+	"eth_getBlockByHash":                    immutable,
+	"eth_getBlockTransactionCountByHash":    immutable,
+	"eth_getTransactionByBlockHashAndIndex": immutable,
+	"eth_getUncleByBlockHashAndIndex":       immutable,
+	"eth_getUncleCountByBlockHash":          immutable,
+	"eth_getBlockByNumber":                  finalized,
+	"eth_getBlockReceipts":                  finalized,
+	"eth_getTransactionByHash":              finalized,
+	"eth_getTransactionReceipt":             finalized,
+	"eth_blockNumber":                       headOnly,
+}
+
+// blockParamMethods read the block given in their params. Their results are
+// final only for an explicit block number or hash, never for a tag such as
+// "latest" or "finalized" that moves to other blocks.
+var blockParamMethods = map[string]bool{
+	"eth_getBlockByNumber": true,
+	"eth_getBlockReceipts": true,
+}
+
+// readMethods lists methods that are never cached but only read chain state
+var readMethods = map[string]bool{
+	"eth_getTransactionByBlockNumberAndIndex": true,
+	"eth_getUncleByBlockNumberAndIndex":       true,
+	"eth_getBlockTransactionCountByNumber":    true,
+	"eth_getUncleCountByBlockNumber":          true,
+	"eth_call":                                true,
+	"eth_estimateGas":                         true,
+	"eth_getBalance":                          true,
+	"eth_getCode":                             true,
+	"eth_getStorageAt":                        true,
+	"eth_getTransactionCount":                 true,
+	"eth_getProof":                            true,
+	"eth_getLogs":                             true,
+	"eth_gasPrice":                            true,
+	"eth_maxPriorityFeePerGas":                true,
+	"eth_feeHistory":                          true,
+	"eth_syncing":                             true,
+	"net_listening":                           true,
+	"net_peerCount":                           true,
+	"web3_clientVersion":                      true,
+}
+
+// blockTimes is the TTL of head-dependent results per blockchain
+var blockTimes = map[string]time.Duration{
+	"ethereum":  12 * time.Second,
+	"polygon":   2 * time.Second,
+	"bsc":       3 * time.Second,
+	"avalanche": 2 * time.Second,
+}
+
+const (
+	defaultBlockTime = 2 * time.Second
+	// headTTL bounds how long a known head is used for finality checks
+	headTTL = 10 * time.Minute
+)
+
+// RPCCache caches JSON-RPC results in Redis according to per-method rules
+type RPCCache struct {
+	redis         *RedisClient
+	finalityDepth uint64
+}
+
+// NewRPCCache creates a cache; results of block-dependent methods are kept
+// only once their block is at least finalityDepth blocks below the head
+func NewRPCCache(redis *RedisClient, finalityDepth uint64) *RPCCache {
+	return &RPCCache{
+		redis:         redis,
+		finalityDepth: finalityDepth,
+	}
+}
+
+// Cacheable reports whether a method can ever be served from cache
+func (c *RPCCache) Cacheable(method string) bool {
+	_, ok := methodPolicies[method]
+	return ok
+}
+
+// Coalescable reports whether identical concurrent calls of a method may share
+// one upstream request. Only idempotent reads qualify: transactions, signing
+// and filters (eth_newFilter, eth_getFilterChanges, ...) always go upstream.
+func Coalescable(method string) bool {
+	_, ok := methodPolicies[method]
+	return ok || readMethods[method]
+}
+
+// Get returns the cached result of a call
+func (c *RPCCache) Get(ctx context.Context, blockchain, network, method string, params json.RawMessage) (json.RawMessage, bool) {
+	if !c.Cacheable(method) {
+		return nil, false
+	}
+
+	value, err := c.redis.Get(ctx, Key(blockchain, network, method, params))
+	if err != nil {
+		return nil, false
+	}
+
+	return json.RawMessage(value), true
+}
+
+// Store caches the result of an upstream response if the method's rule allows it.
+// Errors and null results are never cached.
+func (c *RPCCache) Store(ctx context.Context, blockchain, network, method string, params json.RawMessage, response []byte) error {
+	policy, ok := methodPolicies[method]
+	if !ok {
+		return nil
+	}
+
+	var resp jsonrpc.Response
+	if err := json.Unmarshal(response, &resp); err != nil {
+		return err
+	}
+	if resp.Error != nil || len(resp.Result) == 0 || bytes.Equal(resp.Result, []byte("null")) {
+		return nil
+	}
+
+	var ttl time.Duration
+
+	switch policy {
+	case immutable:
+		ttl = 0 // no expiration
+
+	case headOnly:
+		ttl = blockTime(blockchain)
+		if height, ok := parseHex(resp.Result); ok {
+			c.setHead(ctx, blockchain, network, height)
+		}
+
+	case finalized:
+		if !c.isFinal(ctx, blockchain, network, method, params, resp.Result) {
+			return nil
+		}
+		ttl = 0
+	}
+
+	return c.redis.Set(ctx, Key(blockchain, network, method, params), []byte(resp.Result), ttl)
+}
+
+// isFinal reports whether the block a result belongs to is deep enough to never change
+func (c *RPCCache) isFinal(ctx context.Context, blockchain, network, method string, params, result json.RawMessage) bool {
+	number, ok := blockNumber(method, params, result)
+	if !ok {
+		return false
+	}
+
+	head, ok := c.head(ctx, blockchain, network)
+	if !ok {
+		return false
+	}
+
+	return number+c.finalityDepth <= head
+}
+
+// Head returns the chain's latest block number seen in an eth_blockNumber reply
+func (c *RPCCache) Head(ctx context.Context, blockchain, network string) (uint64, bool) {
+	return c.head(ctx, blockchain, network)
+}
+
+func (c *RPCCache) head(ctx context.Context, blockchain, network string) (uint64, bool) {
+	value, err := c.redis.Get(ctx, headKey(blockchain, network))
+	if err != nil {
+		return 0, false
+	}
+
+	head, err := strconv.ParseUint(value, 10, 64)
+	return head, err == nil
+}
+
+func (c *RPCCache) setHead(ctx context.Context, blockchain, network string, head uint64) {
+	_ = c.redis.Set(ctx, headKey(blockchain, network), head, headTTL)
+}
+
+// Key builds the cache key of a call from its method and normalized params
+func Key(blockchain, network, method string, params json.RawMessage) string {
+	sum := sha256.Sum256(normalizeParams(params))
+	return fmt.Sprintf("rpc:%s:%s:%s:%x", blockchain, network, method, sum[:16])
+}
+
+func headKey(blockchain, network string) string {
+	return fmt.Sprintf("rpc:head:%s:%s", blockchain, network)
+}
+
+// normalizeParams re-encodes params so that equivalent calls share a key:
+// whitespace and object key order are dropped and hex strings are lowercased
+func normalizeParams(params json.RawMessage) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || value == nil {
+		return []byte("[]")
+	}
+
+	normalized, err := json.Marshal(normalizeValue(value))
+	if err != nil {
+		return params
+	}
+
+	return normalized
+}
+
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(v, "0x") || strings.HasPrefix(v, "0X") {
+			return strings.ToLower(v)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = normalizeValue(v[i])
+		}
+		return v
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = normalizeValue(v[key])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// blockNumber finds the block a result belongs to: the block number param if
+// the call has one, otherwise the "blockNumber" or "number" field of the result.
+// Calls of a block param method that name a block by tag have none.
+func blockNumber(method string, params, result json.RawMessage) (uint64, bool) {
+	tag := jsonrpc.BlockTag(method, params)
+	if strings.HasPrefix(tag, "0x") {
+		if number, err := strconv.ParseUint(tag[2:], 16, 64); err == nil {
+			return number, true
+		}
+	} else if blockParamMethods[method] {
+		return 0, false
+	}
+
+	var fields struct {
+		BlockNumber string `json:"blockNumber"`
+		Number      string `json:"number"`
+	}
+	if err := json.Unmarshal(result, &fields); err != nil {
+		return 0, false
+	}
+
+	for _, value := range []string{fields.BlockNumber, fields.Number} {
+		if number, err := strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 64); value != "" && err == nil {
+			return number, true
+		}
+	}
+
+	return 0, false
+}
+
+// parseHex decodes a JSON hex quantity such as "0x10d4f"
+func parseHex(raw json.RawMessage) (uint64, bool) {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return 0, false
+	}
+
+	number, err := strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 64)
+	return number, err == nil
+}
+
+func blockTime(blockchain string) time.Duration {
+	if ttl, ok := blockTimes[blockchain]; ok {
+		return ttl
+	}
+	return defaultBlockTime
+}
@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestKeyNormalization(t *testing.T) {
+	base := Key("ethereum", "mainnet", "eth_getBlockByHash", json.RawMessage(`["0xabc", false]`))
+
+	same := []struct {
+		name   string
+		params string
+	}{
+		{"whitespace", "[ \"0xabc\" ,\n false ]"},
+		{"hex case", `["0xABC", false]`},
+		{"uppercase hex prefix", `["0XAbC", false]`},
+	}
+	for _, tt := range same {
+		if key := Key("ethereum", "mainnet", "eth_getBlockByHash", json.RawMessage(tt.params)); key != base {
+			t.Errorf("%s: key %s differs from %s", tt.name, key, base)
+		}
+	}
+
+	objects := Key("ethereum", "mainnet", "eth_call", json.RawMessage(`[{"to": "0xAB", "data": "0x01"}, "latest"]`))
+	reordered := Key("ethereum", "mainnet", "eth_call", json.RawMessage(`[{"data":"0x01","to":"0xab"},"latest"]`))
+	if objects != reordered {
+		t.Error("object key order changes the key")
+	}
+
+	if Key("ethereum", "mainnet", "eth_blockNumber", nil) != Key("ethereum", "mainnet", "eth_blockNumber", json.RawMessage(`[]`)) {
+		t.Error("missing params and empty params have different keys")
+	}
+
+	different := []struct {
+		name                        string
+		blockchain, network, method string
+		params                      string
+	}{
+		{"params", "ethereum", "mainnet", "eth_getBlockByHash", `["0xabd", false]`},
+		{"flag", "ethereum", "mainnet", "eth_getBlockByHash", `["0xabc", true]`},
+		{"method", "ethereum", "mainnet", "eth_getBlockByNumber", `["0xabc", false]`},
+		{"network", "ethereum", "sepolia", "eth_getBlockByHash", `["0xabc", false]`},
+		{"blockchain", "polygon", "mainnet", "eth_getBlockByHash", `["0xabc", false]`},
+		{"non-hex case", "ethereum", "mainnet", "eth_getBlockByHash", `["ABC", false]`},
+	}
+	for _, tt := range different {
+		if Key(tt.blockchain, tt.network, tt.method, json.RawMessage(tt.params)) == base {
+			t.Errorf("%s: different call shares the key", tt.name)
+		}
+	}
+}
+
+func TestRPCCacheStore(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		params   string
+		response string
+		head     string // eth_blockNumber result stored first; "" for no known head
+		cached   bool
+		ttl      time.Duration
+	}{
+		{"immutable", "eth_chainId", `[]`, `{"jsonrpc":"2.0","id":1,"result":"0x1"}`, "", true, 0},
+		{"uncacheable method", "eth_call", `[{"to":"0xabc"},"0x10"]`, `{"jsonrpc":"2.0","id":1,"result":"0x"}`, "", false, 0},
+		{"error", "eth_chainId", `[]`, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"oops"}}`, "", false, 0},
+		{"null result", "eth_getBlockByHash", `["0xabc",false]`, `{"jsonrpc":"2.0","id":1,"result":null}`, "", false, 0},
+		{"head only", "eth_blockNumber", `[]`, `{"jsonrpc":"2.0","id":1,"result":"0x64"}`, "", true, 12 * time.Second},
+		{"finalized without head", "eth_getBlockByNumber", `["0x10",false]`, `{"jsonrpc":"2.0","id":1,"result":{"number":"0x10"}}`, "", false, 0},
+		{"finalized deep block", "eth_getBlockByNumber", `["0x10",false]`, `{"jsonrpc":"2.0","id":1,"result":{"number":"0x10"}}`, "0x1000", true, 0},
+		{"finalized at depth", "eth_getBlockByNumber", `["0x10",false]`, `{"jsonrpc":"2.0","id":1,"result":{"number":"0x10"}}`, "0x50", true, 0},
+		{"recent block", "eth_getBlockByNumber", `["0x10",false]`, `{"jsonrpc":"2.0","id":1,"result":{"number":"0x10"}}`, "0x4f", false, 0},
+		{"latest block", "eth_getBlockByNumber", `["latest",false]`, `{"jsonrpc":"2.0","id":1,"result":{"number":"0x4f"}}`, "0x50", false, 0},
+		{"finalized tag", "eth_getBlockByNumber", `["finalized",false]`, `{"jsonrpc":"2.0","id":1,"result":{"number":"0x10"}}`, "0x1000", false, 0},
+		{"safe tag", "eth_getBlockByNumber", `["safe",false]`, `{"jsonrpc":"2.0","id":1,"result":{"number":"0x10"}}`, "0x1000", false, 0},
+		{"deep latest block", "eth_getBlockByNumber", `["latest",false]`, `{"jsonrpc":"2.0","id":1,"result":{"number":"0x10"}}`, "0x1000", false, 0},
+		{"missing block param", "eth_getBlockByNumber", `[]`, `{"jsonrpc":"2.0","id":1,"result":{"number":"0x10"}}`, "0x1000", false, 0},
+		{"receipts by tag", "eth_getBlockReceipts", `["finalized"]`, `{"jsonrpc":"2.0","id":1,"result":[{"blockNumber":"0x10"}]}`, "0x1000", false, 0},
+		{"receipts by number", "eth_getBlockReceipts", `["0x10"]`, `{"jsonrpc":"2.0","id":1,"result":[{"blockNumber":"0x10"}]}`, "0x1000", true, 0},
+		{"deep receipt", "eth_getTransactionReceipt", `["0xfeed"]`, `{"jsonrpc":"2.0","id":1,"result":{"blockNumber":"0x1"}}`, "0x50", true, 0},
+		{"recent receipt", "eth_getTransactionReceipt", `["0xfeed"]`, `{"jsonrpc":"2.0","id":1,"result":{"blockNumber":"0x40"}}`, "0x50", false, 0},
+		{"pending transaction", "eth_getTransactionByHash", `["0xfeed"]`, `{"jsonrpc":"2.0","id":1,"result":{"blockNumber":null}}`, "0x50", false, 0},
+	}
+
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redis, client := newFakeRedis(t)
+			// Blocks are final 64 blocks below the head (0x50 - 0x10 = 64)
+			rpcCache := NewRPCCache(client, 64)
+
+			if tt.head != "" {
+				head := `{"jsonrpc":"2.0","id":1,"result":"` + tt.head + `"}`
+				if err := rpcCache.Store(ctx, "ethereum", "mainnet", "eth_blockNumber", nil, []byte(head)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := rpcCache.Store(ctx, "ethereum", "mainnet", tt.method, json.RawMessage(tt.params), []byte(tt.response)); err != nil {
+				t.Fatal(err)
+			}
+
+			result, ok := rpcCache.Get(ctx, "ethereum", "mainnet", tt.method, json.RawMessage(tt.params))
+			if ok != tt.cached {
+				t.Fatalf("cached = %v, want %v", ok, tt.cached)
+			}
+			if !ok {
+				return
+			}
+
+			var resp struct{ Result json.RawMessage }
+			if err := json.Unmarshal([]byte(tt.response), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if string(result) != string(resp.Result) {
+				t.Errorf("cached result = %s, want %s", result, resp.Result)
+			}
+			if ttl := redis.ttl(Key("ethereum", "mainnet", tt.method, json.RawMessage(tt.params))); ttl != tt.ttl {
+				t.Errorf("ttl = %v, want %v", ttl, tt.ttl)
+			}
+		})
+	}
+}
+
+func TestRPCCacheHead(t *testing.T) {
+	redis, client := newFakeRedis(t)
+	rpcCache := NewRPCCache(client, 64)
+	ctx := context.Background()
+
+	err := rpcCache.Store(ctx, "polygon", "mainnet", "eth_blockNumber", nil, []byte(`{"jsonrpc":"2.0","id":1,"result":"0x10d4f"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	head, ok := rpcCache.head(ctx, "polygon", "mainnet")
+	if !ok || head != 0x10d4f {
+		t.Errorf("head = %d, %v; want %d, true", head, ok, 0x10d4f)
+	}
+	if ttl := redis.ttl(headKey("polygon", "mainnet")); ttl != headTTL {
+		t.Errorf("head ttl = %v, want %v", ttl, headTTL)
+	}
+	if _, ok := rpcCache.head(ctx, "polygon", "amoy"); ok {
+		t.Error("networks share the head")
+	}
+}
+
+func TestCoalescable(t *testing.T) {
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{"eth_chainId", true},
+		{"eth_getBlockByNumber", true},
+		{"eth_call", true},
+		{"eth_getBalance", true},
+		{"eth_getLogs", true},
+		{"eth_sendRawTransaction", false},
+		{"eth_sendTransaction", false},
+		{"eth_sign", false},
+		{"eth_signTypedData_v4", false},
+		{"eth_newFilter", false},
+		{"eth_newBlockFilter", false},
+		{"eth_getFilterChanges", false},
+		{"eth_uninstallFilter", false},
+		{"eth_subscribe", false},
+		{"unknown_method", false},
+	}
+
+	for _, tt := range tests {
+		if got := Coalescable(tt.method); got != tt.want {
+			t.Errorf("Coalescable(%s) = %v, want %v", tt.method, got, tt.want)
+		}
+	}
+}
@@ -3,19 +3,35 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Environment string
-	Database    DatabaseConfig
-	Redis       RedisConfig
-	RabbitMQ    RabbitMQConfig
-	JWT         JWTConfig
-	Services    ServicesConfig
-	Email       EmailConfig
+	Environment    string
+	Database       DatabaseConfig
+	Redis          RedisConfig
+	RabbitMQ       RabbitMQConfig
+	JWT            JWTConfig
+	Services       ServicesConfig
+	Gateway        GatewayConfig
+	Email          EmailConfig
+	HealthCheck    HealthCheckConfig
+	NodeSelection  NodeSelectionConfig
+	CircuitBreaker CircuitBreakerConfig
+	Hedging        HedgingConfig
+	RPCCache       RPCCacheConfig
+	Quota          QuotaConfig
+	Billing        BillingConfig
+	Payment        PaymentConfig
+	Crypto         CryptoConfig
+	Ledger         LedgerConfig
+	RequestLog     RequestLogConfig
+	Analytics      AnalyticsConfig
+	APIKeys        APIKeysConfig
 }
 
 type DatabaseConfig struct {
@@ -47,22 +63,126 @@ type JWTConfig struct {
 }
 
 type ServicesConfig struct {
-	APIGatewayPort      string
-	AuthServicePort     string
-	UserServicePort     string
-	BlockchainPort      string
-	AnalyticsPort       string
-	BillingServicePort  string
+	APIGatewayPort     string
+	AuthServicePort    string
+	UserServicePort    string
+	BlockchainPort     string
+	AnalyticsPort      string
+	BillingServicePort string
+}
+
+type GatewayConfig struct {
+	TrustedProxies []string // IPs or CIDRs whose X-Forwarded-For is trusted; none by default
 }
 
 type EmailConfig struct {
 	From string
 }
 
+type HealthCheckConfig struct {
+	Interval         time.Duration
+	Timeout          time.Duration
+	FailureThreshold int
+}
+
+type NodeSelectionConfig struct {
+	MaxBlockLag     uint64
+	DefaultStrategy string
+	Strategies      map[string]string // blockchain type -> strategy
+}
+
+// StrategyFor returns the selection strategy configured for a blockchain type
+func (c *NodeSelectionConfig) StrategyFor(blockchainType string) string {
+	if strategy, ok := c.Strategies[blockchainType]; ok {
+		return strategy
+	}
+	return c.DefaultStrategy
+}
+
+type CircuitBreakerConfig struct {
+	WindowSize         int
+	MinRequests        int
+	ErrorRateThreshold float64
+	LatencyThreshold   time.Duration
+	Cooldown           time.Duration
+	HalfOpenRequests   int
+}
+
+type HedgingConfig struct {
+	Percentile   float64
+	MinSamples   int
+	DefaultDelay time.Duration
+	MinDelay     time.Duration
+}
+
+type RPCCacheConfig struct {
+	Enabled       bool
+	FinalityDepth uint64
+}
+
+type QuotaConfig struct {
+	Enabled       bool
+	FlushInterval time.Duration
+}
+
+type BillingConfig struct {
+	TrialPeriod      time.Duration // free trial of paid plans; 0 disables trials
+	RolloverInterval time.Duration // how often due billing periods are closed
+}
+
+type PaymentConfig struct {
+	Provider        string        // payment provider name; "mock" for local development
+	WebhookSecret   string        // shared secret of signed provider webhooks
+	DunningRetries  int           // charge retries of a failed invoice before the subscription is canceled
+	DunningInterval time.Duration // wait between retries
+}
+
+type CryptoConfig struct {
+	Enabled       bool          // USDT TRC20 top-ups; needs ENCRYPTION_KEY for the deposit wallets
+	TronNodeURL   string        // full node HTTP API
+	TronAPIURL    string        // TronGrid-compatible API with the TRC20 transfer history of addresses
+	TronAPIKey    string        // TronGrid API key; optional
+	Confirmations int           // blocks on top of a deposit before it is credited
+	PollInterval  time.Duration // how often deposit wallets are checked
+}
+
+type LedgerConfig struct {
+	CreditPrice float64 // USD per 1,000 prepaid credits bought from the prepaid balance
+}
+
+type RequestLogConfig struct {
+	Enabled    bool // record every proxied JSON-RPC call in Analytics Service
+	BufferSize int  // calls queued in memory before new ones are dropped
+	Workers    int
+}
+
+type AnalyticsConfig struct {
+	RollupInterval time.Duration // how often request logs are rolled up into usage buckets
+	RollupDelay    time.Duration // wait after a minute ends for its request logs to arrive
+
+	RetentionInterval time.Duration  // how often expired request logs are archived and purged
+	RetentionDays     map[string]int // plan type -> days raw request logs are kept
+	ArchiveDir        string         // where purged request logs are archived as gzipped NDJSON
+}
+
+type APIKeysConfig struct {
+	RotationGrace    time.Duration // how long a rotated key keeps working unless the rotation asks otherwise
+	MaxRotationGrace time.Duration // longest grace period a rotation may ask for
+}
+
 func Load() (*Config, error) {
 	// Load .env file if exists
 	_ = godotenv.Load()
 
+	maxBlockLag, err := getEnvUint("NODE_MAX_BLOCK_LAG", 5)
+	if err != nil {
+		return nil, err
+	}
+	finalityDepth, err := getEnvUint("RPC_CACHE_FINALITY_DEPTH", 64)
+	if err != nil {
+		return nil, err
+	}
+
 	config := &Config{
 		Environment: getEnv("ENVIRONMENT", "development"),
 		Database: DatabaseConfig{
@@ -97,14 +217,121 @@ func Load() (*Config, error) {
 			AnalyticsPort:      getEnv("ANALYTICS_SERVICE_PORT", "50055"),
 			BillingServicePort: getEnv("BILLING_SERVICE_PORT", "50056"),
 		},
+		Gateway: GatewayConfig{
+			TrustedProxies: getEnvList("TRUSTED_PROXIES"),
+		},
 		Email: EmailConfig{
 			From: getEnv("EMAIL_FROM", "noreply@ironnode.com"),
 		},
+		HealthCheck: HealthCheckConfig{
+			Interval:         getEnvDuration("HEALTH_CHECK_INTERVAL", 15*time.Second),
+			Timeout:          getEnvDuration("HEALTH_CHECK_TIMEOUT", 5*time.Second),
+			FailureThreshold: getEnvInt("HEALTH_CHECK_FAILURE_THRESHOLD", 3),
+		},
+		NodeSelection: NodeSelectionConfig{
+			MaxBlockLag:     maxBlockLag,
+			DefaultStrategy: getEnv("NODE_SELECTOR", "priority"),
+			Strategies:      getEnvMap("NODE_SELECTORS"),
+		},
+		CircuitBreaker: CircuitBreakerConfig{
+			WindowSize:         getEnvInt("CIRCUIT_BREAKER_WINDOW", 20),
+			MinRequests:        getEnvInt("CIRCUIT_BREAKER_MIN_REQUESTS", 10),
+			ErrorRateThreshold: getEnvFloat("CIRCUIT_BREAKER_ERROR_RATE", 0.5),
+			LatencyThreshold:   getEnvDuration("CIRCUIT_BREAKER_LATENCY_THRESHOLD", 5*time.Second),
+			Cooldown:           getEnvDuration("CIRCUIT_BREAKER_COOLDOWN", 30*time.Second),
+			HalfOpenRequests:   getEnvInt("CIRCUIT_BREAKER_HALF_OPEN_REQUESTS", 3),
+		},
+		Hedging: HedgingConfig{
+			Percentile:   getEnvFloat("HEDGE_PERCENTILE", 0.95),
+			MinSamples:   getEnvInt("HEDGE_MIN_SAMPLES", 20),
+			DefaultDelay: getEnvDuration("HEDGE_DEFAULT_DELAY", 300*time.Millisecond),
+			MinDelay:     getEnvDuration("HEDGE_MIN_DELAY", 10*time.Millisecond),
+		},
+		RPCCache: RPCCacheConfig{
+			Enabled:       getEnvBool("RPC_CACHE_ENABLED", true),
+			FinalityDepth: finalityDepth,
+		},
+		Quota: QuotaConfig{
+			Enabled:       getEnvBool("QUOTA_ENABLED", true),
+			FlushInterval: getEnvDuration("QUOTA_FLUSH_INTERVAL", 30*time.Second),
+		},
+		Billing: BillingConfig{
+			TrialPeriod:      getEnvDuration("BILLING_TRIAL_PERIOD", 0),
+			RolloverInterval: getEnvDuration("BILLING_ROLLOVER_INTERVAL", time.Minute),
+		},
+		Payment: PaymentConfig{
+			Provider:        getEnv("PAYMENT_PROVIDER", "mock"),
+			WebhookSecret:   getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+			DunningRetries:  getEnvInt("PAYMENT_DUNNING_RETRIES", 3),
+			DunningInterval: getEnvDuration("PAYMENT_DUNNING_INTERVAL", 72*time.Hour),
+		},
+		Crypto: CryptoConfig{
+			Enabled:       getEnvBool("CRYPTO_PAYMENTS_ENABLED", false),
+			TronNodeURL:   getEnv("TRON_NODE_URL", "http://78.46.94.60:8090"),
+			TronAPIURL:    getEnv("TRON_API_URL", "https://api.trongrid.io"),
+			TronAPIKey:    getEnv("TRON_API_KEY", ""),
+			Confirmations: getEnvInt("CRYPTO_CONFIRMATIONS", 19),
+			PollInterval:  getEnvDuration("CRYPTO_POLL_INTERVAL", 30*time.Second),
+		},
+		Ledger: LedgerConfig{
+			CreditPrice: getEnvFloat("LEDGER_CREDIT_PRICE", 0.50),
+		},
+		RequestLog: RequestLogConfig{
+			Enabled:    getEnvBool("REQUEST_LOG_ENABLED", true),
+			BufferSize: getEnvInt("REQUEST_LOG_BUFFER_SIZE", 10000),
+			Workers:    getEnvInt("REQUEST_LOG_WORKERS", 4),
+		},
+		Analytics: AnalyticsConfig{
+			RollupInterval: getEnvDuration("ANALYTICS_ROLLUP_INTERVAL", time.Minute),
+			RollupDelay:    getEnvDuration("ANALYTICS_ROLLUP_DELAY", 30*time.Second),
+
+			RetentionInterval: getEnvDuration("ANALYTICS_RETENTION_INTERVAL", time.Hour),
+			RetentionDays: getEnvIntMap("ANALYTICS_RETENTION_DAYS", map[string]int{
+				"free":         7,
+				"basic":        30,
+				"professional": 90,
+				"enterprise":   365,
+			}),
+			ArchiveDir: getEnv("ANALYTICS_ARCHIVE_DIR", "./archive/request_logs"),
+		},
+		APIKeys: APIKeysConfig{
+			RotationGrace:    getEnvDuration("API_KEY_ROTATION_GRACE", 24*time.Hour),
+			MaxRotationGrace: getEnvDuration("API_KEY_MAX_ROTATION_GRACE", 30*24*time.Hour),
+		},
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
 	}
 
 	return config, nil
 }
 
+// validate rejects settings the services cannot run with
+func (c *Config) validate() error {
+	// Tickers panic on non-positive intervals, and a zero timeout fails every probe
+	positive := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"HEALTH_CHECK_INTERVAL", c.HealthCheck.Interval},
+		{"HEALTH_CHECK_TIMEOUT", c.HealthCheck.Timeout},
+		{"QUOTA_FLUSH_INTERVAL", c.Quota.FlushInterval},
+		{"BILLING_ROLLOVER_INTERVAL", c.Billing.RolloverInterval},
+		{"PAYMENT_DUNNING_INTERVAL", c.Payment.DunningInterval},
+		{"CRYPTO_POLL_INTERVAL", c.Crypto.PollInterval},
+		{"ANALYTICS_ROLLUP_INTERVAL", c.Analytics.RollupInterval},
+		{"ANALYTICS_RETENTION_INTERVAL", c.Analytics.RetentionInterval},
+	}
+	for _, setting := range positive {
+		if setting.value <= 0 {
+			return fmt.Errorf("%s must be positive, got %v", setting.key, setting.value)
+		}
+	}
+
+	return nil
+}
+
 func (c *DatabaseConfig) DSN() string {
 	return fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
@@ -129,3 +356,85 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvUint is getEnvInt for counts that cannot be negative
+func getEnvUint(key string, defaultValue uint64) (uint64, error) {
+	value := getEnvInt(key, int(defaultValue))
+	if value < 0 {
+		return 0, fmt.Errorf("%s must not be negative, got %d", key, value)
+	}
+	return uint64(value), nil
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList parses "value1,value2" into a slice; nil if the variable is unset
+func getEnvList(key string) []string {
+	var result []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// getEnvMap parses "key1=value1,key2=value2" into a map
+func getEnvMap(key string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) == 2 && parts[0] != "" {
+			result[parts[0]] = parts[1]
+		}
+	}
+	return result
+}
+
+// getEnvIntMap parses "key1=1,key2=2" over a copy of defaultValue; invalid values are ignored
+func getEnvIntMap(key string, defaultValue map[string]int) map[string]int {
+	result := make(map[string]int, len(defaultValue))
+	for k, v := range defaultValue {
+		result[k] = v
+	}
+	for k, v := range getEnvMap(key) {
+		if parsed, err := strconv.Atoi(v); err == nil {
+			result[k] = parsed
+		}
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadRejectsNonPositiveIntervals(t *testing.T) {
+	for _, key := range []string{
+		"HEALTH_CHECK_INTERVAL",
+		"HEALTH_CHECK_TIMEOUT",
+		"QUOTA_FLUSH_INTERVAL",
+		"BILLING_ROLLOVER_INTERVAL",
+		"PAYMENT_DUNNING_INTERVAL",
+		"CRYPTO_POLL_INTERVAL",
+		"ANALYTICS_ROLLUP_INTERVAL",
+		"ANALYTICS_RETENTION_INTERVAL",
+	} {
+		for _, value := range []string{"0s", "-5s"} {
+			t.Run(key+"="+value, func(t *testing.T) {
+				t.Setenv(key, value)
+
+				_, err := Load()
+				if err == nil || !strings.Contains(err.Error(), key) {
+					t.Errorf("Load() error = %v, want one naming %s", err, key)
+				}
+			})
+		}
+	}
+}
+
+func TestLoadRejectsNegativeCounts(t *testing.T) {
+	for _, key := range []string{"NODE_MAX_BLOCK_LAG", "RPC_CACHE_FINALITY_DEPTH"} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, "-1")
+
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), key) {
+				t.Errorf("Load() error = %v, want one naming %s", err, key)
+			}
+		})
+	}
+}
+
+func TestLoadMaxBlockLag(t *testing.T) {
+	t.Setenv("NODE_MAX_BLOCK_LAG", "0")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.NodeSelection.MaxBlockLag != 0 {
+		t.Errorf("MaxBlockLag = %d, want 0", cfg.NodeSelection.MaxBlockLag)
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.HealthCheck.Interval <= 0 || cfg.HealthCheck.Timeout <= 0 {
+		t.Errorf("health check interval %v, timeout %v; want positive defaults", cfg.HealthCheck.Interval, cfg.HealthCheck.Timeout)
+	}
+}
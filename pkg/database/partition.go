@@ -0,0 +1,205 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// partitionDayLayout is the day suffix of daily partition names
+const partitionDayLayout = "20060102"
+
+// DailyPartition is one day of a table partitioned by day, in UTC
+type DailyPartition struct {
+	Name string
+	Day  time.Time
+}
+
+// End is the start of the next day, where the partition's range ends
+func (p DailyPartition) End() time.Time {
+	return p.Day.AddDate(0, 0, 1)
+}
+
+// DailyPartitionName names the partition of table holding day's rows, e.g. request_logs_p20240115
+func DailyPartitionName(table string, day time.Time) string {
+	return table + "_p" + day.UTC().Format(partitionDayLayout)
+}
+
+// DefaultPartitionName names the partition of table holding rows of days without a
+// partition of their own, e.g. request_logs_default
+func DefaultPartitionName(table string) string {
+	return table + "_default"
+}
+
+// IsPartitioned reports whether table is a partitioned table
+func IsPartitioned(db *gorm.DB, table string) (bool, error) {
+	var count int64
+	err := db.Raw(`SELECT count(*) FROM pg_partitioned_table p
+		JOIN pg_class c ON c.oid = p.partrelid
+		WHERE c.relname = ? AND pg_table_is_visible(c.oid)`, table).Scan(&count).Error
+	return count > 0, err
+}
+
+// CreateDailyPartitions creates the missing partitions of table for the days from `from` to `to`,
+// and the default partition. Rows that were written to the default partition
+// are moved into partitions of their days first, so no day's rows stay there.
+func CreateDailyPartitions(db *gorm.DB, table, column string, from, to time.Time) error {
+	err := db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF %s DEFAULT`,
+		DefaultPartitionName(table), table)).Error
+	if err != nil {
+		return err
+	}
+
+	var days []string
+	err = db.Raw(fmt.Sprintf(`SELECT DISTINCT to_char(%s AT TIME ZONE 'UTC', 'YYYYMMDD') FROM %s`,
+		column, DefaultPartitionName(table))).Scan(&days).Error
+	if err != nil {
+		return err
+	}
+	for _, name := range days {
+		day, err := time.Parse(partitionDayLayout, name)
+		if err != nil {
+			return err
+		}
+		if err := splitDefaultPartition(db, table, column, day); err != nil {
+			return fmt.Errorf("move %s rows of %s out of the default partition: %w", table, name, err)
+		}
+	}
+
+	return createDailyPartitions(db, table, table, from, to)
+}
+
+// splitDefaultPartition moves day's rows from the default partition of table into
+// a new partition for the day. A partition cannot be created for a range the
+// default partition has rows in, so it is filled first and then attached.
+func splitDefaultPartition(db *gorm.DB, table, column string, day time.Time) error {
+	name := DailyPartitionName(table, day)
+	from, to := day.Format(time.RFC3339), day.AddDate(0, 0, 1).Format(time.RFC3339)
+	inRange := fmt.Sprintf(`%s >= '%s' AND %s < '%s'`, column, from, column, to)
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		statements := []string{
+			fmt.Sprintf(`CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS)`, name, table),
+			fmt.Sprintf(`INSERT INTO %s SELECT * FROM %s WHERE %s`, name, DefaultPartitionName(table), inRange),
+			fmt.Sprintf(`DELETE FROM %s WHERE %s`, DefaultPartitionName(table), inRange),
+			fmt.Sprintf(`ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')`, table, name, from, to),
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// createDailyPartitions creates partitions named after table as partitions of parent
+func createDailyPartitions(db *gorm.DB, parent, table string, from, to time.Time) error {
+	for day := truncateDay(from); !day.After(to); day = day.AddDate(0, 0, 1) {
+		err := db.Exec(fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')`,
+			DailyPartitionName(table, day), parent,
+			day.Format(time.RFC3339), day.AddDate(0, 0, 1).Format(time.RFC3339),
+		)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListDailyPartitions returns the daily partitions of table, oldest first
+func ListDailyPartitions(db *gorm.DB, table string) ([]DailyPartition, error) {
+	var names []string
+	err := db.Raw(`SELECT c.relname FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		WHERE p.relname = ? AND pg_table_is_visible(p.oid)
+		ORDER BY c.relname`, table).Scan(&names).Error
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := make([]DailyPartition, 0, len(names))
+	for _, name := range names {
+		day, err := time.Parse(partitionDayLayout, strings.TrimPrefix(name, table+"_p"))
+		if err != nil {
+			// Not one of ours
+			continue
+		}
+		partitions = append(partitions, DailyPartition{Name: name, Day: day})
+	}
+	return partitions, nil
+}
+
+// PartitionByDay turns a plain table keyed by id into one partitioned by day on column,
+// moving its rows into daily partitions and creating partitions for the next
+// `ahead` days. Rows of later days go to a default partition until their day's
+// partition is created. Indexes are not carried over; recreate them with AutoMigrate.
+// A table that is already partitioned only gets the missing partitions.
+func PartitionByDay(db *gorm.DB, table, column string, ahead int) error {
+	today := truncateDay(time.Now())
+
+	partitioned, err := IsPartitioned(db, table)
+	if err != nil {
+		return err
+	}
+	if partitioned {
+		return CreateDailyPartitions(db, table, column, today, today.AddDate(0, 0, ahead))
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Built next to the plain table and swapped in, so no names clash
+		staging := table + "_partitioned"
+
+		statements := []string{
+			fmt.Sprintf(`CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS) PARTITION BY RANGE (%s)`, staging, table, column),
+			fmt.Sprintf(`ALTER TABLE %s ADD PRIMARY KEY (id, %s)`, staging, column),
+			fmt.Sprintf(`CREATE TABLE %s PARTITION OF %s DEFAULT`, DefaultPartitionName(table), staging),
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		var bounds struct {
+			First *time.Time
+			Last  *time.Time
+		}
+		err := tx.Raw(fmt.Sprintf(`SELECT min(%s) AS first, max(%s) AS last FROM %s`, column, column, table)).
+			Scan(&bounds).Error
+		if err != nil {
+			return err
+		}
+
+		from, to := today, today.AddDate(0, 0, ahead)
+		if bounds.First != nil && bounds.First.Before(from) {
+			from = *bounds.First
+		}
+		if bounds.Last != nil && bounds.Last.After(to) {
+			to = *bounds.Last
+		}
+		if err := createDailyPartitions(tx, staging, table, from, to); err != nil {
+			return err
+		}
+
+		statements = []string{
+			fmt.Sprintf(`INSERT INTO %s SELECT * FROM %s`, staging, table),
+			fmt.Sprintf(`DROP TABLE %s`, table),
+			fmt.Sprintf(`ALTER TABLE %s RENAME TO %s`, staging, table),
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func truncateDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
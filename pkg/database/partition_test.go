@@ -0,0 +1,34 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDailyPartitionName(t *testing.T) {
+	// Days are UTC, so a late evening elsewhere belongs to the next day's partition
+	day := time.Date(2024, 1, 14, 22, 30, 0, 0, time.FixedZone("EST", -5*3600))
+
+	if got := DailyPartitionName("request_logs", day); got != "request_logs_p20240115" {
+		t.Errorf("DailyPartitionName = %s, want request_logs_p20240115", got)
+	}
+	if got := DefaultPartitionName("request_logs"); got != "request_logs_default" {
+		t.Errorf("DefaultPartitionName = %s, want request_logs_default", got)
+	}
+}
+
+func TestDailyPartitionEnd(t *testing.T) {
+	partition := DailyPartition{Name: "request_logs_p20240229", Day: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)}
+
+	if want := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC); !partition.End().Equal(want) {
+		t.Errorf("End = %v, want %v", partition.End(), want)
+	}
+}
+
+func TestTruncateDay(t *testing.T) {
+	got := truncateDay(time.Date(2024, 1, 15, 1, 30, 0, 0, time.FixedZone("CET", 3600)))
+
+	if want := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("truncateDay = %v, want %v", got, want)
+	}
+}
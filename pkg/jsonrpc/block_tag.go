@@ -0,0 +1,106 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// blockParamIndex maps methods to the position of their block parameter
+var blockParamIndex = map[string]int{
+	"eth_getBalance":                          1,
+	"eth_getCode":                             1,
+	"eth_getTransactionCount":                 1,
+	"eth_getStorageAt":                        2,
+	"eth_call":                                1,
+	"eth_estimateGas":                         1,
+	"eth_getProof":                            2,
+	"eth_getBlockByNumber":                    0,
+	"eth_getBlockByHash":                      0,
+	"eth_getBlockTransactionCountByNumber":    0,
+	"eth_getBlockTransactionCountByHash":      0,
+	"eth_getTransactionByBlockNumberAndIndex": 0,
+	"eth_getTransactionByBlockHashAndIndex":   0,
+	"eth_getUncleCountByBlockNumber":          0,
+	"eth_getUncleByBlockNumberAndIndex":       0,
+	"eth_getBlockReceipts":                    0,
+	"eth_feeHistory":                          1,
+}
+
+// headMethods always read the chain head
+var headMethods = map[string]bool{
+	"eth_blockNumber":           true,
+	"eth_gasPrice":              true,
+	"eth_maxPriorityFeePerGas":  true,
+	"eth_sendRawTransaction":    true,
+	"eth_getTransactionByHash":  true,
+	"eth_getTransactionReceipt": true,
+}
+
+// BlockTag returns the block a call refers to: a tag such as "latest" or
+// "finalized", a block number or a block hash. It returns "" when the call
+// has no block context; a missing optional block parameter means "latest".
+func BlockTag(method string, params json.RawMessage) string {
+	if headMethods[method] {
+		return "latest"
+	}
+
+	if method == "eth_getLogs" {
+		return logsBlockTag(params)
+	}
+
+	index, ok := blockParamIndex[method]
+	if !ok {
+		return ""
+	}
+
+	var args []json.RawMessage
+	if err := json.Unmarshal(params, &args); err != nil || index >= len(args) {
+		return "latest"
+	}
+
+	return blockParam(args[index])
+}
+
+// logsBlockTag reads the block range of an eth_getLogs filter
+func logsBlockTag(params json.RawMessage) string {
+	var args []struct {
+		ToBlock   json.RawMessage `json:"toBlock"`
+		BlockHash string          `json:"blockHash"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil || len(args) == 0 {
+		return "latest"
+	}
+
+	if args[0].BlockHash != "" {
+		return args[0].BlockHash
+	}
+	if len(args[0].ToBlock) == 0 {
+		return "latest"
+	}
+
+	return blockParam(args[0].ToBlock)
+}
+
+// blockParam decodes a block parameter, which is either a string
+// (tag, hex number or hash) or an EIP-1898 object
+func blockParam(raw json.RawMessage) string {
+	var tag string
+	if err := json.Unmarshal(raw, &tag); err == nil {
+		return strings.ToLower(tag)
+	}
+
+	var ref struct {
+		BlockNumber string `json:"blockNumber"`
+		BlockHash   string `json:"blockHash"`
+	}
+	if err := json.Unmarshal(raw, &ref); err == nil {
+		if ref.BlockHash != "" {
+			return strings.ToLower(ref.BlockHash)
+		}
+		if ref.BlockNumber != "" {
+			return strings.ToLower(ref.BlockNumber)
+		}
+	}
+
+	return "latest"
+}
@@ -0,0 +1,42 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBlockTag(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		params string
+		want   string
+	}{
+		{"head method", "eth_blockNumber", `[]`, "latest"},
+		{"send is head", "eth_sendRawTransaction", `["0xf86c"]`, "latest"},
+		{"no block context", "net_version", `[]`, ""},
+		{"tag", "eth_getBalance", `["0xabc", "finalized"]`, "finalized"},
+		{"tag is lowercased", "eth_getBalance", `["0xabc", "LATEST"]`, "latest"},
+		{"hex number", "eth_getBlockByNumber", `["0x10", false]`, "0x10"},
+		{"block hash", "eth_getBlockByHash", `["0xAbCd", false]`, "0xabcd"},
+		{"storage index 2", "eth_getStorageAt", `["0xabc", "0x0", "0x5"]`, "0x5"},
+		{"missing optional block", "eth_call", `[{"to": "0xabc"}]`, "latest"},
+		{"invalid params", "eth_getBalance", `{"not": "an array"}`, "latest"},
+		{"EIP-1898 number", "eth_call", `[{"to": "0xabc"}, {"blockNumber": "0x1F"}]`, "0x1f"},
+		{"EIP-1898 hash", "eth_call", `[{"to": "0xabc"}, {"blockHash": "0xBEEF", "requireCanonical": true}]`, "0xbeef"},
+		{"EIP-1898 empty object", "eth_call", `[{"to": "0xabc"}, {}]`, "latest"},
+		{"logs toBlock", "eth_getLogs", `[{"fromBlock": "0x1", "toBlock": "0x20"}]`, "0x20"},
+		{"logs blockHash", "eth_getLogs", `[{"blockHash": "0xfeed"}]`, "0xfeed"},
+		{"logs without toBlock", "eth_getLogs", `[{"fromBlock": "0x1"}]`, "latest"},
+		{"logs without filter", "eth_getLogs", `[]`, "latest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BlockTag(tt.method, json.RawMessage(tt.params))
+			if got != tt.want {
+				t.Errorf("BlockTag(%s, %s) = %q, want %q", tt.method, tt.params, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// Version is the only JSON-RPC protocol version supported by the gateway
+const Version = "2.0"
+
+// Standard JSON-RPC 2.0 error codes
+const (
+	ParseError     = -32700
+	InvalidRequest = -32600
+	MethodNotFound = -32601
+	InvalidParams  = -32602
+	InternalError  = -32603
+	ServerError    = -32000
+	// LimitExceeded is the EIP-1474 code for requests over a rate or quota limit
+	LimitExceeded = -32005
+	// AccessDenied is returned for calls outside an API key's allowed chains, methods, IPs or origins
+	AccessDenied = -32010
+)
+
+// Request represents a single JSON-RPC request object
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response represents a single JSON-RPC response object
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error represents a JSON-RPC error object
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NewErrorResponse builds an error response for the request with the given id
+func NewErrorResponse(id json.RawMessage, code int, message string) *Response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+
+	return &Response{
+		JSONRPC: Version,
+		ID:      id,
+		Error: &Error{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// ParseRequest decodes and validates a single JSON-RPC request
+func ParseRequest(body []byte) (*Request, error) {
+	var req Request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+
+	if req.Method == "" {
+		return nil, errors.New("method is required")
+	}
+
+	return &req, nil
+}
+
+// RequestID extracts the id of a request body on a best-effort basis.
+// It returns nil when the body is not a JSON object.
+func RequestID(body []byte) json.RawMessage {
+	var req struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil
+	}
+	return req.ID
+}
+
+// IsNotification reports whether a request body has no id member.
+// Notifications are never answered, not even with an error.
+func IsNotification(body []byte) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return false
+	}
+	_, ok := fields["id"]
+	return !ok
+}
+
+// WithID returns a copy of a response body with its id replaced
+func WithID(body []byte, id json.RawMessage) ([]byte, error) {
+	var resp Response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	resp.ID = id
+	if len(resp.ID) == 0 {
+		resp.ID = json.RawMessage("null")
+	}
+
+	return json.Marshal(&resp)
+}
+
+// IsBatch reports whether the body is a JSON-RPC batch (a JSON array)
+func IsBatch(body []byte) bool {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// ParseBatch splits a batch body into its raw items.
+// Items are validated separately so one malformed item does not fail the batch.
+func ParseBatch(body []byte) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(body, &items); err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, errors.New("empty batch")
+	}
+
+	return items, nil
+}
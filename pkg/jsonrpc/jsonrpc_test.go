@@ -0,0 +1,21 @@
+package jsonrpc
+
+import "testing"
+
+func TestIsNotification(t *testing.T) {
+	tests := []struct {
+		body string
+		want bool
+	}{
+		{`{"jsonrpc":"2.0","method":"eth_chainId"}`, true},
+		{`{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}`, false},
+		{`{"jsonrpc":"2.0","id":null,"method":"eth_chainId"}`, false},
+		{`{"jsonrpc":"2.0","id":"","method":"eth_chainId"}`, false},
+		{`not json`, false},
+	}
+	for _, tt := range tests {
+		if got := IsNotification([]byte(tt.body)); got != tt.want {
+			t.Errorf("IsNotification(%s) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,17 +10,42 @@ import (
 )
 
 type APIKey struct {
-	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	UserID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
-	Key         string         `gorm:"uniqueIndex;not null" json:"key"`
-	Name        string         `json:"name"`
-	Description string         `json:"description"`
-	IsActive    bool           `gorm:"default:true" json:"is_active"`
-	ExpiresAt   *time.Time     `json:"expires_at"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
-	User        User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	ID                uuid.UUID          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID            uuid.UUID          `gorm:"type:uuid;not null;index" json:"user_id"`
+	Key               string             `gorm:"-" json:"key,omitempty"`  // only set on a key just issued; never stored
+	KeyHash           string             `gorm:"uniqueIndex" json:"-"`    // HashAPIKey of the key
+	KeyPrefix         string             `gorm:"index" json:"key_prefix"` // start of the key, to tell keys apart
+	Name              string             `json:"name"`
+	Description       string             `json:"description"`
+	IsActive          bool               `gorm:"default:true" json:"is_active"`
+	ExpiresAt         *time.Time         `json:"expires_at"`
+	MonthlyLimit      int64              `gorm:"default:0" json:"monthly_limit"`       // credits per billing period, within the plan's; 0 for no limit
+	RequestsPerSecond int64              `gorm:"default:0" json:"requests_per_second"` // 0 for no limit
+	Restrictions      APIKeyRestrictions `gorm:"embedded" json:"restrictions"`
+	RotatedFromID     *uuid.UUID         `gorm:"type:uuid;index" json:"rotated_from_id,omitempty"` // key this one replaced
+	ReplacedByID      *uuid.UUID         `gorm:"type:uuid" json:"replaced_by_id,omitempty"`        // set once rotated; works until ExpiresAt
+	CreatedAt         time.Time          `json:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt     `gorm:"index" json:"-"`
+	User              User               `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// APIKeyPrefixLength is how much of a key is kept in the clear, "qn_" and 8 hex digits
+const APIKeyPrefixLength = 11
+
+// HashAPIKey returns the SHA-256 of a key as stored in APIKey.KeyHash.
+// Keys are 256 random bits, so an unsalted hash cannot be brute-forced.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyPrefix returns the part of a key stored in APIKey.KeyPrefix
+func APIKeyPrefix(key string) string {
+	if len(key) > APIKeyPrefixLength {
+		return key[:APIKeyPrefixLength]
+	}
+	return key
 }
 
 func (a *APIKey) BeforeCreate(tx *gorm.DB) error {
@@ -28,6 +55,11 @@ func (a *APIKey) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// IsDeprecated reports whether the key was rotated and only works until its grace period ends
+func (a *APIKey) IsDeprecated() bool {
+	return a.ReplacedByID != nil
+}
+
 func (a *APIKey) IsExpired() bool {
 	if a.ExpiresAt == nil {
 		return false
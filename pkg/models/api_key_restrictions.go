@@ -0,0 +1,181 @@
+package models
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// APIKeyRestrictions limit where and how an API key may be used, so a key
+// shipped in a frontend bundle is of little use elsewhere. Empty lists allow everything.
+type APIKeyRestrictions struct {
+	AllowedChains  []string `gorm:"type:jsonb;serializer:json" json:"allowed_chains"`  // "ethereum" for any network, or "ethereum/mainnet"
+	AllowedMethods []string `gorm:"type:jsonb;serializer:json" json:"allowed_methods"` // "eth_*" for a namespace, or a method name
+	AllowedIPs     []string `gorm:"type:jsonb;serializer:json" json:"allowed_ips"`     // CIDRs; a bare IP is a single address
+	AllowedOrigins []string `gorm:"type:jsonb;serializer:json" json:"allowed_origins"` // Origin/Referer domains, "*.example.com" for subdomains
+}
+
+// Normalize validates the restrictions and brings them to the form they are matched in
+func (r *APIKeyRestrictions) Normalize() error {
+	chains, err := normalizeEach(r.AllowedChains, func(chain string) (string, error) {
+		chain = strings.ToLower(chain)
+		parts := strings.Split(chain, "/")
+		if len(parts) > 2 || parts[0] == "" || (len(parts) == 2 && parts[1] == "") {
+			return "", fmt.Errorf("invalid chain %q, expected <blockchain> or <blockchain>/<network>", chain)
+		}
+		return chain, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	methods, err := normalizeEach(r.AllowedMethods, func(method string) (string, error) {
+		if strings.ContainsAny(method, " /") || method == "_*" || (strings.Contains(method, "*") && !strings.HasSuffix(method, "_*")) {
+			return "", fmt.Errorf("invalid method %q, expected a namespace such as eth_* or a method name", method)
+		}
+		return method, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	ips, err := normalizeEach(r.AllowedIPs, func(ip string) (string, error) {
+		if !strings.Contains(ip, "/") {
+			addr, err := netip.ParseAddr(ip)
+			if err != nil {
+				return "", fmt.Errorf("invalid IP %q", ip)
+			}
+			addr = addr.Unmap()
+			return netip.PrefixFrom(addr, addr.BitLen()).String(), nil
+		}
+		prefix, err := netip.ParsePrefix(ip)
+		if err != nil {
+			return "", fmt.Errorf("invalid CIDR %q", ip)
+		}
+		// Callers are matched unmapped, so an IPv4-mapped range is stored as IPv4
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		return prefix.Masked().String(), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	origins, err := normalizeEach(r.AllowedOrigins, func(origin string) (string, error) {
+		domain := strings.ToLower(origin)
+		if strings.Contains(domain, "://") {
+			parsed, err := url.Parse(domain)
+			if err != nil {
+				return "", fmt.Errorf("invalid origin %q", origin)
+			}
+			domain = parsed.Hostname()
+		}
+		if domain == "" || domain == "*." || strings.ContainsAny(domain, "/: ") || strings.Contains(strings.TrimPrefix(domain, "*."), "*") {
+			return "", fmt.Errorf("invalid origin %q, expected a domain such as app.example.com or *.example.com", origin)
+		}
+		return domain, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.AllowedChains, r.AllowedMethods, r.AllowedIPs, r.AllowedOrigins = chains, methods, ips, origins
+	return nil
+}
+
+// normalizeEach trims values, drops empty ones and normalizes the rest
+func normalizeEach(values []string, normalize func(string) (string, error)) ([]string, error) {
+	var normalized []string
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		value, err := normalize(value)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, value)
+	}
+	return normalized, nil
+}
+
+// AllowsChain reports whether the key may call the network of blockchain
+func (r *APIKeyRestrictions) AllowsChain(blockchain, network string) bool {
+	if len(r.AllowedChains) == 0 {
+		return true
+	}
+
+	blockchain, network = strings.ToLower(blockchain), strings.ToLower(network)
+	for _, chain := range r.AllowedChains {
+		if chain == blockchain || chain == blockchain+"/"+network {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsMethod reports whether the key may call method. Unlike plan allowlists,
+// methods without a namespace are only allowed when listed.
+func (r *APIKeyRestrictions) AllowsMethod(method string) bool {
+	if len(r.AllowedMethods) == 0 {
+		return true
+	}
+
+	for _, pattern := range r.AllowedMethods {
+		if pattern == method {
+			return true
+		}
+		if namespace, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(method, namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsIP reports whether the key may be used from ip
+func (r *APIKeyRestrictions) AllowsIP(ip string) bool {
+	if len(r.AllowedIPs) == 0 {
+		return true
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, cidr := range r.AllowedIPs {
+		if prefix, err := netip.ParsePrefix(cidr); err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsOrigin reports whether the key may be used from a page at origin, an
+// Origin or Referer header value. Calls without one are refused once origins are set.
+func (r *APIKeyRestrictions) AllowsOrigin(origin string) bool {
+	if len(r.AllowedOrigins) == 0 {
+		return true
+	}
+
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Hostname() == "" {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+
+	for _, domain := range r.AllowedOrigins {
+		if suffix, ok := strings.CutPrefix(domain, "*"); ok {
+			if strings.HasSuffix(host, suffix) {
+				return true
+			}
+		} else if host == domain {
+			return true
+		}
+	}
+	return false
+}
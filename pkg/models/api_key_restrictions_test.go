@@ -0,0 +1,198 @@
+package models
+
+import "testing"
+
+func TestAPIKeyRestrictionsNormalize(t *testing.T) {
+	tests := []struct {
+		name         string
+		restrictions APIKeyRestrictions
+		want         APIKeyRestrictions
+		wantErr      bool
+	}{
+		{
+			name: "trims, lowercases and drops empty values",
+			restrictions: APIKeyRestrictions{
+				AllowedChains:  []string{" Ethereum/Mainnet ", "", "polygon"},
+				AllowedOrigins: []string{"https://App.Example.com:8443", "*.example.org"},
+			},
+			want: APIKeyRestrictions{
+				AllowedChains:  []string{"ethereum/mainnet", "polygon"},
+				AllowedOrigins: []string{"app.example.com", "*.example.org"},
+			},
+		},
+		{
+			name:         "bare IPs become single-address ranges",
+			restrictions: APIKeyRestrictions{AllowedIPs: []string{"203.0.113.7", "2001:db8::1"}},
+			want:         APIKeyRestrictions{AllowedIPs: []string{"203.0.113.7/32", "2001:db8::1/128"}},
+		},
+		{
+			name:         "ranges are masked",
+			restrictions: APIKeyRestrictions{AllowedIPs: []string{"10.1.2.3/8"}},
+			want:         APIKeyRestrictions{AllowedIPs: []string{"10.0.0.0/8"}},
+		},
+		{
+			name:         "IPv4-mapped IPv6 is stored as IPv4",
+			restrictions: APIKeyRestrictions{AllowedIPs: []string{"::ffff:203.0.113.7", "::ffff:10.0.0.0/104"}},
+			want:         APIKeyRestrictions{AllowedIPs: []string{"203.0.113.7/32", "10.0.0.0/8"}},
+		},
+		{name: "invalid IP", restrictions: APIKeyRestrictions{AllowedIPs: []string{"300.1.1.1"}}, wantErr: true},
+		{name: "invalid CIDR", restrictions: APIKeyRestrictions{AllowedIPs: []string{"10.0.0.0/33"}}, wantErr: true},
+		{name: "chain with empty network", restrictions: APIKeyRestrictions{AllowedChains: []string{"ethereum/"}}, wantErr: true},
+		{name: "method wildcard without namespace", restrictions: APIKeyRestrictions{AllowedMethods: []string{"_*"}}, wantErr: true},
+		{name: "method wildcard inside name", restrictions: APIKeyRestrictions{AllowedMethods: []string{"eth_get*Block"}}, wantErr: true},
+		{name: "bare wildcard origin", restrictions: APIKeyRestrictions{AllowedOrigins: []string{"*."}}, wantErr: true},
+		{name: "origin with a path", restrictions: APIKeyRestrictions{AllowedOrigins: []string{"example.com/app"}}, wantErr: true},
+		{name: "nested wildcard origin", restrictions: APIKeyRestrictions{AllowedOrigins: []string{"*.*.example.com"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.restrictions.Normalize()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Normalize() = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize() error = %v", err)
+			}
+
+			for _, field := range []struct {
+				name      string
+				got, want []string
+			}{
+				{"chains", tt.restrictions.AllowedChains, tt.want.AllowedChains},
+				{"methods", tt.restrictions.AllowedMethods, tt.want.AllowedMethods},
+				{"IPs", tt.restrictions.AllowedIPs, tt.want.AllowedIPs},
+				{"origins", tt.restrictions.AllowedOrigins, tt.want.AllowedOrigins},
+			} {
+				if len(field.got) != len(field.want) {
+					t.Errorf("%s = %v, want %v", field.name, field.got, field.want)
+					continue
+				}
+				for i := range field.want {
+					if field.got[i] != field.want[i] {
+						t.Errorf("%s = %v, want %v", field.name, field.got, field.want)
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAPIKeyRestrictionsAllowsIP(t *testing.T) {
+	restrictions := APIKeyRestrictions{AllowedIPs: []string{"203.0.113.7", "10.0.0.0/8", "2001:db8::/32", "::ffff:198.51.100.0/120"}}
+	if err := restrictions.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"203.0.113.7", true},
+		{"203.0.113.8", false},
+		{"10.200.1.1", true},
+		{"::ffff:10.200.1.1", true},
+		{"::ffff:203.0.113.7", true},
+		{"198.51.100.42", true},
+		{"::ffff:198.51.100.42", true},
+		{"198.51.101.1", false},
+		{"2001:db8:1::1", true},
+		{"2001:db9::1", false},
+		{"", false},
+		{"not-an-ip", false},
+	}
+
+	for _, tt := range tests {
+		if got := restrictions.AllowsIP(tt.ip); got != tt.want {
+			t.Errorf("AllowsIP(%q) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestAPIKeyRestrictionsAllowsOrigin(t *testing.T) {
+	restrictions := APIKeyRestrictions{AllowedOrigins: []string{"app.example.com", "*.example.org"}}
+	if err := restrictions.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://app.example.com", true},
+		{"https://APP.example.com:8443", true},
+		{"https://app.example.com/page?q=1", true}, // Referer
+		{"https://example.com", false},
+		{"https://evil.app.example.com", false},
+		{"https://api.example.org", true},
+		{"https://deep.api.example.org", true},
+		{"https://example.org", false}, // the apex is not a subdomain
+		{"https://notexample.org", false},
+		{"https://example.org.evil.com", false},
+		{"", false},
+		{"null", false},
+		{"app.example.com", false}, // not a URL
+	}
+
+	for _, tt := range tests {
+		if got := restrictions.AllowsOrigin(tt.origin); got != tt.want {
+			t.Errorf("AllowsOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestAPIKeyRestrictionsAllowsChainAndMethod(t *testing.T) {
+	restrictions := APIKeyRestrictions{
+		AllowedChains:  []string{"ethereum/mainnet", "polygon"},
+		AllowedMethods: []string{"eth_*", "net_version"},
+	}
+	if err := restrictions.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+
+	chains := []struct {
+		blockchain, network string
+		want                bool
+	}{
+		{"ethereum", "mainnet", true},
+		{"Ethereum", "MAINNET", true},
+		{"ethereum", "sepolia", false},
+		{"polygon", "amoy", true},
+		{"bsc", "mainnet", false},
+	}
+	for _, tt := range chains {
+		if got := restrictions.AllowsChain(tt.blockchain, tt.network); got != tt.want {
+			t.Errorf("AllowsChain(%q, %q) = %v, want %v", tt.blockchain, tt.network, got, tt.want)
+		}
+	}
+
+	methods := []struct {
+		method string
+		want   bool
+	}{
+		{"eth_call", true},
+		{"eth_getLogs", true},
+		{"net_version", true},
+		{"net_peerCount", false},
+		{"debug_traceTransaction", false},
+		{"web3_clientVersion", false},
+	}
+	for _, tt := range methods {
+		if got := restrictions.AllowsMethod(tt.method); got != tt.want {
+			t.Errorf("AllowsMethod(%q) = %v, want %v", tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestAPIKeyRestrictionsUnsetAllowEverything(t *testing.T) {
+	var restrictions APIKeyRestrictions
+
+	if !restrictions.AllowsChain("bsc", "mainnet") || !restrictions.AllowsMethod("debug_traceTransaction") ||
+		!restrictions.AllowsIP("") || !restrictions.AllowsOrigin("") {
+		t.Error("a key without restrictions refused a call")
+	}
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+func TestHashAPIKey(t *testing.T) {
+	if got, want := HashAPIKey("qn_test"), "c7f4ea661c913e46cab8d385af060a069b090258f0913d7bfa6369e160d58dfb"; got != want {
+		t.Errorf("HashAPIKey = %s, want the SHA-256 %s", got, want)
+	}
+}
+
+func TestAPIKeyPrefix(t *testing.T) {
+	key := "qn_" + strings.Repeat("0123456789abcdef", 4)
+
+	if got := APIKeyPrefix(key); got != "qn_01234567" {
+		t.Errorf("APIKeyPrefix = %q, want qn_01234567", got)
+	}
+	if got := APIKeyPrefix("qn_1"); got != "qn_1" {
+		t.Errorf("APIKeyPrefix of a short key = %q, want it whole", got)
+	}
+}
+
+func TestAPIKeyStoresNoPlaintextKey(t *testing.T) {
+	s, err := schema.Parse(&APIKey{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+
+	if field := s.LookUpField("key"); field != nil {
+		t.Errorf("api_keys has a column %q for the plaintext key", field.DBName)
+	}
+	for _, column := range []string{"key_hash", "key_prefix"} {
+		if s.LookUpField(column) == nil {
+			t.Errorf("api_keys has no %s column", column)
+		}
+	}
+}
+
+func TestAPIKeyJSONOmitsHash(t *testing.T) {
+	listed, err := json.Marshal(&APIKey{KeyHash: HashAPIKey("qn_test"), KeyPrefix: "qn_test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(listed, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fields["key"]; ok {
+		t.Error("a stored key marshals a key field")
+	}
+	if strings.Contains(string(listed), HashAPIKey("qn_test")) {
+		t.Error("the key hash is marshaled")
+	}
+}
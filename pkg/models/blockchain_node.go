@@ -16,14 +16,26 @@ const (
 	BSC      BlockchainType = "bsc"
 	Avalanche BlockchainType = "avalanche"
 	Solana   BlockchainType = "solana"
+	Tron     BlockchainType = "tron"
 )
 
+// IsEVM reports whether the chain speaks the Ethereum JSON-RPC API
+func (t BlockchainType) IsEVM() bool {
+	switch t {
+	case Ethereum, Polygon, BSC, Avalanche:
+		return true
+	default:
+		return false
+	}
+}
+
 type BlockchainNode struct {
 	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	Name        string         `json:"name"`
 	Type        BlockchainType `gorm:"type:varchar(50);not null" json:"type"`
 	Network     string         `json:"network"` // mainnet, testnet, etc.
 	URL         string         `json:"url"`
+	WSURL       string         `gorm:"column:ws_url" json:"ws_url"` // WebSocket endpoint for subscriptions
 	IsActive    bool           `gorm:"default:true" json:"is_active"`
 	Priority    int            `gorm:"default:0" json:"priority"` // Higher priority nodes are used first
 	MaxRequests int            `gorm:"default:1000" json:"max_requests"`
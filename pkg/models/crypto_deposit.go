@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DepositStatus string
+
+const (
+	DepositPending   DepositStatus = "pending"   // seen on chain, waiting for confirmations
+	DepositConfirmed DepositStatus = "confirmed" // credited to the prepaid balance
+	DepositReverted  DepositStatus = "reverted"  // disappeared before it was confirmed
+)
+
+// DepositWallet is a user's USDT TRC20 deposit address for billing top-ups.
+// The key pair is stored as a Wallet; this tracks what the watcher has seen.
+type DepositWallet struct {
+	ID             uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID         uuid.UUID   `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	WalletID       uuid.UUID   `gorm:"type:uuid;not null" json:"wallet_id"`
+	Address        string      `gorm:"uniqueIndex;not null" json:"address"`
+	Network        NetworkType `gorm:"type:varchar(10);not null" json:"network"`
+	LastTransferAt int64       `gorm:"default:0" json:"last_transfer_at"` // block time (unix ms) of the newest transfer recorded
+	LastCheckedAt  *time.Time  `json:"last_checked_at,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+func (w *DepositWallet) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+// CryptoDeposit is an incoming transfer to a deposit wallet, one per transaction
+type CryptoDeposit struct {
+	ID              uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID          uuid.UUID     `gorm:"type:uuid;not null;index" json:"user_id"`
+	DepositWalletID uuid.UUID     `gorm:"type:uuid;not null;index;uniqueIndex:idx_crypto_deposits_wallet_tx,priority:1" json:"deposit_wallet_id"`
+	Address         string        `gorm:"not null" json:"address"`
+	TxID            string        `gorm:"not null;uniqueIndex:idx_crypto_deposits_wallet_tx,priority:2" json:"tx_id"`
+	RawAmount       int64         `gorm:"not null" json:"raw_amount"` // USDT raw units
+	Amount          float64       `gorm:"not null" json:"amount"`     // USDT
+	DetectedBlock   int64         `json:"detected_block"`             // block the transaction is in
+	Status          DepositStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	ConfirmedAt     *time.Time    `json:"confirmed_at,omitempty"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+}
+
+func (d *CryptoDeposit) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// PrepaidBalance is money a user has topped up; open invoices are paid from it first
+type PrepaidBalance struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	Balance   float64   `gorm:"default:0" json:"balance"`
+	Currency  string    `gorm:"type:varchar(3);default:'USD'" json:"currency"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (b *PrepaidBalance) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
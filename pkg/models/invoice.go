@@ -0,0 +1,118 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type InvoiceStatus string
+
+const (
+	InvoiceOpen InvoiceStatus = "open"
+	InvoicePaid InvoiceStatus = "paid"
+	InvoiceVoid InvoiceStatus = "void"
+)
+
+// LineItemKind tells what an invoice line bills for
+type LineItemKind string
+
+const (
+	LineItemPlan      LineItemKind = "plan"
+	LineItemProration LineItemKind = "proration"
+	LineItemOverage   LineItemKind = "overage"
+	LineItemPrepaid   LineItemKind = "prepaid" // usage paid from the credit ledger, informational
+	LineItemUsage     LineItemKind = "usage"   // per-chain breakdown, informational
+)
+
+// Invoice bills one closed billing period
+type Invoice struct {
+	ID              uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Number          string            `gorm:"uniqueIndex;not null" json:"number"`
+	UserID          uuid.UUID         `gorm:"type:uuid;not null;index" json:"user_id"`
+	SubscriptionID  uuid.UUID         `gorm:"type:uuid;not null;index" json:"subscription_id"`
+	BillingPeriodID uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex" json:"billing_period_id"`
+	PlanType        PlanType          `gorm:"type:varchar(50)" json:"plan_type"`
+	PeriodStart     time.Time         `json:"period_start"`
+	PeriodEnd       time.Time         `json:"period_end"`
+	Currency        string            `gorm:"type:varchar(3);default:'USD'" json:"currency"`
+	Total           float64           `json:"total"`
+	Status          InvoiceStatus     `gorm:"type:varchar(20);default:'open';index" json:"status"`
+	IssuedAt        time.Time         `json:"issued_at"`
+	DueAt           time.Time         `json:"due_at"`
+	PaidAt          *time.Time        `json:"paid_at,omitempty"`
+	PaymentAttempts int               `gorm:"default:0" json:"payment_attempts"`
+	NextPaymentAt   *time.Time        `gorm:"index" json:"next_payment_at,omitempty"` // when the next dunning retry is due
+	LineItems       []InvoiceLineItem `gorm:"foreignKey:InvoiceID" json:"line_items"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+func (i *Invoice) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+type InvoiceLineItem struct {
+	ID          uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	InvoiceID   uuid.UUID    `gorm:"type:uuid;not null;index" json:"invoice_id"`
+	Kind        LineItemKind `gorm:"type:varchar(20);not null" json:"kind"`
+	Description string       `json:"description"`
+	Blockchain  string       `json:"blockchain,omitempty"`
+	Quantity    int64        `json:"quantity"`
+	UnitPrice   float64      `json:"unit_price"`
+	Amount      float64      `json:"amount"`
+	Position    int          `json:"position"`
+}
+
+func (l *InvoiceLineItem) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
+
+// ChainUsage is the credits a subscription spent on one blockchain in one billing period
+type ChainUsage struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	SubscriptionID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_chain_usage_period" json:"subscription_id"`
+	PeriodStart    time.Time `gorm:"not null;uniqueIndex:idx_chain_usage_period" json:"period_start"`
+	Blockchain     string    `gorm:"not null;uniqueIndex:idx_chain_usage_period" json:"blockchain"`
+	Credits        int64     `gorm:"default:0" json:"credits"`
+}
+
+func (c *ChainUsage) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// KeyUsage is the credits a subscription spent with one API key in one billing
+// period, checked against the key's monthly limit when Redis has no count
+type KeyUsage struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	SubscriptionID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_key_usage_period" json:"subscription_id"`
+	PeriodStart    time.Time `gorm:"not null;uniqueIndex:idx_key_usage_period" json:"period_start"`
+	APIKeyID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_key_usage_period" json:"api_key_id"`
+	Credits        int64     `gorm:"default:0" json:"credits"`
+}
+
+func (k *KeyUsage) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return nil
+}
+
+// UsageIncrement records a usage increment that was applied, so a retry with
+// the same idempotency key is not counted again
+type UsageIncrement struct {
+	IdempotencyKey string    `gorm:"primary_key" json:"idempotency_key"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	Credits        int64     `gorm:"not null" json:"credits"`
+	CreatedAt      time.Time `gorm:"index" json:"created_at"`
+}
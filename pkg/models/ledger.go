@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type LedgerAccountType string
+
+const (
+	LedgerAccountUser   LedgerAccountType = "user"    // credits any of the user's keys can spend
+	LedgerAccountAPIKey LedgerAccountType = "api_key" // credits earmarked for one of the user's keys
+	LedgerAccountSystem LedgerAccountType = "system"  // the platform side of every posting
+)
+
+type LedgerEntryType string
+
+const (
+	LedgerTopUp  LedgerEntryType = "topup"  // credits bought from the prepaid balance
+	LedgerUsage  LedgerEntryType = "usage"  // credits spent over the plan allowance
+	LedgerRefund LedgerEntryType = "refund" // spent credits given back
+	LedgerGrant  LedgerEntryType = "grant"  // promotional credits
+)
+
+// LedgerAccount holds prepaid credits. User and API key accounts keep a running
+// balance; system accounts do not, as every posting would queue on their row.
+type LedgerAccount struct {
+	ID        uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Code      string            `gorm:"uniqueIndex;not null" json:"code"` // user:<id>, user:<id>:key:<id> or system:<name>
+	Type      LedgerAccountType `gorm:"type:varchar(20);not null" json:"type"`
+	UserID    *uuid.UUID        `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	APIKeyID  *uuid.UUID        `gorm:"type:uuid" json:"api_key_id,omitempty"`
+	Balance   int64             `gorm:"default:0" json:"balance"` // credits
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+func (a *LedgerAccount) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// LedgerTransaction is one balanced posting: its entries add up to zero.
+// Transactions are never updated; a mistake is corrected by a new one.
+type LedgerTransaction struct {
+	ID             uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	IdempotencyKey string          `gorm:"uniqueIndex;not null" json:"idempotency_key"` // a replayed event with the same key is not posted again
+	Type           LedgerEntryType `gorm:"type:varchar(20);not null" json:"type"`
+	UserID         uuid.UUID       `gorm:"type:uuid;not null;index" json:"user_id"`
+	APIKeyID       *uuid.UUID      `gorm:"type:uuid" json:"api_key_id,omitempty"`
+	Amount         int64           `gorm:"not null" json:"amount"` // credits moved
+	Cost           float64         `json:"cost"`                   // USD taken from the prepaid balance by a top-up
+	Description    string          `json:"description"`
+	Entries        []LedgerEntry   `gorm:"foreignKey:TransactionID" json:"entries,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func (t *LedgerTransaction) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// LedgerEntry moves credits in (positive) or out (negative) of one account
+type LedgerEntry struct {
+	ID            uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TransactionID uuid.UUID         `gorm:"type:uuid;not null;index" json:"transaction_id"`
+	AccountID     uuid.UUID         `gorm:"type:uuid;not null;index" json:"account_id"`
+	Amount        int64             `gorm:"not null" json:"amount"`
+	BalanceAfter  int64             `json:"balance_after"` // 0 on system accounts
+	Transaction   LedgerTransaction `gorm:"foreignKey:TransactionID" json:"-"`
+	Account       LedgerAccount     `gorm:"foreignKey:AccountID" json:"-"`
+	CreatedAt     time.Time         `gorm:"index" json:"created_at"`
+}
+
+func (e *LedgerEntry) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentPending   PaymentStatus = "pending"
+	PaymentSucceeded PaymentStatus = "succeeded"
+	PaymentFailed    PaymentStatus = "failed"
+	PaymentRefunded  PaymentStatus = "refunded"
+)
+
+// PaymentCustomer links a user to their customer and default payment method at the payment provider
+type PaymentCustomer struct {
+	ID              uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID          uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	Provider        string    `gorm:"type:varchar(50);not null" json:"provider"`
+	CustomerID      string    `gorm:"not null" json:"customer_id"`
+	PaymentMethodID string    `json:"payment_method_id"`
+	CardBrand       string    `gorm:"type:varchar(20)" json:"card_brand"`
+	CardLast4       string    `gorm:"type:varchar(4)" json:"card_last4"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func (c *PaymentCustomer) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// Payment is one attempt to charge an invoice
+type Payment struct {
+	ID             uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID         uuid.UUID     `gorm:"type:uuid;not null;index" json:"user_id"`
+	InvoiceID      uuid.UUID     `gorm:"type:uuid;not null;index" json:"invoice_id"`
+	Provider       string        `gorm:"type:varchar(50);not null" json:"provider"`
+	ChargeID       string        `gorm:"index" json:"charge_id"` // empty if the provider was never called
+	Attempt        int           `json:"attempt"`
+	Amount         float64       `json:"amount"`
+	AmountRefunded float64       `gorm:"default:0" json:"amount_refunded"`
+	Currency       string        `gorm:"type:varchar(3);default:'USD'" json:"currency"`
+	Status         PaymentStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	FailureCode    string        `json:"failure_code,omitempty"`
+	FailureMessage string        `json:"failure_message,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+func (p *Payment) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
@@ -21,6 +21,9 @@ type RequestLog struct {
 	IPAddress     string    `json:"ip_address"`
 	UserAgent     string    `json:"user_agent"`
 	Error         string    `json:"error,omitempty"`
+	CacheHit      bool      `gorm:"default:false" json:"cache_hit"`
+	Coalesced     bool      `gorm:"default:false" json:"coalesced"` // served by an identical in-flight upstream call
+	Credits       int64     `gorm:"default:0" json:"credits"`
 	CreatedAt     time.Time `gorm:"index" json:"created_at"`
 }
 
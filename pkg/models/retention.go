@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// RetentionCursor records how far the request logs of a plan's users have been purged
+type RetentionCursor struct {
+	Plan        PlanType  `gorm:"type:varchar(50);primary_key" json:"plan"`
+	PurgedUntil time.Time `gorm:"not null" json:"purged_until"` // logs before this are archived and deleted
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,26 +12,60 @@ import (
 type PlanType string
 
 const (
-	FreePlan       PlanType = "free"
-	BasicPlan      PlanType = "basic"
+	FreePlan         PlanType = "free"
+	BasicPlan        PlanType = "basic"
 	ProfessionalPlan PlanType = "professional"
-	EnterprisePlan PlanType = "enterprise"
+	EnterprisePlan   PlanType = "enterprise"
 )
 
+// SubscriptionStatus is a state of the subscription lifecycle
+type SubscriptionStatus string
+
+const (
+	StatusTrialing SubscriptionStatus = "trialing"
+	StatusActive   SubscriptionStatus = "active"
+	StatusPastDue  SubscriptionStatus = "past_due"
+	StatusCanceled SubscriptionStatus = "canceled"
+	StatusExpired  SubscriptionStatus = "expired"
+)
+
+// subscriptionTransitions lists the states each state may move to.
+// Canceled and expired are terminal.
+var subscriptionTransitions = map[SubscriptionStatus][]SubscriptionStatus{
+	StatusTrialing: {StatusActive, StatusPastDue, StatusCanceled, StatusExpired},
+	StatusActive:   {StatusPastDue, StatusCanceled, StatusExpired},
+	StatusPastDue:  {StatusActive, StatusCanceled, StatusExpired},
+}
+
+var ErrInvalidTransition = errors.New("invalid subscription status transition")
+
+// IsLive reports whether a subscription in this state still serves requests
+func (s SubscriptionStatus) IsLive() bool {
+	return s == StatusTrialing || s == StatusActive || s == StatusPastDue
+}
+
 type Subscription struct {
-	ID                uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	UserID            uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
-	PlanType          PlanType       `gorm:"type:varchar(50);not null" json:"plan_type"`
-	RequestsPerMonth  int            `json:"requests_per_month"`
-	RequestsUsed      int            `gorm:"default:0" json:"requests_used"`
-	Price             float64        `json:"price"`
-	IsActive          bool           `gorm:"default:true" json:"is_active"`
-	StartsAt          time.Time      `json:"starts_at"`
-	EndsAt            *time.Time     `json:"ends_at"`
-	CreatedAt         time.Time      `json:"created_at"`
-	UpdatedAt         time.Time      `json:"updated_at"`
-	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
-	User              User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	ID                 uuid.UUID          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID             uuid.UUID          `gorm:"type:uuid;not null;index" json:"user_id"`
+	PlanType           PlanType           `gorm:"type:varchar(50);not null" json:"plan_type"`
+	RequestsPerMonth   int                `json:"requests_per_month"`
+	RequestsUsed       int                `gorm:"default:0" json:"requests_used"`
+	PrepaidCreditsUsed int                `gorm:"default:0" json:"prepaid_credits_used"` // part of RequestsUsed over the allowance paid from the credit ledger
+	Price              float64            `json:"price"`
+	IsActive           bool               `gorm:"default:true" json:"is_active"`
+	Status             SubscriptionStatus `gorm:"type:varchar(20);default:'active';index" json:"status"`
+	StartsAt           time.Time          `json:"starts_at"`
+	EndsAt             *time.Time         `json:"ends_at"`
+	TrialEndsAt        *time.Time         `json:"trial_ends_at,omitempty"`
+	CurrentPeriodStart time.Time          `json:"current_period_start"`
+	CurrentPeriodEnd   time.Time          `gorm:"index" json:"current_period_end"`
+	CancelAtPeriodEnd  bool               `gorm:"default:false" json:"cancel_at_period_end"`
+	CanceledAt         *time.Time         `json:"canceled_at,omitempty"`
+	PendingProration   float64            `gorm:"default:0" json:"pending_proration"` // charged (or credited if negative) on the next invoice
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt     `gorm:"index" json:"-"`
+	User               User               `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
 
 func (s *Subscription) BeforeCreate(tx *gorm.DB) error {
@@ -43,9 +79,120 @@ func (s *Subscription) HasRequestsAvailable() bool {
 	return s.RequestsUsed < s.RequestsPerMonth
 }
 
+// CurrentPeriod returns the billing period containing now. Subscriptions
+// created before periods were stored count whole months from StartsAt.
+func (s *Subscription) CurrentPeriod(now time.Time) (time.Time, time.Time) {
+	if !s.CurrentPeriodEnd.IsZero() {
+		return s.CurrentPeriodStart, s.CurrentPeriodEnd
+	}
+
+	start := s.StartsAt
+	months := (now.Year()-start.Year())*12 + int(now.Month()-start.Month())
+
+	// A period starting late in the month may not have started yet in now's month
+	for months > 0 && AddMonths(start, months).After(now) {
+		months--
+	}
+	if months < 0 {
+		months = 0
+	}
+
+	return AddMonths(start, months), AddMonths(start, months+1)
+}
+
+// NextPeriodEnd returns the end of the period starting at periodEnd. Ends are
+// counted in whole months from the first paid period, so a subscription
+// started on Jan 31 renews on Feb 28 and then on Mar 31.
+func (s *Subscription) NextPeriodEnd(periodEnd time.Time) time.Time {
+	anchor := s.StartsAt
+	if s.TrialEndsAt != nil {
+		anchor = *s.TrialEndsAt
+	}
+
+	months := (periodEnd.Year()-anchor.Year())*12 + int(periodEnd.Month()-anchor.Month())
+	if months < 0 {
+		months = 0
+	}
+	for !AddMonths(anchor, months).After(periodEnd) {
+		months++
+	}
+
+	return AddMonths(anchor, months)
+}
+
+// AddMonths adds months to t, clamping the day to the last day of the
+// resulting month instead of overflowing into the next one as AddDate does
+func AddMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// TransitionTo moves the subscription to a new state, keeping IsActive in sync
+func (s *Subscription) TransitionTo(status SubscriptionStatus, at time.Time) error {
+	if s.Status == status {
+		return nil
+	}
+
+	allowed := false
+	for _, next := range subscriptionTransitions[s.Status] {
+		if next == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, s.Status, status)
+	}
+
+	s.Status = status
+	s.IsActive = status.IsLive()
+
+	switch status {
+	case StatusCanceled:
+		s.CanceledAt = &at
+		s.EndsAt = &at
+	case StatusExpired:
+		if s.EndsAt == nil {
+			s.EndsAt = &at
+		}
+	}
+
+	return nil
+}
+
 func (s *Subscription) IsExpired() bool {
 	if s.EndsAt == nil {
 		return false
 	}
 	return time.Now().After(*s.EndsAt)
 }
+
+// BillingPeriod is the archived usage of one closed billing period
+type BillingPeriod struct {
+	ID               uuid.UUID          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	SubscriptionID   uuid.UUID          `gorm:"type:uuid;not null;index" json:"subscription_id"`
+	UserID           uuid.UUID          `gorm:"type:uuid;not null;index" json:"user_id"`
+	PlanType         PlanType           `gorm:"type:varchar(50);not null" json:"plan_type"`
+	Status           SubscriptionStatus `gorm:"type:varchar(20)" json:"status"` // subscription state when the period closed
+	PeriodStart      time.Time          `json:"period_start"`
+	PeriodEnd        time.Time          `gorm:"index" json:"period_end"`
+	RequestsPerMonth int                `json:"requests_per_month"`
+	RequestsUsed     int                `json:"requests_used"`
+	PrepaidCredits   int                `gorm:"default:0" json:"prepaid_credits"` // usage paid from the credit ledger, not billed as overage
+	Price            float64            `json:"price"`
+	Proration        float64            `json:"proration"`
+	Trial            bool               `gorm:"default:false" json:"trial"`
+	CreatedAt        time.Time          `json:"created_at"`
+}
+
+func (p *BillingPeriod) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RollupGranularity string
+
+const (
+	RollupMinute RollupGranularity = "minute" // rolled up from request_logs
+	RollupHour   RollupGranularity = "hour"   // rolled up from minute buckets
+	RollupDay    RollupGranularity = "day"    // rolled up from hour buckets, UTC days
+)
+
+// Duration is the length of one bucket
+func (g RollupGranularity) Duration() time.Duration {
+	switch g {
+	case RollupHour:
+		return time.Hour
+	case RollupDay:
+		return 24 * time.Hour
+	default:
+		return time.Minute
+	}
+}
+
+// Truncate returns the start of the bucket t falls in
+func (g RollupGranularity) Truncate(t time.Time) time.Time {
+	return t.UTC().Truncate(g.Duration())
+}
+
+// RollupLatencyBounds are the upper bounds, in ms, of the latency histogram buckets.
+// The histogram has one more bucket for slower calls.
+var RollupLatencyBounds = []int64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// UsageRollup is the traffic of one API key to one chain method in one time bucket.
+// Percentiles of a single bucket are exact for minutes and estimated from the
+// latency histogram for hours and days, as histograms can be merged and percentiles cannot.
+type UsageRollup struct {
+	ID                uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID            uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex:idx_usage_rollup_bucket,priority:1" json:"user_id"`
+	Granularity       RollupGranularity `gorm:"type:varchar(10);not null;uniqueIndex:idx_usage_rollup_bucket,priority:2" json:"granularity"`
+	BucketStart       time.Time         `gorm:"not null;uniqueIndex:idx_usage_rollup_bucket,priority:3" json:"bucket_start"`
+	APIKeyID          uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex:idx_usage_rollup_bucket,priority:4" json:"api_key_id"`
+	Blockchain        string            `gorm:"not null;uniqueIndex:idx_usage_rollup_bucket,priority:5" json:"blockchain"`
+	Method            string            `gorm:"not null;uniqueIndex:idx_usage_rollup_bucket,priority:6" json:"method"`
+	Requests          int64             `json:"requests"`
+	Errors            int64             `json:"errors"`              // 4xx and 5xx responses and failed calls
+	ResponseTimeTotal int64             `json:"response_time_total"` // ms, for averages
+	P50ResponseTime   int64             `json:"p50_response_time"`   // ms
+	P95ResponseTime   int64             `json:"p95_response_time"`   // ms
+	P99ResponseTime   int64             `json:"p99_response_time"`   // ms
+	RequestBytes      int64             `json:"request_bytes"`
+	ResponseBytes     int64             `json:"response_bytes"`
+	Credits           int64             `json:"credits"`
+	LatencyHistogram  []int64           `gorm:"type:jsonb;serializer:json" json:"latency_histogram"` // calls per RollupLatencyBounds bucket
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+}
+
+func (r *UsageRollup) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// RollupCursor records how far request logs have been rolled up
+type RollupCursor struct {
+	Name        string    `gorm:"primary_key" json:"name"`
+	RolledUntil time.Time `gorm:"not null" json:"rolled_until"` // minute buckets before this are complete
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -8,6 +8,8 @@ import (
 	"io"
 	"math/big"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,11 +19,16 @@ import (
 const (
 	// USDT TRC20 contract address
 	USDTContractAddress = "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+
+	// DefaultAPIURL - TronGrid API с историей TRC20 переводов (у полной ноды ее нет)
+	DefaultAPIURL = "https://api.trongrid.io"
 )
 
 // Client - клиент для работы с Tron API
 type Client struct {
 	nodeURL    string
+	apiURL     string
+	apiKey     string
 	httpClient *http.Client
 }
 
@@ -29,12 +36,42 @@ type Client struct {
 func NewClient(nodeURL string) *Client {
 	return &Client{
 		nodeURL: nodeURL,
+		apiURL:  DefaultAPIURL,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 	}
 }
 
+// SetAPI - задает TronGrid-совместимый API для истории переводов (ключ необязателен)
+func (c *Client) SetAPI(apiURL, apiKey string) {
+	c.apiURL = apiURL
+	c.apiKey = apiKey
+}
+
+// TRC20Transfer - перевод TRC20 токена из истории адреса
+type TRC20Transfer struct {
+	TxID           string `json:"transaction_id"`
+	From           string `json:"from"`
+	To             string `json:"to"`
+	Value          string `json:"value"`           // В минимальных единицах токена
+	BlockTimestamp int64  `json:"block_timestamp"` // Время блока в миллисекундах
+}
+
+// TransactionInfo - блок и результат выполнения транзакции
+type TransactionInfo struct {
+	ID          string `json:"id"`
+	BlockNumber int64  `json:"blockNumber"`
+	Receipt     struct {
+		Result string `json:"result"` // SUCCESS для успешного вызова контракта
+	} `json:"receipt"`
+}
+
+// Succeeded - выполнился ли вызов контракта без ошибки
+func (info *TransactionInfo) Succeeded() bool {
+	return info.Receipt.Result == "SUCCESS"
+}
+
 // BalanceResponse - ответ с балансами
 type BalanceResponse struct {
 	Address     string `json:"address"`
@@ -199,6 +236,125 @@ func (c *Client) GetUSDTBalance(address string) (string, error) {
 	return balance, nil
 }
 
+// GetNowBlockNumber - получает номер последнего блока (для подсчета подтверждений)
+func (c *Client) GetNowBlockNumber() (int64, error) {
+	resp, err := c.httpClient.Post(
+		c.nodeURL+"/wallet/getnowblock",
+		"application/json",
+		bytes.NewBufferString("{}"),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	// Парсим только номер блока из заголовка
+	var block struct {
+		BlockHeader struct {
+			RawData struct {
+				Number int64 `json:"number"`
+			} `json:"raw_data"`
+		} `json:"block_header"`
+	}
+	if err := json.Unmarshal(body, &block); err != nil {
+		return 0, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	if block.BlockHeader.RawData.Number == 0 {
+		return 0, fmt.Errorf("block number not found in response")
+	}
+
+	return block.BlockHeader.RawData.Number, nil
+}
+
+// GetIncomingTRC20Transfers - получает входящие переводы токена на адрес, начиная со
+// времени блока minTimestamp (в миллисекундах, включительно), от старых к новым
+func (c *Client) GetIncomingTRC20Transfers(address, contract string, minTimestamp int64, limit int) ([]TRC20Transfer, error) {
+	query := url.Values{}
+	query.Set("only_to", "true")
+	query.Set("contract_address", contract)
+	query.Set("min_timestamp", strconv.FormatInt(minTimestamp, 10))
+	query.Set("order_by", "block_timestamp,asc")
+	query.Set("limit", strconv.Itoa(limit))
+
+	req, err := http.NewRequest(http.MethodGet, c.apiURL+"/v1/accounts/"+url.PathEscape(address)+"/transactions/trc20?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("TRON-PRO-API-KEY", c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+
+	var result struct {
+		Data    []TRC20Transfer `json:"data"`
+		Success bool            `json:"success"`
+		Error   string          `json:"error"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+	if !result.Success {
+		return nil, fmt.Errorf("request failed: %s", result.Error)
+	}
+
+	return result.Data, nil
+}
+
+// GetTransactionInfo - получает блок и результат транзакции; nil, если транзакции нет в блоках ноды
+func (c *Client) GetTransactionInfo(txID string) (*TransactionInfo, error) {
+	jsonData, err := json.Marshal(map[string]string{"value": txID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	resp, err := c.httpClient.Post(
+		c.nodeURL+"/wallet/gettransactioninfobyid",
+		"application/json",
+		bytes.NewBuffer(jsonData),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	var info TransactionInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	// Для неизвестной транзакции нода возвращает пустой объект
+	if info.ID == "" {
+		return nil, nil
+	}
+
+	return &info, nil
+}
+
 // base58ToHex - конвертирует Tron base58 адрес в hex (упрощенная версия)
 func (c *Client) base58ToHex(address string) (string, error) {
 	// Для visible=true API Tron принимает base58 адреса напрямую
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"net"
+	"time"
 
 	"ironnode/pkg/config"
 	"ironnode/pkg/database"
@@ -32,15 +33,33 @@ func main() {
 	}
 
 	// Auto-migrate models
-	if err := db.AutoMigrate(&models.RequestLog{}); err != nil {
+	if err := db.AutoMigrate(&models.RequestLog{}, &models.UsageRollup{}, &models.RollupCursor{}, &models.RetentionCursor{}); err != nil {
 		logger.Fatal("Failed to migrate database:", err)
 	}
 
 	// Initialize repository, service, and handler
 	analyticsRepo := repository.NewAnalyticsRepository(db)
-	analyticsService := service.NewAnalyticsService(analyticsRepo)
+	rollupRepo := repository.NewRollupRepository(db)
+	retentionRepo := repository.NewRetentionRepository(db)
+	analyticsService := service.NewAnalyticsService(analyticsRepo, rollupRepo)
 	analyticsHandler := handler.NewAnalyticsHandler(analyticsService)
 
+	// Roll request logs up into usage buckets in the background
+	rollups := service.NewRollupWorker(rollupRepo, cfg.Analytics.RollupInterval, cfg.Analytics.RollupDelay)
+	rollups.Start()
+	defer rollups.Stop()
+
+	// Archive and purge request logs past their plan's retention
+	retention := make(map[models.PlanType]time.Duration)
+	for plan, days := range cfg.Analytics.RetentionDays {
+		if days > 0 {
+			retention[models.PlanType(plan)] = time.Duration(days) * 24 * time.Hour
+		}
+	}
+	retentionWorker := service.NewRetentionWorker(retentionRepo, rollupRepo, retention, cfg.Analytics.ArchiveDir, cfg.Analytics.RetentionInterval)
+	retentionWorker.Start()
+	defer retentionWorker.Stop()
+
 	// Create gRPC server
 	grpcServer := grpc.NewServer()
 	pb.RegisterAnalyticsServiceServer(grpcServer, analyticsHandler)
@@ -2,8 +2,11 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"ironnode/pkg/models"
+	"ironnode/services/analytics-service/internal/repository"
 	"ironnode/services/analytics-service/internal/service"
 	pb "ironnode/services/analytics-service/proto"
 
@@ -45,6 +48,9 @@ func (h *AnalyticsHandler) LogRequest(ctx context.Context, req *pb.LogRequestReq
 		req.IpAddress,
 		req.UserAgent,
 		req.Error,
+		req.CacheHit,
+		req.Coalesced,
+		req.Credits,
 	)
 
 	if err != nil {
@@ -57,12 +63,12 @@ func (h *AnalyticsHandler) LogRequest(ctx context.Context, req *pb.LogRequestReq
 }
 
 func (h *AnalyticsHandler) GetRequestHistory(ctx context.Context, req *pb.GetRequestHistoryRequest) (*pb.GetRequestHistoryResponse, error) {
-	userID, err := uuid.Parse(req.UserId)
+	filter, err := parseFilter(req.UserId, req.ApiKeyId, req.Blockchain, req.StartDate, req.EndDate)
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+		return nil, err
 	}
 
-	logs, err := h.analyticsService.GetRequestHistory(userID, int(req.Limit))
+	logs, err := h.analyticsService.GetRequestHistory(filter, int(req.Limit))
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to get request history: %v", err)
 	}
@@ -84,6 +90,9 @@ func (h *AnalyticsHandler) GetRequestHistory(ctx context.Context, req *pb.GetReq
 			UserAgent:    log.UserAgent,
 			Error:        log.Error,
 			CreatedAt:    log.CreatedAt.Format(time.RFC3339),
+			CacheHit:     log.CacheHit,
+			Credits:      log.Credits,
+			Coalesced:    log.Coalesced,
 		})
 	}
 
@@ -93,30 +102,154 @@ func (h *AnalyticsHandler) GetRequestHistory(ctx context.Context, req *pb.GetReq
 }
 
 func (h *AnalyticsHandler) GetUsageStats(ctx context.Context, req *pb.GetUsageStatsRequest) (*pb.GetUsageStatsResponse, error) {
-	userID, err := uuid.Parse(req.UserId)
+	filter, err := parseFilter(req.UserId, req.ApiKeyId, req.Blockchain, req.StartDate, req.EndDate)
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+		return nil, err
 	}
 
-	startDate, err := time.Parse(time.RFC3339, req.StartDate)
+	stats, err := h.analyticsService.GetUsageStats(filter)
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid start date: %v", err)
+		return nil, usageError("failed to get usage stats", err)
+	}
+
+	successRate := float64(0)
+	if stats.Requests > 0 {
+		successRate = float64(stats.Requests-stats.Errors) / float64(stats.Requests) * 100
 	}
 
-	endDate, err := time.Parse(time.RFC3339, req.EndDate)
+	return &pb.GetUsageStatsResponse{
+		TotalRequests:       stats.Requests,
+		SuccessfulRequests:  stats.Requests - stats.Errors,
+		FailedRequests:      stats.Errors,
+		SuccessRate:         successRate,
+		AverageResponseTime: averageResponseTime(stats),
+		P50ResponseTime:     stats.P50ResponseTime,
+		P95ResponseTime:     stats.P95ResponseTime,
+		P99ResponseTime:     stats.P99ResponseTime,
+		RequestBytes:        stats.RequestBytes,
+		ResponseBytes:       stats.ResponseBytes,
+		Credits:             stats.Credits,
+	}, nil
+}
+
+func (h *AnalyticsHandler) GetUsageTimeSeries(ctx context.Context, req *pb.GetUsageTimeSeriesRequest) (*pb.GetUsageTimeSeriesResponse, error) {
+	filter, err := parseFilter(req.UserId, req.ApiKeyId, req.Blockchain, req.StartDate, req.EndDate)
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid end date: %v", err)
+		return nil, err
 	}
+	filter.Method = req.Method
 
-	stats, err := h.analyticsService.GetUsageStats(userID, startDate, endDate)
+	series, err := h.analyticsService.GetUsageTimeSeries(filter, models.RollupGranularity(req.Granularity))
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get usage stats: %v", err)
+		return nil, usageError("failed to get usage time series", err)
 	}
 
-	return &pb.GetUsageStatsResponse{
-		TotalRequests:       stats["total_requests"].(int64),
-		SuccessfulRequests:  stats["successful_requests"].(int64),
-		SuccessRate:         stats["success_rate"].(float64),
-		AverageResponseTime: stats["average_response_time"].(int64),
+	points := make([]*pb.UsagePoint, 0, len(series.Points))
+	for _, point := range series.Points {
+		points = append(points, &pb.UsagePoint{
+			Timestamp:           point.BucketStart.Format(time.RFC3339),
+			Requests:            point.Requests,
+			Errors:              point.Errors,
+			AverageResponseTime: averageResponseTime(point),
+			P50ResponseTime:     point.P50ResponseTime,
+			P95ResponseTime:     point.P95ResponseTime,
+			P99ResponseTime:     point.P99ResponseTime,
+			RequestBytes:        point.RequestBytes,
+			ResponseBytes:       point.ResponseBytes,
+			Credits:             point.Credits,
+		})
+	}
+
+	return &pb.GetUsageTimeSeriesResponse{
+		Granularity: string(series.Granularity),
+		Points:      points,
+	}, nil
+}
+
+func (h *AnalyticsHandler) GetUsageBreakdown(ctx context.Context, req *pb.GetUsageBreakdownRequest) (*pb.GetUsageBreakdownResponse, error) {
+	filter, err := parseFilter(req.UserId, req.ApiKeyId, req.Blockchain, req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	filter.Method = req.Method
+
+	groups, err := h.analyticsService.GetUsageBreakdown(filter, service.UsageGroupBy(req.GroupBy))
+	if err != nil {
+		return nil, usageError("failed to get usage breakdown", err)
+	}
+
+	pbGroups := make([]*pb.UsageGroup, 0, len(groups))
+	for _, group := range groups {
+		pbGroups = append(pbGroups, &pb.UsageGroup{
+			Key:                 group.Key,
+			Requests:            group.Usage.Requests,
+			Errors:              group.Usage.Errors,
+			AverageResponseTime: averageResponseTime(group.Usage),
+			P50ResponseTime:     group.Usage.P50ResponseTime,
+			P95ResponseTime:     group.Usage.P95ResponseTime,
+			P99ResponseTime:     group.Usage.P99ResponseTime,
+			RequestBytes:        group.Usage.RequestBytes,
+			ResponseBytes:       group.Usage.ResponseBytes,
+			Credits:             group.Usage.Credits,
+		})
+	}
+
+	return &pb.GetUsageBreakdownResponse{
+		GroupBy: req.GroupBy,
+		Groups:  pbGroups,
 	}, nil
 }
+
+func averageResponseTime(rollup *models.UsageRollup) int64 {
+	if rollup.Requests == 0 {
+		return 0
+	}
+	return rollup.ResponseTimeTotal / rollup.Requests
+}
+
+func usageError(message string, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidGranularity),
+		errors.Is(err, service.ErrInvalidRange),
+		errors.Is(err, service.ErrRangeTooLong),
+		errors.Is(err, service.ErrInvalidGroupBy):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", message, err)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", message, err)
+	}
+}
+
+// parseFilter builds a request log filter; empty API key, chain and dates match everything
+func parseFilter(userID, apiKeyID, blockchain, startDate, endDate string) (repository.RequestFilter, error) {
+	filter := repository.RequestFilter{Blockchain: blockchain}
+
+	var err error
+	filter.UserID, err = uuid.Parse(userID)
+	if err != nil {
+		return filter, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+
+	if apiKeyID != "" {
+		id, err := uuid.Parse(apiKeyID)
+		if err != nil {
+			return filter, status.Errorf(codes.InvalidArgument, "invalid API key ID: %v", err)
+		}
+		filter.APIKeyID = &id
+	}
+
+	if startDate != "" {
+		filter.StartDate, err = time.Parse(time.RFC3339, startDate)
+		if err != nil {
+			return filter, status.Errorf(codes.InvalidArgument, "invalid start date: %v", err)
+		}
+	}
+
+	if endDate != "" {
+		filter.EndDate, err = time.Parse(time.RFC3339, endDate)
+		if err != nil {
+			return filter, status.Errorf(codes.InvalidArgument, "invalid end date: %v", err)
+		}
+	}
+
+	return filter, nil
+}
@@ -9,10 +9,40 @@ import (
 	"gorm.io/gorm"
 )
 
+// RequestFilter selects a user's request logs or rollups; zero fields match everything
+type RequestFilter struct {
+	UserID     uuid.UUID
+	APIKeyID   *uuid.UUID
+	Blockchain string
+	Method     string
+	StartDate  time.Time
+	EndDate    time.Time
+}
+
+// apply narrows a query to the filter, bounding timeColumn by the dates
+func (f RequestFilter) apply(query *gorm.DB, timeColumn string) *gorm.DB {
+	query = query.Where("user_id = ?", f.UserID)
+	if f.APIKeyID != nil {
+		query = query.Where("api_key_id = ?", *f.APIKeyID)
+	}
+	if f.Blockchain != "" {
+		query = query.Where("blockchain = ?", f.Blockchain)
+	}
+	if f.Method != "" {
+		query = query.Where("method = ?", f.Method)
+	}
+	if !f.StartDate.IsZero() {
+		query = query.Where(timeColumn+" >= ?", f.StartDate)
+	}
+	if !f.EndDate.IsZero() {
+		query = query.Where(timeColumn+" <= ?", f.EndDate)
+	}
+	return query
+}
+
 type AnalyticsRepository interface {
 	LogRequest(log *models.RequestLog) error
-	GetRequestsByUser(userID uuid.UUID, limit int) ([]*models.RequestLog, error)
-	GetUsageStats(userID uuid.UUID, startDate, endDate time.Time) (map[string]interface{}, error)
+	GetRequests(filter RequestFilter, limit int) ([]*models.RequestLog, error)
 }
 
 type analyticsRepository struct {
@@ -27,52 +57,11 @@ func (r *analyticsRepository) LogRequest(log *models.RequestLog) error {
 	return r.db.Create(log).Error
 }
 
-func (r *analyticsRepository) GetRequestsByUser(userID uuid.UUID, limit int) ([]*models.RequestLog, error) {
+func (r *analyticsRepository) GetRequests(filter RequestFilter, limit int) ([]*models.RequestLog, error) {
 	var logs []*models.RequestLog
-	err := r.db.Where("user_id = ?", userID).
+	err := filter.apply(r.db, "created_at").
 		Order("created_at DESC").
 		Limit(limit).
 		Find(&logs).Error
 	return logs, err
 }
-
-func (r *analyticsRepository) GetUsageStats(userID uuid.UUID, startDate, endDate time.Time) (map[string]interface{}, error) {
-	var totalRequests int64
-	var successfulRequests int64
-	var totalResponseTime int64
-
-	// Total requests
-	r.db.Model(&models.RequestLog{}).
-		Where("user_id = ? AND created_at BETWEEN ? AND ?", userID, startDate, endDate).
-		Count(&totalRequests)
-
-	// Successful requests
-	r.db.Model(&models.RequestLog{}).
-		Where("user_id = ? AND status_code = ? AND created_at BETWEEN ? AND ?", userID, 200, startDate, endDate).
-		Count(&successfulRequests)
-
-	// Average response time
-	r.db.Model(&models.RequestLog{}).
-		Where("user_id = ? AND created_at BETWEEN ? AND ?", userID, startDate, endDate).
-		Select("COALESCE(SUM(response_time), 0)").
-		Scan(&totalResponseTime)
-
-	avgResponseTime := int64(0)
-	if totalRequests > 0 {
-		avgResponseTime = totalResponseTime / totalRequests
-	}
-
-	successRate := float64(0)
-	if totalRequests > 0 {
-		successRate = (float64(successfulRequests) / float64(totalRequests)) * 100
-	}
-
-	stats := map[string]interface{}{
-		"total_requests":        totalRequests,
-		"successful_requests":   successfulRequests,
-		"success_rate":          successRate,
-		"average_response_time": avgResponseTime,
-	}
-
-	return stats, nil
-}
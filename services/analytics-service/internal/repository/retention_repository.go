@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"ironnode/pkg/database"
+	"ironnode/pkg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// requestLogsTable is the table the retention applies to
+const requestLogsTable = "request_logs"
+
+// exportBatchSize is how many request logs are read from the database at once while archiving
+const exportBatchSize = 1000
+
+// requestLogPlanSQL is the plan of the user of a request log, the latest live subscription's or free
+var requestLogPlanSQL = fmt.Sprintf(`COALESCE((
+	SELECT s.plan_type FROM subscriptions s
+	WHERE s.user_id = request_logs.user_id AND s.deleted_at IS NULL AND s.status IN ('%s', '%s', '%s')
+	ORDER BY s.created_at DESC LIMIT 1
+), '%s')`, models.StatusTrialing, models.StatusActive, models.StatusPastDue, models.FreePlan)
+
+type RetentionRepository interface {
+	IsPartitioned() (bool, error)
+	// CreatePartitions creates the missing daily partitions for the days from `from` to `to`
+	// and moves rows out of the default partition into partitions of their days
+	CreatePartitions(from, to time.Time) error
+	// ListPartitions returns the daily partitions, oldest first
+	ListPartitions() ([]database.DailyPartition, error)
+	// ExportPartition passes the rows of a partition to write in batches
+	ExportPartition(name string, write func(logs []*models.RequestLog) error) error
+	DropPartition(name string) error
+	// ExportLogs passes the request logs created in [from, to) to write in batches;
+	// only those of users on plan unless plan is empty
+	ExportLogs(from, to time.Time, plan models.PlanType, write func(logs []*models.RequestLog) error) error
+	// DeleteLogs deletes the request logs created in [from, to) in batches, only those
+	// of users on plan unless plan is empty, and returns how many were deleted
+	DeleteLogs(from, to time.Time, plan models.PlanType) (int64, error)
+	// GetPurgeCursor returns the end of the plan's last purge, or the zero time before the first one
+	GetPurgeCursor(plan models.PlanType) (time.Time, error)
+	SetPurgeCursor(plan models.PlanType, purgedUntil time.Time) error
+}
+
+type retentionRepository struct {
+	db *gorm.DB
+}
+
+func NewRetentionRepository(db *gorm.DB) RetentionRepository {
+	return &retentionRepository{db: db}
+}
+
+func (r *retentionRepository) IsPartitioned() (bool, error) {
+	return database.IsPartitioned(r.db, requestLogsTable)
+}
+
+func (r *retentionRepository) CreatePartitions(from, to time.Time) error {
+	return database.CreateDailyPartitions(r.db, requestLogsTable, "created_at", from, to)
+}
+
+func (r *retentionRepository) ListPartitions() ([]database.DailyPartition, error) {
+	return database.ListDailyPartitions(r.db, requestLogsTable)
+}
+
+func (r *retentionRepository) ExportPartition(name string, write func(logs []*models.RequestLog) error) error {
+	var logs []*models.RequestLog
+	return r.db.Table(name).
+		FindInBatches(&logs, exportBatchSize, func(tx *gorm.DB, batch int) error {
+			return write(logs)
+		}).Error
+}
+
+func (r *retentionRepository) DropPartition(name string) error {
+	return r.db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", name)).Error
+}
+
+func (r *retentionRepository) ExportLogs(from, to time.Time, plan models.PlanType, write func(logs []*models.RequestLog) error) error {
+	query := r.db.Where("created_at >= ? AND created_at < ?", from, to)
+	if plan != "" {
+		query = query.Where(requestLogPlanSQL+" = ?", plan)
+	}
+
+	var logs []*models.RequestLog
+	return query.FindInBatches(&logs, exportBatchSize, func(tx *gorm.DB, batch int) error {
+		return write(logs)
+	}).Error
+}
+
+func (r *retentionRepository) DeleteLogs(from, to time.Time, plan models.PlanType) (int64, error) {
+	var deleted int64
+	for {
+		batch := r.db.Model(&models.RequestLog{}).
+			Select("id").
+			Where("created_at >= ? AND created_at < ?", from, to).
+			Limit(exportBatchSize)
+		if plan != "" {
+			batch = batch.Where(requestLogPlanSQL+" = ?", plan)
+		}
+
+		result := r.db.Where("id IN (?)", batch).Delete(&models.RequestLog{})
+		if result.Error != nil {
+			return deleted, result.Error
+		}
+		deleted += result.RowsAffected
+		if result.RowsAffected < exportBatchSize {
+			return deleted, nil
+		}
+	}
+}
+
+func (r *retentionRepository) GetPurgeCursor(plan models.PlanType) (time.Time, error) {
+	var cursor models.RetentionCursor
+	err := r.db.Where("plan = ?", plan).First(&cursor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, nil
+	}
+	return cursor.PurgedUntil, err
+}
+
+func (r *retentionRepository) SetPurgeCursor(plan models.PlanType, purgedUntil time.Time) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "plan"}},
+		DoUpdates: clause.AssignmentColumns([]string{"purged_until", "updated_at"}),
+	}).Create(&models.RetentionCursor{Plan: plan, PurgedUntil: purgedUntil}).Error
+}
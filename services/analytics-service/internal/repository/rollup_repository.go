@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ironnode/pkg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// rollupCursorName names the cursor of the minute rollups
+const rollupCursorName = "request_logs"
+
+// rollupBucketColumns identify a rollup bucket
+var rollupBucketColumns = []string{"user_id", "granularity", "bucket_start", "api_key_id", "blockchain", "method"}
+
+// rollUpMinutesSQL aggregates request logs into minute buckets, replacing buckets rolled up before
+var rollUpMinutesSQL = fmt.Sprintf(`
+INSERT INTO usage_rollups (
+	user_id, granularity, bucket_start, api_key_id, blockchain, method,
+	requests, errors, response_time_total,
+	p50_response_time, p95_response_time, p99_response_time, latency_histogram,
+	request_bytes, response_bytes, credits, created_at, updated_at
+)
+SELECT
+	user_id, 'minute', date_trunc('minute', created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+	api_key_id, COALESCE(blockchain, ''), COALESCE(method, ''),
+	count(*),
+	count(*) FILTER (WHERE status_code >= 400 OR error <> ''),
+	COALESCE(sum(response_time), 0),
+	round(percentile_cont(0.5) WITHIN GROUP (ORDER BY response_time)),
+	round(percentile_cont(0.95) WITHIN GROUP (ORDER BY response_time)),
+	round(percentile_cont(0.99) WITHIN GROUP (ORDER BY response_time)),
+	%s,
+	COALESCE(sum(request_size), 0), COALESCE(sum(response_size), 0), COALESCE(sum(credits), 0),
+	now(), now()
+FROM request_logs
+WHERE created_at >= ? AND created_at < ?
+GROUP BY 1, 3, 4, 5, 6
+ON CONFLICT (%s) DO UPDATE SET
+	requests = EXCLUDED.requests,
+	errors = EXCLUDED.errors,
+	response_time_total = EXCLUDED.response_time_total,
+	p50_response_time = EXCLUDED.p50_response_time,
+	p95_response_time = EXCLUDED.p95_response_time,
+	p99_response_time = EXCLUDED.p99_response_time,
+	latency_histogram = EXCLUDED.latency_histogram,
+	request_bytes = EXCLUDED.request_bytes,
+	response_bytes = EXCLUDED.response_bytes,
+	credits = EXCLUDED.credits,
+	updated_at = EXCLUDED.updated_at`,
+	latencyHistogramSQL(), strings.Join(rollupBucketColumns, ", "))
+
+// latencyHistogramSQL counts the calls in each models.RollupLatencyBounds bucket as a JSON array
+func latencyHistogramSQL() string {
+	bounds := models.RollupLatencyBounds
+
+	counts := make([]string, 0, len(bounds)+1)
+	counts = append(counts, fmt.Sprintf("count(*) FILTER (WHERE response_time <= %d)", bounds[0]))
+	for i := 1; i < len(bounds); i++ {
+		counts = append(counts, fmt.Sprintf("count(*) FILTER (WHERE response_time > %d AND response_time <= %d)", bounds[i-1], bounds[i]))
+	}
+	counts = append(counts, fmt.Sprintf("count(*) FILTER (WHERE response_time > %d)", bounds[len(bounds)-1]))
+
+	return "jsonb_build_array(" + strings.Join(counts, ", ") + ")"
+}
+
+type RollupRepository interface {
+	// GetCursor returns the end of the last rollup, or the zero time before the first one
+	GetCursor() (time.Time, error)
+	SetCursor(rolledUntil time.Time) error
+	// EarliestRequest returns when the oldest request log was created; false if there are none
+	EarliestRequest() (time.Time, bool, error)
+	// RollUpMinutes aggregates the request logs created in [from, to) into minute buckets
+	RollUpMinutes(from, to time.Time) error
+	// ListBuckets returns the rollups of every user with from <= bucket_start < to
+	ListBuckets(granularity models.RollupGranularity, from, to time.Time) ([]*models.UsageRollup, error)
+	// SaveBuckets inserts rollups, replacing the ones of the same bucket
+	SaveBuckets(rollups []*models.UsageRollup) error
+	// ListRollups returns the filter's rollups, oldest first; the filter's dates bound bucket_start
+	ListRollups(filter RequestFilter, granularity models.RollupGranularity) ([]*models.UsageRollup, error)
+}
+
+type rollupRepository struct {
+	db *gorm.DB
+}
+
+func NewRollupRepository(db *gorm.DB) RollupRepository {
+	return &rollupRepository{db: db}
+}
+
+func (r *rollupRepository) GetCursor() (time.Time, error) {
+	var cursor models.RollupCursor
+	err := r.db.Where("name = ?", rollupCursorName).First(&cursor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, nil
+	}
+	return cursor.RolledUntil, err
+}
+
+func (r *rollupRepository) SetCursor(rolledUntil time.Time) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rolled_until", "updated_at"}),
+	}).Create(&models.RollupCursor{Name: rollupCursorName, RolledUntil: rolledUntil}).Error
+}
+
+func (r *rollupRepository) EarliestRequest() (time.Time, bool, error) {
+	var logs []*models.RequestLog
+	err := r.db.Select("created_at").Order("created_at").Limit(1).Find(&logs).Error
+	if err != nil || len(logs) == 0 {
+		return time.Time{}, false, err
+	}
+	return logs[0].CreatedAt, true, nil
+}
+
+func (r *rollupRepository) RollUpMinutes(from, to time.Time) error {
+	return r.db.Exec(rollUpMinutesSQL, from, to).Error
+}
+
+func (r *rollupRepository) ListBuckets(granularity models.RollupGranularity, from, to time.Time) ([]*models.UsageRollup, error) {
+	var rollups []*models.UsageRollup
+	err := r.db.Where("granularity = ? AND bucket_start >= ? AND bucket_start < ?", granularity, from, to).
+		Find(&rollups).Error
+	return rollups, err
+}
+
+func (r *rollupRepository) SaveBuckets(rollups []*models.UsageRollup) error {
+	if len(rollups) == 0 {
+		return nil
+	}
+
+	columns := make([]clause.Column, 0, len(rollupBucketColumns))
+	for _, name := range rollupBucketColumns {
+		columns = append(columns, clause.Column{Name: name})
+	}
+
+	return r.db.Clauses(clause.OnConflict{
+		Columns: columns,
+		DoUpdates: clause.AssignmentColumns([]string{
+			"requests", "errors", "response_time_total",
+			"p50_response_time", "p95_response_time", "p99_response_time", "latency_histogram",
+			"request_bytes", "response_bytes", "credits", "updated_at",
+		}),
+	}).CreateInBatches(rollups, 500).Error
+}
+
+func (r *rollupRepository) ListRollups(filter RequestFilter, granularity models.RollupGranularity) ([]*models.UsageRollup, error) {
+	var rollups []*models.UsageRollup
+	err := filter.apply(r.db, "bucket_start").
+		Where("granularity = ?", granularity).
+		Order("bucket_start").
+		Find(&rollups).Error
+	return rollups, err
+}
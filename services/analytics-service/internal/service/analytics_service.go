@@ -1,6 +1,8 @@
 package service
 
 import (
+	"errors"
+	"sort"
 	"time"
 
 	"ironnode/pkg/models"
@@ -9,18 +11,77 @@ import (
 	"github.com/google/uuid"
 )
 
+// maxSeriesPoints bounds the buckets of one time series
+const maxSeriesPoints = 1500
+
+var (
+	ErrInvalidGranularity = errors.New("granularity must be minute, hour or day")
+	ErrInvalidRange       = errors.New("end date is before start date")
+	ErrRangeTooLong       = errors.New("too many buckets for the range; use a coarser granularity")
+	ErrInvalidGroupBy     = errors.New("group_by must be api_key, method or blockchain")
+)
+
+// UsageGroupBy is the dimension a usage breakdown groups traffic by
+type UsageGroupBy string
+
+const (
+	GroupByAPIKey     UsageGroupBy = "api_key"
+	GroupByMethod     UsageGroupBy = "method"
+	GroupByBlockchain UsageGroupBy = "blockchain"
+)
+
+// key returns the group a rollup belongs to
+func (g UsageGroupBy) key(rollup *models.UsageRollup) string {
+	switch g {
+	case GroupByAPIKey:
+		return rollup.APIKeyID.String()
+	case GroupByMethod:
+		return rollup.Method
+	default:
+		return rollup.Blockchain
+	}
+}
+
+// defaultSeriesRange is the period a series covers when no start date is given
+var defaultSeriesRange = map[models.RollupGranularity]time.Duration{
+	models.RollupMinute: time.Hour,
+	models.RollupHour:   24 * time.Hour,
+	models.RollupDay:    30 * 24 * time.Hour,
+}
+
+// UsageSeries is a user's traffic bucketed for charting
+type UsageSeries struct {
+	Granularity models.RollupGranularity
+	Points      []*models.UsageRollup // one per bucket, oldest first, with empty buckets included
+}
+
+// UsageGroup is the traffic of one API key, method or chain
+type UsageGroup struct {
+	Key   string // API key ID, method or blockchain
+	Usage *models.UsageRollup
+}
+
 type AnalyticsService interface {
-	LogRequest(userID, apiKeyID uuid.UUID, blockchain, method, endpoint string, statusCode int, responseTime, requestSize, responseSize int64, ipAddress, userAgent, errorMsg string) error
-	GetRequestHistory(userID uuid.UUID, limit int) ([]*models.RequestLog, error)
-	GetUsageStats(userID uuid.UUID, startDate, endDate time.Time) (map[string]interface{}, error)
+	LogRequest(userID, apiKeyID uuid.UUID, blockchain, method, endpoint string, statusCode int, responseTime, requestSize, responseSize int64, ipAddress, userAgent, errorMsg string, cacheHit, coalesced bool, credits int64) error
+	GetRequestHistory(filter repository.RequestFilter, limit int) ([]*models.RequestLog, error)
+	// GetUsageStats totals the traffic over the filter's dates, by default the last 30 days.
+	// It is read from rollups, so the range is widened to whole buckets.
+	GetUsageStats(filter repository.RequestFilter) (*models.UsageRollup, error)
+	// GetUsageTimeSeries buckets the traffic over the filter's dates; an empty
+	// granularity picks one for the range
+	GetUsageTimeSeries(filter repository.RequestFilter, granularity models.RollupGranularity) (*UsageSeries, error)
+	// GetUsageBreakdown totals the traffic over the filter's dates per API key, method or chain,
+	// busiest first; the range is widened to whole buckets as for GetUsageStats
+	GetUsageBreakdown(filter repository.RequestFilter, groupBy UsageGroupBy) ([]*UsageGroup, error)
 }
 
 type analyticsService struct {
-	repo repository.AnalyticsRepository
+	repo    repository.AnalyticsRepository
+	rollups repository.RollupRepository
 }
 
-func NewAnalyticsService(repo repository.AnalyticsRepository) AnalyticsService {
-	return &analyticsService{repo: repo}
+func NewAnalyticsService(repo repository.AnalyticsRepository, rollups repository.RollupRepository) AnalyticsService {
+	return &analyticsService{repo: repo, rollups: rollups}
 }
 
 func (s *analyticsService) LogRequest(
@@ -29,6 +90,8 @@ func (s *analyticsService) LogRequest(
 	statusCode int,
 	responseTime, requestSize, responseSize int64,
 	ipAddress, userAgent, errorMsg string,
+	cacheHit, coalesced bool,
+	credits int64,
 ) error {
 	log := &models.RequestLog{
 		UserID:       userID,
@@ -43,15 +106,140 @@ func (s *analyticsService) LogRequest(
 		IPAddress:    ipAddress,
 		UserAgent:    userAgent,
 		Error:        errorMsg,
+		CacheHit:     cacheHit,
+		Coalesced:    coalesced,
+		Credits:      credits,
 	}
 
 	return s.repo.LogRequest(log)
 }
 
-func (s *analyticsService) GetRequestHistory(userID uuid.UUID, limit int) ([]*models.RequestLog, error) {
-	return s.repo.GetRequestsByUser(userID, limit)
+func (s *analyticsService) GetRequestHistory(filter repository.RequestFilter, limit int) ([]*models.RequestLog, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return s.repo.GetRequests(filter, limit)
+}
+
+func (s *analyticsService) GetUsageStats(filter repository.RequestFilter) (*models.UsageRollup, error) {
+	filter, granularity, err := seriesRange(filter, "")
+	if err != nil {
+		return nil, err
+	}
+
+	rollups, err := s.rollups.ListRollups(filter, granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeRollups(rollups), nil
 }
 
-func (s *analyticsService) GetUsageStats(userID uuid.UUID, startDate, endDate time.Time) (map[string]interface{}, error) {
-	return s.repo.GetUsageStats(userID, startDate, endDate)
+func (s *analyticsService) GetUsageTimeSeries(filter repository.RequestFilter, granularity models.RollupGranularity) (*UsageSeries, error) {
+	filter, granularity, err := seriesRange(filter, granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	rollups, err := s.rollups.ListRollups(filter, granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	byBucket := make(map[int64][]*models.UsageRollup)
+	for _, rollup := range rollups {
+		start := rollup.BucketStart.Unix()
+		byBucket[start] = append(byBucket[start], rollup)
+	}
+
+	series := &UsageSeries{Granularity: granularity}
+	for start := filter.StartDate; !start.After(filter.EndDate); start = start.Add(granularity.Duration()) {
+		point := mergeRollups(byBucket[start.Unix()])
+		point.Granularity = granularity
+		point.BucketStart = start
+		series.Points = append(series.Points, point)
+	}
+
+	return series, nil
+}
+
+func (s *analyticsService) GetUsageBreakdown(filter repository.RequestFilter, groupBy UsageGroupBy) ([]*UsageGroup, error) {
+	switch groupBy {
+	case GroupByAPIKey, GroupByMethod, GroupByBlockchain:
+	default:
+		return nil, ErrInvalidGroupBy
+	}
+
+	filter, granularity, err := seriesRange(filter, "")
+	if err != nil {
+		return nil, err
+	}
+
+	rollups, err := s.rollups.ListRollups(filter, granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	byGroup := make(map[string][]*models.UsageRollup)
+	for _, rollup := range rollups {
+		key := groupBy.key(rollup)
+		byGroup[key] = append(byGroup[key], rollup)
+	}
+
+	groups := make([]*UsageGroup, 0, len(byGroup))
+	for key, group := range byGroup {
+		groups = append(groups, &UsageGroup{Key: key, Usage: mergeRollups(group)})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Usage.Requests != groups[j].Usage.Requests {
+			return groups[i].Usage.Requests > groups[j].Usage.Requests
+		}
+		return groups[i].Key < groups[j].Key
+	})
+
+	return groups, nil
+}
+
+// seriesRange fills in the default dates and granularity and aligns the start
+// date to a bucket. Without a granularity, the coarsest one that still charts
+// the range in some detail is used.
+func seriesRange(filter repository.RequestFilter, granularity models.RollupGranularity) (repository.RequestFilter, models.RollupGranularity, error) {
+	if granularity != "" {
+		if _, ok := defaultSeriesRange[granularity]; !ok {
+			return filter, "", ErrInvalidGranularity
+		}
+	}
+
+	if filter.EndDate.IsZero() {
+		filter.EndDate = time.Now()
+	}
+	filter.EndDate = filter.EndDate.UTC()
+
+	if filter.StartDate.IsZero() {
+		filter.StartDate = filter.EndDate.Add(-defaultSeriesRange[granularity])
+		if granularity == "" {
+			filter.StartDate = filter.EndDate.Add(-defaultSeriesRange[models.RollupDay])
+		}
+	}
+	if filter.EndDate.Before(filter.StartDate) {
+		return filter, "", ErrInvalidRange
+	}
+
+	if granularity == "" {
+		switch span := filter.EndDate.Sub(filter.StartDate); {
+		case span <= 6*time.Hour:
+			granularity = models.RollupMinute
+		case span <= 14*24*time.Hour:
+			granularity = models.RollupHour
+		default:
+			granularity = models.RollupDay
+		}
+	}
+
+	filter.StartDate = granularity.Truncate(filter.StartDate)
+	if filter.EndDate.Sub(filter.StartDate)/granularity.Duration() >= maxSeriesPoints {
+		return filter, "", ErrRangeTooLong
+	}
+
+	return filter, granularity, nil
 }
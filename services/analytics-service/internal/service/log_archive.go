@@ -0,0 +1,73 @@
+package service
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"ironnode/pkg/models"
+)
+
+// logArchive writes request logs as gzipped NDJSON, one log per line. The file
+// only appears under its name once committed, so a failed purge leaves no
+// partial archive behind.
+type logArchive struct {
+	path    string
+	file    *os.File
+	gzip    *gzip.Writer
+	encoder *json.Encoder
+	count   int
+}
+
+func createLogArchive(dir, name string) (*logArchive, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	file, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(file)
+	return &logArchive{
+		path:    filepath.Join(dir, name),
+		file:    file,
+		gzip:    gz,
+		encoder: json.NewEncoder(gz),
+	}, nil
+}
+
+func (a *logArchive) Write(logs []*models.RequestLog) error {
+	for _, entry := range logs {
+		if err := a.encoder.Encode(entry); err != nil {
+			return err
+		}
+		a.count++
+	}
+	return nil
+}
+
+// Commit flushes the archive to disk and moves it into place
+func (a *logArchive) Commit() error {
+	if err := a.gzip.Close(); err != nil {
+		a.Abort()
+		return err
+	}
+	if err := a.file.Sync(); err != nil {
+		a.Abort()
+		return err
+	}
+	if err := a.file.Close(); err != nil {
+		os.Remove(a.file.Name())
+		return err
+	}
+	return os.Rename(a.file.Name(), a.path)
+}
+
+// Abort discards the archive
+func (a *logArchive) Abort() {
+	a.file.Close()
+	os.Remove(a.file.Name())
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"ironnode/pkg/models"
+	"ironnode/services/analytics-service/internal/repository"
+)
+
+const (
+	// partitionsAhead is how many days of request log partitions are created in advance
+	partitionsAhead = 7
+	// maxPurgeDays bounds the days purged per plan in one pass while catching up
+	maxPurgeDays = 31
+)
+
+// RetentionWorker keeps raw request logs only as long as the plan of their user
+// allows, archiving them to gzipped NDJSON files before they are deleted. Logs
+// past the longest retention are dropped a whole day partition at a time when
+// request_logs is partitioned; shorter retentions delete the rows of their
+// plan's users. Logs that have not been rolled up yet are never purged, so
+// usage stats outlive the raw logs.
+type RetentionWorker interface {
+	Start()
+	Stop()
+	// Purge creates upcoming partitions and archives and deletes the logs expired at now
+	Purge(now time.Time) error
+}
+
+type retentionWorker struct {
+	repo       repository.RetentionRepository
+	rollups    repository.RollupRepository
+	retention  map[models.PlanType]time.Duration
+	archiveDir string
+	interval   time.Duration
+
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewRetentionWorker creates the worker. retention maps plans to how long their
+// users' logs are kept; the logs of other plans are kept as long as the longest one.
+func NewRetentionWorker(
+	repo repository.RetentionRepository,
+	rollups repository.RollupRepository,
+	retention map[models.PlanType]time.Duration,
+	archiveDir string,
+	interval time.Duration,
+) RetentionWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &retentionWorker{
+		repo:       repo,
+		rollups:    rollups,
+		retention:  retention,
+		archiveDir: archiveDir,
+		interval:   interval,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Start runs the retention loop in a background goroutine
+func (w *retentionWorker) Start() {
+	w.wg.Add(1)
+	go w.run()
+	log.Printf("[Retention] Started (interval: %v, archive: %s)", w.interval, w.archiveDir)
+}
+
+// Stop stops the retention loop and waits for it to finish
+func (w *retentionWorker) Stop() {
+	w.cancel()
+	w.wg.Wait()
+	log.Printf("[Retention] Stopped")
+}
+
+func (w *retentionWorker) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.purgeNow()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.purgeNow()
+		}
+	}
+}
+
+func (w *retentionWorker) purgeNow() {
+	if err := w.Purge(time.Now()); err != nil {
+		log.Printf("[Retention] Purge failed: %v", err)
+	}
+}
+
+func (w *retentionWorker) Purge(now time.Time) error {
+	today := models.RollupDay.Truncate(now)
+
+	partitioned, err := w.repo.IsPartitioned()
+	if err != nil {
+		return err
+	}
+	if partitioned {
+		if err := w.repo.CreatePartitions(today, today.AddDate(0, 0, partitionsAhead)); err != nil {
+			return err
+		}
+	}
+
+	rolledUntil, err := w.rollups.GetCursor()
+	if err != nil {
+		return err
+	}
+	if rolledUntil.IsZero() {
+		// Nothing rolled up yet
+		return nil
+	}
+	limit := models.RollupDay.Truncate(rolledUntil)
+
+	longest := w.longestRetention()
+	for _, plan := range w.plans() {
+		if w.retention[plan] == longest {
+			continue
+		}
+		if err := w.purgePlan(plan, w.cutoff(now, w.retention[plan], limit)); err != nil {
+			return fmt.Errorf("purge %s logs: %w", plan, err)
+		}
+	}
+
+	if longest == 0 {
+		return nil
+	}
+	cutoff := w.cutoff(now, longest, limit)
+	if partitioned {
+		return w.dropPartitions(cutoff)
+	}
+	return w.purgeAll(cutoff)
+}
+
+// cutoff is the day from which logs kept for retention are still kept, at most limit
+func (w *retentionWorker) cutoff(now time.Time, retention time.Duration, limit time.Time) time.Time {
+	cutoff := models.RollupDay.Truncate(now.Add(-retention))
+	if cutoff.After(limit) {
+		return limit
+	}
+	return cutoff
+}
+
+func (w *retentionWorker) longestRetention() time.Duration {
+	var longest time.Duration
+	for _, retention := range w.retention {
+		longest = max(longest, retention)
+	}
+	return longest
+}
+
+// plans returns the plans with a retention, shortest retention first
+func (w *retentionWorker) plans() []models.PlanType {
+	plans := make([]models.PlanType, 0, len(w.retention))
+	for plan := range w.retention {
+		plans = append(plans, plan)
+	}
+	sort.Slice(plans, func(i, j int) bool {
+		if w.retention[plans[i]] != w.retention[plans[j]] {
+			return w.retention[plans[i]] < w.retention[plans[j]]
+		}
+		return plans[i] < plans[j]
+	})
+	return plans
+}
+
+// purgePlan purges the logs of plan's users day by day, from its cursor up to cutoff
+func (w *retentionWorker) purgePlan(plan models.PlanType, cutoff time.Time) error {
+	from, err := w.repo.GetPurgeCursor(plan)
+	if err != nil {
+		return err
+	}
+	if from.IsZero() {
+		earliest, ok, err := w.rollups.EarliestRequest()
+		if err != nil || !ok {
+			return err
+		}
+		from = earliest
+	}
+
+	day := models.RollupDay.Truncate(from)
+	for i := 0; i < maxPurgeDays && day.Before(cutoff) && w.ctx.Err() == nil; i++ {
+		if err := w.purgeDay(day, plan); err != nil {
+			return err
+		}
+		day = day.AddDate(0, 0, 1)
+		if err := w.repo.SetPurgeCursor(plan, day); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purgeAll purges the logs of every user before cutoff, oldest day first
+func (w *retentionWorker) purgeAll(cutoff time.Time) error {
+	earliest, ok, err := w.rollups.EarliestRequest()
+	if err != nil || !ok {
+		return err
+	}
+
+	day := models.RollupDay.Truncate(earliest)
+	for i := 0; i < maxPurgeDays && day.Before(cutoff) && w.ctx.Err() == nil; i++ {
+		if err := w.purgeDay(day, ""); err != nil {
+			return err
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return nil
+}
+
+// purgeDay archives and deletes the logs of day, of plan's users unless plan is empty.
+// The day is past retention, so no logs arrive in it between the export and the delete.
+func (w *retentionWorker) purgeDay(day time.Time, plan models.PlanType) error {
+	end := day.AddDate(0, 0, 1)
+
+	archive, err := createLogArchive(w.archiveDir, archiveName(day, plan))
+	if err != nil {
+		return err
+	}
+
+	if err := w.repo.ExportLogs(day, end, plan, archive.Write); err != nil {
+		archive.Abort()
+		return err
+	}
+	if archive.count == 0 {
+		archive.Abort()
+		return nil
+	}
+	if err := archive.Commit(); err != nil {
+		return err
+	}
+
+	// Failing from here on archives the remaining logs again on the next pass
+	deleted, err := w.repo.DeleteLogs(day, end, plan)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[Retention] Archived %d request logs to %s and deleted %d", archive.count, archive.path, deleted)
+	return nil
+}
+
+// dropPartitions archives and drops the daily partitions that end by cutoff
+func (w *retentionWorker) dropPartitions(cutoff time.Time) error {
+	partitions, err := w.repo.ListPartitions()
+	if err != nil {
+		return err
+	}
+
+	for _, partition := range partitions {
+		if partition.End().After(cutoff) || w.ctx.Err() != nil {
+			break
+		}
+
+		archive, err := createLogArchive(w.archiveDir, archiveName(partition.Day, ""))
+		if err != nil {
+			return err
+		}
+		if err := w.repo.ExportPartition(partition.Name, archive.Write); err != nil {
+			archive.Abort()
+			return err
+		}
+
+		count := archive.count
+		if count == 0 {
+			archive.Abort()
+		} else if err := archive.Commit(); err != nil {
+			return err
+		}
+
+		if err := w.repo.DropPartition(partition.Name); err != nil {
+			return err
+		}
+		log.Printf("[Retention] Archived %d request logs and dropped partition %s", count, partition.Name)
+	}
+	return nil
+}
+
+// archiveName names the archive of day's logs, e.g. request_logs_2024-01-15_free_20240201T030000Z.ndjson.gz.
+// The archiving time keeps logs of the same day archived in different passes apart.
+func archiveName(day time.Time, plan models.PlanType) string {
+	if plan == "" {
+		plan = "all"
+	}
+	return fmt.Sprintf("request_logs_%s_%s_%s.ndjson.gz",
+		day.Format(time.DateOnly), plan, time.Now().UTC().Format("20060102T150405Z"))
+}
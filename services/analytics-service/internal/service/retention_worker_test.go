@@ -0,0 +1,313 @@
+package service
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"ironnode/pkg/database"
+	"ironnode/pkg/models"
+	"ironnode/services/analytics-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// fakeRetentionRepo keeps request logs in memory with the plan of their user
+type fakeRetentionRepo struct {
+	repository.RetentionRepository
+	partitioned bool
+	logs        map[uuid.UUID]*models.RequestLog
+	plans       map[uuid.UUID]models.PlanType // by log
+	partitions  []database.DailyPartition
+	created     [][2]time.Time
+	cursors     map[models.PlanType]time.Time
+}
+
+func newFakeRetentionRepo() *fakeRetentionRepo {
+	return &fakeRetentionRepo{
+		logs:    make(map[uuid.UUID]*models.RequestLog),
+		plans:   make(map[uuid.UUID]models.PlanType),
+		cursors: make(map[models.PlanType]time.Time),
+	}
+}
+
+// add stores a log of a user on plan created at
+func (r *fakeRetentionRepo) add(plan models.PlanType, createdAt time.Time) uuid.UUID {
+	id := uuid.New()
+	r.logs[id] = &models.RequestLog{ID: id, UserID: uuid.New(), Method: "eth_blockNumber", CreatedAt: createdAt}
+	r.plans[id] = plan
+	return id
+}
+
+// matching returns the logs created in [from, to), of users on plan unless plan is empty
+func (r *fakeRetentionRepo) matching(from, to time.Time, plan models.PlanType) []*models.RequestLog {
+	var logs []*models.RequestLog
+	for id, entry := range r.logs {
+		if !entry.CreatedAt.Before(from) && entry.CreatedAt.Before(to) && (plan == "" || r.plans[id] == plan) {
+			logs = append(logs, entry)
+		}
+	}
+	return logs
+}
+
+func (r *fakeRetentionRepo) IsPartitioned() (bool, error) {
+	return r.partitioned, nil
+}
+
+func (r *fakeRetentionRepo) CreatePartitions(from, to time.Time) error {
+	r.created = append(r.created, [2]time.Time{from, to})
+	return nil
+}
+
+func (r *fakeRetentionRepo) ListPartitions() ([]database.DailyPartition, error) {
+	// A copy, as dropping partitions does not change a list already read from the database
+	return append([]database.DailyPartition(nil), r.partitions...), nil
+}
+
+func (r *fakeRetentionRepo) ExportPartition(name string, write func(logs []*models.RequestLog) error) error {
+	for _, partition := range r.partitions {
+		if partition.Name == name {
+			return write(r.matching(partition.Day, partition.End(), ""))
+		}
+	}
+	return nil
+}
+
+func (r *fakeRetentionRepo) DropPartition(name string) error {
+	for i, partition := range r.partitions {
+		if partition.Name == name {
+			r.DeleteLogs(partition.Day, partition.End(), "")
+			r.partitions = append(r.partitions[:i], r.partitions[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *fakeRetentionRepo) ExportLogs(from, to time.Time, plan models.PlanType, write func(logs []*models.RequestLog) error) error {
+	return write(r.matching(from, to, plan))
+}
+
+func (r *fakeRetentionRepo) DeleteLogs(from, to time.Time, plan models.PlanType) (int64, error) {
+	logs := r.matching(from, to, plan)
+	for _, entry := range logs {
+		delete(r.logs, entry.ID)
+	}
+	return int64(len(logs)), nil
+}
+
+func (r *fakeRetentionRepo) GetPurgeCursor(plan models.PlanType) (time.Time, error) {
+	return r.cursors[plan], nil
+}
+
+func (r *fakeRetentionRepo) SetPurgeCursor(plan models.PlanType, purgedUntil time.Time) error {
+	r.cursors[plan] = purgedUntil
+	return nil
+}
+
+// fakeRollups reports how far request logs are rolled up
+type fakeRollups struct {
+	repository.RollupRepository
+	rolledUntil time.Time
+	earliest    time.Time
+}
+
+func (r *fakeRollups) GetCursor() (time.Time, error) {
+	return r.rolledUntil, nil
+}
+
+func (r *fakeRollups) EarliestRequest() (time.Time, bool, error) {
+	return r.earliest, !r.earliest.IsZero(), nil
+}
+
+var testRetention = map[models.PlanType]time.Duration{
+	models.FreePlan:         7 * 24 * time.Hour,
+	models.ProfessionalPlan: 30 * 24 * time.Hour,
+}
+
+func date(month time.Month, day int) time.Time {
+	return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// archived reads the IDs of the logs in the archives of day
+func archived(t *testing.T, dir string, day time.Time, plan string) []uuid.UUID {
+	t.Helper()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "request_logs_"+day.Format(time.DateOnly)+"_"+plan+"_*.ndjson.gz"))
+	var ids []uuid.UUID
+	for _, path := range files {
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatalf("%s is not gzipped: %v", path, err)
+		}
+
+		lines := bufio.NewScanner(gz)
+		for lines.Scan() {
+			var entry models.RequestLog
+			if err := json.Unmarshal(lines.Bytes(), &entry); err != nil {
+				t.Fatalf("%s line is not a request log: %s", path, lines.Text())
+			}
+			ids = append(ids, entry.ID)
+		}
+	}
+	return ids
+}
+
+func remaining(repo *fakeRetentionRepo) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(repo.logs))
+	for id := range repo.logs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	return ids
+}
+
+func sameIDs(got []uuid.UUID, want ...uuid.UUID) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	seen := make(map[uuid.UUID]bool, len(got))
+	for _, id := range got {
+		seen[id] = true
+	}
+	for _, id := range want {
+		if !seen[id] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPurgeKeepsLogsForTheirPlansRetention(t *testing.T) {
+	repo, dir := newFakeRetentionRepo(), t.TempDir()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	freeExpired := repo.add(models.FreePlan, date(10, 1).Add(time.Hour))
+	freeKept := repo.add(models.FreePlan, date(10, 12))
+	proKept := repo.add(models.ProfessionalPlan, date(10, 1).Add(2*time.Hour))
+	proExpired := repo.add(models.ProfessionalPlan, date(9, 1))
+	otherExpired := repo.add(models.BasicPlan, date(9, 2))
+
+	worker := NewRetentionWorker(repo, &fakeRollups{rolledUntil: now, earliest: date(9, 1)}, testRetention, dir, time.Hour)
+	if err := worker.Purge(now); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+
+	if got := remaining(repo); !sameIDs(got, freeKept, proKept) {
+		t.Errorf("kept %v, want the free log of 10-12 and the professional log of 10-01", got)
+	}
+	if got := archived(t, dir, date(10, 1), string(models.FreePlan)); !sameIDs(got, freeExpired) {
+		t.Errorf("free archive of 10-01 has %v, want %v", got, freeExpired)
+	}
+	// Plans without a retention of their own are kept as long as the longest one
+	if got := archived(t, dir, date(9, 1), "all"); !sameIDs(got, proExpired) {
+		t.Errorf("archive of 09-01 has %v, want %v", got, proExpired)
+	}
+	if got := archived(t, dir, date(9, 2), "all"); !sameIDs(got, otherExpired) {
+		t.Errorf("archive of 09-02 has %v, want %v", got, otherExpired)
+	}
+
+	// Days without logs leave no archive behind
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 3 {
+		t.Errorf("archive dir has %d files, want 3: %v", len(files), files)
+	}
+
+	if cursor := repo.cursors[models.FreePlan]; !cursor.Equal(date(10, 2)) {
+		t.Errorf("free purge cursor at %v, want the day after the last one purged", cursor)
+	}
+	if len(repo.created) != 0 {
+		t.Errorf("partitions created for a plain table: %v", repo.created)
+	}
+}
+
+func TestPurgeKeepsLogsNotRolledUp(t *testing.T) {
+	repo, dir := newFakeRetentionRepo(), t.TempDir()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	rolledUp := repo.add(models.FreePlan, date(9, 10))
+	pending := repo.add(models.FreePlan, date(9, 25))
+
+	rollups := &fakeRollups{earliest: date(9, 10)}
+	worker := NewRetentionWorker(repo, rollups, testRetention, dir, time.Hour)
+
+	// Nothing is purged before the first rollup
+	if err := worker.Purge(now); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if len(repo.logs) != 2 {
+		t.Fatalf("%d logs left before any rollup, want 2", len(repo.logs))
+	}
+
+	rollups.rolledUntil = date(9, 20).Add(6 * time.Hour)
+	if err := worker.Purge(now); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if got := remaining(repo); !sameIDs(got, pending) {
+		t.Errorf("kept %v, want only %v which is not rolled up", got, pending)
+	}
+	if got := archived(t, dir, date(9, 10), string(models.FreePlan)); !sameIDs(got, rolledUp) {
+		t.Errorf("archive of 09-10 has %v, want %v", got, rolledUp)
+	}
+}
+
+func TestPurgeDropsExpiredPartitions(t *testing.T) {
+	repo, dir := newFakeRetentionRepo(), t.TempDir()
+	repo.partitioned = true
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	for _, day := range []time.Time{date(9, 14), date(9, 15), date(9, 16)} {
+		repo.partitions = append(repo.partitions, database.DailyPartition{Name: database.DailyPartitionName("request_logs", day), Day: day})
+	}
+	expired := repo.add(models.ProfessionalPlan, date(9, 15).Add(23*time.Hour))
+	kept := repo.add(models.ProfessionalPlan, date(9, 16))
+
+	worker := NewRetentionWorker(repo, &fakeRollups{rolledUntil: now, earliest: date(9, 14)},
+		map[models.PlanType]time.Duration{models.ProfessionalPlan: 30 * 24 * time.Hour}, dir, time.Hour)
+	if err := worker.Purge(now); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+
+	if len(repo.partitions) != 1 || repo.partitions[0].Name != "request_logs_p20260916" {
+		t.Errorf("partitions left %v, want only request_logs_p20260916", repo.partitions)
+	}
+	if got := remaining(repo); !sameIDs(got, kept) {
+		t.Errorf("kept %v, want %v", got, kept)
+	}
+	if got := archived(t, dir, date(9, 15), "all"); !sameIDs(got, expired) {
+		t.Errorf("archive of 09-15 has %v, want %v", got, expired)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 1 {
+		t.Errorf("archive dir has %v, want only the archive of the partition with logs", files)
+	}
+
+	if len(repo.created) != 1 || !repo.created[0][0].Equal(date(10, 16)) || !repo.created[0][1].Equal(date(10, 16).AddDate(0, 0, partitionsAhead)) {
+		t.Errorf("partitions created for %v, want today and %d days ahead", repo.created, partitionsAhead)
+	}
+}
+
+func TestLogArchiveAbortLeavesNothing(t *testing.T) {
+	dir := t.TempDir()
+
+	archive, err := createLogArchive(dir, "request_logs_test.ndjson.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := archive.Write([]*models.RequestLog{{ID: uuid.New()}}); err != nil {
+		t.Fatal(err)
+	}
+	archive.Abort()
+
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 0 {
+		t.Errorf("aborted archive left %v", files)
+	}
+}
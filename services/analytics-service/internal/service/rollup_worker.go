@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"ironnode/pkg/models"
+	"ironnode/services/analytics-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// maxRollupWindow bounds the request logs rolled up at once while catching up
+const maxRollupWindow = time.Hour
+
+// RollupWorker aggregates request logs into minute buckets once a minute is
+// over, and the minutes into hour and day buckets. Hours and days still in
+// progress are rolled up again on every pass, so they stay current.
+type RollupWorker interface {
+	Start()
+	Stop()
+	// Roll rolls up the minutes that ended at least delay before now, up to maxRollupWindow
+	// of them, and returns the end of the rolled up range
+	Roll(now time.Time) (time.Time, error)
+}
+
+type rollupWorker struct {
+	repo     repository.RollupRepository
+	interval time.Duration
+	delay    time.Duration
+
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewRollupWorker creates the worker. delay leaves time for request logs still
+// queued in the gateway to arrive before their minute is rolled up.
+func NewRollupWorker(repo repository.RollupRepository, interval, delay time.Duration) RollupWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &rollupWorker{
+		repo:     repo,
+		interval: interval,
+		delay:    delay,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start runs the rollup loop in a background goroutine
+func (w *rollupWorker) Start() {
+	w.wg.Add(1)
+	go w.run()
+	log.Printf("[Rollup] Started (interval: %v, delay: %v)", w.interval, w.delay)
+}
+
+// Stop stops the rollup loop and waits for it to finish
+func (w *rollupWorker) Stop() {
+	w.cancel()
+	w.wg.Wait()
+	log.Printf("[Rollup] Stopped")
+}
+
+func (w *rollupWorker) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.rollNow()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.rollNow()
+		}
+	}
+}
+
+// rollNow catches up with the request logs, one window at a time
+func (w *rollupWorker) rollNow() {
+	for w.ctx.Err() == nil {
+		now := time.Now()
+		rolledUntil, err := w.Roll(now)
+		if err != nil {
+			log.Printf("[Rollup] Rollup failed: %v", err)
+			return
+		}
+		if !rolledUntil.Before(w.rollableUntil(now)) {
+			return
+		}
+	}
+}
+
+// rollableUntil is the end of the last minute that may be rolled up
+func (w *rollupWorker) rollableUntil(now time.Time) time.Time {
+	return models.RollupMinute.Truncate(now.Add(-w.delay))
+}
+
+func (w *rollupWorker) Roll(now time.Time) (time.Time, error) {
+	to := w.rollableUntil(now)
+
+	from, err := w.repo.GetCursor()
+	if err != nil {
+		return from, err
+	}
+	if from.IsZero() {
+		earliest, ok, err := w.repo.EarliestRequest()
+		if err != nil || !ok {
+			return to, err
+		}
+		from = models.RollupMinute.Truncate(earliest)
+	}
+	from = from.UTC()
+
+	if !from.Before(to) {
+		return from, nil
+	}
+	if to.Sub(from) > maxRollupWindow {
+		to = from.Add(maxRollupWindow)
+	}
+
+	if err := w.repo.RollUpMinutes(from, to); err != nil {
+		return from, err
+	}
+	if err := w.rollUp(models.RollupHour, models.RollupMinute, from, to); err != nil {
+		return from, err
+	}
+	if err := w.rollUp(models.RollupDay, models.RollupHour, from, to); err != nil {
+		return from, err
+	}
+
+	return to, w.repo.SetCursor(to)
+}
+
+// rollUp rebuilds the buckets of granularity that overlap [from, to) from the finer source buckets
+func (w *rollupWorker) rollUp(granularity, source models.RollupGranularity, from, to time.Time) error {
+	rollups, err := w.repo.ListBuckets(source, granularity.Truncate(from), to)
+	if err != nil {
+		return err
+	}
+
+	type bucketKey struct {
+		userID, apiKeyID   uuid.UUID
+		blockchain, method string
+		start              int64
+	}
+
+	groups := make(map[bucketKey][]*models.UsageRollup)
+	for _, rollup := range rollups {
+		key := bucketKey{
+			userID:     rollup.UserID,
+			apiKeyID:   rollup.APIKeyID,
+			blockchain: rollup.Blockchain,
+			method:     rollup.Method,
+			start:      granularity.Truncate(rollup.BucketStart).Unix(),
+		}
+		groups[key] = append(groups[key], rollup)
+	}
+
+	buckets := make([]*models.UsageRollup, 0, len(groups))
+	for key, group := range groups {
+		bucket := mergeRollups(group)
+		bucket.UserID = key.userID
+		bucket.APIKeyID = key.apiKeyID
+		bucket.Blockchain = key.blockchain
+		bucket.Method = key.method
+		bucket.Granularity = granularity
+		bucket.BucketStart = time.Unix(key.start, 0).UTC()
+		buckets = append(buckets, bucket)
+	}
+
+	return w.repo.SaveBuckets(buckets)
+}
+
+// mergeRollups adds rollups up into a new one without bucket or dimensions.
+// A single rollup keeps its percentiles; merged ones are estimated from the latency histogram.
+func mergeRollups(rollups []*models.UsageRollup) *models.UsageRollup {
+	merged := &models.UsageRollup{LatencyHistogram: make([]int64, len(models.RollupLatencyBounds)+1)}
+
+	for _, rollup := range rollups {
+		merged.Requests += rollup.Requests
+		merged.Errors += rollup.Errors
+		merged.ResponseTimeTotal += rollup.ResponseTimeTotal
+		merged.RequestBytes += rollup.RequestBytes
+		merged.ResponseBytes += rollup.ResponseBytes
+		merged.Credits += rollup.Credits
+		for i, count := range rollup.LatencyHistogram {
+			if i < len(merged.LatencyHistogram) {
+				merged.LatencyHistogram[i] += count
+			}
+		}
+	}
+
+	if len(rollups) == 1 {
+		merged.P50ResponseTime = rollups[0].P50ResponseTime
+		merged.P95ResponseTime = rollups[0].P95ResponseTime
+		merged.P99ResponseTime = rollups[0].P99ResponseTime
+		return merged
+	}
+
+	merged.P50ResponseTime = latencyPercentile(merged.LatencyHistogram, 0.50)
+	merged.P95ResponseTime = latencyPercentile(merged.LatencyHistogram, 0.95)
+	merged.P99ResponseTime = latencyPercentile(merged.LatencyHistogram, 0.99)
+	return merged
+}
+
+// latencyPercentile estimates a percentile from a latency histogram,
+// assuming the calls in a bucket are spread evenly over it
+func latencyPercentile(histogram []int64, q float64) int64 {
+	bounds := models.RollupLatencyBounds
+
+	var total int64
+	for _, count := range histogram {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := q * float64(total)
+	var seen int64
+	for i, count := range histogram {
+		if count == 0 || float64(seen+count) < rank {
+			seen += count
+			continue
+		}
+
+		var lower int64
+		if i > 0 {
+			lower = bounds[i-1]
+		}
+		if i >= len(bounds) {
+			// Slower than the last bound; its value is all that is known
+			return lower
+		}
+		return lower + int64(float64(bounds[i]-lower)*(rank-float64(seen))/float64(count))
+	}
+
+	return bounds[len(bounds)-1]
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"ironnode/pkg/models"
+)
+
+// histogram returns a latency histogram with the given counts per bucket index
+func histogram(counts map[int]int64) []int64 {
+	h := make([]int64, len(models.RollupLatencyBounds)+1)
+	for i, count := range counts {
+		h[i] = count
+	}
+	return h
+}
+
+func TestLatencyPercentile(t *testing.T) {
+	// Buckets: 0-5, 5-10, 10-25, 25-50, 50-100, ..., 5000-10000 ms and slower (index 11)
+	tests := []struct {
+		name      string
+		histogram []int64
+		q         float64
+		want      int64
+	}{
+		{"no calls", histogram(nil), 0.5, 0},
+		{"no histogram", nil, 0.99, 0},
+		{"first bucket", histogram(map[int]int64{0: 10}), 0.5, 2},
+		{"interpolated in bucket", histogram(map[int]int64{2: 100}), 0.5, 17},
+		{"near bucket top", histogram(map[int]int64{2: 100}), 0.99, 24},
+		{"whole bucket", histogram(map[int]int64{3: 4}), 1, 50},
+		{"rank at bucket boundary", histogram(map[int]int64{0: 50, 4: 50}), 0.5, 5},
+		{"later bucket p95", histogram(map[int]int64{0: 50, 4: 50}), 0.95, 95},
+		{"later bucket p99", histogram(map[int]int64{0: 50, 4: 50}), 0.99, 99},
+		{"skips empty buckets", histogram(map[int]int64{1: 4}), 0, 5},
+		{"slower than every bound", histogram(map[int]int64{11: 10}), 0.5, 10000},
+		{"tail in overflow bucket", histogram(map[int]int64{0: 98, 11: 2}), 0.99, 10000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := latencyPercentile(tt.histogram, tt.q); got != tt.want {
+				t.Errorf("latencyPercentile(%v, %v) = %d, want %d", tt.histogram, tt.q, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeRollups(t *testing.T) {
+	fast := &models.UsageRollup{
+		Requests:          50,
+		Errors:            1,
+		ResponseTimeTotal: 150,
+		P50ResponseTime:   3,
+		P95ResponseTime:   4,
+		P99ResponseTime:   5,
+		RequestBytes:      1000,
+		ResponseBytes:     5000,
+		Credits:           50,
+		LatencyHistogram:  histogram(map[int]int64{0: 50}),
+	}
+	slow := &models.UsageRollup{
+		Requests:          50,
+		Errors:            4,
+		ResponseTimeTotal: 3750,
+		P50ResponseTime:   70,
+		P95ResponseTime:   98,
+		P99ResponseTime:   100,
+		RequestBytes:      2000,
+		ResponseBytes:     9000,
+		Credits:           200,
+		LatencyHistogram:  histogram(map[int]int64{4: 50}),
+	}
+	// Stored with fewer buckets than there are now
+	short := &models.UsageRollup{Requests: 2, LatencyHistogram: []int64{2}}
+
+	tests := []struct {
+		name    string
+		rollups []*models.UsageRollup
+		want    models.UsageRollup
+	}{
+		{
+			name:    "none",
+			rollups: nil,
+			want:    models.UsageRollup{LatencyHistogram: histogram(nil)},
+		},
+		{
+			name:    "single keeps exact percentiles",
+			rollups: []*models.UsageRollup{slow},
+			want: models.UsageRollup{
+				Requests: 50, Errors: 4, ResponseTimeTotal: 3750,
+				P50ResponseTime: 70, P95ResponseTime: 98, P99ResponseTime: 100,
+				RequestBytes: 2000, ResponseBytes: 9000, Credits: 200,
+				LatencyHistogram: histogram(map[int]int64{4: 50}),
+			},
+		},
+		{
+			name:    "several estimate percentiles from the merged histogram",
+			rollups: []*models.UsageRollup{fast, slow},
+			want: models.UsageRollup{
+				Requests: 100, Errors: 5, ResponseTimeTotal: 3900,
+				P50ResponseTime: 5, P95ResponseTime: 95, P99ResponseTime: 99,
+				RequestBytes: 3000, ResponseBytes: 14000, Credits: 250,
+				LatencyHistogram: histogram(map[int]int64{0: 50, 4: 50}),
+			},
+		},
+		{
+			name:    "shorter histogram",
+			rollups: []*models.UsageRollup{fast, short},
+			want: models.UsageRollup{
+				Requests: 52, Errors: 1, ResponseTimeTotal: 150,
+				P50ResponseTime: 2, P95ResponseTime: 4, P99ResponseTime: 4,
+				RequestBytes: 1000, ResponseBytes: 5000, Credits: 50,
+				LatencyHistogram: histogram(map[int]int64{0: 52}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeRollups(tt.rollups); !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("mergeRollups() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+
+	if !reflect.DeepEqual(slow.LatencyHistogram, histogram(map[int]int64{4: 50})) {
+		t.Error("merging changed a source histogram")
+	}
+}
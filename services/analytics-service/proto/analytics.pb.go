@@ -35,6 +35,9 @@ type LogRequestRequest struct {
 	IpAddress     string                 `protobuf:"bytes,10,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
 	UserAgent     string                 `protobuf:"bytes,11,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
 	Error         string                 `protobuf:"bytes,12,opt,name=error,proto3" json:"error,omitempty"`
+	CacheHit      bool                   `protobuf:"varint,13,opt,name=cache_hit,json=cacheHit,proto3" json:"cache_hit,omitempty"`
+	Credits       int64                  `protobuf:"varint,14,opt,name=credits,proto3" json:"credits,omitempty"`
+	Coalesced     bool                   `protobuf:"varint,15,opt,name=coalesced,proto3" json:"coalesced,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -153,6 +156,27 @@ func (x *LogRequestRequest) GetError() string {
 	return ""
 }
 
+func (x *LogRequestRequest) GetCacheHit() bool {
+	if x != nil {
+		return x.CacheHit
+	}
+	return false
+}
+
+func (x *LogRequestRequest) GetCredits() int64 {
+	if x != nil {
+		return x.Credits
+	}
+	return 0
+}
+
+func (x *LogRequestRequest) GetCoalesced() bool {
+	if x != nil {
+		return x.Coalesced
+	}
+	return false
+}
+
 type LogRequestResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -197,10 +221,15 @@ func (x *LogRequestResponse) GetSuccess() bool {
 	return false
 }
 
+// Dates are RFC3339; empty filters match everything
 type GetRequestHistoryRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	StartDate     string                 `protobuf:"bytes,3,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate       string                 `protobuf:"bytes,4,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	Blockchain    string                 `protobuf:"bytes,5,opt,name=blockchain,proto3" json:"blockchain,omitempty"`
+	ApiKeyId      string                 `protobuf:"bytes,6,opt,name=api_key_id,json=apiKeyId,proto3" json:"api_key_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -249,6 +278,34 @@ func (x *GetRequestHistoryRequest) GetLimit() int32 {
 	return 0
 }
 
+func (x *GetRequestHistoryRequest) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *GetRequestHistoryRequest) GetEndDate() string {
+	if x != nil {
+		return x.EndDate
+	}
+	return ""
+}
+
+func (x *GetRequestHistoryRequest) GetBlockchain() string {
+	if x != nil {
+		return x.Blockchain
+	}
+	return ""
+}
+
+func (x *GetRequestHistoryRequest) GetApiKeyId() string {
+	if x != nil {
+		return x.ApiKeyId
+	}
+	return ""
+}
+
 type GetRequestHistoryResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Logs          []*RequestLog          `protobuf:"bytes,1,rep,name=logs,proto3" json:"logs,omitempty"`
@@ -309,6 +366,9 @@ type RequestLog struct {
 	UserAgent     string                 `protobuf:"bytes,12,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
 	Error         string                 `protobuf:"bytes,13,opt,name=error,proto3" json:"error,omitempty"`
 	CreatedAt     string                 `protobuf:"bytes,14,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	CacheHit      bool                   `protobuf:"varint,15,opt,name=cache_hit,json=cacheHit,proto3" json:"cache_hit,omitempty"`
+	Credits       int64                  `protobuf:"varint,16,opt,name=credits,proto3" json:"credits,omitempty"`
+	Coalesced     bool                   `protobuf:"varint,17,opt,name=coalesced,proto3" json:"coalesced,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -441,11 +501,34 @@ func (x *RequestLog) GetCreatedAt() string {
 	return ""
 }
 
+func (x *RequestLog) GetCacheHit() bool {
+	if x != nil {
+		return x.CacheHit
+	}
+	return false
+}
+
+func (x *RequestLog) GetCredits() int64 {
+	if x != nil {
+		return x.Credits
+	}
+	return 0
+}
+
+func (x *RequestLog) GetCoalesced() bool {
+	if x != nil {
+		return x.Coalesced
+	}
+	return false
+}
+
 type GetUsageStatsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	StartDate     string                 `protobuf:"bytes,2,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
 	EndDate       string                 `protobuf:"bytes,3,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	Blockchain    string                 `protobuf:"bytes,4,opt,name=blockchain,proto3" json:"blockchain,omitempty"`
+	ApiKeyId      string                 `protobuf:"bytes,5,opt,name=api_key_id,json=apiKeyId,proto3" json:"api_key_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -501,12 +584,33 @@ func (x *GetUsageStatsRequest) GetEndDate() string {
 	return ""
 }
 
+func (x *GetUsageStatsRequest) GetBlockchain() string {
+	if x != nil {
+		return x.Blockchain
+	}
+	return ""
+}
+
+func (x *GetUsageStatsRequest) GetApiKeyId() string {
+	if x != nil {
+		return x.ApiKeyId
+	}
+	return ""
+}
+
 type GetUsageStatsResponse struct {
 	state               protoimpl.MessageState `protogen:"open.v1"`
 	TotalRequests       int64                  `protobuf:"varint,1,opt,name=total_requests,json=totalRequests,proto3" json:"total_requests,omitempty"`
 	SuccessfulRequests  int64                  `protobuf:"varint,2,opt,name=successful_requests,json=successfulRequests,proto3" json:"successful_requests,omitempty"`
 	SuccessRate         float64                `protobuf:"fixed64,3,opt,name=success_rate,json=successRate,proto3" json:"success_rate,omitempty"`
 	AverageResponseTime int64                  `protobuf:"varint,4,opt,name=average_response_time,json=averageResponseTime,proto3" json:"average_response_time,omitempty"`
+	FailedRequests      int64                  `protobuf:"varint,5,opt,name=failed_requests,json=failedRequests,proto3" json:"failed_requests,omitempty"`
+	P50ResponseTime     int64                  `protobuf:"varint,6,opt,name=p50_response_time,json=p50ResponseTime,proto3" json:"p50_response_time,omitempty"`
+	P95ResponseTime     int64                  `protobuf:"varint,7,opt,name=p95_response_time,json=p95ResponseTime,proto3" json:"p95_response_time,omitempty"`
+	P99ResponseTime     int64                  `protobuf:"varint,8,opt,name=p99_response_time,json=p99ResponseTime,proto3" json:"p99_response_time,omitempty"`
+	RequestBytes        int64                  `protobuf:"varint,9,opt,name=request_bytes,json=requestBytes,proto3" json:"request_bytes,omitempty"`
+	ResponseBytes       int64                  `protobuf:"varint,10,opt,name=response_bytes,json=responseBytes,proto3" json:"response_bytes,omitempty"`
+	Credits             int64                  `protobuf:"varint,11,opt,name=credits,proto3" json:"credits,omitempty"`
 	unknownFields       protoimpl.UnknownFields
 	sizeCache           protoimpl.SizeCache
 }
@@ -569,11 +673,583 @@ func (x *GetUsageStatsResponse) GetAverageResponseTime() int64 {
 	return 0
 }
 
+func (x *GetUsageStatsResponse) GetFailedRequests() int64 {
+	if x != nil {
+		return x.FailedRequests
+	}
+	return 0
+}
+
+func (x *GetUsageStatsResponse) GetP50ResponseTime() int64 {
+	if x != nil {
+		return x.P50ResponseTime
+	}
+	return 0
+}
+
+func (x *GetUsageStatsResponse) GetP95ResponseTime() int64 {
+	if x != nil {
+		return x.P95ResponseTime
+	}
+	return 0
+}
+
+func (x *GetUsageStatsResponse) GetP99ResponseTime() int64 {
+	if x != nil {
+		return x.P99ResponseTime
+	}
+	return 0
+}
+
+func (x *GetUsageStatsResponse) GetRequestBytes() int64 {
+	if x != nil {
+		return x.RequestBytes
+	}
+	return 0
+}
+
+func (x *GetUsageStatsResponse) GetResponseBytes() int64 {
+	if x != nil {
+		return x.ResponseBytes
+	}
+	return 0
+}
+
+func (x *GetUsageStatsResponse) GetCredits() int64 {
+	if x != nil {
+		return x.Credits
+	}
+	return 0
+}
+
+// granularity is minute, hour or day; empty picks one for the range
+type GetUsageTimeSeriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	StartDate     string                 `protobuf:"bytes,2,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate       string                 `protobuf:"bytes,3,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	Granularity   string                 `protobuf:"bytes,4,opt,name=granularity,proto3" json:"granularity,omitempty"`
+	Blockchain    string                 `protobuf:"bytes,5,opt,name=blockchain,proto3" json:"blockchain,omitempty"`
+	ApiKeyId      string                 `protobuf:"bytes,6,opt,name=api_key_id,json=apiKeyId,proto3" json:"api_key_id,omitempty"`
+	Method        string                 `protobuf:"bytes,7,opt,name=method,proto3" json:"method,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsageTimeSeriesRequest) Reset() {
+	*x = GetUsageTimeSeriesRequest{}
+	mi := &file_services_analytics_service_proto_analytics_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageTimeSeriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageTimeSeriesRequest) ProtoMessage() {}
+
+func (x *GetUsageTimeSeriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_analytics_service_proto_analytics_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsageTimeSeriesRequest.ProtoReflect.Descriptor instead.
+func (*GetUsageTimeSeriesRequest) Descriptor() ([]byte, []int) {
+	return file_services_analytics_service_proto_analytics_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetUsageTimeSeriesRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetUsageTimeSeriesRequest) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *GetUsageTimeSeriesRequest) GetEndDate() string {
+	if x != nil {
+		return x.EndDate
+	}
+	return ""
+}
+
+func (x *GetUsageTimeSeriesRequest) GetGranularity() string {
+	if x != nil {
+		return x.Granularity
+	}
+	return ""
+}
+
+func (x *GetUsageTimeSeriesRequest) GetBlockchain() string {
+	if x != nil {
+		return x.Blockchain
+	}
+	return ""
+}
+
+func (x *GetUsageTimeSeriesRequest) GetApiKeyId() string {
+	if x != nil {
+		return x.ApiKeyId
+	}
+	return ""
+}
+
+func (x *GetUsageTimeSeriesRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+type UsagePoint struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp           string                 `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Requests            int64                  `protobuf:"varint,2,opt,name=requests,proto3" json:"requests,omitempty"`
+	Errors              int64                  `protobuf:"varint,3,opt,name=errors,proto3" json:"errors,omitempty"`
+	AverageResponseTime int64                  `protobuf:"varint,4,opt,name=average_response_time,json=averageResponseTime,proto3" json:"average_response_time,omitempty"`
+	P50ResponseTime     int64                  `protobuf:"varint,5,opt,name=p50_response_time,json=p50ResponseTime,proto3" json:"p50_response_time,omitempty"`
+	P95ResponseTime     int64                  `protobuf:"varint,6,opt,name=p95_response_time,json=p95ResponseTime,proto3" json:"p95_response_time,omitempty"`
+	P99ResponseTime     int64                  `protobuf:"varint,7,opt,name=p99_response_time,json=p99ResponseTime,proto3" json:"p99_response_time,omitempty"`
+	RequestBytes        int64                  `protobuf:"varint,8,opt,name=request_bytes,json=requestBytes,proto3" json:"request_bytes,omitempty"`
+	ResponseBytes       int64                  `protobuf:"varint,9,opt,name=response_bytes,json=responseBytes,proto3" json:"response_bytes,omitempty"`
+	Credits             int64                  `protobuf:"varint,10,opt,name=credits,proto3" json:"credits,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *UsagePoint) Reset() {
+	*x = UsagePoint{}
+	mi := &file_services_analytics_service_proto_analytics_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsagePoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsagePoint) ProtoMessage() {}
+
+func (x *UsagePoint) ProtoReflect() protoreflect.Message {
+	mi := &file_services_analytics_service_proto_analytics_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsagePoint.ProtoReflect.Descriptor instead.
+func (*UsagePoint) Descriptor() ([]byte, []int) {
+	return file_services_analytics_service_proto_analytics_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UsagePoint) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+func (x *UsagePoint) GetRequests() int64 {
+	if x != nil {
+		return x.Requests
+	}
+	return 0
+}
+
+func (x *UsagePoint) GetErrors() int64 {
+	if x != nil {
+		return x.Errors
+	}
+	return 0
+}
+
+func (x *UsagePoint) GetAverageResponseTime() int64 {
+	if x != nil {
+		return x.AverageResponseTime
+	}
+	return 0
+}
+
+func (x *UsagePoint) GetP50ResponseTime() int64 {
+	if x != nil {
+		return x.P50ResponseTime
+	}
+	return 0
+}
+
+func (x *UsagePoint) GetP95ResponseTime() int64 {
+	if x != nil {
+		return x.P95ResponseTime
+	}
+	return 0
+}
+
+func (x *UsagePoint) GetP99ResponseTime() int64 {
+	if x != nil {
+		return x.P99ResponseTime
+	}
+	return 0
+}
+
+func (x *UsagePoint) GetRequestBytes() int64 {
+	if x != nil {
+		return x.RequestBytes
+	}
+	return 0
+}
+
+func (x *UsagePoint) GetResponseBytes() int64 {
+	if x != nil {
+		return x.ResponseBytes
+	}
+	return 0
+}
+
+func (x *UsagePoint) GetCredits() int64 {
+	if x != nil {
+		return x.Credits
+	}
+	return 0
+}
+
+type GetUsageTimeSeriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Granularity   string                 `protobuf:"bytes,1,opt,name=granularity,proto3" json:"granularity,omitempty"`
+	Points        []*UsagePoint          `protobuf:"bytes,2,rep,name=points,proto3" json:"points,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsageTimeSeriesResponse) Reset() {
+	*x = GetUsageTimeSeriesResponse{}
+	mi := &file_services_analytics_service_proto_analytics_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageTimeSeriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageTimeSeriesResponse) ProtoMessage() {}
+
+func (x *GetUsageTimeSeriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_services_analytics_service_proto_analytics_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsageTimeSeriesResponse.ProtoReflect.Descriptor instead.
+func (*GetUsageTimeSeriesResponse) Descriptor() ([]byte, []int) {
+	return file_services_analytics_service_proto_analytics_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetUsageTimeSeriesResponse) GetGranularity() string {
+	if x != nil {
+		return x.Granularity
+	}
+	return ""
+}
+
+func (x *GetUsageTimeSeriesResponse) GetPoints() []*UsagePoint {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+// group_by is api_key, method or blockchain
+type GetUsageBreakdownRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	StartDate     string                 `protobuf:"bytes,2,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate       string                 `protobuf:"bytes,3,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	GroupBy       string                 `protobuf:"bytes,4,opt,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`
+	Blockchain    string                 `protobuf:"bytes,5,opt,name=blockchain,proto3" json:"blockchain,omitempty"`
+	ApiKeyId      string                 `protobuf:"bytes,6,opt,name=api_key_id,json=apiKeyId,proto3" json:"api_key_id,omitempty"`
+	Method        string                 `protobuf:"bytes,7,opt,name=method,proto3" json:"method,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsageBreakdownRequest) Reset() {
+	*x = GetUsageBreakdownRequest{}
+	mi := &file_services_analytics_service_proto_analytics_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageBreakdownRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageBreakdownRequest) ProtoMessage() {}
+
+func (x *GetUsageBreakdownRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_analytics_service_proto_analytics_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsageBreakdownRequest.ProtoReflect.Descriptor instead.
+func (*GetUsageBreakdownRequest) Descriptor() ([]byte, []int) {
+	return file_services_analytics_service_proto_analytics_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetUsageBreakdownRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetUsageBreakdownRequest) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *GetUsageBreakdownRequest) GetEndDate() string {
+	if x != nil {
+		return x.EndDate
+	}
+	return ""
+}
+
+func (x *GetUsageBreakdownRequest) GetGroupBy() string {
+	if x != nil {
+		return x.GroupBy
+	}
+	return ""
+}
+
+func (x *GetUsageBreakdownRequest) GetBlockchain() string {
+	if x != nil {
+		return x.Blockchain
+	}
+	return ""
+}
+
+func (x *GetUsageBreakdownRequest) GetApiKeyId() string {
+	if x != nil {
+		return x.ApiKeyId
+	}
+	return ""
+}
+
+func (x *GetUsageBreakdownRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+type UsageGroup struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Key                 string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Requests            int64                  `protobuf:"varint,2,opt,name=requests,proto3" json:"requests,omitempty"`
+	Errors              int64                  `protobuf:"varint,3,opt,name=errors,proto3" json:"errors,omitempty"`
+	AverageResponseTime int64                  `protobuf:"varint,4,opt,name=average_response_time,json=averageResponseTime,proto3" json:"average_response_time,omitempty"`
+	P50ResponseTime     int64                  `protobuf:"varint,5,opt,name=p50_response_time,json=p50ResponseTime,proto3" json:"p50_response_time,omitempty"`
+	P95ResponseTime     int64                  `protobuf:"varint,6,opt,name=p95_response_time,json=p95ResponseTime,proto3" json:"p95_response_time,omitempty"`
+	P99ResponseTime     int64                  `protobuf:"varint,7,opt,name=p99_response_time,json=p99ResponseTime,proto3" json:"p99_response_time,omitempty"`
+	RequestBytes        int64                  `protobuf:"varint,8,opt,name=request_bytes,json=requestBytes,proto3" json:"request_bytes,omitempty"`
+	ResponseBytes       int64                  `protobuf:"varint,9,opt,name=response_bytes,json=responseBytes,proto3" json:"response_bytes,omitempty"`
+	Credits             int64                  `protobuf:"varint,10,opt,name=credits,proto3" json:"credits,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *UsageGroup) Reset() {
+	*x = UsageGroup{}
+	mi := &file_services_analytics_service_proto_analytics_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsageGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageGroup) ProtoMessage() {}
+
+func (x *UsageGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_services_analytics_service_proto_analytics_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsageGroup.ProtoReflect.Descriptor instead.
+func (*UsageGroup) Descriptor() ([]byte, []int) {
+	return file_services_analytics_service_proto_analytics_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UsageGroup) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *UsageGroup) GetRequests() int64 {
+	if x != nil {
+		return x.Requests
+	}
+	return 0
+}
+
+func (x *UsageGroup) GetErrors() int64 {
+	if x != nil {
+		return x.Errors
+	}
+	return 0
+}
+
+func (x *UsageGroup) GetAverageResponseTime() int64 {
+	if x != nil {
+		return x.AverageResponseTime
+	}
+	return 0
+}
+
+func (x *UsageGroup) GetP50ResponseTime() int64 {
+	if x != nil {
+		return x.P50ResponseTime
+	}
+	return 0
+}
+
+func (x *UsageGroup) GetP95ResponseTime() int64 {
+	if x != nil {
+		return x.P95ResponseTime
+	}
+	return 0
+}
+
+func (x *UsageGroup) GetP99ResponseTime() int64 {
+	if x != nil {
+		return x.P99ResponseTime
+	}
+	return 0
+}
+
+func (x *UsageGroup) GetRequestBytes() int64 {
+	if x != nil {
+		return x.RequestBytes
+	}
+	return 0
+}
+
+func (x *UsageGroup) GetResponseBytes() int64 {
+	if x != nil {
+		return x.ResponseBytes
+	}
+	return 0
+}
+
+func (x *UsageGroup) GetCredits() int64 {
+	if x != nil {
+		return x.Credits
+	}
+	return 0
+}
+
+// Groups are ordered by requests, busiest first
+type GetUsageBreakdownResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupBy       string                 `protobuf:"bytes,1,opt,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`
+	Groups        []*UsageGroup          `protobuf:"bytes,2,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsageBreakdownResponse) Reset() {
+	*x = GetUsageBreakdownResponse{}
+	mi := &file_services_analytics_service_proto_analytics_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageBreakdownResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageBreakdownResponse) ProtoMessage() {}
+
+func (x *GetUsageBreakdownResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_services_analytics_service_proto_analytics_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsageBreakdownResponse.ProtoReflect.Descriptor instead.
+func (*GetUsageBreakdownResponse) Descriptor() ([]byte, []int) {
+	return file_services_analytics_service_proto_analytics_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetUsageBreakdownResponse) GetGroupBy() string {
+	if x != nil {
+		return x.GroupBy
+	}
+	return ""
+}
+
+func (x *GetUsageBreakdownResponse) GetGroups() []*UsageGroup {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
 var File_services_analytics_service_proto_analytics_proto protoreflect.FileDescriptor
 
 const file_services_analytics_service_proto_analytics_proto_rawDesc = "" +
 	"\n" +
-	"0services/analytics-service/proto/analytics.proto\x12\tanalytics\"\x80\x03\n" +
+	"0services/analytics-service/proto/analytics.proto\x12\tanalytics\"\xd5\x03\n" +
 	"\x11LogRequestRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1c\n" +
 	"\n" +
@@ -593,14 +1269,25 @@ const file_services_analytics_service_proto_analytics_proto_rawDesc = "" +
 	" \x01(\tR\tipAddress\x12\x1d\n" +
 	"\n" +
 	"user_agent\x18\v \x01(\tR\tuserAgent\x12\x14\n" +
-	"\x05error\x18\f \x01(\tR\x05error\".\n" +
+	"\x05error\x18\f \x01(\tR\x05error\x12\x1b\n" +
+	"\tcache_hit\x18\r \x01(\bR\bcacheHit\x12\x18\n" +
+	"\acredits\x18\x0e \x01(\x03R\acredits\x12\x1c\n" +
+	"\tcoalesced\x18\x0f \x01(\bR\tcoalesced\".\n" +
 	"\x12LogRequestResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"I\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\xc1\x01\n" +
 	"\x18GetRequestHistoryRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
-	"\x05limit\x18\x02 \x01(\x05R\x05limit\"F\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x1d\n" +
+	"\n" +
+	"start_date\x18\x03 \x01(\tR\tstartDate\x12\x19\n" +
+	"\bend_date\x18\x04 \x01(\tR\aendDate\x12\x1e\n" +
+	"\n" +
+	"blockchain\x18\x05 \x01(\tR\n" +
+	"blockchain\x12\x1c\n" +
+	"\n" +
+	"api_key_id\x18\x06 \x01(\tR\bapiKeyId\"F\n" +
 	"\x19GetRequestHistoryResponse\x12)\n" +
-	"\x04logs\x18\x01 \x03(\v2\x15.analytics.RequestLogR\x04logs\"\xa8\x03\n" +
+	"\x04logs\x18\x01 \x03(\v2\x15.analytics.RequestLogR\x04logs\"\xfd\x03\n" +
 	"\n" +
 	"RequestLog\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
@@ -624,22 +1311,96 @@ const file_services_analytics_service_proto_analytics_proto_rawDesc = "" +
 	"user_agent\x18\f \x01(\tR\tuserAgent\x12\x14\n" +
 	"\x05error\x18\r \x01(\tR\x05error\x12\x1d\n" +
 	"\n" +
-	"created_at\x18\x0e \x01(\tR\tcreatedAt\"i\n" +
+	"created_at\x18\x0e \x01(\tR\tcreatedAt\x12\x1b\n" +
+	"\tcache_hit\x18\x0f \x01(\bR\bcacheHit\x12\x18\n" +
+	"\acredits\x18\x10 \x01(\x03R\acredits\x12\x1c\n" +
+	"\tcoalesced\x18\x11 \x01(\bR\tcoalesced\"\xa7\x01\n" +
 	"\x14GetUsageStatsRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1d\n" +
 	"\n" +
 	"start_date\x18\x02 \x01(\tR\tstartDate\x12\x19\n" +
-	"\bend_date\x18\x03 \x01(\tR\aendDate\"\xc6\x01\n" +
+	"\bend_date\x18\x03 \x01(\tR\aendDate\x12\x1e\n" +
+	"\n" +
+	"blockchain\x18\x04 \x01(\tR\n" +
+	"blockchain\x12\x1c\n" +
+	"\n" +
+	"api_key_id\x18\x05 \x01(\tR\bapiKeyId\"\xd9\x03\n" +
 	"\x15GetUsageStatsResponse\x12%\n" +
 	"\x0etotal_requests\x18\x01 \x01(\x03R\rtotalRequests\x12/\n" +
 	"\x13successful_requests\x18\x02 \x01(\x03R\x12successfulRequests\x12!\n" +
 	"\fsuccess_rate\x18\x03 \x01(\x01R\vsuccessRate\x122\n" +
-	"\x15average_response_time\x18\x04 \x01(\x03R\x13averageResponseTime2\x91\x02\n" +
+	"\x15average_response_time\x18\x04 \x01(\x03R\x13averageResponseTime\x12'\n" +
+	"\x0ffailed_requests\x18\x05 \x01(\x03R\x0efailedRequests\x12*\n" +
+	"\x11p50_response_time\x18\x06 \x01(\x03R\x0fp50ResponseTime\x12*\n" +
+	"\x11p95_response_time\x18\a \x01(\x03R\x0fp95ResponseTime\x12*\n" +
+	"\x11p99_response_time\x18\b \x01(\x03R\x0fp99ResponseTime\x12#\n" +
+	"\rrequest_bytes\x18\t \x01(\x03R\frequestBytes\x12%\n" +
+	"\x0eresponse_bytes\x18\n" +
+	" \x01(\x03R\rresponseBytes\x12\x18\n" +
+	"\acredits\x18\v \x01(\x03R\acredits\"\xe6\x01\n" +
+	"\x19GetUsageTimeSeriesRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1d\n" +
+	"\n" +
+	"start_date\x18\x02 \x01(\tR\tstartDate\x12\x19\n" +
+	"\bend_date\x18\x03 \x01(\tR\aendDate\x12 \n" +
+	"\vgranularity\x18\x04 \x01(\tR\vgranularity\x12\x1e\n" +
+	"\n" +
+	"blockchain\x18\x05 \x01(\tR\n" +
+	"blockchain\x12\x1c\n" +
+	"\n" +
+	"api_key_id\x18\x06 \x01(\tR\bapiKeyId\x12\x16\n" +
+	"\x06method\x18\a \x01(\tR\x06method\"\xfc\x02\n" +
+	"\n" +
+	"UsagePoint\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\tR\ttimestamp\x12\x1a\n" +
+	"\brequests\x18\x02 \x01(\x03R\brequests\x12\x16\n" +
+	"\x06errors\x18\x03 \x01(\x03R\x06errors\x122\n" +
+	"\x15average_response_time\x18\x04 \x01(\x03R\x13averageResponseTime\x12*\n" +
+	"\x11p50_response_time\x18\x05 \x01(\x03R\x0fp50ResponseTime\x12*\n" +
+	"\x11p95_response_time\x18\x06 \x01(\x03R\x0fp95ResponseTime\x12*\n" +
+	"\x11p99_response_time\x18\a \x01(\x03R\x0fp99ResponseTime\x12#\n" +
+	"\rrequest_bytes\x18\b \x01(\x03R\frequestBytes\x12%\n" +
+	"\x0eresponse_bytes\x18\t \x01(\x03R\rresponseBytes\x12\x18\n" +
+	"\acredits\x18\n" +
+	" \x01(\x03R\acredits\"m\n" +
+	"\x1aGetUsageTimeSeriesResponse\x12 \n" +
+	"\vgranularity\x18\x01 \x01(\tR\vgranularity\x12-\n" +
+	"\x06points\x18\x02 \x03(\v2\x15.analytics.UsagePointR\x06points\"\xde\x01\n" +
+	"\x18GetUsageBreakdownRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1d\n" +
+	"\n" +
+	"start_date\x18\x02 \x01(\tR\tstartDate\x12\x19\n" +
+	"\bend_date\x18\x03 \x01(\tR\aendDate\x12\x19\n" +
+	"\bgroup_by\x18\x04 \x01(\tR\agroupBy\x12\x1e\n" +
+	"\n" +
+	"blockchain\x18\x05 \x01(\tR\n" +
+	"blockchain\x12\x1c\n" +
+	"\n" +
+	"api_key_id\x18\x06 \x01(\tR\bapiKeyId\x12\x16\n" +
+	"\x06method\x18\a \x01(\tR\x06method\"\xf0\x02\n" +
+	"\n" +
+	"UsageGroup\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1a\n" +
+	"\brequests\x18\x02 \x01(\x03R\brequests\x12\x16\n" +
+	"\x06errors\x18\x03 \x01(\x03R\x06errors\x122\n" +
+	"\x15average_response_time\x18\x04 \x01(\x03R\x13averageResponseTime\x12*\n" +
+	"\x11p50_response_time\x18\x05 \x01(\x03R\x0fp50ResponseTime\x12*\n" +
+	"\x11p95_response_time\x18\x06 \x01(\x03R\x0fp95ResponseTime\x12*\n" +
+	"\x11p99_response_time\x18\a \x01(\x03R\x0fp99ResponseTime\x12#\n" +
+	"\rrequest_bytes\x18\b \x01(\x03R\frequestBytes\x12%\n" +
+	"\x0eresponse_bytes\x18\t \x01(\x03R\rresponseBytes\x12\x18\n" +
+	"\acredits\x18\n" +
+	" \x01(\x03R\acredits\"e\n" +
+	"\x19GetUsageBreakdownResponse\x12\x19\n" +
+	"\bgroup_by\x18\x01 \x01(\tR\agroupBy\x12-\n" +
+	"\x06groups\x18\x02 \x03(\v2\x15.analytics.UsageGroupR\x06groups2\xd4\x03\n" +
 	"\x10AnalyticsService\x12I\n" +
 	"\n" +
 	"LogRequest\x12\x1c.analytics.LogRequestRequest\x1a\x1d.analytics.LogRequestResponse\x12^\n" +
 	"\x11GetRequestHistory\x12#.analytics.GetRequestHistoryRequest\x1a$.analytics.GetRequestHistoryResponse\x12R\n" +
-	"\rGetUsageStats\x12\x1f.analytics.GetUsageStatsRequest\x1a .analytics.GetUsageStatsResponseB2Z0quicknode-clone/services/analytics-service/protob\x06proto3"
+	"\rGetUsageStats\x12\x1f.analytics.GetUsageStatsRequest\x1a .analytics.GetUsageStatsResponse\x12a\n" +
+	"\x12GetUsageTimeSeries\x12$.analytics.GetUsageTimeSeriesRequest\x1a%.analytics.GetUsageTimeSeriesResponse\x12^\n" +
+	"\x11GetUsageBreakdown\x12#.analytics.GetUsageBreakdownRequest\x1a$.analytics.GetUsageBreakdownResponseB2Z0quicknode-clone/services/analytics-service/protob\x06proto3"
 
 var (
 	file_services_analytics_service_proto_analytics_proto_rawDescOnce sync.Once
@@ -653,29 +1414,41 @@ func file_services_analytics_service_proto_analytics_proto_rawDescGZIP() []byte
 	return file_services_analytics_service_proto_analytics_proto_rawDescData
 }
 
-var file_services_analytics_service_proto_analytics_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_services_analytics_service_proto_analytics_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_services_analytics_service_proto_analytics_proto_goTypes = []any{
-	(*LogRequestRequest)(nil),         // 0: analytics.LogRequestRequest
-	(*LogRequestResponse)(nil),        // 1: analytics.LogRequestResponse
-	(*GetRequestHistoryRequest)(nil),  // 2: analytics.GetRequestHistoryRequest
-	(*GetRequestHistoryResponse)(nil), // 3: analytics.GetRequestHistoryResponse
-	(*RequestLog)(nil),                // 4: analytics.RequestLog
-	(*GetUsageStatsRequest)(nil),      // 5: analytics.GetUsageStatsRequest
-	(*GetUsageStatsResponse)(nil),     // 6: analytics.GetUsageStatsResponse
+	(*LogRequestRequest)(nil),          // 0: analytics.LogRequestRequest
+	(*LogRequestResponse)(nil),         // 1: analytics.LogRequestResponse
+	(*GetRequestHistoryRequest)(nil),   // 2: analytics.GetRequestHistoryRequest
+	(*GetRequestHistoryResponse)(nil),  // 3: analytics.GetRequestHistoryResponse
+	(*RequestLog)(nil),                 // 4: analytics.RequestLog
+	(*GetUsageStatsRequest)(nil),       // 5: analytics.GetUsageStatsRequest
+	(*GetUsageStatsResponse)(nil),      // 6: analytics.GetUsageStatsResponse
+	(*GetUsageTimeSeriesRequest)(nil),  // 7: analytics.GetUsageTimeSeriesRequest
+	(*UsagePoint)(nil),                 // 8: analytics.UsagePoint
+	(*GetUsageTimeSeriesResponse)(nil), // 9: analytics.GetUsageTimeSeriesResponse
+	(*GetUsageBreakdownRequest)(nil),   // 10: analytics.GetUsageBreakdownRequest
+	(*UsageGroup)(nil),                 // 11: analytics.UsageGroup
+	(*GetUsageBreakdownResponse)(nil),  // 12: analytics.GetUsageBreakdownResponse
 }
 var file_services_analytics_service_proto_analytics_proto_depIdxs = []int32{
-	4, // 0: analytics.GetRequestHistoryResponse.logs:type_name -> analytics.RequestLog
-	0, // 1: analytics.AnalyticsService.LogRequest:input_type -> analytics.LogRequestRequest
-	2, // 2: analytics.AnalyticsService.GetRequestHistory:input_type -> analytics.GetRequestHistoryRequest
-	5, // 3: analytics.AnalyticsService.GetUsageStats:input_type -> analytics.GetUsageStatsRequest
-	1, // 4: analytics.AnalyticsService.LogRequest:output_type -> analytics.LogRequestResponse
-	3, // 5: analytics.AnalyticsService.GetRequestHistory:output_type -> analytics.GetRequestHistoryResponse
-	6, // 6: analytics.AnalyticsService.GetUsageStats:output_type -> analytics.GetUsageStatsResponse
-	4, // [4:7] is the sub-list for method output_type
-	1, // [1:4] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	4,  // 0: analytics.GetRequestHistoryResponse.logs:type_name -> analytics.RequestLog
+	8,  // 1: analytics.GetUsageTimeSeriesResponse.points:type_name -> analytics.UsagePoint
+	11, // 2: analytics.GetUsageBreakdownResponse.groups:type_name -> analytics.UsageGroup
+	0,  // 3: analytics.AnalyticsService.LogRequest:input_type -> analytics.LogRequestRequest
+	2,  // 4: analytics.AnalyticsService.GetRequestHistory:input_type -> analytics.GetRequestHistoryRequest
+	5,  // 5: analytics.AnalyticsService.GetUsageStats:input_type -> analytics.GetUsageStatsRequest
+	7,  // 6: analytics.AnalyticsService.GetUsageTimeSeries:input_type -> analytics.GetUsageTimeSeriesRequest
+	10, // 7: analytics.AnalyticsService.GetUsageBreakdown:input_type -> analytics.GetUsageBreakdownRequest
+	1,  // 8: analytics.AnalyticsService.LogRequest:output_type -> analytics.LogRequestResponse
+	3,  // 9: analytics.AnalyticsService.GetRequestHistory:output_type -> analytics.GetRequestHistoryResponse
+	6,  // 10: analytics.AnalyticsService.GetUsageStats:output_type -> analytics.GetUsageStatsResponse
+	9,  // 11: analytics.AnalyticsService.GetUsageTimeSeries:output_type -> analytics.GetUsageTimeSeriesResponse
+	12, // 12: analytics.AnalyticsService.GetUsageBreakdown:output_type -> analytics.GetUsageBreakdownResponse
+	8,  // [8:13] is the sub-list for method output_type
+	3,  // [3:8] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_services_analytics_service_proto_analytics_proto_init() }
@@ -689,7 +1462,7 @@ func file_services_analytics_service_proto_analytics_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_services_analytics_service_proto_analytics_proto_rawDesc), len(file_services_analytics_service_proto_analytics_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
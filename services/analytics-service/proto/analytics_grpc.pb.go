@@ -19,9 +19,11 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	AnalyticsService_LogRequest_FullMethodName        = "/analytics.AnalyticsService/LogRequest"
-	AnalyticsService_GetRequestHistory_FullMethodName = "/analytics.AnalyticsService/GetRequestHistory"
-	AnalyticsService_GetUsageStats_FullMethodName     = "/analytics.AnalyticsService/GetUsageStats"
+	AnalyticsService_LogRequest_FullMethodName         = "/analytics.AnalyticsService/LogRequest"
+	AnalyticsService_GetRequestHistory_FullMethodName  = "/analytics.AnalyticsService/GetRequestHistory"
+	AnalyticsService_GetUsageStats_FullMethodName      = "/analytics.AnalyticsService/GetUsageStats"
+	AnalyticsService_GetUsageTimeSeries_FullMethodName = "/analytics.AnalyticsService/GetUsageTimeSeries"
+	AnalyticsService_GetUsageBreakdown_FullMethodName  = "/analytics.AnalyticsService/GetUsageBreakdown"
 )
 
 // AnalyticsServiceClient is the client API for AnalyticsService service.
@@ -31,6 +33,8 @@ type AnalyticsServiceClient interface {
 	LogRequest(ctx context.Context, in *LogRequestRequest, opts ...grpc.CallOption) (*LogRequestResponse, error)
 	GetRequestHistory(ctx context.Context, in *GetRequestHistoryRequest, opts ...grpc.CallOption) (*GetRequestHistoryResponse, error)
 	GetUsageStats(ctx context.Context, in *GetUsageStatsRequest, opts ...grpc.CallOption) (*GetUsageStatsResponse, error)
+	GetUsageTimeSeries(ctx context.Context, in *GetUsageTimeSeriesRequest, opts ...grpc.CallOption) (*GetUsageTimeSeriesResponse, error)
+	GetUsageBreakdown(ctx context.Context, in *GetUsageBreakdownRequest, opts ...grpc.CallOption) (*GetUsageBreakdownResponse, error)
 }
 
 type analyticsServiceClient struct {
@@ -68,6 +72,24 @@ func (c *analyticsServiceClient) GetUsageStats(ctx context.Context, in *GetUsage
 	return out, nil
 }
 
+func (c *analyticsServiceClient) GetUsageTimeSeries(ctx context.Context, in *GetUsageTimeSeriesRequest, opts ...grpc.CallOption) (*GetUsageTimeSeriesResponse, error) {
+	out := new(GetUsageTimeSeriesResponse)
+	err := c.cc.Invoke(ctx, AnalyticsService_GetUsageTimeSeries_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) GetUsageBreakdown(ctx context.Context, in *GetUsageBreakdownRequest, opts ...grpc.CallOption) (*GetUsageBreakdownResponse, error) {
+	out := new(GetUsageBreakdownResponse)
+	err := c.cc.Invoke(ctx, AnalyticsService_GetUsageBreakdown_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AnalyticsServiceServer is the server API for AnalyticsService service.
 // All implementations must embed UnimplementedAnalyticsServiceServer
 // for forward compatibility
@@ -75,6 +97,8 @@ type AnalyticsServiceServer interface {
 	LogRequest(context.Context, *LogRequestRequest) (*LogRequestResponse, error)
 	GetRequestHistory(context.Context, *GetRequestHistoryRequest) (*GetRequestHistoryResponse, error)
 	GetUsageStats(context.Context, *GetUsageStatsRequest) (*GetUsageStatsResponse, error)
+	GetUsageTimeSeries(context.Context, *GetUsageTimeSeriesRequest) (*GetUsageTimeSeriesResponse, error)
+	GetUsageBreakdown(context.Context, *GetUsageBreakdownRequest) (*GetUsageBreakdownResponse, error)
 	mustEmbedUnimplementedAnalyticsServiceServer()
 }
 
@@ -91,6 +115,12 @@ func (UnimplementedAnalyticsServiceServer) GetRequestHistory(context.Context, *G
 func (UnimplementedAnalyticsServiceServer) GetUsageStats(context.Context, *GetUsageStatsRequest) (*GetUsageStatsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUsageStats not implemented")
 }
+func (UnimplementedAnalyticsServiceServer) GetUsageTimeSeries(context.Context, *GetUsageTimeSeriesRequest) (*GetUsageTimeSeriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsageTimeSeries not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) GetUsageBreakdown(context.Context, *GetUsageBreakdownRequest) (*GetUsageBreakdownResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsageBreakdown not implemented")
+}
 func (UnimplementedAnalyticsServiceServer) mustEmbedUnimplementedAnalyticsServiceServer() {}
 
 // UnsafeAnalyticsServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -158,6 +188,42 @@ func _AnalyticsService_GetUsageStats_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AnalyticsService_GetUsageTimeSeries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUsageTimeSeriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).GetUsageTimeSeries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_GetUsageTimeSeries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).GetUsageTimeSeries(ctx, req.(*GetUsageTimeSeriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_GetUsageBreakdown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUsageBreakdownRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).GetUsageBreakdown(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_GetUsageBreakdown_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).GetUsageBreakdown(ctx, req.(*GetUsageBreakdownRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AnalyticsService_ServiceDesc is the grpc.ServiceDesc for AnalyticsService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -177,6 +243,14 @@ var AnalyticsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUsageStats",
 			Handler:    _AnalyticsService_GetUsageStats_Handler,
 		},
+		{
+			MethodName: "GetUsageTimeSeries",
+			Handler:    _AnalyticsService_GetUsageTimeSeries_Handler,
+		},
+		{
+			MethodName: "GetUsageBreakdown",
+			Handler:    _AnalyticsService_GetUsageBreakdown_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "services/analytics-service/proto/analytics.proto",
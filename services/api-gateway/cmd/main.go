@@ -1,6 +1,12 @@
 package main
 
 import (
+	"context"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"ironnode/pkg/config"
 	"ironnode/pkg/logger"
 	"ironnode/pkg/middleware"
@@ -30,12 +36,22 @@ func main() {
 	// Initialize Gin router
 	router := gin.Default()
 
+	// Clients are identified by their peer address unless it is a trusted proxy,
+	// so X-Forwarded-For cannot get around API key IP restrictions
+	if err := router.SetTrustedProxies(cfg.Gateway.TrustedProxies); err != nil {
+		logger.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+
 	// Apply middleware
 	router.Use(middleware.CORS())
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(cfg)
 	blockchainHandler := handler.NewBlockchainHandler(cfg)
+	rpcHandler := handler.NewRPCHandler(cfg)
+	apiKeyHandler := handler.NewAPIKeyHandler(cfg)
+	billingHandler := handler.NewBillingHandler(cfg)
+	analyticsHandler := handler.NewAnalyticsHandler(cfg)
 
 	// Initialize wallet service
 	if err := handler.InitWalletService(cfg.Database.DSN()); err != nil {
@@ -44,17 +60,38 @@ func main() {
 	logger.Info("Wallet service initialized successfully")
 
 	// Initialize Tron client
-	tronNodeURL := "http://78.46.94.60:8090"
-	handler.InitTronClient(tronNodeURL)
-	logger.Info("Tron client initialized successfully. Node:", tronNodeURL)
+	handler.InitTronClient(cfg.Crypto.TronNodeURL)
+	logger.Info("Tron client initialized successfully. Node:", cfg.Crypto.TronNodeURL)
 
 	// Setup routes
-	routes.SetupRoutes(router, authHandler, blockchainHandler, redisClient)
+	routes.SetupRoutes(router, authHandler, blockchainHandler, rpcHandler, apiKeyHandler, billingHandler, analyticsHandler, redisClient)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	// Start server
-	address := ":" + cfg.Services.APIGatewayPort
-	logger.Info("API Gateway is running on", address)
-	if err := router.Run(address); err != nil {
-		logger.Fatal("Failed to start server:", err)
+	srv := &http.Server{
+		Addr:    ":" + cfg.Services.APIGatewayPort,
+		Handler: router,
 	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Fatal("Failed to start server:", err)
+		}
+	}()
+	logger.Info("API Gateway is running on", srv.Addr)
+
+	<-ctx.Done()
+	stop()
+	logger.Info("Shutting down API Gateway...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Server forced to shutdown:", err)
+	}
+
+	// Flush usage counters and queued request logs once no request can add to them
+	rpcHandler.Close()
+	logger.Info("API Gateway stopped")
 }
@@ -1,46 +1,291 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
+	"ironnode/pkg/config"
 	"ironnode/pkg/response"
+	pb "ironnode/services/analytics-service/proto"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-func GetUsageStats(c *gin.Context) {
-	// This would call Analytics Service
-	stats := gin.H{
-		"total_requests":    1500,
-		"requests_today":    150,
-		"requests_this_month": 4500,
-		"success_rate":      98.5,
-		"average_response_time": 120, // ms
-	}
-
-	response.Success(c, http.StatusOK, "Usage stats retrieved", stats)
-}
-
-func GetRequestHistory(c *gin.Context) {
-	// This would call Analytics Service
-	history := []gin.H{
-		{
-			"id":            "1",
-			"blockchain":    "ethereum",
-			"method":        "eth_getBlockByNumber",
-			"status_code":   200,
-			"response_time": 125,
-			"timestamp":     "2024-01-15T10:30:00Z",
-		},
-		{
-			"id":            "2",
-			"blockchain":    "polygon",
-			"method":        "eth_call",
-			"status_code":   200,
-			"response_time": 98,
-			"timestamp":     "2024-01-15T10:29:00Z",
-		},
+// defaultStatsPeriod is how far back usage stats go when no start_date is given
+const defaultStatsPeriod = 30 * 24 * time.Hour
+
+type AnalyticsHandler struct {
+	analyticsClient pb.AnalyticsServiceClient
+}
+
+func NewAnalyticsHandler(cfg *config.Config) *AnalyticsHandler {
+	// Connect to Analytics Service via gRPC
+	conn := dialService("ANALYTICS_SERVICE_HOST", cfg.Services.AnalyticsPort)
+
+	return &AnalyticsHandler{
+		analyticsClient: pb.NewAnalyticsServiceClient(conn),
+	}
+}
+
+// GetUsageStats returns request totals for the period, by default the last 30 days
+// GET /api/v1/analytics/usage?start_date=2024-01-01&end_date=2024-01-31&blockchain=ethereum&api_key_id=
+func (h *AnalyticsHandler) GetUsageStats(c *gin.Context) {
+	startDate, endDate, err := parseDateRange(c)
+	if err != nil {
+		response.BadRequest(c, "Invalid date range", err)
+		return
+	}
+
+	end := time.Now().UTC()
+	if endDate != nil {
+		end = *endDate
+	}
+	start := end.Add(-defaultStatsPeriod)
+	if startDate != nil {
+		start = *startDate
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.analyticsClient.GetUsageStats(ctx, &pb.GetUsageStatsRequest{
+		UserId:     c.GetString("user_id"),
+		StartDate:  start.Format(time.RFC3339),
+		EndDate:    end.Format(time.RFC3339),
+		Blockchain: c.Query("blockchain"),
+		ApiKeyId:   c.Query("api_key_id"),
+	})
+	if err != nil {
+		analyticsError(c, "Failed to get usage stats", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Usage stats retrieved", gin.H{
+		"start_date":            start,
+		"end_date":              end,
+		"total_requests":        resp.TotalRequests,
+		"successful_requests":   resp.SuccessfulRequests,
+		"failed_requests":       resp.FailedRequests,
+		"success_rate":          resp.SuccessRate,
+		"average_response_time": resp.AverageResponseTime, // ms
+		"p50_response_time":     resp.P50ResponseTime,
+		"p95_response_time":     resp.P95ResponseTime,
+		"p99_response_time":     resp.P99ResponseTime,
+		"request_bytes":         resp.RequestBytes,
+		"response_bytes":        resp.ResponseBytes,
+		"credits":               resp.Credits,
+	})
+}
+
+// GetUsageTimeSeries returns the traffic in minute, hour or day buckets for charting.
+// Without a granularity, one is picked for the range (by default the last 30 days).
+// GET /api/v1/analytics/timeseries?granularity=hour&start_date=&end_date=&blockchain=&api_key_id=&method=
+func (h *AnalyticsHandler) GetUsageTimeSeries(c *gin.Context) {
+	startDate, endDate, err := parseDateRange(c)
+	if err != nil {
+		response.BadRequest(c, "Invalid date range", err)
+		return
+	}
+
+	req := &pb.GetUsageTimeSeriesRequest{
+		UserId:      c.GetString("user_id"),
+		Granularity: c.Query("granularity"),
+		Blockchain:  c.Query("blockchain"),
+		ApiKeyId:    c.Query("api_key_id"),
+		Method:      c.Query("method"),
+	}
+	if startDate != nil {
+		req.StartDate = startDate.Format(time.RFC3339)
+	}
+	if endDate != nil {
+		req.EndDate = endDate.Format(time.RFC3339)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.analyticsClient.GetUsageTimeSeries(ctx, req)
+	if err != nil {
+		analyticsError(c, "Failed to get usage time series", err)
+		return
+	}
+
+	points := make([]gin.H, 0, len(resp.Points))
+	for _, point := range resp.Points {
+		points = append(points, gin.H{
+			"timestamp":             point.Timestamp,
+			"requests":              point.Requests,
+			"errors":                point.Errors,
+			"average_response_time": point.AverageResponseTime,
+			"p50_response_time":     point.P50ResponseTime,
+			"p95_response_time":     point.P95ResponseTime,
+			"p99_response_time":     point.P99ResponseTime,
+			"request_bytes":         point.RequestBytes,
+			"response_bytes":        point.ResponseBytes,
+			"credits":               point.Credits,
+		})
+	}
+
+	response.Success(c, http.StatusOK, "Usage time series retrieved", gin.H{
+		"granularity": resp.Granularity,
+		"points":      points,
+	})
+}
+
+// GetUsageBreakdown returns the traffic per API key, method or chain, busiest first,
+// by default over the last 30 days
+// GET /api/v1/analytics/breakdown?group_by=api_key|method|blockchain&start_date=&end_date=&blockchain=&api_key_id=&method=
+func (h *AnalyticsHandler) GetUsageBreakdown(c *gin.Context) {
+	startDate, endDate, err := parseDateRange(c)
+	if err != nil {
+		response.BadRequest(c, "Invalid date range", err)
+		return
+	}
+
+	req := &pb.GetUsageBreakdownRequest{
+		UserId:     c.GetString("user_id"),
+		GroupBy:    c.DefaultQuery("group_by", "api_key"),
+		Blockchain: c.Query("blockchain"),
+		ApiKeyId:   c.Query("api_key_id"),
+		Method:     c.Query("method"),
+	}
+	if startDate != nil {
+		req.StartDate = startDate.Format(time.RFC3339)
+	}
+	if endDate != nil {
+		req.EndDate = endDate.Format(time.RFC3339)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.analyticsClient.GetUsageBreakdown(ctx, req)
+	if err != nil {
+		analyticsError(c, "Failed to get usage breakdown", err)
+		return
+	}
+
+	groups := make([]gin.H, 0, len(resp.Groups))
+	for _, group := range resp.Groups {
+		groups = append(groups, gin.H{
+			"key":                   group.Key, // API key ID, method or blockchain
+			"requests":              group.Requests,
+			"errors":                group.Errors,
+			"average_response_time": group.AverageResponseTime,
+			"p50_response_time":     group.P50ResponseTime,
+			"p95_response_time":     group.P95ResponseTime,
+			"p99_response_time":     group.P99ResponseTime,
+			"request_bytes":         group.RequestBytes,
+			"response_bytes":        group.ResponseBytes,
+			"credits":               group.Credits,
+		})
+	}
+
+	response.Success(c, http.StatusOK, "Usage breakdown retrieved", gin.H{
+		"group_by": resp.GroupBy,
+		"groups":   groups,
+	})
+}
+
+// GetRequestHistory returns the user's latest proxied calls, newest first
+// GET /api/v1/analytics/requests?limit=20&start_date=&end_date=&blockchain=&api_key_id=
+func (h *AnalyticsHandler) GetRequestHistory(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	startDate, endDate, err := parseDateRange(c)
+	if err != nil {
+		response.BadRequest(c, "Invalid date range", err)
+		return
+	}
+
+	req := &pb.GetRequestHistoryRequest{
+		UserId:     c.GetString("user_id"),
+		Limit:      int32(limit),
+		Blockchain: c.Query("blockchain"),
+		ApiKeyId:   c.Query("api_key_id"),
+	}
+	if startDate != nil {
+		req.StartDate = startDate.Format(time.RFC3339)
+	}
+	if endDate != nil {
+		req.EndDate = endDate.Format(time.RFC3339)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.analyticsClient.GetRequestHistory(ctx, req)
+	if err != nil {
+		analyticsError(c, "Failed to get request history", err)
+		return
+	}
+
+	history := make([]gin.H, 0, len(resp.Logs))
+	for _, log := range resp.Logs {
+		history = append(history, gin.H{
+			"id":            log.Id,
+			"api_key_id":    log.ApiKeyId,
+			"blockchain":    log.Blockchain,
+			"method":        log.Method,
+			"endpoint":      log.Endpoint,
+			"status_code":   log.StatusCode,
+			"response_time": log.ResponseTime,
+			"credits":       log.Credits,
+			"cache_hit":     log.CacheHit,
+			"error":         log.Error,
+			"timestamp":     log.CreatedAt,
+		})
 	}
 
 	response.Success(c, http.StatusOK, "Request history retrieved", history)
 }
+
+// parseDateRange reads the optional start_date and end_date query parameters,
+// given as RFC3339 or as a date; a date-only end_date includes that whole day
+func parseDateRange(c *gin.Context) (start, end *time.Time, err error) {
+	if value := c.Query("start_date"); value != "" {
+		t, _, err := parseDate(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		start = &t
+	}
+
+	if value := c.Query("end_date"); value != "" {
+		t, dateOnly, err := parseDate(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		if dateOnly {
+			t = t.Add(24*time.Hour - time.Second)
+		}
+		end = &t
+	}
+
+	if start != nil && end != nil && end.Before(*start) {
+		return nil, nil, errors.New("end_date is before start_date")
+	}
+
+	return start, end, nil
+}
+
+func parseDate(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t.UTC(), false, err
+}
+
+func analyticsError(c *gin.Context, message string, err error) {
+	if status.Code(err) == codes.InvalidArgument {
+		response.BadRequest(c, message, err)
+		return
+	}
+	response.InternalServerError(c, message, err)
+}
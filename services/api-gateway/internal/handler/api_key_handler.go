@@ -1,64 +1,392 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
+	"ironnode/pkg/config"
+	"ironnode/pkg/jsonrpc"
+	"ironnode/pkg/models"
 	"ironnode/pkg/response"
+	pb "ironnode/services/user-service/proto"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-func ListAPIKeys(c *gin.Context) {
-	// This would call User Service
-	keys := []gin.H{
-		{
-			"id":          "1",
-			"name":        "Production API Key",
-			"key":         "qn_***************",
-			"is_active":   true,
-			"created_at":  "2024-01-01T00:00:00Z",
-		},
-		{
-			"id":          "2",
-			"name":        "Development API Key",
-			"key":         "qn_***************",
-			"is_active":   true,
-			"created_at":  "2024-01-10T00:00:00Z",
-		},
+const (
+	// apiKeyPrefix is the prefix of every key issued by User Service
+	apiKeyPrefix = "qn_"
+	// deprecatedKeyLogInterval is how often calls made with the same rotated key are logged
+	deprecatedKeyLogInterval = time.Hour
+)
+
+// deprecatedKeyHeaders flag responses to calls made with a rotated API key
+var deprecatedKeyHeaders = []string{"X-API-Key-Deprecated", "Sunset"}
+
+type APIKeyHandler struct {
+	userClient pb.UserServiceClient
+
+	mu             sync.Mutex
+	deprecatedSeen map[string]time.Time // when calls with each rotated key were last logged
+}
+
+func NewAPIKeyHandler(cfg *config.Config) *APIKeyHandler {
+	// Connect to User Service via gRPC
+	conn := dialService("USER_SERVICE_HOST", cfg.Services.UserServicePort)
+
+	return &APIKeyHandler{
+		userClient:     pb.NewUserServiceClient(conn),
+		deprecatedSeen: make(map[string]time.Time),
+	}
+}
+
+// APIKeyMiddleware authenticates JSON-RPC clients by API key.
+// The key is read from the /rpc/<key>/<blockchain>/<network> path or the X-API-Key header.
+func (h *APIKeyHandler) APIKeyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, blockchain, network, ok := splitRPCPath(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusNotFound, jsonrpc.NewErrorResponse(nil, jsonrpc.InvalidRequest, "Expected /rpc/<blockchain>/<network>"))
+			return
+		}
+
+		if key == "" {
+			key = c.GetHeader("X-API-Key")
+		}
+
+		if key == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, jsonrpc.NewErrorResponse(nil, jsonrpc.ServerError, "API key required"))
+			return
+		}
+
+		if !strings.HasPrefix(key, apiKeyPrefix) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, jsonrpc.NewErrorResponse(nil, jsonrpc.ServerError, "Invalid API key"))
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		resp, err := h.userClient.ValidateAPIKey(ctx, &pb.ValidateAPIKeyRequest{
+			Key: key,
+		})
+
+		if err != nil || !resp.Valid {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, jsonrpc.NewErrorResponse(nil, jsonrpc.ServerError, "Invalid or expired API key"))
+			return
+		}
+
+		restrictions := restrictionsFromProto(resp.Restrictions)
+		if reason, allowed := checkRestrictions(restrictions, blockchain, network, c.ClientIP(), requestOrigin(c)); !allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, jsonrpc.NewErrorResponse(nil, jsonrpc.AccessDenied, reason))
+			return
+		}
+
+		if resp.Deprecated {
+			h.warnDeprecatedKey(c, resp)
+		}
+
+		// Set user and API key IDs in context
+		c.Set("user_id", resp.UserId)
+		c.Set("api_key_id", resp.ApiKeyId)
+		c.Set("api_key_monthly_limit", resp.MonthlyLimit)
+		c.Set("api_key_requests_per_second", resp.RequestsPerSecond)
+		c.Set("api_key_restrictions", restrictions) // methods are checked per call
+		c.Set("api_key_expires_at", resp.ExpiresAt)
+		c.Set("api_key", key) // WebSocket sessions re-validate it while open
+		c.Next()
+	}
+}
+
+// restrictionsFromProto converts the restrictions User Service returns for a key
+func restrictionsFromProto(restrictions *pb.APIKeyRestrictions) *models.APIKeyRestrictions {
+	return &models.APIKeyRestrictions{
+		AllowedChains:  restrictions.GetAllowedChains(),
+		AllowedMethods: restrictions.GetAllowedMethods(),
+		AllowedIPs:     restrictions.GetAllowedIps(),
+		AllowedOrigins: restrictions.GetAllowedOrigins(),
+	}
+}
+
+// requestOrigin returns the page a browser call came from
+func requestOrigin(c *gin.Context) string {
+	if origin := c.GetHeader("Origin"); origin != "" {
+		return origin
+	}
+	return c.GetHeader("Referer")
+}
+
+// checkRestrictions checks the chain, client IP and page origin of a call
+// against the API key's restrictions and explains a refusal
+func checkRestrictions(restrictions *models.APIKeyRestrictions, blockchain, network, ipAddress, origin string) (string, bool) {
+	if !restrictions.AllowsChain(blockchain, network) {
+		return fmt.Sprintf("API key is not allowed on %s/%s", blockchain, network), false
+	}
+
+	if !restrictions.AllowsIP(ipAddress) {
+		return fmt.Sprintf("API key is not allowed from IP %s", ipAddress), false
+	}
+
+	if !restrictions.AllowsOrigin(origin) {
+		if origin == "" {
+			return "API key requires an Origin or Referer header", false
+		}
+		return fmt.Sprintf("API key is not allowed from origin %s", origin), false
+	}
+
+	return "", true
+}
+
+// warnDeprecatedKey flags a call made with a rotated key, which stops working when its
+// grace period ends, in the response headers and, once per deprecatedKeyLogInterval
+// for each key, in the log so stragglers can be found
+func (h *APIKeyHandler) warnDeprecatedKey(c *gin.Context, resp *pb.ValidateAPIKeyResponse) {
+	c.Header("X-API-Key-Deprecated", "true")
+	if expiresAt, err := time.Parse(time.RFC3339, resp.ExpiresAt); err == nil {
+		c.Header("Sunset", expiresAt.UTC().Format(http.TimeFormat))
+	}
+
+	if !h.logDeprecatedKey(resp.ApiKeyId, time.Now()) {
+		return
+	}
+	log.Printf("[APIKey] Deprecated API key %s of user %s used from %s (replaced by %s, works until %s)",
+		resp.ApiKeyId, resp.UserId, c.ClientIP(), resp.ReplacedById, resp.ExpiresAt)
+}
+
+// logDeprecatedKey reports whether a call made with the rotated key apiKeyID at now
+// should be logged, and if so starts its next interval
+func (h *APIKeyHandler) logDeprecatedKey(apiKeyID string, now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if last, ok := h.deprecatedSeen[apiKeyID]; ok && now.Sub(last) < deprecatedKeyLogInterval {
+		return false
+	}
+
+	// Keys past their grace period are no longer used; forget them
+	for id, last := range h.deprecatedSeen {
+		if now.Sub(last) >= deprecatedKeyLogInterval {
+			delete(h.deprecatedSeen, id)
+		}
+	}
+	h.deprecatedSeen[apiKeyID] = now
+	return true
+}
+
+// ListAPIKeys returns the user's keys, inactive ones included; keys are shown by prefix only
+// GET /api/v1/api-keys
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.userClient.GetAPIKeys(ctx, &pb.GetAPIKeysRequest{
+		UserId: c.GetString("user_id"),
+	})
+	if err != nil {
+		apiKeyError(c, "Failed to list API keys", err)
+		return
+	}
+
+	keys := make([]gin.H, 0, len(resp.ApiKeys))
+	for _, key := range resp.ApiKeys {
+		keys = append(keys, apiKeyJSON(key))
 	}
 
 	response.Success(c, http.StatusOK, "API keys retrieved", keys)
 }
 
-func CreateAPIKey(c *gin.Context) {
-	var req struct {
-		Name        string `json:"name" binding:"required"`
-		Description string `json:"description"`
+type APIKeyRestrictionsRequest struct {
+	AllowedChains  []string `json:"allowed_chains"`
+	AllowedMethods []string `json:"allowed_methods"`
+	AllowedIPs     []string `json:"allowed_ips"`
+	AllowedOrigins []string `json:"allowed_origins"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name              string                    `json:"name" binding:"required"`
+	Description       string                    `json:"description"`
+	MonthlyLimit      int64                     `json:"monthly_limit"`       // 0 for no limit
+	RequestsPerSecond int64                     `json:"requests_per_second"` // 0 for no limit
+	Restrictions      APIKeyRestrictionsRequest `json:"restrictions"`
+}
+
+// CreateAPIKey issues a key; the response is the only time the full key is shown
+// POST /api/v1/api-keys
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key, err := h.userClient.CreateAPIKey(ctx, &pb.CreateAPIKeyRequest{
+		UserId:            c.GetString("user_id"),
+		Name:              req.Name,
+		Description:       req.Description,
+		MonthlyLimit:      req.MonthlyLimit,
+		RequestsPerSecond: req.RequestsPerSecond,
+		Restrictions: &pb.APIKeyRestrictions{
+			AllowedChains:  req.Restrictions.AllowedChains,
+			AllowedMethods: req.Restrictions.AllowedMethods,
+			AllowedIps:     req.Restrictions.AllowedIPs,
+			AllowedOrigins: req.Restrictions.AllowedOrigins,
+		},
+	})
+	if err != nil {
+		apiKeyError(c, "Failed to create API key", err)
+		return
 	}
 
+	response.Success(c, http.StatusCreated, "API key created", apiKeyJSON(key))
+}
+
+// UpdateAPIKeyRequest changes the fields that are present
+type UpdateAPIKeyRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	IsActive    *bool   `json:"is_active"`
+	ExpiresAt   *string `json:"expires_at"` // RFC3339; "" for a key that never expires
+}
+
+// UpdateAPIKey renames, describes, activates or deactivates a key or changes its expiry
+// PATCH /api/v1/api-keys/:id
+func (h *APIKeyHandler) UpdateAPIKey(c *gin.Context) {
+	var req UpdateAPIKeyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.BadRequest(c, "Invalid request", err)
 		return
 	}
 
-	// This would call User Service to create API key
-	newKey := gin.H{
-		"id":          "3",
-		"name":        req.Name,
-		"description": req.Description,
-		"key":         "qn_abc123def456ghi789jkl",
-		"is_active":   true,
-		"created_at":  "2024-01-15T00:00:00Z",
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key, err := h.userClient.UpdateAPIKey(ctx, &pb.UpdateAPIKeyRequest{
+		Id:          c.Param("id"),
+		UserId:      c.GetString("user_id"),
+		Name:        req.Name,
+		Description: req.Description,
+		IsActive:    req.IsActive,
+		ExpiresAt:   req.ExpiresAt,
+	})
+	if err != nil {
+		apiKeyError(c, "Failed to update API key", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "API key updated", apiKeyJSON(key))
+}
+
+type RotateAPIKeyRequest struct {
+	GracePeriodSeconds int64 `json:"grace_period_seconds"` // 0 for the default
+}
+
+// RotateAPIKey issues a key with the same settings; the old one keeps working for the grace period.
+// The response is the only time the new key is shown.
+// POST /api/v1/api-keys/:id/rotate
+func (h *APIKeyHandler) RotateAPIKey(c *gin.Context) {
+	var req RotateAPIKeyRequest
+	// The body is optional
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(c, "Invalid request", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key, err := h.userClient.RotateAPIKey(ctx, &pb.RotateAPIKeyRequest{
+		Id:                 c.Param("id"),
+		UserId:             c.GetString("user_id"),
+		GracePeriodSeconds: req.GracePeriodSeconds,
+	})
+	if err != nil {
+		apiKeyError(c, "Failed to rotate API key", err)
+		return
 	}
 
-	response.Success(c, http.StatusCreated, "API key created", newKey)
+	response.Success(c, http.StatusCreated, "API key rotated", apiKeyJSON(key))
 }
 
-func DeleteAPIKey(c *gin.Context) {
+// DeleteAPIKey revokes a key at once
+// DELETE /api/v1/api-keys/:id
+func (h *APIKeyHandler) DeleteAPIKey(c *gin.Context) {
 	keyID := c.Param("id")
 
-	// This would call User Service to delete API key
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := h.userClient.DeleteAPIKey(ctx, &pb.DeleteAPIKeyRequest{
+		Id:     keyID,
+		UserId: c.GetString("user_id"),
+	})
+	if err != nil {
+		apiKeyError(c, "Failed to delete API key", err)
+		return
+	}
+
 	response.Success(c, http.StatusOK, "API key deleted", gin.H{
 		"id": keyID,
 	})
 }
+
+// apiKeyJSON renders a key; "key" is only present right after it was issued
+func apiKeyJSON(key *pb.APIKeyResponse) gin.H {
+	data := gin.H{
+		"id":                  key.Id,
+		"name":                key.Name,
+		"description":         key.Description,
+		"key_prefix":          key.KeyPrefix,
+		"is_active":           key.IsActive,
+		"monthly_limit":       key.MonthlyLimit,
+		"requests_per_second": key.RequestsPerSecond,
+		"restrictions": gin.H{
+			"allowed_chains":  key.Restrictions.GetAllowedChains(),
+			"allowed_methods": key.Restrictions.GetAllowedMethods(),
+			"allowed_ips":     key.Restrictions.GetAllowedIps(),
+			"allowed_origins": key.Restrictions.GetAllowedOrigins(),
+		},
+		"expires_at":      nil,
+		"rotated_from_id": nil,
+		"replaced_by_id":  nil,
+		"created_at":      key.CreatedAt,
+	}
+	if key.Key != "" {
+		data["key"] = key.Key
+	}
+	if key.ExpiresAt != "" {
+		data["expires_at"] = key.ExpiresAt
+	}
+	if key.RotatedFromId != "" {
+		data["rotated_from_id"] = key.RotatedFromId
+	}
+	if key.ReplacedById != "" {
+		data["replaced_by_id"] = key.ReplacedById
+	}
+	return data
+}
+
+// apiKeyError replies to a failed User Service call; keys of other users are not found
+func apiKeyError(c *gin.Context, message string, err error) {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		response.BadRequest(c, message, err)
+	case codes.NotFound:
+		response.NotFound(c, "API key not found")
+	case codes.FailedPrecondition:
+		response.Error(c, http.StatusConflict, message, err)
+	default:
+		response.InternalServerError(c, message, err)
+	}
+}
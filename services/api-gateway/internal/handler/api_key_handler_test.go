@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"ironnode/pkg/jsonrpc"
+	pb "ironnode/services/user-service/proto"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+)
+
+// fakeUserClient validates the keys it knows and refuses every other key
+type fakeUserClient struct {
+	pb.UserServiceClient
+	keys map[string]*pb.ValidateAPIKeyResponse
+}
+
+func (c *fakeUserClient) ValidateAPIKey(ctx context.Context, in *pb.ValidateAPIKeyRequest, opts ...grpc.CallOption) (*pb.ValidateAPIKeyResponse, error) {
+	if resp, ok := c.keys[in.Key]; ok {
+		return resp, nil
+	}
+	return &pb.ValidateAPIKeyResponse{Valid: false}, nil
+}
+
+// rotatedKey is the validation of a key replaced by another one, in use until expiresAt
+func rotatedKey(apiKeyID string, expiresAt time.Time) *pb.ValidateAPIKeyResponse {
+	return &pb.ValidateAPIKeyResponse{
+		Valid:        true,
+		UserId:       "user",
+		ApiKeyId:     apiKeyID,
+		Deprecated:   true,
+		ReplacedById: "replacement",
+		ExpiresAt:    expiresAt.Format(time.RFC3339),
+	}
+}
+
+// newKeyRouter routes /rpc/* calls through the API key middleware to a handler echoing the context it set
+func newKeyRouter(h *APIKeyHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/rpc/*path", h.APIKeyMiddleware(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetString("user_id"), "api_key_id": c.GetString("api_key_id")})
+	})
+	return router
+}
+
+// captureLog sends the standard logger to a buffer for the rest of the test
+func captureLog(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+func TestDeprecatedKeyHeadersOnEveryCallLoggedOncePerInterval(t *testing.T) {
+	logs := captureLog(t)
+
+	expiresAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	h := &APIKeyHandler{
+		userClient: &fakeUserClient{keys: map[string]*pb.ValidateAPIKeyResponse{
+			"qn_old":   rotatedKey("qn_old", expiresAt),
+			"qn_other": rotatedKey("qn_other", expiresAt),
+		}},
+		deprecatedSeen: make(map[string]time.Time),
+	}
+	router := newKeyRouter(h)
+
+	call := func(key string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/rpc/"+key+"/ethereum/mainnet", nil))
+		return rec
+	}
+
+	for i := 0; i < 3; i++ {
+		rec := call("qn_old")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("call %d: status %d", i, rec.Code)
+		}
+		if rec.Header().Get("X-API-Key-Deprecated") != "true" || rec.Header().Get("Sunset") != expiresAt.Format(http.TimeFormat) {
+			t.Errorf("call %d: deprecation headers %v", i, rec.Header())
+		}
+	}
+	if n := strings.Count(logs.String(), "Deprecated API key qn_old"); n != 1 {
+		t.Errorf("logged %d times within the interval, want once", n)
+	}
+
+	// Another rotated key is logged on its own
+	call("qn_other")
+	if n := strings.Count(logs.String(), "Deprecated API key qn_other"); n != 1 {
+		t.Errorf("second key logged %d times, want once", n)
+	}
+
+	// Once the interval has passed the key is logged again
+	h.mu.Lock()
+	h.deprecatedSeen["qn_old"] = time.Now().Add(-deprecatedKeyLogInterval)
+	h.mu.Unlock()
+	call("qn_old")
+	if n := strings.Count(logs.String(), "Deprecated API key qn_old"); n != 2 {
+		t.Errorf("logged %d times after the interval, want twice", n)
+	}
+}
+
+func TestLogDeprecatedKeyForgetsQuietKeys(t *testing.T) {
+	h := &APIKeyHandler{deprecatedSeen: make(map[string]time.Time)}
+	now := time.Now()
+
+	h.logDeprecatedKey("quiet", now.Add(-2*deprecatedKeyLogInterval))
+	if !h.logDeprecatedKey("busy", now) {
+		t.Fatal("first call with a key was not logged")
+	}
+
+	if _, ok := h.deprecatedSeen["quiet"]; ok || len(h.deprecatedSeen) != 1 {
+		t.Errorf("tracked keys = %v, want only busy", h.deprecatedSeen)
+	}
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	h := &APIKeyHandler{
+		userClient: &fakeUserClient{keys: map[string]*pb.ValidateAPIKeyResponse{
+			"qn_valid": {Valid: true, UserId: "user-1", ApiKeyId: "key-1"},
+			"qn_mainnet": {
+				Valid: true, UserId: "user-1", ApiKeyId: "key-2",
+				Restrictions: &pb.APIKeyRestrictions{AllowedChains: []string{"ethereum/mainnet"}},
+			},
+		}},
+		deprecatedSeen: make(map[string]time.Time),
+	}
+	router := newKeyRouter(h)
+
+	tests := []struct {
+		name      string
+		path      string
+		header    string
+		status    int
+		wantKeyID string
+	}{
+		{name: "key in path", path: "/rpc/qn_valid/ethereum/mainnet", status: http.StatusOK, wantKeyID: "key-1"},
+		{name: "key in header", path: "/rpc/ethereum/mainnet", header: "qn_valid", status: http.StatusOK, wantKeyID: "key-1"},
+		{name: "path key wins over header", path: "/rpc/qn_valid/ethereum/mainnet", header: "qn_unknown", status: http.StatusOK, wantKeyID: "key-1"},
+		{name: "no key", path: "/rpc/ethereum/mainnet", status: http.StatusUnauthorized},
+		{name: "not an issued key", path: "/rpc/ethereum/mainnet", header: "sk_live_123", status: http.StatusUnauthorized},
+		{name: "unknown, inactive or expired key", path: "/rpc/qn_unknown/ethereum/mainnet", status: http.StatusUnauthorized},
+		{name: "malformed path", path: "/rpc/qn_valid/ethereum/mainnet/extra", status: http.StatusNotFound},
+		{name: "chain outside the key's restrictions", path: "/rpc/qn_mainnet/ethereum/sepolia", status: http.StatusForbidden},
+		{name: "chain within the key's restrictions", path: "/rpc/qn_mainnet/ethereum/mainnet", status: http.StatusOK, wantKeyID: "key-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("X-API-Key", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				var resp jsonrpc.Response
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Error == nil {
+					t.Errorf("refusal is not a JSON-RPC error: %s", rec.Body)
+				}
+				return
+			}
+
+			var set map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &set); err != nil {
+				t.Fatal(err)
+			}
+			if set["user_id"] != "user-1" || set["api_key_id"] != tt.wantKeyID {
+				t.Errorf("context user %q, key %q; want user-1, %s", set["user_id"], set["api_key_id"], tt.wantKeyID)
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package handler
 import (
 	"context"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
@@ -12,8 +11,6 @@ import (
 	pb "ironnode/services/auth-service/proto"
 
 	"github.com/gin-gonic/gin"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 type AuthHandler struct {
@@ -22,24 +19,10 @@ type AuthHandler struct {
 
 func NewAuthHandler(cfg *config.Config) *AuthHandler {
 	// Connect to Auth Service via gRPC
-	// Use AUTH_SERVICE_HOST from environment if available, otherwise localhost
-	authServiceHost := os.Getenv("AUTH_SERVICE_HOST")
-	if authServiceHost == "" {
-		authServiceHost = "localhost"
-	}
-
-	conn, err := grpc.Dial(
-		authServiceHost+":"+cfg.Services.AuthServicePort,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	if err != nil {
-		panic(err)
-	}
-
-	client := pb.NewAuthServiceClient(conn)
+	conn := dialService("AUTH_SERVICE_HOST", cfg.Services.AuthServicePort)
 
 	return &AuthHandler{
-		authClient: client,
+		authClient: pb.NewAuthServiceClient(conn),
 	}
 }
 
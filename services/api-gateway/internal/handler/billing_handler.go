@@ -0,0 +1,435 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ironnode/pkg/config"
+	"ironnode/pkg/response"
+	"ironnode/services/api-gateway/internal/invoice"
+	pb "ironnode/services/billing-service/proto"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxWebhookBody bounds the size of a payment provider webhook delivery
+const maxWebhookBody = 1 << 20
+
+type BillingHandler struct {
+	billingClient pb.BillingServiceClient
+}
+
+func NewBillingHandler(cfg *config.Config) *BillingHandler {
+	// Connect to Billing Service via gRPC
+	conn := dialService("BILLING_SERVICE_HOST", cfg.Services.BillingServicePort)
+
+	return &BillingHandler{
+		billingClient: pb.NewBillingServiceClient(conn),
+	}
+}
+
+// ListInvoices returns the user's invoices, newest first
+// GET /api/v1/billing/invoices?limit=12
+func (h *BillingHandler) ListInvoices(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "12"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.billingClient.ListInvoices(ctx, &pb.ListInvoicesRequest{
+		UserId: c.GetString("user_id"),
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		response.InternalServerError(c, "Failed to list invoices", err)
+		return
+	}
+
+	invoices := make([]gin.H, 0, len(resp.Invoices))
+	for _, inv := range resp.Invoices {
+		invoices = append(invoices, invoiceJSON(inv))
+	}
+
+	response.Success(c, http.StatusOK, "Invoices retrieved successfully", invoices)
+}
+
+// GetInvoice renders one invoice as JSON (default), CSV or PDF
+// GET /api/v1/billing/invoices/:id?format=json|csv|pdf
+func (h *BillingHandler) GetInvoice(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	inv, err := h.billingClient.GetInvoice(ctx, &pb.GetInvoiceRequest{
+		UserId:    c.GetString("user_id"),
+		InvoiceId: c.Param("id"),
+	})
+	if err != nil {
+		switch status.Code(err) {
+		case codes.NotFound, codes.InvalidArgument:
+			response.NotFound(c, "Invoice not found")
+		default:
+			response.InternalServerError(c, "Failed to get invoice", err)
+		}
+		return
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		data := invoiceJSON(inv)
+		lineItems := make([]gin.H, 0, len(inv.LineItems))
+		for _, item := range inv.LineItems {
+			lineItems = append(lineItems, gin.H{
+				"kind":        item.Kind,
+				"description": item.Description,
+				"blockchain":  item.Blockchain,
+				"quantity":    item.Quantity,
+				"unit_price":  item.UnitPrice,
+				"amount":      item.Amount,
+			})
+		}
+		data["line_items"] = lineItems
+		response.Success(c, http.StatusOK, "Invoice retrieved successfully", data)
+
+	case "csv":
+		data, err := invoice.CSV(inv)
+		if err != nil {
+			response.InternalServerError(c, "Failed to render invoice", err)
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, inv.Number))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+
+	case "pdf":
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, inv.Number))
+		c.Data(http.StatusOK, "application/pdf", invoice.PDF(inv))
+
+	default:
+		response.BadRequest(c, "Unsupported format, expected json, csv or pdf", nil)
+	}
+}
+
+type AttachPaymentMethodRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// AttachPaymentMethod sets the card that open and future invoices are charged to
+// POST /api/v1/billing/payment-method
+func (h *BillingHandler) AttachPaymentMethod(c *gin.Context) {
+	var req AttachPaymentMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resp, err := h.billingClient.AttachPaymentMethod(ctx, &pb.AttachPaymentMethodRequest{
+		UserId: c.GetString("user_id"),
+		Token:  req.Token,
+	})
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			response.BadRequest(c, "Invalid payment method", err)
+			return
+		}
+		response.InternalServerError(c, "Failed to attach payment method", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Payment method attached successfully", gin.H{
+		"provider":   resp.Provider,
+		"card_brand": resp.CardBrand,
+		"card_last4": resp.CardLast4,
+	})
+}
+
+// Webhook receives payment provider events. The body is forwarded unparsed
+// because the signature covers the exact bytes that were sent.
+// POST /api/v1/billing/webhooks/:provider with X-Webhook-Signature header
+func (h *BillingHandler) Webhook(c *gin.Context) {
+	signature := c.GetHeader("X-Webhook-Signature")
+	if signature == "" {
+		response.Unauthorized(c, "Missing webhook signature")
+		return
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody+1))
+	if err != nil {
+		response.BadRequest(c, "Failed to read request body", err)
+		return
+	}
+	if len(payload) > maxWebhookBody {
+		response.Error(c, http.StatusRequestEntityTooLarge, "Webhook payload too large", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err = h.billingClient.HandleWebhook(ctx, &pb.HandleWebhookRequest{
+		Provider:  c.Param("provider"),
+		Payload:   payload,
+		Signature: signature,
+	})
+	if err != nil {
+		switch status.Code(err) {
+		case codes.Unauthenticated:
+			response.Unauthorized(c, "Invalid webhook signature")
+		case codes.NotFound:
+			response.NotFound(c, "Unknown payment provider")
+		case codes.InvalidArgument:
+			response.BadRequest(c, "Invalid webhook payload", err)
+		default:
+			// A 5xx makes the provider redeliver later
+			response.InternalServerError(c, "Failed to handle webhook", err)
+		}
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Webhook received", nil)
+}
+
+// GetDepositAddress returns the user's USDT TRC20 address for prepaid top-ups
+// GET /api/v1/billing/crypto/deposit-address
+func (h *BillingHandler) GetDepositAddress(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := h.billingClient.GetDepositAddress(ctx, &pb.GetDepositAddressRequest{
+		UserId: c.GetString("user_id"),
+	})
+	if err != nil {
+		cryptoError(c, "Failed to get deposit address", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Deposit address retrieved successfully", gin.H{
+		"address":       resp.Address,
+		"network":       resp.Network,
+		"token":         resp.Token,
+		"confirmations": resp.Confirmations,
+	})
+}
+
+// ListDeposits returns the user's USDT deposits, newest first
+// GET /api/v1/billing/crypto/deposits?limit=20
+func (h *BillingHandler) ListDeposits(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.billingClient.ListDeposits(ctx, &pb.ListDepositsRequest{
+		UserId: c.GetString("user_id"),
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		cryptoError(c, "Failed to list deposits", err)
+		return
+	}
+
+	deposits := make([]gin.H, 0, len(resp.Deposits))
+	for _, deposit := range resp.Deposits {
+		item := gin.H{
+			"id":             deposit.Id,
+			"address":        deposit.Address,
+			"tx_id":          deposit.TxId,
+			"amount":         deposit.Amount,
+			"status":         deposit.Status,
+			"detected_block": deposit.DetectedBlock,
+			"created_at":     time.Unix(deposit.CreatedAt, 0).UTC(),
+			"confirmed_at":   nil,
+		}
+		if deposit.ConfirmedAt != 0 {
+			item["confirmed_at"] = time.Unix(deposit.ConfirmedAt, 0).UTC()
+		}
+		deposits = append(deposits, item)
+	}
+
+	response.Success(c, http.StatusOK, "Deposits retrieved successfully", deposits)
+}
+
+// GetPrepaidBalance returns the balance open invoices are paid from first
+// GET /api/v1/billing/balance
+func (h *BillingHandler) GetPrepaidBalance(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.billingClient.GetPrepaidBalance(ctx, &pb.GetPrepaidBalanceRequest{
+		UserId: c.GetString("user_id"),
+	})
+	if err != nil {
+		cryptoError(c, "Failed to get balance", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Balance retrieved successfully", gin.H{
+		"balance":  resp.Balance,
+		"currency": resp.Currency,
+	})
+}
+
+// GetCredits returns the user's prepaid credits, spent once the plan allowance is used up
+// GET /api/v1/billing/credits?api_key_id=
+func (h *BillingHandler) GetCredits(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.billingClient.GetBalance(ctx, &pb.GetBalanceRequest{
+		UserId:   c.GetString("user_id"),
+		ApiKeyId: c.Query("api_key_id"),
+	})
+	if err != nil {
+		ledgerError(c, "Failed to get credits", err)
+		return
+	}
+
+	keys := make([]gin.H, 0, len(resp.Keys))
+	for _, key := range resp.Keys {
+		keys = append(keys, gin.H{
+			"api_key_id": key.ApiKeyId,
+			"balance":    key.Balance,
+		})
+	}
+
+	response.Success(c, http.StatusOK, "Credits retrieved successfully", gin.H{
+		"balance":   resp.Balance,
+		"keys":      keys,
+		"total":     resp.Total,
+		"available": resp.Available,
+	})
+}
+
+// ListLedgerEntries returns the postings to the user's credit accounts, newest first
+// GET /api/v1/billing/credits/entries?limit=20&api_key_id=
+func (h *BillingHandler) ListLedgerEntries(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.billingClient.ListLedgerEntries(ctx, &pb.ListLedgerEntriesRequest{
+		UserId:   c.GetString("user_id"),
+		ApiKeyId: c.Query("api_key_id"),
+		Limit:    int32(limit),
+	})
+	if err != nil {
+		ledgerError(c, "Failed to list ledger entries", err)
+		return
+	}
+
+	entries := make([]gin.H, 0, len(resp.Entries))
+	for _, entry := range resp.Entries {
+		entries = append(entries, gin.H{
+			"id":             entry.Id,
+			"transaction_id": entry.TransactionId,
+			"type":           entry.Type,
+			"api_key_id":     entry.ApiKeyId,
+			"amount":         entry.Amount,
+			"balance_after":  entry.BalanceAfter,
+			"description":    entry.Description,
+			"created_at":     time.Unix(entry.CreatedAt, 0).UTC(),
+		})
+	}
+
+	response.Success(c, http.StatusOK, "Ledger entries retrieved successfully", entries)
+}
+
+type TopUpCreditsRequest struct {
+	Credits  int64  `json:"credits" binding:"required,gt=0"`
+	APIKeyID string `json:"api_key_id"` // optional; earmarks the credits for one key
+}
+
+// TopUpCredits buys credits with the prepaid balance. The Idempotency-Key header
+// is required; repeating a request with the same key returns the first top-up.
+// POST /api/v1/billing/credits/top-up
+func (h *BillingHandler) TopUpCredits(c *gin.Context) {
+	idempotencyKey := c.GetHeader("Idempotency-Key")
+	if idempotencyKey == "" {
+		response.BadRequest(c, "Idempotency-Key header is required", nil)
+		return
+	}
+
+	var req TopUpCreditsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.billingClient.TopUpCredits(ctx, &pb.CreditRequest{
+		UserId:         c.GetString("user_id"),
+		ApiKeyId:       req.APIKeyID,
+		Credits:        req.Credits,
+		IdempotencyKey: idempotencyKey,
+	})
+	if err != nil {
+		ledgerError(c, "Failed to top up credits", err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Credits topped up successfully", gin.H{
+		"id":          resp.Id,
+		"type":        resp.Type,
+		"api_key_id":  resp.ApiKeyId,
+		"credits":     resp.Amount,
+		"cost":        resp.Cost,
+		"description": resp.Description,
+		"created_at":  time.Unix(resp.CreatedAt, 0).UTC(),
+	})
+}
+
+func ledgerError(c *gin.Context, message string, err error) {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		response.BadRequest(c, message, err)
+	case codes.AlreadyExists:
+		response.Error(c, http.StatusConflict, "Idempotency-Key was used for a different request", err)
+	case codes.FailedPrecondition:
+		response.Error(c, http.StatusPaymentRequired, "Prepaid balance is too low", err)
+	default:
+		response.InternalServerError(c, message, err)
+	}
+}
+
+func cryptoError(c *gin.Context, message string, err error) {
+	if status.Code(err) == codes.Unimplemented {
+		response.Error(c, http.StatusServiceUnavailable, "Crypto payments are disabled", nil)
+		return
+	}
+	response.InternalServerError(c, message, err)
+}
+
+func invoiceJSON(inv *pb.Invoice) gin.H {
+	data := gin.H{
+		"id":               inv.Id,
+		"number":           inv.Number,
+		"plan_type":        inv.PlanType,
+		"period_start":     time.Unix(inv.PeriodStart, 0).UTC(),
+		"period_end":       time.Unix(inv.PeriodEnd, 0).UTC(),
+		"currency":         inv.Currency,
+		"total":            inv.Total,
+		"status":           inv.Status,
+		"issued_at":        time.Unix(inv.IssuedAt, 0).UTC(),
+		"due_at":           time.Unix(inv.DueAt, 0).UTC(),
+		"paid_at":          nil,
+		"payment_attempts": inv.PaymentAttempts,
+		"next_payment_at":  nil,
+	}
+	if inv.PaidAt != 0 {
+		data["paid_at"] = time.Unix(inv.PaidAt, 0).UTC()
+	}
+	if inv.NextPaymentAt != 0 {
+		data["next_payment_at"] = time.Unix(inv.NextPaymentAt, 0).UTC()
+	}
+	return data
+}
@@ -1,42 +1,52 @@
 package handler
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"ironnode/pkg/config"
 	"ironnode/pkg/response"
+	pb "ironnode/services/blockchain-service/proto"
 
 	"github.com/gin-gonic/gin"
 )
 
 type BlockchainHandler struct {
-	config *config.Config
+	config           *config.Config
+	blockchainClient pb.BlockchainServiceClient
 }
 
 func NewBlockchainHandler(cfg *config.Config) *BlockchainHandler {
+	// Connect to Blockchain Service via gRPC
+	conn := dialService("BLOCKCHAIN_SERVICE_HOST", cfg.Services.BlockchainPort)
+
 	return &BlockchainHandler{
-		config: cfg,
+		config:           cfg,
+		blockchainClient: pb.NewBlockchainServiceClient(conn),
 	}
 }
 
 func (h *BlockchainHandler) ListNodes(c *gin.Context) {
-	// This would typically call the Blockchain Service via gRPC
-	// For now, returning mock data
-	nodes := []gin.H{
-		{
-			"id":       "1",
-			"name":     "Ethereum Mainnet",
-			"type":     "ethereum",
-			"network":  "mainnet",
-			"is_active": true,
-		},
-		{
-			"id":       "2",
-			"name":     "Polygon Mainnet",
-			"type":     "polygon",
-			"network":  "mainnet",
-			"is_active": true,
-		},
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.blockchainClient.ListNodes(ctx, &pb.ListNodesRequest{})
+	if err != nil {
+		response.InternalServerError(c, "Failed to list nodes", err)
+		return
+	}
+
+	nodes := make([]gin.H, 0, len(resp.Nodes))
+	for _, node := range resp.Nodes {
+		nodes = append(nodes, gin.H{
+			"id":        node.Id,
+			"name":      node.Name,
+			"type":      node.Type,
+			"network":   node.Network,
+			"is_active": node.IsActive,
+			"priority":  node.Priority,
+		})
 	}
 
 	response.Success(c, http.StatusOK, "Nodes retrieved successfully", nodes)
@@ -45,14 +55,23 @@ func (h *BlockchainHandler) ListNodes(c *gin.Context) {
 func (h *BlockchainHandler) GetNode(c *gin.Context) {
 	nodeID := c.Param("id")
 
-	// This would typically call the Blockchain Service via gRPC
-	node := gin.H{
-		"id":       nodeID,
-		"name":     "Ethereum Mainnet",
-		"type":     "ethereum",
-		"network":  "mainnet",
-		"is_active": true,
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	node, err := h.blockchainClient.GetNode(ctx, &pb.GetNodeRequest{
+		Id: nodeID,
+	})
+	if err != nil {
+		response.NotFound(c, "Node not found")
+		return
 	}
 
-	response.Success(c, http.StatusOK, "Node retrieved successfully", node)
+	response.Success(c, http.StatusOK, "Node retrieved successfully", gin.H{
+		"id":        node.Id,
+		"name":      node.Name,
+		"type":      node.Type,
+		"network":   node.Network,
+		"is_active": node.IsActive,
+		"priority":  node.Priority,
+	})
 }
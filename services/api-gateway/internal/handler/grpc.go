@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// dialService connects to a backend gRPC service.
+// The host is taken from hostEnv (e.g. AUTH_SERVICE_HOST), otherwise localhost.
+func dialService(hostEnv, port string) *grpc.ClientConn {
+	host := os.Getenv(hostEnv)
+	if host == "" {
+		host = "localhost"
+	}
+
+	conn, err := grpc.Dial(
+		host+":"+port,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return conn
+}
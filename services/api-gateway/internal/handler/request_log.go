@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"ironnode/pkg/async"
+	"ironnode/pkg/config"
+	"ironnode/pkg/models"
+	analyticspb "ironnode/services/analytics-service/proto"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// newRequestLogger queues proxied calls and sends them to Analytics Service in the background.
+// It returns nil if request logging is disabled.
+func newRequestLogger(cfg *config.Config) *async.AsyncLogger {
+	if !cfg.RequestLog.Enabled {
+		return nil
+	}
+
+	// Connect to Analytics Service via gRPC
+	conn := dialService("ANALYTICS_SERVICE_HOST", cfg.Services.AnalyticsPort)
+	analyticsClient := analyticspb.NewAnalyticsServiceClient(conn)
+
+	return async.NewAsyncLoggerWithWriter(func(entry *models.RequestLog) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_, err := analyticsClient.LogRequest(ctx, &analyticspb.LogRequestRequest{
+			UserId:       entry.UserID.String(),
+			ApiKeyId:     entry.APIKeyID.String(),
+			Blockchain:   entry.Blockchain,
+			Method:       entry.Method,
+			Endpoint:     entry.Endpoint,
+			StatusCode:   int32(entry.StatusCode),
+			ResponseTime: entry.ResponseTime,
+			RequestSize:  entry.RequestSize,
+			ResponseSize: entry.ResponseSize,
+			IpAddress:    entry.IPAddress,
+			UserAgent:    entry.UserAgent,
+			Error:        entry.Error,
+			CacheHit:     entry.CacheHit,
+			Coalesced:    entry.Coalesced,
+			Credits:      entry.Credits,
+		})
+		return err
+	}, cfg.RequestLog.BufferSize, cfg.RequestLog.Workers)
+}
+
+// RequestLogMiddleware records every JSON-RPC call made over HTTP, including the
+// ones rejected by rate limits and quotas. WebSocket connections are skipped here;
+// their sessions record each message instead.
+func (h *RPCHandler) RequestLogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.requestLog == nil || websocket.IsWebSocketUpgrade(c.Request) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		_, blockchain, network, _ := splitRPCPath(c)
+		h.logRequest(c.GetString("user_id"), c.GetString("api_key_id"), &models.RequestLog{
+			Blockchain:   blockchain,
+			Method:       c.GetString("rpc_method"),
+			Endpoint:     rpcEndpoint(blockchain, network),
+			StatusCode:   c.Writer.Status(),
+			ResponseTime: time.Since(start).Milliseconds(),
+			RequestSize:  max(c.Request.ContentLength, 0),
+			ResponseSize: int64(max(c.Writer.Size(), 0)),
+			IPAddress:    c.ClientIP(),
+			UserAgent:    c.Request.UserAgent(),
+			CacheHit:     c.GetBool("cache_hit"),
+			Coalesced:    c.GetBool("coalesced"),
+			Credits:      c.GetInt64("credits"),
+		})
+	}
+}
+
+// logRequest queues a call made with the user's API key.
+// Calls that never got past API key authentication are not recorded.
+func (h *RPCHandler) logRequest(userID, apiKeyID string, entry *models.RequestLog) {
+	if h.requestLog == nil {
+		return
+	}
+
+	var err error
+	if entry.UserID, err = uuid.Parse(userID); err != nil {
+		return
+	}
+	if entry.APIKeyID, err = uuid.Parse(apiKeyID); err != nil {
+		return
+	}
+	if entry.StatusCode >= http.StatusBadRequest && entry.Error == "" {
+		entry.Error = http.StatusText(entry.StatusCode)
+	}
+
+	h.requestLog.Log(entry)
+}
+
+// rpcEndpoint is the path a call was made to, without the API key some clients put in it
+func rpcEndpoint(blockchain, network string) string {
+	if blockchain == "" {
+		return "/rpc"
+	}
+	return "/rpc/" + blockchain + "/" + network
+}
@@ -0,0 +1,462 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ironnode/pkg/async"
+	"ironnode/pkg/cache"
+	"ironnode/pkg/config"
+	"ironnode/pkg/jsonrpc"
+	"ironnode/pkg/models"
+	"ironnode/services/api-gateway/internal/rpc/policy"
+	"ironnode/services/api-gateway/internal/rpc/service"
+	billingpb "ironnode/services/billing-service/proto"
+	pb "ironnode/services/blockchain-service/proto"
+	userpb "ironnode/services/user-service/proto"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxRPCBodySize limits the size of a JSON-RPC request body (5 MB)
+	maxRPCBodySize = 5 << 20
+	// maxBatchSize limits the number of calls in a single JSON-RPC batch
+	maxBatchSize = 1000
+	// batchMethod is the method request logs show for a JSON-RPC batch
+	batchMethod = "batch"
+)
+
+type RPCHandler struct {
+	rpcService    service.RPCService
+	subscriptions service.SubscriptionManager
+	usage         service.UsageService
+	userClient    userpb.UserServiceClient // re-validates API keys of open WebSocket connections
+	policy        *policy.MethodPolicy
+	enforceQuota  bool
+	requestLog    *async.AsyncLogger // nil when request logging is disabled
+}
+
+func NewRPCHandler(cfg *config.Config) *RPCHandler {
+	// Connect to Blockchain Service via gRPC
+	conn := dialService("BLOCKCHAIN_SERVICE_HOST", cfg.Services.BlockchainPort)
+
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	breaker := async.NewCircuitBreaker(async.CircuitBreakerConfig{
+		WindowSize:         cfg.CircuitBreaker.WindowSize,
+		MinRequests:        cfg.CircuitBreaker.MinRequests,
+		ErrorRateThreshold: cfg.CircuitBreaker.ErrorRateThreshold,
+		LatencyThreshold:   cfg.CircuitBreaker.LatencyThreshold,
+		Cooldown:           cfg.CircuitBreaker.Cooldown,
+		HalfOpenRequests:   cfg.CircuitBreaker.HalfOpenRequests,
+	})
+	breaker.OnStateChange(func(change async.StateChange) {
+		log.Printf("[CircuitBreaker] Node %s: %s -> %s (error rate %.0f%%)",
+			change.NodeURL, change.From, change.To, change.ErrorRate*100)
+	})
+
+	blockchainClient := pb.NewBlockchainServiceClient(conn)
+
+	// Connect to Billing Service for plans and usage accounting
+	billingConn := dialService("BILLING_SERVICE_HOST", cfg.Services.BillingServicePort)
+
+	// Connect to User Service to re-check API keys of long-lived connections
+	userConn := dialService("USER_SERVICE_HOST", cfg.Services.UserServicePort)
+
+	redisClient := connectRedis(cfg)
+
+	var rpcCache *cache.RPCCache
+	if redisClient != nil && cfg.RPCCache.Enabled {
+		rpcCache = cache.NewRPCCache(redisClient, cfg.RPCCache.FinalityDepth)
+	}
+
+	var quotaCounter *cache.QuotaCounter
+	if redisClient != nil && cfg.Quota.Enabled {
+		quotaCounter = cache.NewQuotaCounter(redisClient)
+	}
+
+	usage := service.NewUsageService(billingpb.NewBillingServiceClient(billingConn), quotaCounter, cfg.Quota.FlushInterval)
+	usage.Start()
+
+	return &RPCHandler{
+		rpcService: service.NewRPCService(blockchainClient, httpClient, breaker, async.HedgeConfig{
+			Percentile:   cfg.Hedging.Percentile,
+			MinSamples:   cfg.Hedging.MinSamples,
+			DefaultDelay: cfg.Hedging.DefaultDelay,
+			MinDelay:     cfg.Hedging.MinDelay,
+		}, rpcCache),
+		subscriptions: service.NewSubscriptionManager(blockchainClient),
+		usage:         usage,
+		userClient:    userpb.NewUserServiceClient(userConn),
+		policy:        policy.NewMethodPolicy(),
+		enforceQuota:  cfg.Quota.Enabled,
+		requestLog:    newRequestLogger(cfg),
+	}
+}
+
+// Close flushes outstanding usage to Billing Service and queued request logs to Analytics Service
+func (h *RPCHandler) Close() {
+	h.usage.Stop()
+	if h.requestLog != nil {
+		h.requestLog.Shutdown(10 * time.Second)
+	}
+}
+
+// connectRedis connects the response cache and quota counters.
+// The proxy runs uncached, with usage written straight to Billing Service, if Redis is unavailable.
+func connectRedis(cfg *config.Config) *cache.RedisClient {
+	if !cfg.RPCCache.Enabled && !cfg.Quota.Enabled {
+		return nil
+	}
+
+	redisClient, err := cache.NewRedisClient(cfg.Redis.Address(), cfg.Redis.Password, cfg.Redis.DB)
+	if err != nil {
+		log.Printf("[RPCHandler] Redis unavailable, cache and quota counters disabled: %v", err)
+		return nil
+	}
+
+	return redisClient
+}
+
+// QuotaMiddleware rejects calls over the API key's own limits, and calls once the
+// user's monthly credits and the prepaid credits available to the API key are used up.
+// Every response carries the allowance as it stood before the call. The key's limits
+// are set by its owner and apply even when plan quotas are not enforced.
+func (h *RPCHandler) QuotaMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keyQuota, err := h.usage.KeyQuota(c.Request.Context(), c.GetString("user_id"), c.GetString("api_key_id"), keyLimits(c))
+		if err != nil {
+			log.Printf("[Quota] Key limit check failed: %v", err)
+		}
+		if keyQuota.MonthlyCredits > 0 {
+			c.Header("X-Key-Quota-Limit", strconv.FormatInt(keyQuota.MonthlyCredits, 10))
+			c.Header("X-Key-Quota-Remaining", strconv.FormatInt(keyQuota.Remaining(), 10))
+		}
+		if message, exceeded := keyLimitExceeded(keyQuota); exceeded {
+			c.Header("Retry-After", strconv.FormatInt(keyRetryAfter(keyQuota), 10))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, jsonrpc.NewErrorResponse(nil, jsonrpc.LimitExceeded, message))
+			return
+		}
+
+		if !h.enforceQuota {
+			c.Next()
+			return
+		}
+
+		quota, err := h.usage.Quota(c.Request.Context(), c.GetString("user_id"), c.GetString("api_key_id"))
+		if err != nil {
+			// Fail open: a Redis outage should not take the proxy down
+			log.Printf("[Quota] Check failed: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-Quota-Limit", strconv.FormatInt(quota.Limit, 10))
+		c.Header("X-Quota-Remaining", strconv.FormatInt(quota.Remaining(), 10))
+		c.Header("X-Quota-Reset", strconv.FormatInt(quota.ResetAt.Unix(), 10))
+
+		if quota.Exceeded() {
+			c.Header("Retry-After", strconv.FormatInt(int64(time.Until(quota.ResetAt).Seconds())+1, 10))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, jsonrpc.NewErrorResponse(nil, jsonrpc.LimitExceeded, quotaExceededMessage(quota)))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// quotaExceeded reports whether a user has no credits left; used for calls on an open WebSocket
+func (h *RPCHandler) quotaExceeded(ctx context.Context, userID, apiKeyID string) (*service.Quota, bool) {
+	if !h.enforceQuota {
+		return nil, false
+	}
+
+	quota, err := h.usage.Quota(ctx, userID, apiKeyID)
+	if err != nil {
+		return nil, false
+	}
+
+	return quota, quota.Exceeded()
+}
+
+// keyLimits returns the limits of the API key the call was authenticated with
+func keyLimits(c *gin.Context) service.KeyLimits {
+	return service.KeyLimits{
+		MonthlyCredits:    c.GetInt64("api_key_monthly_limit"),
+		RequestsPerSecond: c.GetInt64("api_key_requests_per_second"),
+	}
+}
+
+// keyRestrictions returns the restrictions of the API key the call was authenticated with
+func keyRestrictions(c *gin.Context) *models.APIKeyRestrictions {
+	if restrictions, ok := c.Get("api_key_restrictions"); ok {
+		return restrictions.(*models.APIKeyRestrictions)
+	}
+	return &models.APIKeyRestrictions{}
+}
+
+func keyMethodDeniedMessage(method string) string {
+	return fmt.Sprintf("Method %s is not allowed for this API key", method)
+}
+
+// keyLimitExceeded counts a call made on an open WebSocket against the API key's own limits
+func (h *RPCHandler) keyLimitExceeded(ctx context.Context, userID, apiKeyID string, limits service.KeyLimits) (string, bool) {
+	quota, err := h.usage.KeyQuota(ctx, userID, apiKeyID, limits)
+	if err != nil {
+		return "", false
+	}
+
+	return keyLimitExceeded(quota)
+}
+
+// keyLimitExceeded reports whether a call goes over an API key limit, and which
+func keyLimitExceeded(quota *service.KeyQuota) (string, bool) {
+	switch {
+	case quota.RateLimited():
+		return fmt.Sprintf("API key rate limit of %d requests per second exceeded", quota.RequestsPerSecond), true
+	case quota.Exceeded():
+		return fmt.Sprintf("API key monthly limit of %d credits exceeded, resets at %s", quota.MonthlyCredits, quota.ResetAt.UTC().Format(time.RFC3339)), true
+	default:
+		return "", false
+	}
+}
+
+// keyRetryAfter is how many seconds to wait before the API key may be used again
+func keyRetryAfter(quota *service.KeyQuota) int64 {
+	if quota.RateLimited() {
+		return 1
+	}
+	return int64(time.Until(quota.ResetAt).Seconds()) + 1
+}
+
+func quotaExceededMessage(quota *service.Quota) string {
+	return fmt.Sprintf("Monthly quota of %d credits exceeded, resets at %s", quota.Limit, quota.ResetAt.UTC().Format(time.RFC3339))
+}
+
+// credits returns the credit cost of a call; eth_getLogs ranges running up to a
+// block tag are priced against the chain's latest known block
+func (h *RPCHandler) credits(ctx context.Context, blockchain, network string, req *jsonrpc.Request) int64 {
+	var head uint64
+	if req.Method == "eth_getLogs" {
+		head, _ = h.rpcService.Head(ctx, blockchain, network)
+	}
+	return h.policy.Credits(req.Method, req.Params, head)
+}
+
+// isUnavailable reports whether no node could take the call at all
+func isUnavailable(err error) bool {
+	return errors.Is(err, service.ErrNoNodes) || errors.Is(err, async.ErrCircuitOpen)
+}
+
+// checkBatch applies the plan's method policy and the API key's method restrictions
+// to batch items. Allowed items are returned for forwarding along with their
+// positions; denied items get their error response in place. Items that are not
+// valid requests are passed through so the upstream path reports them.
+func (h *RPCHandler) checkBatch(ctx context.Context, blockchain, network string, plan models.PlanType, restrictions *models.APIKeyRestrictions, items []json.RawMessage) (allowed []json.RawMessage, positions []int, responses []*jsonrpc.Response, credits int64) {
+	responses = make([]*jsonrpc.Response, len(items))
+
+	for i, item := range items {
+		req, err := jsonrpc.ParseRequest(item)
+		if err == nil {
+			if !h.policy.Allowed(plan, req.Method) {
+				responses[i] = jsonrpc.NewErrorResponse(req.ID, jsonrpc.MethodNotFound, policy.DeniedMessage(plan, req.Method))
+				continue
+			}
+			if !restrictions.AllowsMethod(req.Method) {
+				responses[i] = jsonrpc.NewErrorResponse(req.ID, jsonrpc.AccessDenied, keyMethodDeniedMessage(req.Method))
+				continue
+			}
+			credits += h.credits(ctx, blockchain, network, req)
+		}
+
+		allowed = append(allowed, item)
+		positions = append(positions, i)
+	}
+
+	return allowed, positions, responses, credits
+}
+
+// forwardBatch forwards the allowed batch items and merges their replies into responses
+func (h *RPCHandler) forwardBatch(ctx context.Context, blockchain, network string, allowed []json.RawMessage, positions []int, responses []*jsonrpc.Response) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	forwarded, err := h.rpcService.ForwardBatch(ctx, blockchain, network, allowed)
+	if err != nil {
+		return err
+	}
+
+	for j, resp := range forwarded {
+		responses[positions[j]] = resp
+	}
+
+	return nil
+}
+
+// batchReplies drops the responses to notifications, which JSON-RPC never answers
+func batchReplies(items []json.RawMessage, responses []*jsonrpc.Response) []*jsonrpc.Response {
+	replies := make([]*jsonrpc.Response, 0, len(responses))
+	for i, resp := range responses {
+		if !jsonrpc.IsNotification(items[i]) {
+			replies = append(replies, resp)
+		}
+	}
+	return replies
+}
+
+// splitRPCPath parses the /rpc/*path wildcard, which is either
+// <blockchain>/<network> or <key>/<blockchain>/<network>.
+func splitRPCPath(c *gin.Context) (key, blockchain, network string, ok bool) {
+	segments := strings.Split(strings.Trim(c.Param("path"), "/"), "/")
+
+	switch len(segments) {
+	case 2:
+		return "", segments[0], segments[1], true
+	case 3:
+		return segments[0], segments[1], segments[2], true
+	default:
+		return "", "", "", false
+	}
+}
+
+// Proxy forwards a JSON-RPC call to an upstream node and returns its reply
+// POST /rpc/:blockchain/:network
+// POST /rpc/:key/:blockchain/:network
+func (h *RPCHandler) Proxy(c *gin.Context) {
+	_, blockchain, network, ok := splitRPCPath(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, jsonrpc.NewErrorResponse(nil, jsonrpc.InvalidRequest, "Expected /rpc/<blockchain>/<network>"))
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxRPCBodySize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, jsonrpc.NewErrorResponse(nil, jsonrpc.ParseError, "Failed to read request body"))
+		return
+	}
+	if len(body) > maxRPCBodySize {
+		c.JSON(http.StatusRequestEntityTooLarge, jsonrpc.NewErrorResponse(nil, jsonrpc.InvalidRequest, "Request body too large"))
+		return
+	}
+
+	if jsonrpc.IsBatch(body) {
+		h.proxyBatch(c, blockchain, network, body)
+		return
+	}
+
+	req, err := jsonrpc.ParseRequest(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, jsonrpc.NewErrorResponse(nil, jsonrpc.InvalidRequest, "Invalid JSON-RPC request"))
+		return
+	}
+	c.Set("rpc_method", req.Method)
+
+	userID := c.GetString("user_id")
+	plan := h.usage.Plan(c.Request.Context(), userID)
+	if !h.policy.Allowed(plan, req.Method) {
+		c.JSON(http.StatusForbidden, jsonrpc.NewErrorResponse(req.ID, jsonrpc.MethodNotFound, policy.DeniedMessage(plan, req.Method)))
+		return
+	}
+	if !keyRestrictions(c).AllowsMethod(req.Method) {
+		c.JSON(http.StatusForbidden, jsonrpc.NewErrorResponse(req.ID, jsonrpc.AccessDenied, keyMethodDeniedMessage(req.Method)))
+		return
+	}
+
+	credits := h.credits(c.Request.Context(), blockchain, network, req)
+	c.Set("credits", credits)
+
+	result, err := h.rpcService.Forward(c.Request.Context(), blockchain, network, body)
+	if err != nil {
+		// Pass the node's error reply through unchanged
+		var reply *async.ReplyError
+		if errors.As(err, &reply) {
+			contentType := reply.ContentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			c.Data(reply.StatusCode, contentType, reply.Body)
+			return
+		}
+		if isUnavailable(err) {
+			c.JSON(http.StatusServiceUnavailable, jsonrpc.NewErrorResponse(req.ID, jsonrpc.ServerError, err.Error()))
+			return
+		}
+		c.JSON(http.StatusBadGateway, jsonrpc.NewErrorResponse(req.ID, jsonrpc.InternalError, "Upstream node request failed"))
+		return
+	}
+
+	// Picked up by request logging
+	c.Set("cache_hit", result.CacheHit)
+	c.Set("coalesced", result.Coalesced)
+	if result.CacheHit {
+		c.Header("X-Cache", "HIT")
+	} else {
+		c.Header("X-Cache", "MISS")
+	}
+
+	h.usage.RecordUsage(userID, c.GetString("api_key_id"), blockchain, credits)
+	c.Data(http.StatusOK, "application/json", result.Data)
+}
+
+// Stats returns request coalescing and request log statistics
+// GET /metrics/rpc
+func (h *RPCHandler) Stats(c *gin.Context) {
+	stats := gin.H{
+		"coalescing": h.rpcService.Stats(),
+	}
+	if h.requestLog != nil {
+		stats["request_log"] = h.requestLog.Stats()
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// proxyBatch handles a JSON-RPC batch; item failures are reported per item
+func (h *RPCHandler) proxyBatch(c *gin.Context, blockchain, network string, body []byte) {
+	items, err := jsonrpc.ParseBatch(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, jsonrpc.NewErrorResponse(nil, jsonrpc.InvalidRequest, "Invalid JSON-RPC batch"))
+		return
+	}
+	c.Set("rpc_method", batchMethod)
+
+	if len(items) > maxBatchSize {
+		c.JSON(http.StatusBadRequest, jsonrpc.NewErrorResponse(nil, jsonrpc.InvalidRequest, fmt.Sprintf("Batch exceeds %d calls", maxBatchSize)))
+		return
+	}
+
+	userID := c.GetString("user_id")
+	plan := h.usage.Plan(c.Request.Context(), userID)
+	allowed, positions, responses, credits := h.checkBatch(c.Request.Context(), blockchain, network, plan, keyRestrictions(c), items)
+	c.Set("credits", credits)
+
+	if err := h.forwardBatch(c.Request.Context(), blockchain, network, allowed, positions, responses); err != nil {
+		if isUnavailable(err) {
+			c.JSON(http.StatusServiceUnavailable, jsonrpc.NewErrorResponse(nil, jsonrpc.ServerError, err.Error()))
+			return
+		}
+		c.JSON(http.StatusBadGateway, jsonrpc.NewErrorResponse(nil, jsonrpc.InternalError, "Upstream node request failed"))
+		return
+	}
+
+	h.usage.RecordUsage(userID, c.GetString("api_key_id"), blockchain, credits)
+
+	replies := batchReplies(items, responses)
+	if len(replies) == 0 {
+		// A batch of notifications gets no reply at all
+		c.Status(http.StatusNoContent)
+		return
+	}
+	c.JSON(http.StatusOK, replies)
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"ironnode/pkg/jsonrpc"
+	"ironnode/services/api-gateway/internal/rpc/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeUsageService reports a fixed quota for every user and API key
+type fakeUsageService struct {
+	service.UsageService
+	quota    service.Quota
+	keyQuota service.KeyQuota
+	err      error
+}
+
+func (s *fakeUsageService) Quota(ctx context.Context, userID, apiKeyID string) (*service.Quota, error) {
+	quota := s.quota
+	return &quota, s.err
+}
+
+func (s *fakeUsageService) KeyQuota(ctx context.Context, userID, apiKeyID string, limits service.KeyLimits) (*service.KeyQuota, error) {
+	quota := s.keyQuota
+	quota.KeyLimits = limits
+	return &quota, nil
+}
+
+// newQuotaRouter routes /rpc/* calls with the given API key limits through the quota middleware
+func newQuotaRouter(h *RPCHandler, limits service.KeyLimits) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/rpc/*path", func(c *gin.Context) {
+		c.Set("user_id", "user")
+		c.Set("api_key_id", "key")
+		c.Set("api_key_monthly_limit", limits.MonthlyCredits)
+		c.Set("api_key_requests_per_second", limits.RequestsPerSecond)
+	}, h.QuotaMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func callQuotaRouter(router *gin.Engine) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/rpc/ethereum/mainnet", nil))
+	return rec
+}
+
+// assertLimitExceeded checks a refusal is a 429 carrying a JSON-RPC limit error and Retry-After
+func assertLimitExceeded(t *testing.T, rec *httptest.ResponseRecorder) {
+	t.Helper()
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rec.Code)
+	}
+	var resp jsonrpc.Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Error == nil || resp.Error.Code != jsonrpc.LimitExceeded {
+		t.Errorf("body %s is not a JSON-RPC limit exceeded error", rec.Body)
+	}
+	if retryAfter, err := strconv.Atoi(rec.Header().Get("Retry-After")); err != nil || retryAfter <= 0 {
+		t.Errorf("Retry-After = %q", rec.Header().Get("Retry-After"))
+	}
+}
+
+func TestQuotaMiddleware(t *testing.T) {
+	resetAt := time.Now().Add(72 * time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name          string
+		quota         service.Quota
+		status        int
+		wantRemaining string
+	}{
+		{name: "within allowance", quota: service.Quota{Limit: 1000, Used: 400, ResetAt: resetAt}, status: http.StatusOK, wantRemaining: "600"},
+		{name: "allowance used up", quota: service.Quota{Limit: 1000, Used: 1000, ResetAt: resetAt}, status: http.StatusTooManyRequests, wantRemaining: "0"},
+		{name: "prepaid credits left", quota: service.Quota{Limit: 1000, Used: 1200, Prepaid: 50, ResetAt: resetAt}, status: http.StatusOK, wantRemaining: "50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &RPCHandler{usage: &fakeUsageService{quota: tt.quota}, enforceQuota: true}
+			rec := callQuotaRouter(newQuotaRouter(h, service.KeyLimits{}))
+
+			if tt.status == http.StatusTooManyRequests {
+				assertLimitExceeded(t, rec)
+			} else if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+
+			if got := rec.Header().Get("X-Quota-Limit"); got != "1000" {
+				t.Errorf("X-Quota-Limit = %q, want 1000", got)
+			}
+			if got := rec.Header().Get("X-Quota-Remaining"); got != tt.wantRemaining {
+				t.Errorf("X-Quota-Remaining = %q, want %s", got, tt.wantRemaining)
+			}
+			if got := rec.Header().Get("X-Quota-Reset"); got != strconv.FormatInt(resetAt.Unix(), 10) {
+				t.Errorf("X-Quota-Reset = %q, want %d", got, resetAt.Unix())
+			}
+		})
+	}
+}
+
+func TestQuotaMiddlewareNotEnforced(t *testing.T) {
+	h := &RPCHandler{usage: &fakeUsageService{quota: service.Quota{Limit: 1000, Used: 5000}}}
+	rec := callQuotaRouter(newQuotaRouter(h, service.KeyLimits{}))
+
+	if rec.Code != http.StatusOK || rec.Header().Get("X-Quota-Limit") != "" {
+		t.Errorf("status = %d, headers %v; want the call through without quota headers", rec.Code, rec.Header())
+	}
+}
+
+func TestQuotaMiddlewareFailsOpen(t *testing.T) {
+	h := &RPCHandler{usage: &fakeUsageService{err: errors.New("redis unavailable")}, enforceQuota: true}
+
+	if rec := callQuotaRouter(newQuotaRouter(h, service.KeyLimits{})); rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want the call through while the quota cannot be checked", rec.Code)
+	}
+}
+
+func TestQuotaMiddlewareKeyLimits(t *testing.T) {
+	resetAt := time.Now().Add(time.Hour)
+
+	// Key limits apply even when plan quotas are not enforced
+	h := &RPCHandler{usage: &fakeUsageService{keyQuota: service.KeyQuota{Used: 500, ResetAt: resetAt}}}
+	rec := callQuotaRouter(newQuotaRouter(h, service.KeyLimits{MonthlyCredits: 500}))
+	assertLimitExceeded(t, rec)
+	if rec.Header().Get("X-Key-Quota-Limit") != "500" || rec.Header().Get("X-Key-Quota-Remaining") != "0" {
+		t.Errorf("key quota headers %v", rec.Header())
+	}
+
+	h = &RPCHandler{usage: &fakeUsageService{keyQuota: service.KeyQuota{Calls: 11}}}
+	rec = callQuotaRouter(newQuotaRouter(h, service.KeyLimits{RequestsPerSecond: 10}))
+	assertLimitExceeded(t, rec)
+	if rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("Retry-After = %q after a rate limit, want 1", rec.Header().Get("Retry-After"))
+	}
+
+	h = &RPCHandler{usage: &fakeUsageService{keyQuota: service.KeyQuota{Used: 100, Calls: 10}}}
+	rec = callQuotaRouter(newQuotaRouter(h, service.KeyLimits{MonthlyCredits: 500, RequestsPerSecond: 10}))
+	if rec.Code != http.StatusOK || rec.Header().Get("X-Key-Quota-Remaining") != "400" {
+		t.Errorf("status = %d, remaining %q; want the call through with 400 left", rec.Code, rec.Header().Get("X-Key-Quota-Remaining"))
+	}
+}
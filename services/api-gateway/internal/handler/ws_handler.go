@@ -0,0 +1,488 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"ironnode/pkg/jsonrpc"
+	"ironnode/pkg/models"
+	"ironnode/services/api-gateway/internal/rpc/policy"
+	"ironnode/services/api-gateway/internal/rpc/service"
+	userpb "ironnode/services/user-service/proto"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// maxSubscriptionsPerConn limits eth_subscribe calls on one client connection
+	maxSubscriptionsPerConn = 100
+	// maxCallsPerConn limits calls served at once on one client connection
+	maxCallsPerConn = 32
+	// wsSendBuffer is the number of outgoing messages queued per client before it is dropped as too slow
+	wsSendBuffer = 256
+
+	wsPingInterval = 30 * time.Second
+	wsPongWait     = 2 * wsPingInterval
+	wsWriteWait    = 10 * time.Second
+
+	// wsKeyCheckInterval is how often the API key of an open connection is re-validated,
+	// so deactivated, deleted or expired keys stop working on sockets opened before
+	wsKeyCheckInterval = time.Minute
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	// Clients authenticate with an API key, so any origin may connect
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// WebSocket serves JSON-RPC over WebSocket, including eth_subscribe / eth_unsubscribe
+// GET /rpc/:blockchain/:network
+// GET /rpc/:key/:blockchain/:network
+func (h *RPCHandler) WebSocket(c *gin.Context) {
+	_, blockchain, network, ok := splitRPCPath(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, jsonrpc.NewErrorResponse(nil, jsonrpc.InvalidRequest, "Expected /rpc/<blockchain>/<network>"))
+		return
+	}
+
+	if !websocket.IsWebSocketUpgrade(c.Request) {
+		c.JSON(http.StatusBadRequest, jsonrpc.NewErrorResponse(nil, jsonrpc.InvalidRequest, "Expected WebSocket upgrade"))
+		return
+	}
+
+	// The plan is resolved once; it applies for the lifetime of the connection
+	userID := c.GetString("user_id")
+	plan := h.usage.Plan(c.Request.Context(), userID)
+
+	// Upgrade writes its own response, so the rotated key warning is carried over explicitly
+	header := http.Header{}
+	for _, name := range deprecatedKeyHeaders {
+		if value := c.Writer.Header().Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, header)
+	if err != nil {
+		// Upgrade has already replied with an HTTP error
+		return
+	}
+
+	session := &wsSession{
+		handler:       h,
+		conn:          conn,
+		blockchain:    blockchain,
+		network:       network,
+		userID:        userID,
+		apiKey:        c.GetString("api_key"),
+		apiKeyID:      c.GetString("api_key_id"),
+		keyLimits:     keyLimits(c),
+		restrictions:  keyRestrictions(c),
+		keyExpiresAt:  keyExpiry(c.GetString("api_key_expires_at")),
+		ipAddress:     c.ClientIP(),
+		origin:        requestOrigin(c),
+		userAgent:     c.Request.UserAgent(),
+		plan:          plan,
+		send:          make(chan []byte, wsSendBuffer),
+		calls:         make(chan struct{}, maxCallsPerConn),
+		done:          make(chan struct{}),
+		subscriptions: make(map[string]bool),
+	}
+	session.run()
+}
+
+// wsSession is a single client WebSocket connection
+type wsSession struct {
+	handler    *RPCHandler
+	conn       *websocket.Conn
+	blockchain string
+	network    string
+	userID     string
+	apiKey     string
+	apiKeyID   string
+	ipAddress  string
+	origin     string
+	userAgent  string
+	plan       models.PlanType
+
+	send      chan []byte
+	calls     chan struct{} // one slot per call being served
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu            sync.Mutex
+	subscriptions map[string]bool // client subscription IDs owned by this session
+	// The API key's limits, restrictions and expiry as of its last check
+	keyLimits    service.KeyLimits
+	restrictions *models.APIKeyRestrictions
+	keyExpiresAt time.Time // zero for no expiry
+}
+
+// run serves the connection until the client disconnects
+func (s *wsSession) run() {
+	go s.writeLoop()
+	go s.keyCheckLoop()
+	defer s.cleanup()
+
+	s.conn.SetReadLimit(maxRPCBodySize)
+	s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		_, message, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+
+		// Calls are served concurrently up to maxCallsPerConn; replies are matched by id
+		select {
+		case s.calls <- struct{}{}:
+		default:
+			s.reply(jsonrpc.NewErrorResponse(jsonrpc.RequestID(message), jsonrpc.LimitExceeded, "Too many concurrent calls on this connection"))
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-s.calls }()
+			s.handleMessage(message)
+		}()
+	}
+}
+
+// handleMessage serves one message and records it like a JSON-RPC call over HTTP
+func (s *wsSession) handleMessage(message []byte) {
+	start := time.Now()
+	call := &models.RequestLog{StatusCode: http.StatusOK}
+	s.serve(message, call)
+
+	call.Blockchain = s.blockchain
+	call.Endpoint = rpcEndpoint(s.blockchain, s.network)
+	call.ResponseTime = time.Since(start).Milliseconds()
+	call.RequestSize = int64(len(message))
+	call.IPAddress = s.ipAddress
+	call.UserAgent = s.userAgent
+	s.handler.logRequest(s.userID, s.apiKeyID, call)
+}
+
+// serve answers one message; call gets the method, outcome and credits, with
+// failures given the status code the same failure has over HTTP
+func (s *wsSession) serve(message []byte, call *models.RequestLog) {
+	ctx := context.Background()
+
+	limits, restrictions, expired := s.keyState()
+	if expired {
+		call.StatusCode = http.StatusUnauthorized
+		s.revoke("API key has expired")
+		return
+	}
+
+	if reason, exceeded := s.handler.keyLimitExceeded(ctx, s.userID, s.apiKeyID, limits); exceeded {
+		call.StatusCode = http.StatusTooManyRequests
+		s.reply(jsonrpc.NewErrorResponse(jsonrpc.RequestID(message), jsonrpc.LimitExceeded, reason))
+		return
+	}
+
+	if quota, exceeded := s.handler.quotaExceeded(ctx, s.userID, s.apiKeyID); exceeded {
+		call.StatusCode = http.StatusTooManyRequests
+		s.reply(jsonrpc.NewErrorResponse(jsonrpc.RequestID(message), jsonrpc.LimitExceeded, quotaExceededMessage(quota)))
+		return
+	}
+
+	if jsonrpc.IsBatch(message) {
+		call.Method = batchMethod
+		items, err := jsonrpc.ParseBatch(message)
+		if err != nil || len(items) > maxBatchSize {
+			call.StatusCode = http.StatusBadRequest
+			s.reply(jsonrpc.NewErrorResponse(nil, jsonrpc.InvalidRequest, "Invalid JSON-RPC batch"))
+			return
+		}
+
+		allowed, positions, responses, credits := s.handler.checkBatch(ctx, s.blockchain, s.network, s.plan, restrictions, items)
+		call.Credits = credits
+		if err := s.handler.forwardBatch(ctx, s.blockchain, s.network, allowed, positions, responses); err != nil {
+			call.StatusCode = http.StatusBadGateway
+			s.reply(jsonrpc.NewErrorResponse(nil, jsonrpc.InternalError, "Upstream node request failed"))
+			return
+		}
+		s.handler.usage.RecordUsage(s.userID, s.apiKeyID, s.blockchain, credits)
+		if replies := batchReplies(items, responses); len(replies) > 0 {
+			s.reply(replies)
+		}
+		return
+	}
+
+	req, err := jsonrpc.ParseRequest(message)
+	if err != nil {
+		call.StatusCode = http.StatusBadRequest
+		s.reply(jsonrpc.NewErrorResponse(nil, jsonrpc.InvalidRequest, "Invalid JSON-RPC request"))
+		return
+	}
+	call.Method = req.Method
+
+	if !s.handler.policy.Allowed(s.plan, req.Method) {
+		call.StatusCode = http.StatusForbidden
+		s.reply(jsonrpc.NewErrorResponse(req.ID, jsonrpc.MethodNotFound, policy.DeniedMessage(s.plan, req.Method)))
+		return
+	}
+	if !restrictions.AllowsMethod(req.Method) {
+		call.StatusCode = http.StatusForbidden
+		s.reply(jsonrpc.NewErrorResponse(req.ID, jsonrpc.AccessDenied, keyMethodDeniedMessage(req.Method)))
+		return
+	}
+
+	switch req.Method {
+	case "eth_subscribe":
+		s.subscribe(ctx, req, call)
+	case "eth_unsubscribe":
+		s.unsubscribe(req)
+	default:
+		result, err := s.handler.rpcService.Forward(ctx, s.blockchain, s.network, message)
+		if err != nil {
+			if isUnavailable(err) {
+				call.StatusCode = http.StatusServiceUnavailable
+				s.reply(jsonrpc.NewErrorResponse(req.ID, jsonrpc.ServerError, err.Error()))
+				return
+			}
+			call.StatusCode = http.StatusBadGateway
+			s.reply(jsonrpc.NewErrorResponse(req.ID, jsonrpc.InternalError, "Upstream node request failed"))
+			return
+		}
+		call.Credits = s.handler.credits(ctx, s.blockchain, s.network, req)
+		call.ResponseSize = int64(len(result.Data))
+		call.CacheHit = result.CacheHit
+		call.Coalesced = result.Coalesced
+		s.handler.usage.RecordUsage(s.userID, s.apiKeyID, s.blockchain, call.Credits)
+		s.enqueue(result.Data)
+	}
+}
+
+// keyState returns the API key's current limits and restrictions and whether it has expired
+func (s *wsSession) keyState() (service.KeyLimits, *models.APIKeyRestrictions, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired := !s.keyExpiresAt.IsZero() && time.Now().After(s.keyExpiresAt)
+	return s.keyLimits, s.restrictions, expired
+}
+
+// keyCheckLoop re-validates the API key until the connection closes and drops
+// the connection once the key is no longer valid for it
+func (s *wsSession) keyCheckLoop() {
+	ticker := time.NewTicker(wsKeyCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if reason, valid := s.checkKey(); !valid {
+				s.revoke(reason)
+				return
+			}
+		}
+	}
+}
+
+// checkKey validates the API key with User Service again and refreshes its limits,
+// restrictions and expiry. The last known state is kept if User Service is unreachable.
+func (s *wsSession) checkKey() (string, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := s.handler.userClient.ValidateAPIKey(ctx, &userpb.ValidateAPIKeyRequest{
+		Key: s.apiKey,
+	})
+	if err != nil {
+		log.Printf("[WebSocket] Failed to re-validate API key %s: %v", s.apiKeyID, err)
+		return "", true
+	}
+	if !resp.Valid {
+		return "Invalid or expired API key", false
+	}
+
+	restrictions := restrictionsFromProto(resp.Restrictions)
+	if reason, allowed := checkRestrictions(restrictions, s.blockchain, s.network, s.ipAddress, s.origin); !allowed {
+		return reason, false
+	}
+
+	s.mu.Lock()
+	s.keyLimits = service.KeyLimits{
+		MonthlyCredits:    resp.MonthlyLimit,
+		RequestsPerSecond: resp.RequestsPerSecond,
+	}
+	s.restrictions = restrictions
+	s.keyExpiresAt = keyExpiry(resp.ExpiresAt)
+	s.mu.Unlock()
+
+	return "", true
+}
+
+// keyExpiry parses the expiry User Service reports for a key; zero for none
+func keyExpiry(expiresAt string) time.Time {
+	t, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func (s *wsSession) subscribe(ctx context.Context, req *jsonrpc.Request, call *models.RequestLog) {
+	s.mu.Lock()
+	count := len(s.subscriptions)
+	s.mu.Unlock()
+
+	if count >= maxSubscriptionsPerConn {
+		call.StatusCode = http.StatusTooManyRequests
+		s.reply(jsonrpc.NewErrorResponse(req.ID, jsonrpc.ServerError, "Too many subscriptions on this connection"))
+		return
+	}
+
+	id, err := s.handler.subscriptions.Subscribe(ctx, s.blockchain, s.network, req.Params, s.enqueue)
+	if err != nil {
+		if isUnavailable(err) {
+			call.StatusCode = http.StatusServiceUnavailable
+			s.reply(jsonrpc.NewErrorResponse(req.ID, jsonrpc.ServerError, err.Error()))
+			return
+		}
+		call.StatusCode = http.StatusBadGateway
+		s.reply(jsonrpc.NewErrorResponse(req.ID, jsonrpc.InternalError, "Failed to create subscription"))
+		return
+	}
+
+	s.mu.Lock()
+	s.subscriptions[id] = true
+	s.mu.Unlock()
+
+	call.Credits = s.handler.credits(ctx, s.blockchain, s.network, req)
+	s.handler.usage.RecordUsage(s.userID, s.apiKeyID, s.blockchain, call.Credits)
+
+	s.replyResult(req.ID, id)
+}
+
+func (s *wsSession) unsubscribe(req *jsonrpc.Request) {
+	var params []string
+	if err := json.Unmarshal(req.Params, &params); err != nil || len(params) != 1 {
+		s.reply(jsonrpc.NewErrorResponse(req.ID, jsonrpc.InvalidParams, "Expected [subscriptionId]"))
+		return
+	}
+
+	s.mu.Lock()
+	owned := s.subscriptions[params[0]]
+	delete(s.subscriptions, params[0])
+	s.mu.Unlock()
+
+	// Clients may only cancel their own subscriptions
+	if !owned {
+		s.replyResult(req.ID, false)
+		return
+	}
+
+	s.replyResult(req.ID, s.handler.subscriptions.Unsubscribe(params[0]) == nil)
+}
+
+func (s *wsSession) replyResult(id json.RawMessage, result interface{}) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+
+	s.reply(&jsonrpc.Response{
+		JSONRPC: jsonrpc.Version,
+		ID:      id,
+		Result:  data,
+	})
+}
+
+func (s *wsSession) reply(message interface{}) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+	s.enqueue(data)
+}
+
+// enqueue queues a message for the client without blocking.
+// A client that cannot keep up is disconnected.
+func (s *wsSession) enqueue(data []byte) {
+	select {
+	case <-s.done:
+	case s.send <- data:
+	default:
+		s.close()
+	}
+}
+
+func (s *wsSession) writeLoop() {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case data := <-s.send:
+			s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := s.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				s.close()
+				return
+			}
+		case <-ticker.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				s.close()
+				return
+			}
+		}
+	}
+}
+
+// revoke tells the client why its API key no longer works and drops the connection
+func (s *wsSession) revoke(reason string) {
+	message := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason)
+	s.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(wsWriteWait))
+	s.close()
+}
+
+// close drops the connection; the read loop then exits and cleans up
+func (s *wsSession) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.conn.Close()
+	})
+}
+
+// cleanup cancels every subscription of the session
+func (s *wsSession) cleanup() {
+	s.close()
+
+	s.mu.Lock()
+	ids := make([]string, 0, len(s.subscriptions))
+	for id := range s.subscriptions {
+		ids = append(ids, id)
+	}
+	s.subscriptions = make(map[string]bool)
+	s.mu.Unlock()
+
+	for _, id := range ids {
+		s.handler.subscriptions.Unsubscribe(id)
+	}
+}
@@ -0,0 +1,129 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page in points
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	pageMargin = 50
+)
+
+// columns are the x positions of table cells
+var columns = []int{pageMargin, 330, 410, 480}
+
+// pdfDocument lays out lines of Helvetica text top to bottom, starting a
+// new page when one fills up. It writes just enough PDF for any viewer:
+// a catalog, a page tree, one standard font and a content stream per page.
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	y     int
+}
+
+func newPDFDocument() *pdfDocument {
+	doc := &pdfDocument{}
+	doc.newPage()
+	return doc
+}
+
+func (d *pdfDocument) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - pageMargin
+}
+
+// advance moves down by one line of the given font size
+func (d *pdfDocument) advance(size int) *bytes.Buffer {
+	lineHeight := size + size/2
+	if d.y-lineHeight < pageMargin {
+		d.newPage()
+	}
+	d.y -= lineHeight
+	return d.pages[len(d.pages)-1]
+}
+
+func (d *pdfDocument) text(size int, s string) {
+	page := d.advance(size)
+	fmt.Fprintf(page, "BT /F1 %d Tf %d %d Td (%s) Tj ET\n", size, pageMargin, d.y, escapePDF(s))
+}
+
+// row writes table cells at the column positions
+func (d *pdfDocument) row(size int, cells ...string) {
+	page := d.advance(size)
+	for i, cell := range cells {
+		if cell == "" || i >= len(columns) {
+			continue
+		}
+		fmt.Fprintf(page, "BT /F1 %d Tf %d %d Td (%s) Tj ET\n", size, columns[i], d.y, escapePDF(cell))
+	}
+}
+
+// rule draws a horizontal line under the previous line
+func (d *pdfDocument) rule() {
+	page := d.pages[len(d.pages)-1]
+	fmt.Fprintf(page, "0.5 w %d %d m %d %d l S\n", pageMargin, d.y-4, pageWidth-pageMargin, d.y-4)
+}
+
+func (d *pdfDocument) gap() {
+	d.advance(6)
+}
+
+func (d *pdfDocument) bytes() []byte {
+	// Objects 1-3 are the catalog, page tree and font; each page adds a page and a content object
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // page tree, filled in below
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+	}
+
+	kids := make([]string, 0, len(d.pages))
+	for _, content := range d.pages {
+		pageID := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageID))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				pageWidth, pageHeight, pageID+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return out.Bytes()
+}
+
+// escapePDF makes s safe inside a PDF string literal; characters outside
+// printable ASCII are replaced since the font uses a single-byte encoding
+func escapePDF(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
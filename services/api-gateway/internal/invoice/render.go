@@ -0,0 +1,87 @@
+// Package invoice renders billing invoices for download
+package invoice
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	pb "ironnode/services/billing-service/proto"
+)
+
+const dateFormat = "2006-01-02"
+
+// CSV renders the invoice as one row per line item, preceded by a header row
+func CSV(inv *pb.Invoice) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	rows := [][]string{
+		{"invoice", "period_start", "period_end", "kind", "description", "blockchain", "quantity", "unit_price", "amount", "currency"},
+	}
+	for _, item := range inv.LineItems {
+		rows = append(rows, []string{
+			inv.Number,
+			formatDate(inv.PeriodStart),
+			formatDate(inv.PeriodEnd),
+			item.Kind,
+			item.Description,
+			item.Blockchain,
+			strconv.FormatInt(item.Quantity, 10),
+			strconv.FormatFloat(item.UnitPrice, 'f', -1, 64),
+			formatMoney(item.Amount),
+			inv.Currency,
+		})
+	}
+	rows = append(rows, []string{
+		inv.Number, formatDate(inv.PeriodStart), formatDate(inv.PeriodEnd),
+		"total", "Total", "", "", "", formatMoney(inv.Total), inv.Currency,
+	})
+
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// PDF renders the invoice as a plain single-font PDF document
+func PDF(inv *pb.Invoice) []byte {
+	doc := newPDFDocument()
+
+	doc.text(16, fmt.Sprintf("Invoice %s", inv.Number))
+	doc.gap()
+	doc.text(10, fmt.Sprintf("Plan: %s", inv.PlanType))
+	doc.text(10, fmt.Sprintf("Billing period: %s - %s", formatDate(inv.PeriodStart), formatDate(inv.PeriodEnd)))
+	doc.text(10, fmt.Sprintf("Issued: %s    Due: %s", formatDate(inv.IssuedAt), formatDate(inv.DueAt)))
+	doc.text(10, fmt.Sprintf("Status: %s", inv.Status))
+	doc.gap()
+
+	doc.row(10, "Description", "Quantity", "Unit price", "Amount")
+	doc.rule()
+	for _, item := range inv.LineItems {
+		unitPrice, amount := "", ""
+		if item.Kind != "usage" && item.Kind != "prepaid" {
+			unitPrice = strconv.FormatFloat(item.UnitPrice, 'f', -1, 64)
+			amount = formatMoney(item.Amount)
+		}
+		doc.row(10, item.Description, strconv.FormatInt(item.Quantity, 10), unitPrice, amount)
+	}
+	doc.rule()
+	doc.row(12, "Total", "", "", fmt.Sprintf("%s %s", formatMoney(inv.Total), inv.Currency))
+
+	return doc.bytes()
+}
+
+func formatDate(unix int64) string {
+	if unix == 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).UTC().Format(dateFormat)
+}
+
+func formatMoney(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
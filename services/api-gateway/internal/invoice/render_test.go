@@ -0,0 +1,124 @@
+package invoice
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	pb "ironnode/services/billing-service/proto"
+)
+
+func testInvoice(usageLines int) *pb.Invoice {
+	inv := &pb.Invoice{
+		Number:      "INV-202610-ABCDEF12",
+		PlanType:    "basic",
+		Status:      "open",
+		Currency:    "USD",
+		PeriodStart: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC).Unix(),
+		PeriodEnd:   time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC).Unix(),
+		IssuedAt:    time.Date(2026, 10, 1, 1, 0, 0, 0, time.UTC).Unix(),
+		DueAt:       time.Date(2026, 10, 15, 1, 0, 0, 0, time.UTC).Unix(),
+		Total:       39.99,
+		LineItems: []*pb.InvoiceLineItem{
+			{Kind: "plan", Description: "Basic plan", Quantity: 1, UnitPrice: 29.99, Amount: 29.99},
+			{Kind: "overage", Description: "Overage credits over 100000 included", Quantity: 20000, UnitPrice: 0.0005, Amount: 10},
+		},
+	}
+	for i := 0; i < usageLines; i++ {
+		inv.LineItems = append(inv.LineItems, &pb.InvoiceLineItem{
+			Kind: "usage", Description: fmt.Sprintf("chain%d usage (credits)", i), Blockchain: fmt.Sprintf("chain%d", i), Quantity: 1000,
+		})
+	}
+	return inv
+}
+
+func TestCSV(t *testing.T) {
+	data, err := CSV(testInvoice(1))
+	if err != nil {
+		t.Fatalf("CSV: %v", err)
+	}
+
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not CSV: %v", err)
+	}
+
+	want := [][]string{
+		{"invoice", "period_start", "period_end", "kind", "description", "blockchain", "quantity", "unit_price", "amount", "currency"},
+		{"INV-202610-ABCDEF12", "2026-09-01", "2026-10-01", "plan", "Basic plan", "", "1", "29.99", "29.99", "USD"},
+		{"INV-202610-ABCDEF12", "2026-09-01", "2026-10-01", "overage", "Overage credits over 100000 included", "", "20000", "0.0005", "10.00", "USD"},
+		{"INV-202610-ABCDEF12", "2026-09-01", "2026-10-01", "usage", "chain0 usage (credits)", "chain0", "1000", "0", "0.00", "USD"},
+		{"INV-202610-ABCDEF12", "2026-09-01", "2026-10-01", "total", "Total", "", "", "", "39.99", "USD"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("%d rows, want %d:\n%s", len(rows), len(want), data)
+	}
+	for i := range want {
+		if strings.Join(rows[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("row %d = %v, want %v", i, rows[i], want[i])
+		}
+	}
+}
+
+func TestPDF(t *testing.T) {
+	data := PDF(testInvoice(1))
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("not a PDF document:\n%s", data)
+	}
+	for _, text := range []string{"(Invoice INV-202610-ABCDEF12)", "(Billing period: 2026-09-01 - 2026-10-01)", "(Basic plan)", "(39.99 USD)"} {
+		if !bytes.Contains(data, []byte(text)) {
+			t.Errorf("PDF lacks %s", text)
+		}
+	}
+	assertXref(t, data)
+}
+
+func TestPDFStartsNewPages(t *testing.T) {
+	data := PDF(testInvoice(100))
+
+	count := regexp.MustCompile(`/Type /Pages /Kids \[[^\]]*\] /Count (\d+)`).FindSubmatch(data)
+	if count == nil {
+		t.Fatal("PDF has no page tree")
+	}
+	if pages, _ := strconv.Atoi(string(count[1])); pages < 2 {
+		t.Errorf("%d pages for 100 usage lines, want the table to continue on a new page", pages)
+	}
+	if !bytes.Contains(data, []byte("(chain99 usage \\(credits\\))")) {
+		t.Error("last usage line missing or not escaped")
+	}
+	assertXref(t, data)
+}
+
+// assertXref checks every cross-reference entry points at its object
+func assertXref(t *testing.T, data []byte) {
+	t.Helper()
+
+	match := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if match == nil {
+		t.Fatal("PDF has no startxref")
+	}
+	xref, _ := strconv.Atoi(string(match[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if header := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(data[offset:], []byte(header)) {
+			t.Errorf("xref entry %d points at %q", i+1, data[offset:min(offset+12, len(data))])
+		}
+	}
+}
+
+func TestEscapePDF(t *testing.T) {
+	if got, want := escapePDF(`a (b) \ c`+"\n€"), `a \(b\) \\ c??`; got != want {
+		t.Errorf("escapePDF = %q, want %q", got, want)
+	}
+}
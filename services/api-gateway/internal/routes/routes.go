@@ -14,6 +14,10 @@ func SetupRoutes(
 	router *gin.Engine,
 	authHandler *handler.AuthHandler,
 	blockchainHandler *handler.BlockchainHandler,
+	rpcHandler *handler.RPCHandler,
+	apiKeyHandler *handler.APIKeyHandler,
+	billingHandler *handler.BillingHandler,
+	analyticsHandler *handler.AnalyticsHandler,
 	redisClient *redis.Client,
 ) {
 	// Health check
@@ -21,9 +25,27 @@ func SetupRoutes(
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// JSON-RPC proxy metrics (require authentication)
+	router.GET("/metrics/rpc", authHandler.AuthMiddleware(), rpcHandler.Stats)
+
 	// API Documentation - serve static HTML
 	router.Static("/docs", "./docs")
 
+	// Rate limiter middleware
+	rateLimiter := middleware.NewRateLimiter(redisClient, 100, 1*time.Minute)
+
+	// JSON-RPC proxy routes (require API key)
+	// /rpc/:blockchain/:network with X-API-Key header, or /rpc/:key/:blockchain/:network
+	// Traffic is limited by the plan's quota and the key's per-second limit
+	rpc := router.Group("/rpc")
+	rpc.Use(apiKeyHandler.APIKeyMiddleware())
+	rpc.Use(rpcHandler.RequestLogMiddleware())
+	rpc.Use(rpcHandler.QuotaMiddleware())
+	{
+		rpc.POST("/*path", rpcHandler.Proxy)
+		rpc.GET("/*path", rpcHandler.WebSocket) // WebSocket, eth_subscribe
+	}
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
@@ -37,8 +59,8 @@ func SetupRoutes(
 			auth.POST("/reset-password", authHandler.ResetPassword)
 		}
 
-		// Rate limiter middleware
-		rateLimiter := middleware.NewRateLimiter(redisClient, 100, 1*time.Minute)
+		// Payment provider webhooks (public, authenticated by signature)
+		v1.POST("/billing/webhooks/:provider", billingHandler.Webhook)
 
 		// Protected routes (require authentication)
 		protected := v1.Group("")
@@ -58,16 +80,34 @@ func SetupRoutes(
 			// Analytics routes
 			analytics := protected.Group("/analytics")
 			{
-				analytics.GET("/usage", handler.GetUsageStats)
-				analytics.GET("/requests", handler.GetRequestHistory)
+				analytics.GET("/usage", analyticsHandler.GetUsageStats)           // ?start_date=&end_date=&blockchain=&api_key_id=
+				analytics.GET("/requests", analyticsHandler.GetRequestHistory)    // ?limit=&start_date=&end_date=&blockchain=&api_key_id=
+				analytics.GET("/timeseries", analyticsHandler.GetUsageTimeSeries) // ?granularity=minute|hour|day&start_date=&end_date=&blockchain=&api_key_id=&method=
+				analytics.GET("/breakdown", analyticsHandler.GetUsageBreakdown)   // ?group_by=api_key|method|blockchain&start_date=&end_date=&blockchain=&api_key_id=&method=
+			}
+
+			// Billing routes
+			billing := protected.Group("/billing")
+			{
+				billing.GET("/invoices", billingHandler.ListInvoices)
+				billing.GET("/invoices/:id", billingHandler.GetInvoice) // ?format=json|csv|pdf
+				billing.POST("/payment-method", billingHandler.AttachPaymentMethod)
+				billing.GET("/balance", billingHandler.GetPrepaidBalance)
+				billing.GET("/crypto/deposit-address", billingHandler.GetDepositAddress)
+				billing.GET("/crypto/deposits", billingHandler.ListDeposits)
+				billing.GET("/credits", billingHandler.GetCredits)
+				billing.GET("/credits/entries", billingHandler.ListLedgerEntries)
+				billing.POST("/credits/top-up", billingHandler.TopUpCredits)
 			}
 
 			// API Keys routes
 			apiKeys := protected.Group("/api-keys")
 			{
-				apiKeys.GET("", handler.ListAPIKeys)
-				apiKeys.POST("", handler.CreateAPIKey)
-				apiKeys.DELETE("/:id", handler.DeleteAPIKey)
+				apiKeys.GET("", apiKeyHandler.ListAPIKeys)
+				apiKeys.POST("", apiKeyHandler.CreateAPIKey)
+				apiKeys.PATCH("/:id", apiKeyHandler.UpdateAPIKey)
+				apiKeys.POST("/:id/rotate", apiKeyHandler.RotateAPIKey)
+				apiKeys.DELETE("/:id", apiKeyHandler.DeleteAPIKey)
 			}
 
 			// Wallet routes
@@ -0,0 +1,183 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ironnode/pkg/models"
+)
+
+// defaultAllowlists lists the method namespaces each plan may call.
+// "eth_*" allows a namespace, "*" allows everything, anything else is an exact method name.
+// Methods without a namespace (Bitcoin, Solana) are not restricted.
+var defaultAllowlists = map[models.PlanType][]string{
+	models.FreePlan:         {"eth_*", "net_*", "web3_*"},
+	models.BasicPlan:        {"eth_*", "net_*", "web3_*", "txpool_*"},
+	models.ProfessionalPlan: {"eth_*", "net_*", "web3_*", "txpool_*"},
+	models.EnterprisePlan:   {"*"},
+}
+
+// defaultWeights is the credit cost of a call; unlisted methods cost defaultWeight
+var defaultWeights = map[string]int64{
+	"eth_call":                      2,
+	"eth_estimateGas":               2,
+	"eth_feeHistory":                2,
+	"eth_getBlockByHash":            2,
+	"eth_getBlockByNumber":          2,
+	"eth_getFilterChanges":          2,
+	"eth_getTransactionReceipt":     2,
+	"eth_getProof":                  5,
+	"eth_newFilter":                 5,
+	"eth_sendRawTransaction":        5,
+	"eth_getBlockReceipts":          10,
+	"eth_subscribe":                 10,
+	"eth_getLogs":                   20, // per logsBlocksPerUnit blocks
+	"eth_getFilterLogs":             20,
+	"txpool_content":                20,
+	"debug_traceCall":               50,
+	"debug_traceTransaction":        50,
+	"trace_call":                    50,
+	"trace_transaction":             50,
+	"trace_replayTransaction":       100,
+	"debug_traceBlockByHash":        200,
+	"debug_traceBlockByNumber":      200,
+	"trace_block":                   200,
+	"trace_filter":                  200,
+	"trace_replayBlockTransactions": 300,
+}
+
+const (
+	defaultWeight = 1
+	// logsBlocksPerUnit is the eth_getLogs block range covered by its base weight
+	logsBlocksPerUnit = 1000
+	// maxLogsWeight caps the cost of a single eth_getLogs call
+	maxLogsWeight = 1000
+)
+
+// MethodPolicy decides which methods a plan may call and how many credits a call costs
+type MethodPolicy struct {
+	allowlists map[models.PlanType][]string
+	weights    map[string]int64
+}
+
+// NewMethodPolicy creates a policy with the default allowlists and weights
+func NewMethodPolicy() *MethodPolicy {
+	return &MethodPolicy{
+		allowlists: defaultAllowlists,
+		weights:    defaultWeights,
+	}
+}
+
+// Allowed reports whether the plan may call the method
+func (p *MethodPolicy) Allowed(plan models.PlanType, method string) bool {
+	separator := strings.Index(method, "_")
+	if separator < 0 {
+		return true
+	}
+	namespace := method[:separator]
+
+	allowlist, ok := p.allowlists[plan]
+	if !ok {
+		allowlist = p.allowlists[models.FreePlan]
+	}
+
+	for _, pattern := range allowlist {
+		switch {
+		case pattern == "*":
+			return true
+		case strings.HasSuffix(pattern, "_*"):
+			if strings.TrimSuffix(pattern, "_*") == namespace {
+				return true
+			}
+		case pattern == method:
+			return true
+		}
+	}
+
+	return false
+}
+
+// DeniedMessage explains why a method was rejected
+func DeniedMessage(plan models.PlanType, method string) string {
+	return fmt.Sprintf("Method %s is not available on the %s plan", method, plan)
+}
+
+// Credits returns the credit cost of a call. head is the chain's latest known
+// block number, or 0 if it is not known.
+func (p *MethodPolicy) Credits(method string, params json.RawMessage, head uint64) int64 {
+	weight, ok := p.weights[method]
+	if !ok {
+		weight = defaultWeight
+	}
+
+	if method == "eth_getLogs" {
+		weight *= logsRangeUnits(params, head)
+		if weight > maxLogsWeight {
+			weight = maxLogsWeight
+		}
+	}
+
+	return weight
+}
+
+// logsRangeUnits returns how many logsBlocksPerUnit block spans an eth_getLogs filter covers.
+// Bounds that are tags near the chain head ("latest", "safe", ...) resolve to head;
+// a range from a fixed block up to such a tag costs the maximum while head is unknown.
+// Filters by block hash count as a single unit.
+func logsRangeUnits(params json.RawMessage, head uint64) int64 {
+	var filters []struct {
+		FromBlock string `json:"fromBlock"`
+		ToBlock   string `json:"toBlock"`
+		BlockHash string `json:"blockHash"`
+	}
+	if err := json.Unmarshal(params, &filters); err != nil || len(filters) == 0 || filters[0].BlockHash != "" {
+		return 1
+	}
+
+	from, fromHead, ok := logsBound(filters[0].FromBlock)
+	if !ok {
+		return 1
+	}
+	to, toHead, ok := logsBound(filters[0].ToBlock)
+	if !ok {
+		return 1
+	}
+
+	switch {
+	case fromHead && toHead:
+		return 1
+	case toHead && head == 0:
+		return maxLogsWeight
+	case toHead:
+		to = head
+	case fromHead:
+		// Ends at a fixed block at or below the head
+		return 1
+	}
+	if to < from {
+		return 1
+	}
+
+	units := (to-from)/logsBlocksPerUnit + 1
+	if units > maxLogsWeight {
+		return maxLogsWeight
+	}
+
+	return int64(units)
+}
+
+// logsBound parses an eth_getLogs block bound: a hex number, "earliest" or a tag
+// near the head, which is reported in atHead. A missing bound means "latest".
+func logsBound(value string) (number uint64, atHead bool, ok bool) {
+	switch strings.ToLower(value) {
+	case "", "latest", "pending", "safe", "finalized":
+		return 0, true, true
+	case "earliest":
+		return 0, false, true
+	}
+
+	number, err := strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 64)
+	return number, false, err == nil
+}
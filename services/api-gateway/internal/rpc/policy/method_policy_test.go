@@ -0,0 +1,68 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"ironnode/pkg/models"
+)
+
+func TestAllowed(t *testing.T) {
+	p := NewMethodPolicy()
+
+	tests := []struct {
+		plan   models.PlanType
+		method string
+		want   bool
+	}{
+		{models.FreePlan, "eth_call", true},
+		{models.FreePlan, "txpool_content", false},
+		{models.FreePlan, "debug_traceTransaction", false},
+		{models.BasicPlan, "txpool_content", true},
+		{models.ProfessionalPlan, "trace_block", false},
+		{models.EnterprisePlan, "trace_block", true},
+		{models.FreePlan, "getblockcount", true}, // no namespace
+		{"unknown", "eth_call", true},            // falls back to free
+		{"unknown", "txpool_content", false},
+	}
+	for _, tt := range tests {
+		if got := p.Allowed(tt.plan, tt.method); got != tt.want {
+			t.Errorf("Allowed(%s, %s) = %v, want %v", tt.plan, tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestCredits(t *testing.T) {
+	p := NewMethodPolicy()
+
+	tests := []struct {
+		name   string
+		method string
+		params string
+		head   uint64
+		want   int64
+	}{
+		{"unlisted method", "eth_chainId", `[]`, 0, 1},
+		{"weighted method", "debug_traceTransaction", `["0xabc"]`, 0, 50},
+		{"logs by hash", "eth_getLogs", `[{"blockHash":"0xabc"}]`, 0, 20},
+		{"logs without range", "eth_getLogs", `[{}]`, 0, 20},
+		{"logs at head", "eth_getLogs", `[{"fromBlock":"latest","toBlock":"latest"}]`, 0, 20},
+		{"logs finalized to latest", "eth_getLogs", `[{"fromBlock":"finalized","toBlock":"latest"}]`, 0, 20},
+		{"logs small range", "eth_getLogs", `[{"fromBlock":"0x10","toBlock":"0x20"}]`, 0, 20},
+		{"logs numeric range", "eth_getLogs", `[{"fromBlock":"0x0","toBlock":"0xbb8"}]`, 0, 80}, // 3000 blocks: 4 units
+		{"logs full chain numeric", "eth_getLogs", `[{"fromBlock":"0x0","toBlock":"0x1000000"}]`, 0, maxLogsWeight},
+		{"logs to latest with head", "eth_getLogs", `[{"fromBlock":"0x1000","toBlock":"latest"}]`, 0x1000 + 1500, 40},
+		{"logs to latest without head", "eth_getLogs", `[{"fromBlock":"0x1000","toBlock":"latest"}]`, 0, maxLogsWeight},
+		{"logs zero to latest", "eth_getLogs", `[{"fromBlock":"0x0","toBlock":"latest"}]`, 20_000_000, maxLogsWeight},
+		{"logs earliest to latest", "eth_getLogs", `[{"fromBlock":"earliest","toBlock":"latest"}]`, 20_000_000, maxLogsWeight},
+		{"logs earliest to latest without head", "eth_getLogs", `[{"fromBlock":"earliest"}]`, 0, maxLogsWeight},
+		{"logs earliest to block", "eth_getLogs", `[{"fromBlock":"earliest","toBlock":"0x7cf"}]`, 0, 40},
+		{"logs reversed range", "eth_getLogs", `[{"fromBlock":"0x20","toBlock":"0x10"}]`, 0, 20},
+		{"logs invalid params", "eth_getLogs", `{"fromBlock":"0x0"}`, 0, 20},
+	}
+	for _, tt := range tests {
+		if got := p.Credits(tt.method, json.RawMessage(tt.params), tt.head); got != tt.want {
+			t.Errorf("%s: credits = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,439 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ironnode/pkg/async"
+	"ironnode/pkg/cache"
+	"ironnode/pkg/jsonrpc"
+	pb "ironnode/services/blockchain-service/proto"
+)
+
+const (
+	// upstreamBatchSize caps the calls sent to a node in one native batch
+	upstreamBatchSize = 100
+	// maxBatchAttempts is how many nodes a batch item is tried on
+	maxBatchAttempts = 2
+	// upstreamBatchMethod names native batches for latency tracking
+	upstreamBatchMethod = "batch"
+)
+
+// ErrNoNodes is returned when no active node serves the requested chain and network
+var ErrNoNodes = errors.New("no active nodes for this blockchain and network")
+
+// ForwardResult is the reply to a single JSON-RPC call
+type ForwardResult struct {
+	Data      []byte
+	CacheHit  bool
+	Coalesced bool // served by an identical call already in flight
+}
+
+type RPCService interface {
+	Forward(ctx context.Context, blockchain, network string, body []byte) (*ForwardResult, error)
+	ForwardBatch(ctx context.Context, blockchain, network string, items []json.RawMessage) ([]*jsonrpc.Response, error)
+	Stats() async.CoalescerStats
+	// Head returns the chain's latest known block number
+	Head(ctx context.Context, blockchain, network string) (uint64, bool)
+}
+
+type rpcService struct {
+	blockchainClient pb.BlockchainServiceClient
+	httpClient       *http.Client
+	requester        *async.ParallelRequester
+	breaker          *async.CircuitBreaker
+	cache            *cache.RPCCache // nil disables caching
+	coalescer        *async.Coalescer
+}
+
+func NewRPCService(blockchainClient pb.BlockchainServiceClient, httpClient *http.Client, breaker *async.CircuitBreaker, hedge async.HedgeConfig, rpcCache *cache.RPCCache) RPCService {
+	s := &rpcService{
+		blockchainClient: blockchainClient,
+		httpClient:       httpClient,
+		breaker:          breaker,
+		cache:            rpcCache,
+		coalescer:        async.NewCoalescer(),
+	}
+	s.requester = async.NewParallelRequester(s.doRequest, httpClient.Timeout)
+	s.requester.SetCircuitBreaker(breaker)
+	s.requester.SetHedgeConfig(hedge)
+
+	return s
+}
+
+// Forward sends a raw JSON-RPC body to the best node for the block the call reads
+// and returns the node's reply. For idempotent reads a backup request goes to the next
+// node if the first one is slower than usual for the method; other calls fail over only
+// when a node cannot be reached. Nodes with an open circuit are skipped.
+// Immutable results are served from cache without going upstream, and identical
+// concurrent reads are coalesced.
+func (s *rpcService) Forward(ctx context.Context, blockchain, network string, body []byte) (*ForwardResult, error) {
+	req, err := jsonrpc.ParseRequest(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp, ok := s.cached(ctx, blockchain, network, req); ok {
+		data, err := json.Marshal(resp)
+		if err == nil {
+			return &ForwardResult{Data: data, CacheHit: true}, nil
+		}
+	}
+
+	if !cache.Coalescable(req.Method) {
+		data, err := s.forwardUpstream(ctx, blockchain, network, req, body)
+		if err != nil {
+			return nil, err
+		}
+		return &ForwardResult{Data: data}, nil
+	}
+
+	// Identical concurrent reads share one upstream request
+	key := cache.Key(blockchain, network, req.Method, req.Params)
+	data, shared, err := s.coalescer.Do(key, func() ([]byte, error) {
+		// Detached from the caller: other callers may be waiting for this result
+		return s.forwardUpstream(context.WithoutCancel(ctx), blockchain, network, req, body)
+	})
+	if err != nil {
+		var reply *async.ReplyError
+		if shared && errors.As(err, &reply) {
+			// Answer with a copy carrying this caller's id
+			if body, idErr := jsonrpc.WithID(reply.Body, req.ID); idErr == nil {
+				return nil, &async.ReplyError{StatusCode: reply.StatusCode, ContentType: reply.ContentType, Body: body}
+			}
+		}
+		return nil, err
+	}
+
+	if shared {
+		// The reply carries the id of the caller that made the upstream request
+		data, err = jsonrpc.WithID(data, req.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ForwardResult{Data: data, Coalesced: shared}, nil
+}
+
+// forwardUpstream sends a single call to the chain's nodes and caches the result
+func (s *rpcService) forwardUpstream(ctx context.Context, blockchain, network string, req *jsonrpc.Request, body []byte) ([]byte, error) {
+	nodes, err := s.getNodes(ctx, blockchain, network, jsonrpc.BlockTag(req.Method, req.Params))
+	if err != nil {
+		return nil, err
+	}
+
+	// Nodes are ordered by the chain's selection strategy (best first)
+	nodeURLs := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		nodeURLs = append(nodeURLs, node.Url)
+	}
+
+	// Only idempotent reads are hedged: a second copy of a transaction would reach
+	// another node and could answer "already known" although the first one succeeded
+	var resp *async.NodeResponse
+	if cache.Coalescable(req.Method) {
+		resp, err = s.requester.RequestHedged(ctx, nodeURLs, req.Method, body)
+	} else {
+		resp, err = s.requester.RequestSequential(ctx, nodeURLs, req.Method, body)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.store(ctx, blockchain, network, req, resp.Data)
+
+	return resp.Data, nil
+}
+
+// cached returns the cached reply to a call, carrying the client's id
+func (s *rpcService) cached(ctx context.Context, blockchain, network string, req *jsonrpc.Request) (*jsonrpc.Response, bool) {
+	if s.cache == nil {
+		return nil, false
+	}
+
+	result, ok := s.cache.Get(ctx, blockchain, network, req.Method, req.Params)
+	if !ok {
+		return nil, false
+	}
+
+	id := req.ID
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+
+	return &jsonrpc.Response{
+		JSONRPC: jsonrpc.Version,
+		ID:      id,
+		Result:  result,
+	}, true
+}
+
+// store caches a node's reply to a call if the method's rule allows it
+func (s *rpcService) store(ctx context.Context, blockchain, network string, req *jsonrpc.Request, data []byte) {
+	if s.cache == nil {
+		return
+	}
+
+	if err := s.cache.Store(ctx, blockchain, network, req.Method, req.Params, data); err != nil {
+		log.Printf("[RPCCache] Failed to store %s result: %v", req.Method, err)
+	}
+}
+
+func (s *rpcService) Head(ctx context.Context, blockchain, network string) (uint64, bool) {
+	if s.cache == nil {
+		return 0, false
+	}
+	return s.cache.Head(ctx, blockchain, network)
+}
+
+// Stats returns request coalescing statistics
+func (s *rpcService) Stats() async.CoalescerStats {
+	return s.coalescer.Stats()
+}
+
+// pickNode returns the first node from offset (wrapping around) whose circuit
+// allows a request and its position, or -1 if every circuit is open
+func (s *rpcService) pickNode(nodes []*pb.NodeResponse, offset int) (*pb.NodeResponse, int) {
+	for i := 0; i < len(nodes); i++ {
+		position := (offset + i) % len(nodes)
+		if s.breaker.Allow(nodes[position].Url) {
+			return nodes[position], position
+		}
+	}
+	return nil, -1
+}
+
+// batchCall is a batch item on its way upstream
+type batchCall struct {
+	index int // position in the client's batch
+	req   *jsonrpc.Request
+	nodes []*pb.NodeResponse // nodes selected for the block the item reads
+	next  int                // position in nodes to try next
+}
+
+// ForwardBatch spreads batch items across the chain's nodes and returns the
+// replies in the original order. Each item is routed by the block it reads, and
+// the items for a node go to it as native JSON-RPC batches. Items of a batch
+// that fails are retried once on another node; an item that still gets no reply
+// gets its own JSON-RPC error instead of failing the whole batch.
+// Cached items are answered without going upstream.
+func (s *rpcService) ForwardBatch(ctx context.Context, blockchain, network string, items []json.RawMessage) ([]*jsonrpc.Response, error) {
+	responses := make([]*jsonrpc.Response, len(items))
+	calls := make([]*batchCall, 0, len(items))
+
+	// Node lists per block tag, so each distinct tag is resolved once
+	nodesByTag := make(map[string][]*pb.NodeResponse)
+	// sent counts items per tag for round-robin
+	sent := make(map[string]int)
+
+	for i, item := range items {
+		req, err := jsonrpc.ParseRequest(item)
+		if err != nil {
+			responses[i] = jsonrpc.NewErrorResponse(jsonrpc.RequestID(item), jsonrpc.InvalidRequest, "Invalid JSON-RPC request")
+			continue
+		}
+
+		if resp, ok := s.cached(ctx, blockchain, network, req); ok {
+			responses[i] = resp
+			continue
+		}
+
+		tag := jsonrpc.BlockTag(req.Method, req.Params)
+		nodes, exists := nodesByTag[tag]
+		if !exists {
+			nodes, err = s.getNodes(ctx, blockchain, network, tag)
+			if err != nil {
+				return nil, err
+			}
+			nodesByTag[tag] = nodes
+		}
+
+		// Round-robin items over the nodes selected for the tag
+		calls = append(calls, &batchCall{index: i, req: req, nodes: nodes, next: sent[tag] % len(nodes)})
+		sent[tag]++
+	}
+
+	for attempt := 0; attempt < maxBatchAttempts && len(calls) > 0; attempt++ {
+		calls = s.sendBatch(ctx, blockchain, network, calls, responses)
+	}
+	for _, call := range calls {
+		responses[call.index] = jsonrpc.NewErrorResponse(call.req.ID, jsonrpc.InternalError, "Upstream node request failed")
+	}
+
+	return responses, nil
+}
+
+// sendBatch sends each call to its next node, in native batches of at most
+// upstreamBatchSize calls, and fills in the replies. It returns the calls left
+// without a reply, each moved on to the node after the one it was sent to.
+func (s *rpcService) sendBatch(ctx context.Context, blockchain, network string, calls []*batchCall, responses []*jsonrpc.Response) []*batchCall {
+	// Calls per node, in the order the nodes are first used
+	byNode := make(map[string][]*batchCall)
+	var nodeURLs []string
+
+	for _, call := range calls {
+		node, position := s.pickNode(call.nodes, call.next)
+		if node == nil {
+			responses[call.index] = jsonrpc.NewErrorResponse(call.req.ID, jsonrpc.ServerError, async.ErrCircuitOpen.Error())
+			continue
+		}
+		call.next = (position + 1) % len(call.nodes)
+
+		if _, ok := byNode[node.Url]; !ok {
+			nodeURLs = append(nodeURLs, node.Url)
+		}
+		byNode[node.Url] = append(byNode[node.Url], call)
+	}
+
+	var requests []async.NodeRequest
+	var chunks [][]*batchCall
+	for _, nodeURL := range nodeURLs {
+		nodeCalls := byNode[nodeURL]
+		for start := 0; start < len(nodeCalls); start += upstreamBatchSize {
+			chunk := nodeCalls[start:min(start+upstreamBatchSize, len(nodeCalls))]
+			body, err := batchBody(chunk)
+			if err != nil {
+				continue // the chunk's calls get no reply
+			}
+			requests = append(requests, async.NodeRequest{NodeURL: nodeURL, Method: upstreamBatchMethod, Params: body})
+			chunks = append(chunks, chunk)
+		}
+	}
+
+	var failed []*batchCall
+	if len(requests) == 0 {
+		return failed
+	}
+
+	nodeResponses, err := s.requester.BatchRequest(ctx, "", requests)
+	if err != nil {
+		for _, chunk := range chunks {
+			failed = append(failed, chunk...)
+		}
+		return failed
+	}
+
+	for c, nodeResp := range nodeResponses {
+		var replies map[int]json.RawMessage
+		if nodeResp.Error == nil {
+			replies = batchReplies(nodeResp.Data)
+		}
+
+		for k, call := range chunks[c] {
+			reply, ok := replies[k]
+			if !ok {
+				failed = append(failed, call)
+				continue
+			}
+
+			var resp jsonrpc.Response
+			if err := json.Unmarshal(reply, &resp); err != nil {
+				failed = append(failed, call)
+				continue
+			}
+
+			// Back to the client's id
+			resp.ID = call.req.ID
+			if len(resp.ID) == 0 {
+				resp.ID = json.RawMessage("null")
+			}
+			responses[call.index] = &resp
+			s.store(ctx, blockchain, network, call.req, reply)
+		}
+	}
+
+	return failed
+}
+
+// batchBody encodes calls as a native JSON-RPC batch; each call's id is its
+// position in the batch, since the ids clients chose may repeat
+func batchBody(calls []*batchCall) ([]byte, error) {
+	batch := make([]*jsonrpc.Request, len(calls))
+	for k, call := range calls {
+		batch[k] = &jsonrpc.Request{
+			JSONRPC: jsonrpc.Version,
+			ID:      json.RawMessage(strconv.Itoa(k)),
+			Method:  call.req.Method,
+			Params:  call.req.Params,
+		}
+	}
+	return json.Marshal(batch)
+}
+
+// batchReplies indexes a node's reply to a native batch by the positions in its ids.
+// It returns nil if the reply is not a batch.
+func batchReplies(data []byte) map[int]json.RawMessage {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil
+	}
+
+	replies := make(map[int]json.RawMessage, len(items))
+	for _, item := range items {
+		if position, err := strconv.Atoi(string(jsonrpc.RequestID(item))); err == nil {
+			replies[position] = item
+		}
+	}
+	return replies
+}
+
+// doRequest posts a single JSON-RPC body to a node.
+// It implements async.RequestFunc; params holds the full request body.
+// A reply with an error status is returned as an *async.ReplyError.
+func (s *rpcService) doRequest(ctx context.Context, nodeURL string, method string, params []byte) ([]byte, int64, error) {
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, nodeURL, bytes.NewReader(params))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, time.Since(start).Milliseconds(), err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	responseTime := time.Since(start).Milliseconds()
+	if err != nil {
+		return nil, responseTime, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseTime, &async.ReplyError{
+			StatusCode:  resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        data,
+		}
+	}
+
+	return data, responseTime, nil
+}
+
+// getNodes asks Blockchain Service which nodes can serve a call for the given block
+func (s *rpcService) getNodes(ctx context.Context, blockchain, network, blockTag string) ([]*pb.NodeResponse, error) {
+	resp, err := s.blockchainClient.SelectNodes(ctx, &pb.SelectNodesRequest{
+		Type:     blockchain,
+		Network:  network,
+		BlockTag: blockTag,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Nodes) == 0 {
+		return nil, ErrNoNodes
+	}
+
+	return resp.Nodes, nil
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ironnode/pkg/async"
+	"ironnode/pkg/jsonrpc"
+	pb "ironnode/services/blockchain-service/proto"
+
+	"google.golang.org/grpc"
+)
+
+// fakeBlockchainClient selects the same nodes for every block
+type fakeBlockchainClient struct {
+	pb.BlockchainServiceClient
+	urls []string
+}
+
+func (c *fakeBlockchainClient) SelectNodes(ctx context.Context, in *pb.SelectNodesRequest, opts ...grpc.CallOption) (*pb.ListNodesResponse, error) {
+	resp := &pb.ListNodesResponse{}
+	for _, url := range c.urls {
+		resp.Nodes = append(resp.Nodes, &pb.NodeResponse{Url: url})
+	}
+	return resp, nil
+}
+
+// batchNode answers native batches in reverse order with the method as result;
+// a node that is down answers 503
+type batchNode struct {
+	server *httptest.Server
+	posts  atomic.Int32
+	down   bool
+}
+
+func newBatchNode(t *testing.T, down bool) *batchNode {
+	node := &batchNode{down: down}
+	node.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		node.posts.Add(1)
+		if node.down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var batch []jsonrpc.Request
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			t.Errorf("node got a body that is not a batch: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		replies := make([]jsonrpc.Response, 0, len(batch))
+		for i := len(batch) - 1; i >= 0; i-- {
+			result, _ := json.Marshal(batch[i].Method)
+			replies = append(replies, jsonrpc.Response{JSONRPC: jsonrpc.Version, ID: batch[i].ID, Result: result})
+		}
+		json.NewEncoder(w).Encode(replies)
+	}))
+	t.Cleanup(node.server.Close)
+	return node
+}
+
+func newBatchService(nodes ...*batchNode) RPCService {
+	var urls []string
+	for _, node := range nodes {
+		urls = append(urls, node.server.URL)
+	}
+	return NewRPCService(&fakeBlockchainClient{urls: urls}, &http.Client{Timeout: time.Second},
+		async.NewCircuitBreaker(async.DefaultCircuitBreakerConfig()), async.DefaultHedgeConfig(), nil)
+}
+
+func batchItems(items ...string) []json.RawMessage {
+	raw := make([]json.RawMessage, len(items))
+	for i, item := range items {
+		raw[i] = json.RawMessage(item)
+	}
+	return raw
+}
+
+func TestForwardBatchKeepsOrderAndIDs(t *testing.T) {
+	first, second := newBatchNode(t, false), newBatchNode(t, false)
+	rpcService := newBatchService(first, second)
+
+	items := batchItems(
+		`{"jsonrpc":"2.0","id":"a","method":"eth_chainId"}`,
+		`{"jsonrpc":"2.0","id":7,"method":"eth_gasPrice"}`,
+		`not a request`,
+		`{"jsonrpc":"2.0","id":7,"method":"net_version"}`,
+		`{"jsonrpc":"2.0","method":"eth_syncing"}`,
+	)
+	responses, err := rpcService.ForwardBatch(context.Background(), "ethereum", "mainnet", items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct{ id, result string }{
+		{`"a"`, `"eth_chainId"`},
+		{`7`, `"eth_gasPrice"`},
+		{`null`, ``},
+		{`7`, `"net_version"`},
+		{`null`, `"eth_syncing"`},
+	}
+	for i, w := range want {
+		if got := string(responses[i].ID); got != w.id {
+			t.Errorf("item %d id = %s, want %s", i, got, w.id)
+		}
+		if got := string(responses[i].Result); got != w.result {
+			t.Errorf("item %d result = %s, want %s", i, got, w.result)
+		}
+	}
+	if responses[2].Error == nil || responses[2].Error.Code != jsonrpc.InvalidRequest {
+		t.Errorf("invalid item error = %+v, want invalid request", responses[2].Error)
+	}
+
+	// One native batch per node, not one request per item
+	if posts := first.posts.Load() + second.posts.Load(); posts != 2 {
+		t.Errorf("%d upstream requests, want 2", posts)
+	}
+}
+
+func TestForwardBatchRetriesFailedNode(t *testing.T) {
+	down, up := newBatchNode(t, true), newBatchNode(t, false)
+	rpcService := newBatchService(down, up)
+
+	items := batchItems(
+		`{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"eth_gasPrice"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"net_version"}`,
+	)
+	responses, err := rpcService.ForwardBatch(context.Background(), "ethereum", "mainnet", items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, resp := range responses {
+		if resp.Error != nil {
+			t.Errorf("item %d failed: %s", i, resp.Error.Message)
+		}
+	}
+	if down.posts.Load() == 0 {
+		t.Error("no item was routed to the failing node")
+	}
+}
+
+func TestForwardBatchPartialFailure(t *testing.T) {
+	rpcService := newBatchService(newBatchNode(t, true))
+
+	items := batchItems(
+		`{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"eth_gasPrice"}`,
+	)
+	responses, err := rpcService.ForwardBatch(context.Background(), "ethereum", "mainnet", items)
+	if err != nil {
+		t.Fatalf("a failed node failed the whole batch: %v", err)
+	}
+
+	for i, resp := range responses {
+		if resp.Error == nil || resp.Error.Code != jsonrpc.InternalError {
+			t.Errorf("item %d error = %+v, want an internal error", i, resp.Error)
+		}
+		if want := string(items[i]); string(resp.ID) != string(jsonrpc.RequestID([]byte(want))) {
+			t.Errorf("item %d id = %s, want the client's", i, resp.ID)
+		}
+	}
+}
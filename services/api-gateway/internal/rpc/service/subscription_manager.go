@@ -0,0 +1,466 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"ironnode/pkg/cache"
+	"ironnode/pkg/jsonrpc"
+	pb "ironnode/services/blockchain-service/proto"
+)
+
+// ErrSubscriptionNotFound is returned when unsubscribing an unknown subscription
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+const (
+	resubscribeMinBackoff = 1 * time.Second
+	resubscribeMaxBackoff = 30 * time.Second
+)
+
+// SubscriptionSink receives notifications of a client subscription.
+// It is called with internal locks held and must not block.
+type SubscriptionSink func(notification []byte)
+
+// SubscriptionManager multiplexes client eth_subscribe subscriptions onto shared
+// upstream subscriptions. Clients with the same filter on the same chain and network
+// share one upstream subscription; client subscription IDs survive node failover.
+type SubscriptionManager interface {
+	Subscribe(ctx context.Context, blockchain, network string, params json.RawMessage, sink SubscriptionSink) (string, error)
+	Unsubscribe(subscriptionID string) error
+}
+
+type subscriptionManager struct {
+	blockchainClient pb.BlockchainServiceClient
+
+	mu     sync.Mutex
+	hubs   map[string]*subscriptionHub // keyed by "blockchain/network"
+	owners map[string]*subscriptionHub // client subscription ID -> hub
+}
+
+func NewSubscriptionManager(blockchainClient pb.BlockchainServiceClient) SubscriptionManager {
+	return &subscriptionManager{
+		blockchainClient: blockchainClient,
+		hubs:             make(map[string]*subscriptionHub),
+		owners:           make(map[string]*subscriptionHub),
+	}
+}
+
+// Subscribe registers a client subscription and returns its ID
+func (m *subscriptionManager) Subscribe(ctx context.Context, blockchain, network string, params json.RawMessage, sink SubscriptionSink) (string, error) {
+	hub := m.hub(blockchain, network)
+
+	id, err := hub.subscribe(ctx, params, sink)
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	m.owners[id] = hub
+	m.mu.Unlock()
+
+	return id, nil
+}
+
+// Unsubscribe removes a client subscription
+func (m *subscriptionManager) Unsubscribe(subscriptionID string) error {
+	m.mu.Lock()
+	hub, ok := m.owners[subscriptionID]
+	delete(m.owners, subscriptionID)
+	m.mu.Unlock()
+
+	if !ok {
+		return ErrSubscriptionNotFound
+	}
+
+	hub.unsubscribe(subscriptionID)
+	return nil
+}
+
+func (m *subscriptionManager) hub(blockchain, network string) *subscriptionHub {
+	key := blockchain + "/" + network
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hub, exists := m.hubs[key]
+	if !exists {
+		hub = &subscriptionHub{
+			blockchainClient: m.blockchainClient,
+			blockchain:       blockchain,
+			network:          network,
+			shared:           make(map[string]*sharedSubscription),
+			byUpstream:       make(map[string]*sharedSubscription),
+			byClient:         make(map[string]*sharedSubscription),
+		}
+		m.hubs[key] = hub
+	}
+
+	return hub
+}
+
+// sharedSubscription is one upstream subscription fanned out to many clients
+type sharedSubscription struct {
+	key         string
+	params      json.RawMessage
+	upstreamID  string
+	subscribers map[string]SubscriptionSink // client subscription ID -> sink
+
+	ready chan struct{} // closed once the upstream subscription is confirmed
+	err   error
+}
+
+// subscriptionHub holds the upstream connection and subscriptions of one chain and network
+type subscriptionHub struct {
+	blockchainClient pb.BlockchainServiceClient
+	blockchain       string
+	network          string
+
+	// connMu serializes connecting; it is never held while mu is wanted by the read loop
+	connMu       sync.Mutex
+	conn         *upstreamConn
+	reconnecting bool
+
+	mu         sync.Mutex
+	shared     map[string]*sharedSubscription // filter key -> subscription
+	byUpstream map[string]*sharedSubscription // upstream subscription ID -> subscription
+	byClient   map[string]*sharedSubscription // client subscription ID -> subscription
+}
+
+func (h *subscriptionHub) subscribe(ctx context.Context, params json.RawMessage, sink SubscriptionSink) (string, error) {
+	key := cache.Key(h.blockchain, h.network, "eth_subscribe", params)
+	clientID := newSubscriptionID()
+
+	h.mu.Lock()
+	sub, exists := h.shared[key]
+	if !exists {
+		sub = &sharedSubscription{
+			key:         key,
+			params:      params,
+			subscribers: make(map[string]SubscriptionSink),
+			ready:       make(chan struct{}),
+		}
+		h.shared[key] = sub
+	}
+	sub.subscribers[clientID] = sink
+	h.byClient[clientID] = sub
+	h.mu.Unlock()
+
+	if exists {
+		// Another client already created the upstream subscription
+		select {
+		case <-sub.ready:
+		case <-ctx.Done():
+			h.unsubscribe(clientID)
+			return "", ctx.Err()
+		}
+		if sub.err != nil {
+			h.unsubscribe(clientID)
+			return "", sub.err
+		}
+		return clientID, nil
+	}
+
+	err := h.subscribeUpstream(ctx, sub)
+
+	h.mu.Lock()
+	sub.err = err
+	close(sub.ready)
+	if err != nil {
+		for id := range sub.subscribers {
+			delete(h.byClient, id)
+		}
+		delete(h.shared, key)
+	}
+	h.mu.Unlock()
+
+	if err != nil {
+		return "", err
+	}
+
+	return clientID, nil
+}
+
+// subscribeUpstream creates the upstream subscription on the current connection
+func (h *subscriptionHub) subscribeUpstream(ctx context.Context, sub *sharedSubscription) error {
+	conn, err := h.connection(ctx)
+	if err != nil {
+		return err
+	}
+
+	return h.register(ctx, conn, sub)
+}
+
+// register sends eth_subscribe for a shared subscription and records its upstream ID
+func (h *subscriptionHub) register(ctx context.Context, conn *upstreamConn, sub *sharedSubscription) error {
+	result, err := conn.call(ctx, "eth_subscribe", sub.params)
+	if err != nil {
+		return err
+	}
+
+	var upstreamID string
+	if err := json.Unmarshal(result, &upstreamID); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if sub.upstreamID != "" {
+		delete(h.byUpstream, sub.upstreamID)
+	}
+	sub.upstreamID = upstreamID
+	h.byUpstream[upstreamID] = sub
+
+	return nil
+}
+
+func (h *subscriptionHub) unsubscribe(clientID string) {
+	h.mu.Lock()
+	sub, ok := h.byClient[clientID]
+	if !ok {
+		h.mu.Unlock()
+		return
+	}
+	delete(h.byClient, clientID)
+	delete(sub.subscribers, clientID)
+
+	if len(sub.subscribers) > 0 || h.shared[sub.key] != sub {
+		h.mu.Unlock()
+		return
+	}
+
+	// Last client left: drop the upstream subscription
+	delete(h.shared, sub.key)
+	upstreamID := sub.upstreamID
+	if upstreamID != "" {
+		delete(h.byUpstream, upstreamID)
+	}
+	h.mu.Unlock()
+
+	if upstreamID == "" {
+		return
+	}
+
+	h.connMu.Lock()
+	conn := h.conn
+	h.connMu.Unlock()
+
+	if conn != nil {
+		go conn.call(context.Background(), "eth_unsubscribe", []string{upstreamID})
+	}
+}
+
+// dispatch fans an upstream notification out to every client of the subscription
+func (h *subscriptionHub) dispatch(upstreamID string, result json.RawMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub, ok := h.byUpstream[upstreamID]
+	if !ok {
+		return
+	}
+
+	for clientID, sink := range sub.subscribers {
+		notification, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": jsonrpc.Version,
+			"method":  "eth_subscription",
+			"params": map[string]interface{}{
+				"subscription": clientID,
+				"result":       result,
+			},
+		})
+		if err != nil {
+			continue
+		}
+		sink(notification)
+	}
+}
+
+// connection returns the hub's upstream connection, dialing the best node if needed
+func (h *subscriptionHub) connection(ctx context.Context) (*upstreamConn, error) {
+	h.connMu.Lock()
+	defer h.connMu.Unlock()
+
+	if h.conn != nil {
+		return h.conn, nil
+	}
+
+	conn, err := h.dial(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	h.conn = conn
+	return conn, nil
+}
+
+// dial connects to the first reachable node, trying skipURL last
+func (h *subscriptionHub) dial(ctx context.Context, skipURL string) (*upstreamConn, error) {
+	resp, err := h.blockchainClient.SelectNodes(ctx, &pb.SelectNodesRequest{
+		Type:     h.blockchain,
+		Network:  h.network,
+		BlockTag: "latest",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Nodes) == 0 {
+		return nil, ErrNoNodes
+	}
+
+	urls := make([]string, 0, len(resp.Nodes))
+	var skipped []string
+	for _, node := range resp.Nodes {
+		url := wsURL(node)
+		if url == skipURL {
+			skipped = append(skipped, url)
+			continue
+		}
+		urls = append(urls, url)
+	}
+	urls = append(urls, skipped...)
+
+	var lastErr error
+	for _, url := range urls {
+		conn, err := dialUpstream(ctx, url, h.dispatch, h.onDisconnect)
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
+
+// onDisconnect is called when the upstream connection drops
+func (h *subscriptionHub) onDisconnect(conn *upstreamConn) {
+	h.connMu.Lock()
+	defer h.connMu.Unlock()
+
+	if h.conn != conn {
+		return
+	}
+	h.conn = nil
+
+	if !h.reconnecting {
+		h.reconnecting = true
+		go h.resubscribe(conn.url)
+	}
+}
+
+// resubscribe fails over to another node and restores every shared subscription.
+// Client subscription IDs do not change, so clients keep receiving notifications.
+func (h *subscriptionHub) resubscribe(failedURL string) {
+	backoff := resubscribeMinBackoff
+
+	for {
+		h.mu.Lock()
+		subs := make([]*sharedSubscription, 0, len(h.shared))
+		for _, sub := range h.shared {
+			subs = append(subs, sub)
+		}
+		h.mu.Unlock()
+
+		if len(subs) == 0 || h.restore(failedURL, subs, backoff) {
+			h.connMu.Lock()
+			// The new connection may have dropped already; keep going in that case
+			done := len(subs) == 0 || h.conn != nil
+			if done {
+				h.reconnecting = false
+			}
+			h.connMu.Unlock()
+
+			if done {
+				return
+			}
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > resubscribeMaxBackoff {
+			backoff = resubscribeMaxBackoff
+		}
+	}
+}
+
+// restore connects to a healthy node and re-registers the subscriptions on it
+func (h *subscriptionHub) restore(failedURL string, subs []*sharedSubscription, backoff time.Duration) bool {
+	ctx := context.Background()
+
+	conn, err := h.reconnect(ctx, failedURL)
+	if err == nil {
+		for _, sub := range subs {
+			if err = h.register(ctx, conn, sub); err != nil {
+				conn.close()
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		log.Printf("[Subscriptions] %s/%s: failover failed, retrying in %v: %v", h.blockchain, h.network, backoff, err)
+		return false
+	}
+
+	log.Printf("[Subscriptions] %s/%s: restored %d subscriptions on %s after losing %s", h.blockchain, h.network, len(subs), conn.url, failedURL)
+	return true
+}
+
+// reconnect returns the current connection or dials a new one, avoiding failedURL if possible
+func (h *subscriptionHub) reconnect(ctx context.Context, failedURL string) (*upstreamConn, error) {
+	h.connMu.Lock()
+	conn := h.conn
+	h.connMu.Unlock()
+
+	if conn != nil {
+		return conn, nil
+	}
+
+	conn, err := h.dial(ctx, failedURL)
+	if err != nil {
+		return nil, err
+	}
+
+	h.connMu.Lock()
+	if h.conn == nil {
+		h.conn = conn
+		h.connMu.Unlock()
+		return conn, nil
+	}
+	// A subscribe call connected in the meantime; use its connection
+	existing := h.conn
+	h.connMu.Unlock()
+
+	conn.close()
+	return existing, nil
+}
+
+// wsURL returns the node's WebSocket endpoint, derived from its HTTP URL if not set
+func wsURL(node *pb.NodeResponse) string {
+	if node.WsUrl != "" {
+		return node.WsUrl
+	}
+
+	switch {
+	case strings.HasPrefix(node.Url, "https://"):
+		return "wss://" + strings.TrimPrefix(node.Url, "https://")
+	case strings.HasPrefix(node.Url, "http://"):
+		return "ws://" + strings.TrimPrefix(node.Url, "http://")
+	default:
+		return node.Url
+	}
+}
+
+// newSubscriptionID generates a client subscription ID in the node format (0x + 32 hex)
+func newSubscriptionID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "0x" + hex.EncodeToString(b)
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"ironnode/pkg/jsonrpc"
+
+	"github.com/gorilla/websocket"
+)
+
+// upstreamSubscription is an eth_subscribe a wsNode accepted
+type upstreamSubscription struct {
+	id     string
+	params string
+}
+
+// wsNode is a WebSocket node that accepts every eth_subscribe and sends
+// notifications on demand
+type wsNode struct {
+	server *httptest.Server
+
+	mu     sync.Mutex // guards conns and serializes writes
+	conns  []*websocket.Conn
+	nextID int
+
+	subscribed   chan upstreamSubscription
+	unsubscribed chan string
+}
+
+func newWSNode(t *testing.T) *wsNode {
+	node := &wsNode{
+		subscribed:   make(chan upstreamSubscription, 16),
+		unsubscribed: make(chan string, 16),
+	}
+	upgrader := websocket.Upgrader{}
+
+	node.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		node.mu.Lock()
+		node.conns = append(node.conns, conn)
+		node.mu.Unlock()
+
+		for {
+			var req jsonrpc.Request
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+
+			var result interface{} = true
+			switch req.Method {
+			case "eth_subscribe":
+				node.mu.Lock()
+				node.nextID++
+				id := fmt.Sprintf("0x%x", node.nextID)
+				node.mu.Unlock()
+				result = id
+				node.subscribed <- upstreamSubscription{id: id, params: string(req.Params)}
+			case "eth_unsubscribe":
+				var ids []string
+				json.Unmarshal(req.Params, &ids)
+				node.unsubscribed <- ids[0]
+			}
+
+			node.mu.Lock()
+			conn.WriteJSON(map[string]interface{}{"jsonrpc": jsonrpc.Version, "id": req.ID, "result": result})
+			node.mu.Unlock()
+		}
+	}))
+	t.Cleanup(func() {
+		node.drop()
+		node.server.Close()
+	})
+	return node
+}
+
+// notify sends a notification of an upstream subscription on the latest connection
+func (n *wsNode) notify(upstreamID string, result string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.conns[len(n.conns)-1].WriteJSON(map[string]interface{}{
+		"jsonrpc": jsonrpc.Version,
+		"method":  "eth_subscription",
+		"params":  map[string]interface{}{"subscription": upstreamID, "result": json.RawMessage(result)},
+	})
+}
+
+// drop closes every connection as a node going down would
+func (n *wsNode) drop() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for _, conn := range n.conns {
+		conn.Close()
+	}
+}
+
+// subscribedOn waits for the node to accept an eth_subscribe
+func subscribedOn(t *testing.T, node *wsNode) upstreamSubscription {
+	t.Helper()
+	select {
+	case sub := <-node.subscribed:
+		return sub
+	case <-time.After(5 * time.Second):
+		t.Fatal("node got no eth_subscribe")
+		return upstreamSubscription{}
+	}
+}
+
+// notificationSink collects the notifications of a client subscription
+func notificationSink() (SubscriptionSink, chan []byte) {
+	received := make(chan []byte, 16)
+	return func(notification []byte) { received <- notification }, received
+}
+
+// receive waits for a notification and returns its subscription ID and result
+func receive(t *testing.T, received chan []byte) (string, string) {
+	t.Helper()
+	select {
+	case data := <-received:
+		var notification struct {
+			Method string `json:"method"`
+			Params struct {
+				Subscription string          `json:"subscription"`
+				Result       json.RawMessage `json:"result"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(data, &notification); err != nil || notification.Method != "eth_subscription" {
+			t.Fatalf("not a subscription notification: %s", data)
+		}
+		return notification.Params.Subscription, string(notification.Params.Result)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification received")
+		return "", ""
+	}
+}
+
+func TestSubscriptionsWithSameFilterShareUpstream(t *testing.T) {
+	node := newWSNode(t)
+	manager := NewSubscriptionManager(&fakeBlockchainClient{urls: []string{node.server.URL}})
+	ctx := context.Background()
+
+	sinkA, receivedA := notificationSink()
+	sinkB, receivedB := notificationSink()
+	sinkLogs, receivedLogs := notificationSink()
+
+	idA, err := manager.Subscribe(ctx, "ethereum", "mainnet", json.RawMessage(`["newHeads"]`), sinkA)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	heads := subscribedOn(t, node)
+
+	idB, err := manager.Subscribe(ctx, "ethereum", "mainnet", json.RawMessage(`["newHeads"]`), sinkB)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if _, err := manager.Subscribe(ctx, "ethereum", "mainnet", json.RawMessage(`["logs",{"address":"0x1"}]`), sinkLogs); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if logs := subscribedOn(t, node); logs.params == heads.params {
+		t.Fatalf("logs filter sent as %s", logs.params)
+	}
+	if idA == idB {
+		t.Fatalf("clients share the subscription ID %s", idA)
+	}
+
+	// One upstream notification reaches both clients of the filter under their own IDs
+	node.notify(heads.id, `{"number":"0x1"}`)
+	for _, client := range []struct {
+		id       string
+		received chan []byte
+	}{{idA, receivedA}, {idB, receivedB}} {
+		if id, result := receive(t, client.received); id != client.id || result != `{"number":"0x1"}` {
+			t.Errorf("notification for %s, result %s; want %s", id, result, client.id)
+		}
+	}
+	select {
+	case data := <-receivedLogs:
+		t.Errorf("logs client got a newHeads notification: %s", data)
+	default:
+	}
+	select {
+	case sub := <-node.subscribed:
+		t.Errorf("second newHeads client subscribed upstream again: %+v", sub)
+	default:
+	}
+
+	// The upstream subscription is kept until its last client leaves
+	if err := manager.Unsubscribe(idA); err != nil {
+		t.Fatalf("Unsubscribe: %v", err)
+	}
+	if err := manager.Unsubscribe(idB); err != nil {
+		t.Fatalf("Unsubscribe: %v", err)
+	}
+	select {
+	case id := <-node.unsubscribed:
+		if id != heads.id {
+			t.Errorf("unsubscribed %s upstream, want %s", id, heads.id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("last client left without an upstream eth_unsubscribe")
+	}
+	select {
+	case id := <-node.unsubscribed:
+		t.Errorf("unsubscribed %s upstream more than once", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := manager.Unsubscribe(idA); !errors.Is(err, ErrSubscriptionNotFound) {
+		t.Errorf("second Unsubscribe = %v, want ErrSubscriptionNotFound", err)
+	}
+}
+
+func TestSubscriptionsResubscribeOnFailover(t *testing.T) {
+	primary, backup := newWSNode(t), newWSNode(t)
+	manager := NewSubscriptionManager(&fakeBlockchainClient{urls: []string{primary.server.URL, backup.server.URL}})
+
+	sink, received := notificationSink()
+	id, err := manager.Subscribe(context.Background(), "ethereum", "mainnet", json.RawMessage(`["newHeads"]`), sink)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	subscribedOn(t, primary)
+
+	primary.drop()
+
+	restored := subscribedOn(t, backup)
+	if restored.params != `["newHeads"]` {
+		t.Errorf("restored filter %s, want [\"newHeads\"]", restored.params)
+	}
+
+	// The client keeps its subscription ID across the failover
+	backup.notify(restored.id, `{"number":"0x2"}`)
+	if got, result := receive(t, received); got != id || result != `{"number":"0x2"}` {
+		t.Errorf("notification for %s, result %s; want %s", got, result, id)
+	}
+}
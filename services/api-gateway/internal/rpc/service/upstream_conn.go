@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ironnode/pkg/jsonrpc"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	upstreamDialTimeout  = 10 * time.Second
+	upstreamCallTimeout  = 15 * time.Second
+	upstreamPingInterval = 30 * time.Second
+	upstreamPongWait     = 2 * upstreamPingInterval
+	upstreamWriteWait    = 10 * time.Second
+)
+
+var errUpstreamClosed = errors.New("upstream connection closed")
+
+// upstreamConn is a WebSocket JSON-RPC connection to a single node
+type upstreamConn struct {
+	url string
+	ws  *websocket.Conn
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan *jsonrpc.Response
+
+	closeOnce sync.Once
+	closed    chan struct{}
+
+	onNotify func(upstreamID string, result json.RawMessage)
+	onClose  func(conn *upstreamConn)
+}
+
+// dialUpstream connects to a node and starts reading from it
+func dialUpstream(ctx context.Context, url string, onNotify func(string, json.RawMessage), onClose func(*upstreamConn)) (*upstreamConn, error) {
+	ctx, cancel := context.WithTimeout(ctx, upstreamDialTimeout)
+	defer cancel()
+
+	ws, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &upstreamConn{
+		url:      url,
+		ws:       ws,
+		pending:  make(map[uint64]chan *jsonrpc.Response),
+		closed:   make(chan struct{}),
+		onNotify: onNotify,
+		onClose:  onClose,
+	}
+
+	go conn.readLoop()
+	go conn.pingLoop()
+
+	return conn, nil
+}
+
+// call sends a request and waits for its response
+func (c *upstreamConn) call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, upstreamCallTimeout)
+	defer cancel()
+
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	respChan := make(chan *jsonrpc.Response, 1)
+	c.pending[id] = respChan
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	err := c.write(map[string]interface{}{
+		"jsonrpc": jsonrpc.Version,
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case resp := <-respChan:
+		if resp.Error != nil {
+			return nil, fmt.Errorf("%s: %s (code %d)", method, resp.Error.Message, resp.Error.Code)
+		}
+		return resp.Result, nil
+	case <-c.closed:
+		return nil, errUpstreamClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *upstreamConn) write(message interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.ws.SetWriteDeadline(time.Now().Add(upstreamWriteWait))
+	return c.ws.WriteJSON(message)
+}
+
+// readLoop dispatches responses to waiting calls and notifications to onNotify
+func (c *upstreamConn) readLoop() {
+	defer c.close()
+
+	c.ws.SetReadDeadline(time.Now().Add(upstreamPongWait))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(upstreamPongWait))
+	})
+
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.ws.SetReadDeadline(time.Now().Add(upstreamPongWait))
+
+		var msg struct {
+			jsonrpc.Response
+			Method string `json:"method"`
+			Params struct {
+				Subscription string          `json:"subscription"`
+				Result       json.RawMessage `json:"result"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+
+		if strings.HasSuffix(msg.Method, "_subscription") {
+			c.onNotify(msg.Params.Subscription, msg.Params.Result)
+			continue
+		}
+
+		var id uint64
+		if err := json.Unmarshal(msg.ID, &id); err != nil {
+			continue
+		}
+
+		c.mu.Lock()
+		respChan, ok := c.pending[id]
+		c.mu.Unlock()
+
+		if ok {
+			resp := msg.Response
+			respChan <- &resp
+		}
+	}
+}
+
+// pingLoop keeps the connection alive and detects dead nodes
+func (c *upstreamConn) pingLoop() {
+	ticker := time.NewTicker(upstreamPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-ticker.C:
+			c.writeMu.Lock()
+			err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(upstreamWriteWait))
+			c.writeMu.Unlock()
+			if err != nil {
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+// close shuts the connection down and notifies the owner once
+func (c *upstreamConn) close() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.ws.Close()
+		if c.onClose != nil {
+			c.onClose(c)
+		}
+	})
+}
@@ -0,0 +1,452 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"ironnode/pkg/cache"
+	"ironnode/pkg/models"
+	pb "ironnode/services/billing-service/proto"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// planCacheTTL is how long a user's subscription is reused before asking Billing Service again
+	planCacheTTL       = time.Minute
+	billingCallTimeout = 5 * time.Second
+	// freePlanCredits is the monthly allowance of users without a subscription,
+	// matching the free plan in Billing Service
+	freePlanCredits = 10000
+	// flushBatchSize limits how many users are drained from Redis at once
+	flushBatchSize = 500
+)
+
+// Quota is a user's credit allowance for the current billing period
+type Quota struct {
+	Plan    models.PlanType
+	Limit   int64
+	Used    int64
+	Prepaid int64 // prepaid credits, looked up once the allowance is used up
+	ResetAt time.Time
+}
+
+// Remaining returns the credits left in the period, or the prepaid credits once it is used up
+func (q *Quota) Remaining() int64 {
+	if q.Used >= q.Limit {
+		return max(q.Prepaid, 0)
+	}
+	return q.Limit - q.Used
+}
+
+// Exceeded reports whether the period allowance and the prepaid credits are used up
+func (q *Quota) Exceeded() bool {
+	return q.Used >= q.Limit && q.Prepaid <= 0
+}
+
+// KeyLimits are the optional limits of an API key; zero means no limit
+type KeyLimits struct {
+	MonthlyCredits    int64 // credits per billing period of the key's user
+	RequestsPerSecond int64
+}
+
+// KeyQuota is an API key's usage against its own limits
+type KeyQuota struct {
+	KeyLimits
+	Used    int64 // credits spent with the key in the billing period
+	Calls   int64 // calls made with the key in the current second, this one included
+	ResetAt time.Time
+}
+
+// Remaining returns the credits the key may still spend in the period
+func (q *KeyQuota) Remaining() int64 {
+	return max(q.MonthlyCredits-q.Used, 0)
+}
+
+// Exceeded reports whether the key has spent its monthly credits
+func (q *KeyQuota) Exceeded() bool {
+	return q.MonthlyCredits > 0 && q.Used >= q.MonthlyCredits
+}
+
+// RateLimited reports whether the key made more calls this second than it may
+func (q *KeyQuota) RateLimited() bool {
+	return q.RequestsPerSecond > 0 && q.Calls > q.RequestsPerSecond
+}
+
+// UsageService resolves a user's plan and quota and records the credits their calls consume
+type UsageService interface {
+	Plan(ctx context.Context, userID string) models.PlanType
+	// Quota returns the allowance of calls made with apiKeyID, which may have prepaid credits of its own
+	Quota(ctx context.Context, userID, apiKeyID string) (*Quota, error)
+	// KeyQuota counts a call made with apiKeyID and returns its usage against limits.
+	// The per-second limit needs the Redis counters; without them calls are not counted.
+	KeyQuota(ctx context.Context, userID, apiKeyID string, limits KeyLimits) (*KeyQuota, error)
+	RecordUsage(userID, apiKeyID, blockchain string, credits int64)
+	Start()
+	Stop()
+}
+
+// subscriptionInfo is the part of a subscription the gateway needs per request
+type subscriptionInfo struct {
+	plan        models.PlanType
+	limit       int64
+	used        int64 // as last stored by Billing Service
+	periodStart time.Time
+	periodEnd   time.Time
+	subscribed  bool // prepaid credits are only spent on top of a subscription
+	expiresAt   time.Time
+}
+
+// keyInfo is the prepaid credit balance of a user and API key, and the credits
+// spent with the key in the period, as last seen by Billing Service
+type keyInfo struct {
+	prepaid   int64
+	used      int64
+	expiresAt time.Time
+}
+
+type usageService struct {
+	billingClient pb.BillingServiceClient
+	// counter is nil when Redis is unavailable; usage then goes straight to Billing Service
+	counter       *cache.QuotaCounter
+	flushInterval time.Duration
+
+	mu            sync.RWMutex
+	subscriptions map[string]*subscriptionInfo
+	keys          map[string]*keyInfo // by user and API key
+
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewUsageService creates a usage service. Usage counted in Redis is written
+// to Billing Service every flushInterval once Start is called.
+func NewUsageService(billingClient pb.BillingServiceClient, counter *cache.QuotaCounter, flushInterval time.Duration) UsageService {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &usageService{
+		billingClient: billingClient,
+		counter:       counter,
+		flushInterval: flushInterval,
+		subscriptions: make(map[string]*subscriptionInfo),
+		keys:          make(map[string]*keyInfo),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// Plan returns the user's active plan; users without a subscription are on the free plan
+func (s *usageService) Plan(ctx context.Context, userID string) models.PlanType {
+	return s.subscription(ctx, userID).plan
+}
+
+// Quota returns the user's allowance and usage for the current period
+func (s *usageService) Quota(ctx context.Context, userID, apiKeyID string) (*Quota, error) {
+	sub := s.subscription(ctx, userID)
+
+	quota := &Quota{
+		Plan:    sub.plan,
+		Limit:   sub.limit,
+		Used:    sub.used,
+		ResetAt: sub.periodEnd,
+	}
+
+	if s.counter != nil && userID != "" {
+		used, err := s.counter.Used(ctx, userID, sub.periodStart)
+		if err != nil {
+			return quota, err
+		}
+		// The counter starts over if Redis loses it; the database still has the flushed usage
+		quota.Used = max(quota.Used, used)
+	}
+
+	if quota.Used >= quota.Limit && sub.subscribed {
+		quota.Prepaid = s.keyInfo(ctx, userID, apiKeyID).prepaid
+	}
+
+	return quota, nil
+}
+
+func (s *usageService) KeyQuota(ctx context.Context, userID, apiKeyID string, limits KeyLimits) (*KeyQuota, error) {
+	quota := &KeyQuota{KeyLimits: limits}
+	if apiKeyID == "" {
+		return quota, nil
+	}
+
+	if limits.MonthlyCredits > 0 {
+		sub := s.subscription(ctx, userID)
+		quota.ResetAt = sub.periodEnd
+		quota.Used = s.keyInfo(ctx, userID, apiKeyID).used
+	}
+
+	if s.counter == nil {
+		return quota, nil
+	}
+
+	if limits.RequestsPerSecond > 0 {
+		calls, err := s.counter.CountCall(ctx, apiKeyID, time.Now())
+		if err != nil {
+			return quota, err
+		}
+		quota.Calls = calls
+	}
+
+	if limits.MonthlyCredits > 0 {
+		used, err := s.counter.KeyUsed(ctx, apiKeyID, s.subscription(ctx, userID).periodStart)
+		if err != nil {
+			return quota, err
+		}
+		// As with the user's usage, the database still has what was flushed if Redis lost the counter
+		quota.Used = max(quota.Used, used)
+	}
+
+	return quota, nil
+}
+
+// keyInfo returns the cached prepaid balance and period usage of calls made with
+// apiKeyID, refreshing them from Billing Service when stale. Both are written when
+// usage is flushed, so they can trail by up to planCacheTTL plus a flush interval.
+func (s *usageService) keyInfo(ctx context.Context, userID, apiKeyID string) *keyInfo {
+	now := time.Now()
+	cacheKey := userID + "/" + apiKeyID
+
+	s.mu.RLock()
+	cached, ok := s.keys[cacheKey]
+	s.mu.RUnlock()
+
+	if ok && now.Before(cached.expiresAt) {
+		return cached
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, billingCallTimeout)
+	defer cancel()
+
+	info := &keyInfo{expiresAt: now.Add(planCacheTTL)}
+	resp, err := s.billingClient.CheckQuota(ctx, &pb.CheckQuotaRequest{
+		UserId:   userID,
+		ApiKeyId: apiKeyID,
+	})
+	switch {
+	case err == nil:
+		info.prepaid = resp.PrepaidCredits
+		info.used = resp.KeyUsed
+	case ok:
+		// Billing Service is unreachable; keep the last known balance and usage
+		info.prepaid = cached.prepaid
+		info.used = cached.used
+	}
+
+	s.mu.Lock()
+	s.keys[cacheKey] = info
+	s.mu.Unlock()
+
+	return info
+}
+
+// subscription returns the user's cached subscription, refreshing it from Billing Service when stale
+func (s *usageService) subscription(ctx context.Context, userID string) *subscriptionInfo {
+	now := time.Now()
+
+	s.mu.RLock()
+	cached, ok := s.subscriptions[userID]
+	s.mu.RUnlock()
+
+	if ok && now.Before(cached.expiresAt) {
+		return cached
+	}
+
+	info := freeSubscription(now)
+
+	if userID != "" {
+		ctx, cancel := context.WithTimeout(ctx, billingCallTimeout)
+		defer cancel()
+
+		resp, err := s.billingClient.GetSubscription(ctx, &pb.GetSubscriptionRequest{
+			UserId: userID,
+		})
+		switch {
+		case err == nil && resp.IsActive:
+			info = &subscriptionInfo{
+				plan:        models.PlanType(resp.PlanType),
+				limit:       int64(resp.RequestsPerMonth),
+				used:        int64(resp.RequestsUsed),
+				periodStart: time.Unix(resp.PeriodStart, 0),
+				periodEnd:   time.Unix(resp.PeriodEnd, 0),
+				subscribed:  true,
+			}
+		case err != nil && status.Code(err) != codes.NotFound && ok:
+			// Billing Service is unreachable; keep serving the last known subscription
+			copied := *cached
+			info = &copied
+		}
+	}
+	info.expiresAt = now.Add(planCacheTTL)
+
+	s.mu.Lock()
+	s.subscriptions[userID] = info
+	s.mu.Unlock()
+
+	return info
+}
+
+// freeSubscription describes a user without a subscription; their periods are calendar months (UTC)
+func freeSubscription(now time.Time) *subscriptionInfo {
+	now = now.UTC()
+	periodStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	return &subscriptionInfo{
+		plan:        models.FreePlan,
+		limit:       freePlanCredits,
+		periodStart: periodStart,
+		periodEnd:   periodStart.AddDate(0, 1, 0),
+	}
+}
+
+// RecordUsage charges credits spent with an API key on a blockchain to the user's current period
+func (s *usageService) RecordUsage(userID, apiKeyID, blockchain string, credits int64) {
+	if userID == "" || credits <= 0 {
+		return
+	}
+
+	byChain := map[string]int64{blockchain: credits}
+
+	if s.counter == nil {
+		go s.incrementUsage(userID, apiKeyID, byChain, uuid.NewString())
+		return
+	}
+
+	sub := s.subscription(context.Background(), userID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), billingCallTimeout)
+	defer cancel()
+
+	if _, err := s.counter.Add(ctx, userID, apiKeyID, blockchain, sub.periodStart, sub.periodEnd, credits); err != nil {
+		log.Printf("[Usage] Failed to count %d credits for user %s: %v", credits, userID, err)
+		go s.incrementUsage(userID, apiKeyID, byChain, uuid.NewString())
+	}
+}
+
+// incrementUsage writes credits to Subscription.RequestsUsed through Billing Service,
+// which spends prepaid credits of the user and API key on anything over the allowance.
+// Billing Service counts each idempotencyKey once, so a retried write is not charged twice.
+func (s *usageService) incrementUsage(userID, apiKeyID string, byChain map[string]int64, idempotencyKey string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), billingCallTimeout)
+	defer cancel()
+
+	var credits int64
+	for _, chainCredits := range byChain {
+		credits += chainCredits
+	}
+
+	_, err := s.billingClient.IncrementUsage(ctx, &pb.IncrementUsageRequest{
+		UserId:            userID,
+		Credits:           credits,
+		BlockchainCredits: byChain,
+		ApiKeyId:          apiKeyID,
+		IdempotencyKey:    idempotencyKey,
+	})
+	if err != nil {
+		log.Printf("[Usage] Failed to record %d credits for user %s: %v", credits, userID, err)
+	}
+	return err
+}
+
+// Start runs the flush loop in a background goroutine
+func (s *usageService) Start() {
+	if s.counter == nil {
+		return
+	}
+
+	s.wg.Add(1)
+	go s.run()
+	log.Printf("[Usage] Started (flush interval: %v)", s.flushInterval)
+}
+
+// Stop flushes outstanding usage and stops the flush loop
+func (s *usageService) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *usageService) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			s.flush()
+			return
+		case <-ticker.C:
+			s.flush()
+		}
+	}
+}
+
+// flush moves pending usage from Redis into Subscription.RequestsUsed,
+// one IncrementUsage call per user and API key instead of one per request.
+// A batch is retried with the same idempotency keys until every part is written,
+// so a call that timed out after Billing Service committed it is not charged again.
+func (s *usageService) flush() {
+	ctx := context.Background()
+
+	for {
+		batches, err := s.counter.Drain(ctx, flushBatchSize)
+		if err != nil {
+			log.Printf("[Usage] Failed to drain usage counters: %v", err)
+			return
+		}
+
+		failed := false
+		for userID, batch := range batches {
+			restore := false
+			for apiKeyID, byChain := range splitByKey(batch.Credits) {
+				if err := s.incrementUsage(userID, apiKeyID, byChain, batch.IdempotencyKey(apiKeyID)); err != nil {
+					restore = true
+					continue
+				}
+
+				fields := make([]string, 0, len(byChain))
+				for blockchain := range byChain {
+					fields = append(fields, cache.UsageField(apiKeyID, blockchain))
+				}
+				// An unacknowledged part is sent again with its key and not counted twice
+				if err := s.counter.Ack(ctx, userID, batch, fields); err != nil {
+					log.Printf("[Usage] Failed to acknowledge usage batch %s of user %s: %v", batch.ID, userID, err)
+				}
+			}
+
+			if restore {
+				failed = true
+				if err := s.counter.Restore(ctx, userID); err != nil {
+					log.Printf("[Usage] Failed to requeue usage batch %s of user %s: %v", batch.ID, userID, err)
+				}
+			}
+		}
+
+		// Retry failures on the next tick rather than spinning
+		if failed || len(batches) < flushBatchSize {
+			return
+		}
+	}
+}
+
+// splitByKey groups drained deltas by API key, then blockchain
+func splitByKey(byField map[string]int64) map[string]map[string]int64 {
+	byKey := make(map[string]map[string]int64)
+	for field, credits := range byField {
+		apiKeyID, blockchain := cache.SplitUsageField(field)
+		if byKey[apiKeyID] == nil {
+			byKey[apiKeyID] = make(map[string]int64)
+		}
+		byKey[apiKeyID][blockchain] += credits
+	}
+	return byKey
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "ironnode/services/billing-service/proto"
+
+	"google.golang.org/grpc"
+)
+
+// fakeBillingClient serves one active subscription and the per-key usage Billing Service has stored
+type fakeBillingClient struct {
+	pb.BillingServiceClient
+	periodStart time.Time
+	used        int32
+	keyUsed     int64
+	prepaid     int64
+	down        bool
+	checks      int
+}
+
+func (c *fakeBillingClient) GetSubscription(ctx context.Context, in *pb.GetSubscriptionRequest, opts ...grpc.CallOption) (*pb.SubscriptionResponse, error) {
+	return &pb.SubscriptionResponse{
+		IsActive:         true,
+		PlanType:         "basic",
+		RequestsPerMonth: 100000,
+		RequestsUsed:     c.used,
+		PeriodStart:      c.periodStart.Unix(),
+		PeriodEnd:        c.periodStart.AddDate(0, 1, 0).Unix(),
+	}, nil
+}
+
+func (c *fakeBillingClient) CheckQuota(ctx context.Context, in *pb.CheckQuotaRequest, opts ...grpc.CallOption) (*pb.CheckQuotaResponse, error) {
+	c.checks++
+	if c.down {
+		return nil, errors.New("billing service unavailable")
+	}
+	return &pb.CheckQuotaResponse{HasQuota: true, KeyUsed: c.keyUsed, PrepaidCredits: c.prepaid}, nil
+}
+
+func TestQuotaUsesStoredUsageWithoutRedis(t *testing.T) {
+	billing := &fakeBillingClient{periodStart: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), used: 40000, prepaid: 500}
+	usage := NewUsageService(billing, nil, time.Minute)
+
+	quota, err := usage.Quota(context.Background(), "user", "key")
+	if err != nil {
+		t.Fatalf("Quota: %v", err)
+	}
+	if quota.Limit != 100000 || quota.Used != 40000 || quota.Remaining() != 60000 || quota.Exceeded() {
+		t.Errorf("limit %d, used %d, remaining %d; want 60000 of 100000 left", quota.Limit, quota.Used, quota.Remaining())
+	}
+	if billing.checks != 0 {
+		t.Errorf("prepaid credits looked up %d times while the allowance lasts", billing.checks)
+	}
+	if want := billing.periodStart.AddDate(0, 1, 0); !quota.ResetAt.Equal(want) {
+		t.Errorf("resets at %v, want %v", quota.ResetAt, want)
+	}
+}
+
+func TestQuotaSpendsPrepaidCreditsOnceAllowanceIsUsedUp(t *testing.T) {
+	billing := &fakeBillingClient{periodStart: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), used: 100000, prepaid: 500}
+	usage := NewUsageService(billing, nil, time.Minute).(*usageService)
+
+	quota, err := usage.Quota(context.Background(), "user", "key")
+	if err != nil {
+		t.Fatalf("Quota: %v", err)
+	}
+	if quota.Exceeded() || quota.Remaining() != 500 {
+		t.Errorf("exceeded = %v, remaining %d; want the 500 prepaid credits", quota.Exceeded(), quota.Remaining())
+	}
+
+	// Once the prepaid credits are spent too, calls are refused until the period resets
+	billing.prepaid = 0
+	usage.keys["user/key"].expiresAt = time.Now()
+
+	quota, _ = usage.Quota(context.Background(), "user", "key")
+	if !quota.Exceeded() || quota.Remaining() != 0 {
+		t.Errorf("exceeded = %v, remaining %d; want the quota used up", quota.Exceeded(), quota.Remaining())
+	}
+}
+
+func TestKeyQuotaUsesStoredUsageWithoutRedis(t *testing.T) {
+	billing := &fakeBillingClient{periodStart: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), keyUsed: 1000}
+	usage := NewUsageService(billing, nil, time.Minute)
+
+	quota, err := usage.KeyQuota(context.Background(), "user", "key", KeyLimits{MonthlyCredits: 1000})
+	if err != nil {
+		t.Fatalf("KeyQuota: %v", err)
+	}
+
+	if quota.Used != 1000 || !quota.Exceeded() {
+		t.Errorf("used = %d, exceeded = %v; want the stored 1000 credits to exhaust the key", quota.Used, quota.Exceeded())
+	}
+	if want := billing.periodStart.AddDate(0, 1, 0); !quota.ResetAt.Equal(want) {
+		t.Errorf("resets at %v, want %v", quota.ResetAt, want)
+	}
+}
+
+func TestKeyQuotaKeepsStoredUsageWhileBillingIsDown(t *testing.T) {
+	billing := &fakeBillingClient{periodStart: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), keyUsed: 400}
+	usage := NewUsageService(billing, nil, time.Minute).(*usageService)
+	limits := KeyLimits{MonthlyCredits: 1000}
+
+	if _, err := usage.KeyQuota(context.Background(), "user", "key", limits); err != nil {
+		t.Fatalf("KeyQuota: %v", err)
+	}
+
+	// The cached usage goes stale while Billing Service cannot be reached
+	billing.down = true
+	usage.keys["user/key"].expiresAt = time.Now()
+
+	quota, _ := usage.KeyQuota(context.Background(), "user", "key", limits)
+	if quota.Used != 400 {
+		t.Errorf("used = %d, want the last known 400", quota.Used)
+	}
+}
+
+func TestKeyQuotaWithoutMonthlyLimit(t *testing.T) {
+	billing := &fakeBillingClient{keyUsed: 5000}
+	usage := NewUsageService(billing, nil, time.Minute)
+
+	quota, err := usage.KeyQuota(context.Background(), "user", "key", KeyLimits{RequestsPerSecond: 10})
+	if err != nil {
+		t.Fatalf("KeyQuota: %v", err)
+	}
+
+	if quota.Exceeded() || quota.RateLimited() || billing.checks != 0 {
+		t.Errorf("exceeded = %v, rate limited = %v, billing checks = %d; want nothing looked up",
+			quota.Exceeded(), quota.RateLimited(), billing.checks)
+	}
+}
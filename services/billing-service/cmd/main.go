@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"os/signal"
+	"syscall"
 
 	"ironnode/pkg/config"
+	"ironnode/pkg/crypto"
 	"ironnode/pkg/database"
 	"ironnode/pkg/logger"
 	"ironnode/pkg/models"
+	"ironnode/pkg/tron"
 	"ironnode/services/billing-service/internal/handler"
+	"ironnode/services/billing-service/internal/payment"
 	"ironnode/services/billing-service/internal/repository"
 	"ironnode/services/billing-service/internal/service"
 	pb "ironnode/services/billing-service/proto"
@@ -32,14 +38,69 @@ func main() {
 	}
 
 	// Auto-migrate models
-	if err := db.AutoMigrate(&models.Subscription{}); err != nil {
+	if err := db.AutoMigrate(
+		&models.Subscription{},
+		&models.BillingPeriod{},
+		&models.ChainUsage{},
+		&models.KeyUsage{},
+		&models.UsageIncrement{},
+		&models.Invoice{},
+		&models.InvoiceLineItem{},
+		&models.PaymentCustomer{},
+		&models.Payment{},
+		&models.Wallet{},
+		&models.DepositWallet{},
+		&models.CryptoDeposit{},
+		&models.PrepaidBalance{},
+		&models.LedgerAccount{},
+		&models.LedgerTransaction{},
+		&models.LedgerEntry{},
+	); err != nil {
 		logger.Fatal("Failed to migrate database:", err)
 	}
 
+	// Payment provider that charges invoices
+	provider, err := payment.NewProvider(cfg.Payment.Provider, cfg.Payment.WebhookSecret)
+	if err != nil {
+		logger.Fatal("Failed to create payment provider:", err)
+	}
+
 	// Initialize repository, service, and handler
 	billingRepo := repository.NewBillingRepository(db)
-	billingService := service.NewBillingService(billingRepo)
-	billingHandler := handler.NewBillingHandler(billingService)
+	invoiceRepo := repository.NewInvoiceRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	ledgerRepo := repository.NewLedgerRepository(db)
+	billingService := service.NewBillingService(billingRepo, ledgerRepo, cfg.Billing.TrialPeriod)
+	invoiceService := service.NewInvoiceService(invoiceRepo)
+	paymentService := service.NewPaymentService(provider, paymentRepo, invoiceRepo, billingRepo,
+		cfg.Payment.DunningRetries, cfg.Payment.DunningInterval)
+	ledgerService := service.NewLedgerService(ledgerRepo, cfg.Ledger.CreditPrice)
+
+	// USDT TRC20 top-ups of the prepaid balance
+	var cryptoService service.CryptoService
+	var watcher service.DepositWatcher
+	if cfg.Crypto.Enabled {
+		encryptionService, err := crypto.NewEncryptionService()
+		if err != nil {
+			logger.Fatal("Failed to initialize encryption service:", err)
+		}
+
+		cryptoRepo := repository.NewCryptoRepository(db)
+		cryptoService = service.NewCryptoService(cryptoRepo, encryptionService, cfg.Crypto.Confirmations)
+
+		tronClient := tron.NewClient(cfg.Crypto.TronNodeURL)
+		tronClient.SetAPI(cfg.Crypto.TronAPIURL, cfg.Crypto.TronAPIKey)
+
+		watcher = service.NewDepositWatcher(cryptoRepo, invoiceRepo, tronClient,
+			cfg.Crypto.Confirmations, cfg.Crypto.PollInterval)
+		watcher.Start()
+	}
+
+	billingHandler := handler.NewBillingHandler(billingService, invoiceService, paymentService, cryptoService, ledgerService)
+
+	// Close ended billing periods, invoice them and collect payments in the background
+	lifecycle := service.NewLifecycleManager(billingRepo, invoiceService, paymentService, cfg.Billing.RolloverInterval)
+	lifecycle.Start()
 
 	// Create gRPC server
 	grpcServer := grpc.NewServer()
@@ -52,8 +113,25 @@ func main() {
 		logger.Fatal("Failed to listen:", err)
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			logger.Fatal("Failed to serve:", err)
+		}
+	}()
 	logger.Info("Billing Service is running on", address)
-	if err := grpcServer.Serve(listener); err != nil {
-		logger.Fatal("Failed to serve:", err)
+
+	<-ctx.Done()
+	stop()
+	logger.Info("Shutting down Billing Service...")
+
+	// Finish in-flight calls, then let the workers complete their current pass
+	grpcServer.GracefulStop()
+	lifecycle.Stop()
+	if watcher != nil {
+		watcher.Stop()
 	}
+	logger.Info("Billing Service stopped")
 }
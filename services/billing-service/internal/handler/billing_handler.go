@@ -2,23 +2,43 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"ironnode/pkg/models"
+	"ironnode/services/billing-service/internal/payment"
 	"ironnode/services/billing-service/internal/service"
 	pb "ironnode/services/billing-service/proto"
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
 )
 
 type BillingHandler struct {
 	pb.UnimplementedBillingServiceServer
 	billingService service.BillingService
+	invoiceService service.InvoiceService
+	paymentService service.PaymentService
+	cryptoService  service.CryptoService // nil when crypto payments are disabled
+	ledgerService  service.LedgerService
 }
 
-func NewBillingHandler(billingService service.BillingService) *BillingHandler {
-	return &BillingHandler{billingService: billingService}
+func NewBillingHandler(
+	billingService service.BillingService,
+	invoiceService service.InvoiceService,
+	paymentService service.PaymentService,
+	cryptoService service.CryptoService,
+	ledgerService service.LedgerService,
+) *BillingHandler {
+	return &BillingHandler{
+		billingService: billingService,
+		invoiceService: invoiceService,
+		paymentService: paymentService,
+		cryptoService:  cryptoService,
+		ledgerService:  ledgerService,
+	}
 }
 
 func (h *BillingHandler) CreateSubscription(ctx context.Context, req *pb.CreateSubscriptionRequest) (*pb.SubscriptionResponse, error) {
@@ -34,15 +54,7 @@ func (h *BillingHandler) CreateSubscription(ctx context.Context, req *pb.CreateS
 		return nil, status.Errorf(codes.Internal, "failed to create subscription: %v", err)
 	}
 
-	return &pb.SubscriptionResponse{
-		Id:               subscription.ID.String(),
-		UserId:           subscription.UserID.String(),
-		PlanType:         string(subscription.PlanType),
-		RequestsPerMonth: int32(subscription.RequestsPerMonth),
-		RequestsUsed:     int32(subscription.RequestsUsed),
-		Price:            subscription.Price,
-		IsActive:         subscription.IsActive,
-	}, nil
+	return toSubscriptionResponse(subscription), nil
 }
 
 func (h *BillingHandler) GetSubscription(ctx context.Context, req *pb.GetSubscriptionRequest) (*pb.SubscriptionResponse, error) {
@@ -56,15 +68,7 @@ func (h *BillingHandler) GetSubscription(ctx context.Context, req *pb.GetSubscri
 		return nil, status.Errorf(codes.NotFound, "subscription not found: %v", err)
 	}
 
-	return &pb.SubscriptionResponse{
-		Id:               subscription.ID.String(),
-		UserId:           subscription.UserID.String(),
-		PlanType:         string(subscription.PlanType),
-		RequestsPerMonth: int32(subscription.RequestsPerMonth),
-		RequestsUsed:     int32(subscription.RequestsUsed),
-		Price:            subscription.Price,
-		IsActive:         subscription.IsActive,
-	}, nil
+	return toSubscriptionResponse(subscription), nil
 }
 
 func (h *BillingHandler) CheckQuota(ctx context.Context, req *pb.CheckQuotaRequest) (*pb.CheckQuotaResponse, error) {
@@ -73,7 +77,12 @@ func (h *BillingHandler) CheckQuota(ctx context.Context, req *pb.CheckQuotaReque
 		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
 	}
 
-	hasQuota, err := h.billingService.CheckQuota(userID)
+	apiKeyID, err := parseOptionalID(req.ApiKeyId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid API key ID: %v", err)
+	}
+
+	hasQuota, prepaid, err := h.billingService.CheckQuota(userID, apiKeyID)
 	if err != nil {
 		return &pb.CheckQuotaResponse{
 			HasQuota: false,
@@ -81,9 +90,18 @@ func (h *BillingHandler) CheckQuota(ctx context.Context, req *pb.CheckQuotaReque
 		}, nil
 	}
 
+	var keyUsed int64
+	if apiKeyID != nil {
+		if keyUsed, err = h.billingService.KeyUsage(userID, *apiKeyID); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get API key usage: %v", err)
+		}
+	}
+
 	return &pb.CheckQuotaResponse{
-		HasQuota: hasQuota,
-		Message:  "OK",
+		HasQuota:       hasQuota,
+		Message:        "OK",
+		PrepaidCredits: prepaid,
+		KeyUsed:        keyUsed,
 	}, nil
 }
 
@@ -93,7 +111,12 @@ func (h *BillingHandler) IncrementUsage(ctx context.Context, req *pb.IncrementUs
 		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
 	}
 
-	if err := h.billingService.IncrementUsage(userID); err != nil {
+	apiKeyID, err := parseOptionalID(req.ApiKeyId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid API key ID: %v", err)
+	}
+
+	if err := h.billingService.IncrementUsage(userID, apiKeyID, req.Credits, req.BlockchainCredits, req.IdempotencyKey); err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to increment usage: %v", err)
 	}
 
@@ -101,3 +124,504 @@ func (h *BillingHandler) IncrementUsage(ctx context.Context, req *pb.IncrementUs
 		Success: true,
 	}, nil
 }
+
+func (h *BillingHandler) UpdateSubscription(ctx context.Context, req *pb.UpdateSubscriptionRequest) (*pb.SubscriptionResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+
+	subscription, err := h.billingService.UpdateSubscription(userID, models.PlanType(req.PlanType))
+	if err != nil {
+		return nil, lifecycleError("failed to update subscription", err)
+	}
+
+	return toSubscriptionResponse(subscription), nil
+}
+
+func (h *BillingHandler) CancelSubscription(ctx context.Context, req *pb.CancelSubscriptionRequest) (*pb.SubscriptionResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+
+	subscription, err := h.billingService.CancelSubscription(userID, req.AtPeriodEnd)
+	if err != nil {
+		return nil, lifecycleError("failed to cancel subscription", err)
+	}
+
+	return toSubscriptionResponse(subscription), nil
+}
+
+func (h *BillingHandler) ListBillingPeriods(ctx context.Context, req *pb.ListBillingPeriodsRequest) (*pb.ListBillingPeriodsResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+
+	periods, err := h.billingService.ListBillingPeriods(userID, int(req.Limit))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list billing periods: %v", err)
+	}
+
+	resp := &pb.ListBillingPeriodsResponse{
+		Periods: make([]*pb.BillingPeriod, 0, len(periods)),
+	}
+	for _, period := range periods {
+		resp.Periods = append(resp.Periods, &pb.BillingPeriod{
+			Id:               period.ID.String(),
+			PlanType:         string(period.PlanType),
+			Status:           string(period.Status),
+			PeriodStart:      period.PeriodStart.Unix(),
+			PeriodEnd:        period.PeriodEnd.Unix(),
+			RequestsPerMonth: int32(period.RequestsPerMonth),
+			RequestsUsed:     int32(period.RequestsUsed),
+			Price:            period.Price,
+			Proration:        period.Proration,
+		})
+	}
+
+	return resp, nil
+}
+
+func (h *BillingHandler) ListInvoices(ctx context.Context, req *pb.ListInvoicesRequest) (*pb.ListInvoicesResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+
+	invoices, err := h.invoiceService.ListInvoices(userID, int(req.Limit))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list invoices: %v", err)
+	}
+
+	resp := &pb.ListInvoicesResponse{
+		Invoices: make([]*pb.Invoice, 0, len(invoices)),
+	}
+	for _, invoice := range invoices {
+		resp.Invoices = append(resp.Invoices, toInvoiceResponse(invoice))
+	}
+
+	return resp, nil
+}
+
+func (h *BillingHandler) GetInvoice(ctx context.Context, req *pb.GetInvoiceRequest) (*pb.Invoice, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+
+	invoiceID, err := uuid.Parse(req.InvoiceId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid invoice ID: %v", err)
+	}
+
+	invoice, err := h.invoiceService.GetInvoice(userID, invoiceID)
+	if err != nil {
+		if errors.Is(err, service.ErrInvoiceNotFound) || errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "invoice not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get invoice: %v", err)
+	}
+
+	return toInvoiceResponse(invoice), nil
+}
+
+func (h *BillingHandler) AttachPaymentMethod(ctx context.Context, req *pb.AttachPaymentMethodRequest) (*pb.PaymentMethodResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+
+	customer, err := h.paymentService.AttachPaymentMethod(userID, req.Token)
+	if err != nil {
+		return nil, paymentError("failed to attach payment method", err)
+	}
+
+	return &pb.PaymentMethodResponse{
+		Provider:        customer.Provider,
+		CustomerId:      customer.CustomerID,
+		PaymentMethodId: customer.PaymentMethodID,
+		CardBrand:       customer.CardBrand,
+		CardLast4:       customer.CardLast4,
+	}, nil
+}
+
+func (h *BillingHandler) RefundPayment(ctx context.Context, req *pb.RefundPaymentRequest) (*pb.Payment, error) {
+	paymentID, err := uuid.Parse(req.PaymentId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payment ID: %v", err)
+	}
+
+	if req.Amount < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "refund amount must not be negative")
+	}
+
+	record, err := h.paymentService.RefundPayment(paymentID, req.Amount)
+	if err != nil {
+		return nil, paymentError("failed to refund payment", err)
+	}
+
+	return &pb.Payment{
+		Id:             record.ID.String(),
+		InvoiceId:      record.InvoiceID.String(),
+		Provider:       record.Provider,
+		ChargeId:       record.ChargeID,
+		Attempt:        int32(record.Attempt),
+		Amount:         record.Amount,
+		AmountRefunded: record.AmountRefunded,
+		Currency:       record.Currency,
+		Status:         string(record.Status),
+		FailureCode:    record.FailureCode,
+	}, nil
+}
+
+func (h *BillingHandler) HandleWebhook(ctx context.Context, req *pb.HandleWebhookRequest) (*pb.HandleWebhookResponse, error) {
+	if err := h.paymentService.HandleWebhook(req.Provider, req.Payload, req.Signature); err != nil {
+		return nil, paymentError("failed to handle webhook", err)
+	}
+
+	return &pb.HandleWebhookResponse{Success: true}, nil
+}
+
+func (h *BillingHandler) GetDepositAddress(ctx context.Context, req *pb.GetDepositAddressRequest) (*pb.DepositAddressResponse, error) {
+	if h.cryptoService == nil {
+		return nil, status.Errorf(codes.Unimplemented, "crypto payments are disabled")
+	}
+
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+
+	wallet, err := h.cryptoService.GetDepositAddress(userID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get deposit address: %v", err)
+	}
+
+	return &pb.DepositAddressResponse{
+		Address:       wallet.Address,
+		Network:       string(wallet.Network),
+		Token:         "USDT",
+		Confirmations: int32(h.cryptoService.Confirmations()),
+	}, nil
+}
+
+func (h *BillingHandler) ListDeposits(ctx context.Context, req *pb.ListDepositsRequest) (*pb.ListDepositsResponse, error) {
+	if h.cryptoService == nil {
+		return nil, status.Errorf(codes.Unimplemented, "crypto payments are disabled")
+	}
+
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+
+	deposits, err := h.cryptoService.ListDeposits(userID, int(req.Limit))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list deposits: %v", err)
+	}
+
+	resp := &pb.ListDepositsResponse{
+		Deposits: make([]*pb.Deposit, 0, len(deposits)),
+	}
+	for _, deposit := range deposits {
+		item := &pb.Deposit{
+			Id:            deposit.ID.String(),
+			Address:       deposit.Address,
+			TxId:          deposit.TxID,
+			Amount:        deposit.Amount,
+			Status:        string(deposit.Status),
+			DetectedBlock: deposit.DetectedBlock,
+			CreatedAt:     deposit.CreatedAt.Unix(),
+		}
+		if deposit.ConfirmedAt != nil {
+			item.ConfirmedAt = deposit.ConfirmedAt.Unix()
+		}
+		resp.Deposits = append(resp.Deposits, item)
+	}
+
+	return resp, nil
+}
+
+func (h *BillingHandler) GetPrepaidBalance(ctx context.Context, req *pb.GetPrepaidBalanceRequest) (*pb.PrepaidBalanceResponse, error) {
+	if h.cryptoService == nil {
+		return nil, status.Errorf(codes.Unimplemented, "crypto payments are disabled")
+	}
+
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+
+	balance, err := h.cryptoService.GetPrepaidBalance(userID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get prepaid balance: %v", err)
+	}
+
+	return &pb.PrepaidBalanceResponse{
+		Balance:  balance.Balance,
+		Currency: balance.Currency,
+	}, nil
+}
+
+func (h *BillingHandler) GetBalance(ctx context.Context, req *pb.GetBalanceRequest) (*pb.BalanceResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+
+	apiKeyID, err := parseOptionalID(req.ApiKeyId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid API key ID: %v", err)
+	}
+
+	balance, err := h.ledgerService.GetBalance(userID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get balance: %v", err)
+	}
+
+	resp := &pb.BalanceResponse{
+		Balance:   balance.Balance,
+		Keys:      make([]*pb.KeyBalance, 0, len(balance.Keys)),
+		Total:     balance.Total(),
+		Available: balance.Available(apiKeyID),
+	}
+	for keyID, credits := range balance.Keys {
+		resp.Keys = append(resp.Keys, &pb.KeyBalance{
+			ApiKeyId: keyID.String(),
+			Balance:  credits,
+		})
+	}
+
+	return resp, nil
+}
+
+func (h *BillingHandler) ListLedgerEntries(ctx context.Context, req *pb.ListLedgerEntriesRequest) (*pb.ListLedgerEntriesResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+
+	apiKeyID, err := parseOptionalID(req.ApiKeyId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid API key ID: %v", err)
+	}
+
+	entries, err := h.ledgerService.ListEntries(userID, apiKeyID, int(req.Limit))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list ledger entries: %v", err)
+	}
+
+	resp := &pb.ListLedgerEntriesResponse{
+		Entries: make([]*pb.LedgerEntry, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		item := &pb.LedgerEntry{
+			Id:            entry.ID.String(),
+			TransactionId: entry.TransactionID.String(),
+			Type:          string(entry.Transaction.Type),
+			Amount:        entry.Amount,
+			BalanceAfter:  entry.BalanceAfter,
+			Description:   entry.Transaction.Description,
+			CreatedAt:     entry.CreatedAt.Unix(),
+		}
+		if entry.Account.APIKeyID != nil {
+			item.ApiKeyId = entry.Account.APIKeyID.String()
+		}
+		resp.Entries = append(resp.Entries, item)
+	}
+
+	return resp, nil
+}
+
+func (h *BillingHandler) TopUpCredits(ctx context.Context, req *pb.CreditRequest) (*pb.LedgerTransaction, error) {
+	userID, apiKeyID, err := parseCreditRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := h.ledgerService.TopUp(userID, apiKeyID, req.Credits, req.IdempotencyKey)
+	if err != nil {
+		return nil, ledgerError("failed to top up credits", err)
+	}
+
+	return toLedgerTransactionResponse(transaction), nil
+}
+
+func (h *BillingHandler) GrantCredits(ctx context.Context, req *pb.CreditRequest) (*pb.LedgerTransaction, error) {
+	userID, apiKeyID, err := parseCreditRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := h.ledgerService.Grant(userID, apiKeyID, req.Credits, req.IdempotencyKey, req.Description)
+	if err != nil {
+		return nil, ledgerError("failed to grant credits", err)
+	}
+
+	return toLedgerTransactionResponse(transaction), nil
+}
+
+func (h *BillingHandler) RefundCredits(ctx context.Context, req *pb.CreditRequest) (*pb.LedgerTransaction, error) {
+	userID, apiKeyID, err := parseCreditRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := h.ledgerService.Refund(userID, apiKeyID, req.Credits, req.IdempotencyKey, req.Description)
+	if err != nil {
+		return nil, ledgerError("failed to refund credits", err)
+	}
+
+	return toLedgerTransactionResponse(transaction), nil
+}
+
+func parseCreditRequest(req *pb.CreditRequest) (uuid.UUID, *uuid.UUID, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return uuid.Nil, nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+
+	apiKeyID, err := parseOptionalID(req.ApiKeyId)
+	if err != nil {
+		return uuid.Nil, nil, status.Errorf(codes.InvalidArgument, "invalid API key ID: %v", err)
+	}
+
+	return userID, apiKeyID, nil
+}
+
+// parseOptionalID parses an ID that may be left empty
+func parseOptionalID(value string) (*uuid.UUID, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// ledgerError maps credit ledger errors to gRPC status codes
+func ledgerError(message string, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidCredits), errors.Is(err, service.ErrIdempotencyKeyRequired), errors.Is(err, service.ErrTopUpTooSmall):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", message, err)
+	case errors.Is(err, service.ErrIdempotencyKeyReused):
+		return status.Errorf(codes.AlreadyExists, "%s: %v", message, err)
+	case errors.Is(err, service.ErrInsufficientBalance):
+		return status.Errorf(codes.FailedPrecondition, "%s: %v", message, err)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", message, err)
+	}
+}
+
+// paymentError maps payment errors to gRPC status codes
+func paymentError(message string, err error) error {
+	switch {
+	case errors.Is(err, payment.ErrInvalidSignature):
+		return status.Errorf(codes.Unauthenticated, "%s: %v", message, err)
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, payment.ErrChargeNotFound), errors.Is(err, service.ErrUnknownProvider):
+		return status.Errorf(codes.NotFound, "%s: %v", message, err)
+	case errors.Is(err, payment.ErrInvalidPayload), errors.Is(err, payment.ErrInvalidPaymentMethod):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", message, err)
+	case errors.Is(err, service.ErrPaymentNotRefundable), errors.Is(err, payment.ErrRefundExceedsCharge):
+		return status.Errorf(codes.FailedPrecondition, "%s: %v", message, err)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", message, err)
+	}
+}
+
+// lifecycleError maps lifecycle errors to gRPC status codes
+func lifecycleError(message string, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return status.Errorf(codes.NotFound, "%s: %v", message, err)
+	case errors.Is(err, service.ErrInvalidPlan):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", message, err)
+	case errors.Is(err, service.ErrSubscriptionClosed), errors.Is(err, models.ErrInvalidTransition):
+		return status.Errorf(codes.FailedPrecondition, "%s: %v", message, err)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", message, err)
+	}
+}
+
+func toSubscriptionResponse(subscription *models.Subscription) *pb.SubscriptionResponse {
+	periodStart, periodEnd := subscription.CurrentPeriod(time.Now())
+
+	resp := &pb.SubscriptionResponse{
+		Id:                subscription.ID.String(),
+		UserId:            subscription.UserID.String(),
+		PlanType:          string(subscription.PlanType),
+		RequestsPerMonth:  int32(subscription.RequestsPerMonth),
+		RequestsUsed:      int32(subscription.RequestsUsed),
+		Price:             subscription.Price,
+		IsActive:          subscription.IsActive,
+		PeriodStart:       periodStart.Unix(),
+		PeriodEnd:         periodEnd.Unix(),
+		Status:            string(subscription.Status),
+		CancelAtPeriodEnd: subscription.CancelAtPeriodEnd,
+		PendingProration:  subscription.PendingProration,
+	}
+	if subscription.TrialEndsAt != nil {
+		resp.TrialEndsAt = subscription.TrialEndsAt.Unix()
+	}
+
+	return resp
+}
+
+func toInvoiceResponse(invoice *models.Invoice) *pb.Invoice {
+	resp := &pb.Invoice{
+		Id:              invoice.ID.String(),
+		Number:          invoice.Number,
+		UserId:          invoice.UserID.String(),
+		PlanType:        string(invoice.PlanType),
+		PeriodStart:     invoice.PeriodStart.Unix(),
+		PeriodEnd:       invoice.PeriodEnd.Unix(),
+		Currency:        invoice.Currency,
+		Total:           invoice.Total,
+		Status:          string(invoice.Status),
+		IssuedAt:        invoice.IssuedAt.Unix(),
+		DueAt:           invoice.DueAt.Unix(),
+		PaymentAttempts: int32(invoice.PaymentAttempts),
+		LineItems:       make([]*pb.InvoiceLineItem, 0, len(invoice.LineItems)),
+	}
+	if invoice.PaidAt != nil {
+		resp.PaidAt = invoice.PaidAt.Unix()
+	}
+	if invoice.NextPaymentAt != nil && invoice.Status == models.InvoiceOpen {
+		resp.NextPaymentAt = invoice.NextPaymentAt.Unix()
+	}
+
+	for _, item := range invoice.LineItems {
+		resp.LineItems = append(resp.LineItems, &pb.InvoiceLineItem{
+			Kind:        string(item.Kind),
+			Description: item.Description,
+			Blockchain:  item.Blockchain,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      item.Amount,
+		})
+	}
+
+	return resp
+}
+
+func toLedgerTransactionResponse(transaction *models.LedgerTransaction) *pb.LedgerTransaction {
+	resp := &pb.LedgerTransaction{
+		Id:             transaction.ID.String(),
+		IdempotencyKey: transaction.IdempotencyKey,
+		Type:           string(transaction.Type),
+		Amount:         transaction.Amount,
+		Cost:           transaction.Cost,
+		Description:    transaction.Description,
+		CreatedAt:      transaction.CreatedAt.Unix(),
+	}
+	if transaction.APIKeyID != nil {
+		resp.ApiKeyId = transaction.APIKeyID.String()
+	}
+
+	return resp
+}
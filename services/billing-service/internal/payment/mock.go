@@ -0,0 +1,188 @@
+package payment
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mockCard is the behaviour of a test card token
+type mockCard struct {
+	brand       string
+	last4       string
+	failureCode string // charges to the card are declined with this code
+}
+
+// mockCards are the tokens the mock provider accepts
+var mockCards = map[string]mockCard{
+	"tok_visa":               {brand: "visa", last4: "4242"},
+	"tok_mastercard":         {brand: "mastercard", last4: "4444"},
+	"tok_declined":           {brand: "visa", last4: "0002", failureCode: "card_declined"},
+	"tok_insufficient_funds": {brand: "visa", last4: "9995", failureCode: "insufficient_funds"},
+}
+
+var mockFailureMessages = map[string]string{
+	"card_declined":      "Your card was declined.",
+	"insufficient_funds": "Your card has insufficient funds.",
+}
+
+// MockProvider is an in-memory provider for local development. It is
+// deterministic: IDs derive from the customer reference or idempotency key
+// (or are sequential without one), and the outcome of a charge depends only
+// on the card token the payment method was attached with. The token is part
+// of the payment method ID, so charges keep working after a restart; charges
+// themselves are kept in memory only, so refunds need the same process.
+type MockProvider struct {
+	webhookSecret string
+
+	mu          sync.Mutex
+	seq         int
+	charges     map[string]*Charge
+	idempotency map[string]*Charge
+}
+
+func NewMockProvider(webhookSecret string) *MockProvider {
+	return &MockProvider{
+		webhookSecret: webhookSecret,
+		charges:       make(map[string]*Charge),
+		idempotency:   make(map[string]*Charge),
+	}
+}
+
+func (p *MockProvider) Name() string {
+	return "mock"
+}
+
+func (p *MockProvider) CreateCustomer(ctx context.Context, reference string) (*Customer, error) {
+	return &Customer{ID: stableID("cus", reference), Reference: reference}, nil
+}
+
+func (p *MockProvider) AttachPaymentMethod(ctx context.Context, customerID, token string) (*PaymentMethod, error) {
+	card, ok := mockCards[token]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown test token %q", ErrInvalidPaymentMethod, token)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return &PaymentMethod{
+		ID:         fmt.Sprintf("%s_%s", p.nextID("pm"), strings.TrimPrefix(token, "tok_")),
+		CustomerID: customerID,
+		Brand:      card.brand,
+		Last4:      card.last4,
+	}, nil
+}
+
+func (p *MockProvider) Charge(ctx context.Context, req ChargeRequest) (*Charge, error) {
+	card, ok := mockCardFor(req.PaymentMethodID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPaymentMethod, req.PaymentMethodID)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if req.IdempotencyKey != "" {
+		if charge, ok := p.idempotency[req.IdempotencyKey]; ok {
+			copied := *charge
+			return &copied, nil
+		}
+	}
+
+	id := p.nextID("ch")
+	if req.IdempotencyKey != "" {
+		id = stableID("ch", req.IdempotencyKey)
+	}
+
+	charge := &Charge{
+		ID:         id,
+		CustomerID: req.CustomerID,
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		Status:     ChargeSucceeded,
+	}
+	if card.failureCode != "" {
+		charge.Status = ChargeFailed
+		charge.FailureCode = card.failureCode
+		charge.FailureMessage = mockFailureMessages[card.failureCode]
+	}
+
+	p.charges[charge.ID] = charge
+	if req.IdempotencyKey != "" {
+		p.idempotency[req.IdempotencyKey] = charge
+	}
+
+	copied := *charge
+	return &copied, nil
+}
+
+func (p *MockProvider) Refund(ctx context.Context, chargeID string, amount float64) (*Refund, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charge, ok := p.charges[chargeID]
+	if !ok || charge.Status != ChargeSucceeded {
+		return nil, ErrChargeNotFound
+	}
+
+	left := math.Round((charge.Amount-charge.AmountRefunded)*100) / 100
+	if amount == 0 {
+		amount = left
+	}
+	if amount <= 0 || amount > left {
+		return nil, ErrRefundExceedsCharge
+	}
+
+	charge.AmountRefunded += amount
+
+	return &Refund{ID: p.nextID("re"), ChargeID: chargeID, Amount: amount}, nil
+}
+
+func (p *MockProvider) HandleWebhook(payload []byte, signature string) (*Event, error) {
+	if err := VerifySignature(p.webhookSecret, payload, signature, time.Now()); err != nil {
+		return nil, err
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	if event.Charge.ID == "" {
+		return nil, fmt.Errorf("%w: missing charge", ErrInvalidPayload)
+	}
+
+	return &event, nil
+}
+
+// nextID returns a sequential ID with the given prefix; callers hold p.mu
+func (p *MockProvider) nextID(prefix string) string {
+	p.seq++
+	return fmt.Sprintf("%s_mock_%06d", prefix, p.seq)
+}
+
+// stableID derives an ID from a key, so it stays unique across restarts
+func stableID(prefix, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return fmt.Sprintf("%s_mock_%s", prefix, hex.EncodeToString(sum[:8]))
+}
+
+// mockCardFor recovers the card from a payment method ID made by AttachPaymentMethod
+func mockCardFor(paymentMethodID string) (mockCard, bool) {
+	if !strings.HasPrefix(paymentMethodID, "pm_mock_") {
+		return mockCard{}, false
+	}
+	// pm_mock_<seq>_<token without tok_>
+	_, suffix, ok := strings.Cut(strings.TrimPrefix(paymentMethodID, "pm_mock_"), "_")
+	if !ok {
+		return mockCard{}, false
+	}
+	card, ok := mockCards["tok_"+suffix]
+	return card, ok
+}
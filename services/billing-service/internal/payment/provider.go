@@ -0,0 +1,103 @@
+// Package payment abstracts the payment processor that charges invoices
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidSignature     = errors.New("invalid webhook signature")
+	ErrInvalidPayload       = errors.New("invalid webhook payload")
+	ErrInvalidPaymentMethod = errors.New("invalid payment method")
+	ErrChargeNotFound       = errors.New("charge not found")
+	ErrRefundExceedsCharge  = errors.New("refund exceeds the charged amount")
+)
+
+type ChargeStatus string
+
+const (
+	ChargePending   ChargeStatus = "pending"
+	ChargeSucceeded ChargeStatus = "succeeded"
+	ChargeFailed    ChargeStatus = "failed"
+)
+
+// EventType is the kind of a webhook event
+type EventType string
+
+const (
+	EventChargeSucceeded EventType = "charge.succeeded"
+	EventChargeFailed    EventType = "charge.failed"
+	EventChargeRefunded  EventType = "charge.refunded"
+)
+
+type Customer struct {
+	ID        string `json:"id"`
+	Reference string `json:"reference"` // our user ID
+}
+
+type PaymentMethod struct {
+	ID         string `json:"id"`
+	CustomerID string `json:"customer_id"`
+	Brand      string `json:"brand"`
+	Last4      string `json:"last4"`
+}
+
+type ChargeRequest struct {
+	CustomerID      string
+	PaymentMethodID string
+	Amount          float64
+	Currency        string
+	Description     string
+	// IdempotencyKey makes retries of the same request return the original charge
+	IdempotencyKey string
+}
+
+type Charge struct {
+	ID             string       `json:"id"`
+	CustomerID     string       `json:"customer_id"`
+	Amount         float64      `json:"amount"`
+	AmountRefunded float64      `json:"amount_refunded"`
+	Currency       string       `json:"currency"`
+	Status         ChargeStatus `json:"status"`
+	FailureCode    string       `json:"failure_code,omitempty"`
+	FailureMessage string       `json:"failure_message,omitempty"`
+}
+
+type Refund struct {
+	ID       string  `json:"id"`
+	ChargeID string  `json:"charge_id"`
+	Amount   float64 `json:"amount"`
+}
+
+// Event is a verified webhook notification about a charge
+type Event struct {
+	ID     string    `json:"id"`
+	Type   EventType `json:"type"`
+	Charge Charge    `json:"charge"`
+}
+
+// PaymentProvider is a payment processor. A declined charge is not an error:
+// Charge returns it with status failed and a failure code. Errors mean the
+// provider could not be reached or rejected the request itself.
+type PaymentProvider interface {
+	Name() string
+	CreateCustomer(ctx context.Context, reference string) (*Customer, error)
+	AttachPaymentMethod(ctx context.Context, customerID, token string) (*PaymentMethod, error)
+	Charge(ctx context.Context, req ChargeRequest) (*Charge, error)
+	// Refund returns amount of a charge to the customer; 0 refunds what is left
+	Refund(ctx context.Context, chargeID string, amount float64) (*Refund, error)
+	// HandleWebhook verifies the signature of a webhook delivery and parses its event
+	HandleWebhook(payload []byte, signature string) (*Event, error)
+}
+
+// NewProvider creates the provider configured by name
+func NewProvider(name, webhookSecret string) (PaymentProvider, error) {
+	switch name {
+	case "mock":
+		return NewMockProvider(webhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", name)
+	}
+}
@@ -0,0 +1,70 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	const secret = "whsec_test"
+	payload := []byte(`{"id":"evt_1","type":"payment.succeeded"}`)
+	signedAt := time.Unix(1700000000, 0)
+	header := SignPayload(secret, payload, signedAt)
+
+	tests := []struct {
+		name    string
+		secret  string
+		payload []byte
+		header  string
+		now     time.Time
+		valid   bool
+	}{
+		{"valid", secret, payload, header, signedAt, true},
+		{"within tolerance", secret, payload, header, signedAt.Add(signatureTolerance), true},
+		{"clock skew within tolerance", secret, payload, header, signedAt.Add(-signatureTolerance), true},
+		{"spaces in header", secret, payload, "t=1700000000, v1=" + computeSignature(secret, "1700000000", payload), signedAt, true},
+		{"one of several signatures", secret, payload, header + ",v1=deadbeef", signedAt, true},
+		{"tampered payload", secret, []byte(`{"id":"evt_1","type":"payment.failed"}`), header, signedAt, false},
+		{"wrong secret", "whsec_other", payload, header, signedAt, false},
+		{"no secret configured", "", payload, header, signedAt, false},
+		{"expired", secret, payload, header, signedAt.Add(signatureTolerance + time.Second), false},
+		{"from the future", secret, payload, header, signedAt.Add(-signatureTolerance - time.Second), false},
+		{"tampered timestamp", secret, payload, fmt.Sprintf("t=1700000001,v1=%s", computeSignature(secret, "1700000000", payload)), signedAt, false},
+		{"missing signature", secret, payload, "t=1700000000", signedAt, false},
+		{"missing timestamp", secret, payload, "v1=" + computeSignature(secret, "1700000000", payload), signedAt, false},
+		{"empty header", secret, payload, "", signedAt, false},
+		{"unknown scheme only", secret, payload, "t=1700000000,v0=" + computeSignature(secret, "1700000000", payload), signedAt, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignature(tt.secret, tt.payload, tt.header, tt.now)
+			if tt.valid && err != nil {
+				t.Errorf("VerifySignature() = %v, want nil", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("VerifySignature() = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestSignPayload(t *testing.T) {
+	payload := []byte(`{"id":"evt_1"}`)
+	at := time.Unix(1700000000, 0)
+
+	header := SignPayload("secret", payload, at)
+	want := "t=1700000000,v1=" + computeSignature("secret", "1700000000", payload)
+	if header != want {
+		t.Errorf("SignPayload() = %s, want %s", header, want)
+	}
+
+	if SignPayload("secret", payload, at) != header {
+		t.Error("signature is not deterministic")
+	}
+	if SignPayload("secret", payload, at.Add(time.Second)) == header {
+		t.Error("signature does not cover the timestamp")
+	}
+}
@@ -1,17 +1,28 @@
 package repository
 
 import (
+	"errors"
+	"time"
+
 	"ironnode/pkg/models"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BillingRepository interface {
 	CreateSubscription(subscription *models.Subscription) error
 	GetSubscriptionByUser(userID uuid.UUID) (*models.Subscription, error)
+	GetSubscriptionByID(id uuid.UUID) (*models.Subscription, error)
 	UpdateSubscription(subscription *models.Subscription) error
-	IncrementUsage(userID uuid.UUID) error
+	IncrementUsage(userID uuid.UUID, credits int64, byChain map[string]int64, debit *models.LedgerTransaction) error
+	GetKeyUsage(subscriptionID uuid.UUID, periodStart time.Time, apiKeyID uuid.UUID) (int64, error)
+	// PruneUsageIncrements forgets idempotency keys of increments applied before the given time
+	PruneUsageIncrements(before time.Time) (int64, error)
+	ListDueSubscriptions(now time.Time, limit int) ([]*models.Subscription, error)
+	ClosePeriods(subscription *models.Subscription, periods []*models.BillingPeriod) error
+	ListBillingPeriods(userID uuid.UUID, limit int) ([]*models.BillingPeriod, error)
 }
 
 type billingRepository struct {
@@ -32,12 +43,165 @@ func (r *billingRepository) GetSubscriptionByUser(userID uuid.UUID) (*models.Sub
 	return &subscription, err
 }
 
+func (r *billingRepository) GetSubscriptionByID(id uuid.UUID) (*models.Subscription, error) {
+	var subscription models.Subscription
+	err := r.db.Where("id = ?", id).First(&subscription).Error
+	return &subscription, err
+}
+
+// UpdateSubscription saves everything except usage, which only IncrementUsage and ClosePeriods change
 func (r *billingRepository) UpdateSubscription(subscription *models.Subscription) error {
-	return r.db.Save(subscription).Error
+	return r.db.Omit("requests_used", "prepaid_credits_used").Save(subscription).Error
+}
+
+// IncrementUsage adds credits to the active subscription and to its per-chain
+// and per-key usage for the current period. Credits beyond the allowance are debited from
+// the credit ledger as far as it goes; debit carries the user, API key and
+// idempotency key of the increment, and an increment replayed with a key that
+// was applied already is skipped. Users without a subscription are not billed.
+func (r *billingRepository) IncrementUsage(userID uuid.UUID, credits int64, byChain map[string]int64, debit *models.LedgerTransaction) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var subscription models.Subscription
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "current_period_start", "requests_per_month", "requests_used").
+			Where("user_id = ? AND is_active = ?", userID, true).
+			First(&subscription).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// A concurrent replay waits on the key until this transaction ends, then inserts nothing
+		increment := &models.UsageIncrement{
+			IdempotencyKey: debit.IdempotencyKey,
+			UserID:         userID,
+			Credits:        credits,
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(increment)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		allowance := int64(subscription.RequestsPerMonth)
+		used := int64(subscription.RequestsUsed)
+		over := max(used+credits-allowance, 0) - max(used-allowance, 0)
+		if over > 0 {
+			if err := debitUsage(tx, debit, over); err != nil {
+				return err
+			}
+		}
+
+		err = tx.Model(&models.Subscription{}).
+			Where("id = ?", subscription.ID).
+			Updates(map[string]interface{}{
+				"requests_used":        gorm.Expr("requests_used + ?", credits),
+				"prepaid_credits_used": gorm.Expr("prepaid_credits_used + ?", debit.Amount),
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		for blockchain, chainCredits := range byChain {
+			usage := &models.ChainUsage{
+				SubscriptionID: subscription.ID,
+				PeriodStart:    subscription.CurrentPeriodStart,
+				Blockchain:     blockchain,
+				Credits:        chainCredits,
+			}
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "subscription_id"}, {Name: "period_start"}, {Name: "blockchain"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"credits": gorm.Expr("chain_usages.credits + EXCLUDED.credits")}),
+			}).Create(usage).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if debit.APIKeyID != nil {
+			usage := &models.KeyUsage{
+				SubscriptionID: subscription.ID,
+				PeriodStart:    subscription.CurrentPeriodStart,
+				APIKeyID:       *debit.APIKeyID,
+				Credits:        credits,
+			}
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "subscription_id"}, {Name: "period_start"}, {Name: "api_key_id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"credits": gorm.Expr("key_usages.credits + EXCLUDED.credits")}),
+			}).Create(usage).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetKeyUsage returns the credits spent with an API key in the period starting at periodStart
+func (r *billingRepository) GetKeyUsage(subscriptionID uuid.UUID, periodStart time.Time, apiKeyID uuid.UUID) (int64, error) {
+	var credits int64
+	err := r.db.Model(&models.KeyUsage{}).
+		Where("subscription_id = ? AND period_start = ? AND api_key_id = ?", subscriptionID, periodStart, apiKeyID).
+		Select("COALESCE(SUM(credits), 0)").
+		Scan(&credits).Error
+	return credits, err
+}
+
+func (r *billingRepository) PruneUsageIncrements(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&models.UsageIncrement{})
+	return result.RowsAffected, result.Error
+}
+
+// ListDueSubscriptions returns live subscriptions whose current period has ended,
+// including ones created before periods were stored
+func (r *billingRepository) ListDueSubscriptions(now time.Time, limit int) ([]*models.Subscription, error) {
+	var subscriptions []*models.Subscription
+	err := r.db.
+		Where("is_active = ? AND status IN ? AND (current_period_end IS NULL OR current_period_end <= ?)", true,
+			[]models.SubscriptionStatus{models.StatusTrialing, models.StatusActive, models.StatusPastDue}, now).
+		Order("current_period_end").
+		Limit(limit).
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// ClosePeriods archives closed periods and saves the rolled-over subscription in one transaction.
+// Archived usage is subtracted rather than reset, so increments that land meanwhile are kept.
+func (r *billingRepository) ClosePeriods(subscription *models.Subscription, periods []*models.BillingPeriod) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		archived, prepaid := 0, 0
+		for _, period := range periods {
+			if err := tx.Create(period).Error; err != nil {
+				return err
+			}
+			archived += period.RequestsUsed
+			prepaid += period.PrepaidCredits
+		}
+
+		if err := tx.Omit("requests_used", "prepaid_credits_used").Save(subscription).Error; err != nil {
+			return err
+		}
+
+		if archived == 0 && prepaid == 0 {
+			return nil
+		}
+
+		return tx.Model(&models.Subscription{}).
+			Where("id = ?", subscription.ID).
+			Updates(map[string]interface{}{
+				"requests_used":        gorm.Expr("GREATEST(requests_used - ?, 0)", archived),
+				"prepaid_credits_used": gorm.Expr("GREATEST(prepaid_credits_used - ?, 0)", prepaid),
+			}).Error
+	})
 }
 
-func (r *billingRepository) IncrementUsage(userID uuid.UUID) error {
-	return r.db.Model(&models.Subscription{}).
-		Where("user_id = ? AND is_active = ?", userID, true).
-		Update("requests_used", gorm.Expr("requests_used + ?", 1)).Error
+func (r *billingRepository) ListBillingPeriods(userID uuid.UUID, limit int) ([]*models.BillingPeriod, error) {
+	var periods []*models.BillingPeriod
+	err := r.db.Where("user_id = ?", userID).
+		Order("period_end DESC").
+		Limit(limit).
+		Find(&periods).Error
+	return periods, err
 }
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"time"
+
+	"ironnode/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CryptoRepository interface {
+	GetDepositWallet(userID uuid.UUID) (*models.DepositWallet, error)
+	// CreateDepositWallet stores the key pair and its deposit wallet together
+	CreateDepositWallet(wallet *models.Wallet, deposit *models.DepositWallet) error
+	// ListDepositWallets pages through all deposit wallets in ID order, starting after afterID
+	ListDepositWallets(afterID uuid.UUID, limit int) ([]*models.DepositWallet, error)
+	// RecordTransfers records a wallet check, creating deposits for transfers not recorded
+	// before; a transaction already recorded for the wallet is skipped
+	RecordTransfers(wallet *models.DepositWallet, deposits []*models.CryptoDeposit) error
+	ListPendingDeposits(walletID uuid.UUID) ([]*models.CryptoDeposit, error)
+	// MoveDeposit records that a pending deposit's transaction is now in another block
+	MoveDeposit(deposit *models.CryptoDeposit) error
+	// RevertDeposit marks a pending deposit whose transaction is gone as reverted
+	RevertDeposit(deposit *models.CryptoDeposit) error
+	// ConfirmDeposit marks the deposit confirmed and credits it to the user's prepaid balance
+	ConfirmDeposit(deposit *models.CryptoDeposit, now time.Time) error
+	ListDeposits(userID uuid.UUID, limit int) ([]*models.CryptoDeposit, error)
+	GetPrepaidBalance(userID uuid.UUID) (*models.PrepaidBalance, error)
+}
+
+type cryptoRepository struct {
+	db *gorm.DB
+}
+
+func NewCryptoRepository(db *gorm.DB) CryptoRepository {
+	return &cryptoRepository{db: db}
+}
+
+func (r *cryptoRepository) GetDepositWallet(userID uuid.UUID) (*models.DepositWallet, error) {
+	var wallet models.DepositWallet
+	err := r.db.Where("user_id = ?", userID).First(&wallet).Error
+	return &wallet, err
+}
+
+func (r *cryptoRepository) CreateDepositWallet(wallet *models.Wallet, deposit *models.DepositWallet) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("User").Create(wallet).Error; err != nil {
+			return err
+		}
+		deposit.WalletID = wallet.ID
+		return tx.Create(deposit).Error
+	})
+}
+
+func (r *cryptoRepository) ListDepositWallets(afterID uuid.UUID, limit int) ([]*models.DepositWallet, error) {
+	var wallets []*models.DepositWallet
+	err := r.db.Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&wallets).Error
+	return wallets, err
+}
+
+func (r *cryptoRepository) RecordTransfers(wallet *models.DepositWallet, deposits []*models.CryptoDeposit) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, deposit := range deposits {
+			err := tx.Clauses(clause.OnConflict{
+				Columns:     []clause.Column{{Name: "deposit_wallet_id"}, {Name: "tx_id"}},
+				TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "tx_id <> ''"}}},
+				DoNothing:   true,
+			}).Create(deposit).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.DepositWallet{}).
+			Where("id = ?", wallet.ID).
+			Updates(map[string]interface{}{
+				"last_transfer_at": wallet.LastTransferAt,
+				"last_checked_at":  wallet.LastCheckedAt,
+			}).Error
+	})
+}
+
+// ListPendingDeposits returns the wallet's unconfirmed deposits, oldest first
+func (r *cryptoRepository) ListPendingDeposits(walletID uuid.UUID) ([]*models.CryptoDeposit, error) {
+	var deposits []*models.CryptoDeposit
+	err := r.db.Where("deposit_wallet_id = ? AND status = ?", walletID, models.DepositPending).
+		Order("created_at").
+		Find(&deposits).Error
+	return deposits, err
+}
+
+func (r *cryptoRepository) MoveDeposit(deposit *models.CryptoDeposit) error {
+	return r.db.Model(&models.CryptoDeposit{}).
+		Where("id = ? AND status = ?", deposit.ID, models.DepositPending).
+		Update("detected_block", deposit.DetectedBlock).Error
+}
+
+func (r *cryptoRepository) RevertDeposit(deposit *models.CryptoDeposit) error {
+	return r.db.Model(&models.CryptoDeposit{}).
+		Where("id = ? AND status = ?", deposit.ID, models.DepositPending).
+		Update("status", models.DepositReverted).Error
+}
+
+func (r *cryptoRepository) ConfirmDeposit(deposit *models.CryptoDeposit, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Only a pending deposit is credited, so a retried confirmation cannot credit twice
+		result := tx.Model(&models.CryptoDeposit{}).
+			Where("id = ? AND status = ?", deposit.ID, models.DepositPending).
+			Updates(map[string]interface{}{
+				"status":       models.DepositConfirmed,
+				"confirmed_at": now,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		balance := &models.PrepaidBalance{UserID: deposit.UserID, Balance: deposit.Amount}
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"balance":    gorm.Expr("prepaid_balances.balance + EXCLUDED.balance"),
+				"updated_at": now,
+			}),
+		}).Create(balance).Error
+		if err != nil {
+			return err
+		}
+
+		deposit.Status = models.DepositConfirmed
+		deposit.ConfirmedAt = &now
+		return nil
+	})
+}
+
+func (r *cryptoRepository) ListDeposits(userID uuid.UUID, limit int) ([]*models.CryptoDeposit, error) {
+	var deposits []*models.CryptoDeposit
+	err := r.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&deposits).Error
+	return deposits, err
+}
+
+func (r *cryptoRepository) GetPrepaidBalance(userID uuid.UUID) (*models.PrepaidBalance, error) {
+	var balance models.PrepaidBalance
+	err := r.db.Where("user_id = ?", userID).First(&balance).Error
+	return &balance, err
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"time"
+
+	"ironnode/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type InvoiceRepository interface {
+	ListUninvoicedPeriods(limit int) ([]*models.BillingPeriod, error)
+	GetChainUsage(subscriptionID uuid.UUID, periodStart time.Time) ([]*models.ChainUsage, error)
+	CreateInvoice(invoice *models.Invoice) error
+	ListInvoices(userID uuid.UUID, limit int) ([]*models.Invoice, error)
+	GetInvoice(id uuid.UUID) (*models.Invoice, error)
+	ListCollectibleInvoices(now time.Time, maxAttempts, limit int) ([]*models.Invoice, error)
+	RetryOpenInvoices(userID uuid.UUID, now time.Time) error
+}
+
+type invoiceRepository struct {
+	db *gorm.DB
+}
+
+func NewInvoiceRepository(db *gorm.DB) InvoiceRepository {
+	return &invoiceRepository{db: db}
+}
+
+// ListUninvoicedPeriods returns closed billing periods that have no invoice yet, oldest first
+func (r *invoiceRepository) ListUninvoicedPeriods(limit int) ([]*models.BillingPeriod, error) {
+	var periods []*models.BillingPeriod
+	err := r.db.
+		Joins("LEFT JOIN invoices ON invoices.billing_period_id = billing_periods.id").
+		Where("invoices.id IS NULL").
+		Order("billing_periods.period_end").
+		Limit(limit).
+		Find(&periods).Error
+	return periods, err
+}
+
+func (r *invoiceRepository) GetChainUsage(subscriptionID uuid.UUID, periodStart time.Time) ([]*models.ChainUsage, error) {
+	var usage []*models.ChainUsage
+	err := r.db.Where("subscription_id = ? AND period_start = ?", subscriptionID, periodStart).
+		Order("credits DESC").
+		Find(&usage).Error
+	return usage, err
+}
+
+// CreateInvoice stores the invoice with its line items
+func (r *invoiceRepository) CreateInvoice(invoice *models.Invoice) error {
+	return r.db.Create(invoice).Error
+}
+
+func (r *invoiceRepository) ListInvoices(userID uuid.UUID, limit int) ([]*models.Invoice, error) {
+	var invoices []*models.Invoice
+	err := r.db.Where("user_id = ?", userID).
+		Order("period_end DESC").
+		Limit(limit).
+		Find(&invoices).Error
+	return invoices, err
+}
+
+func (r *invoiceRepository) GetInvoice(id uuid.UUID) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.db.Preload("LineItems", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).Where("id = ?", id).First(&invoice).Error
+	return &invoice, err
+}
+
+// ListCollectibleInvoices returns open invoices that are due for a payment attempt, oldest first
+func (r *invoiceRepository) ListCollectibleInvoices(now time.Time, maxAttempts, limit int) ([]*models.Invoice, error) {
+	var invoices []*models.Invoice
+	err := r.db.
+		Where("status = ? AND total > 0 AND payment_attempts < ?", models.InvoiceOpen, maxAttempts).
+		Where("next_payment_at IS NULL OR next_payment_at <= ?", now).
+		Order("issued_at").
+		Limit(limit).
+		Find(&invoices).Error
+	return invoices, err
+}
+
+// RetryOpenInvoices moves the user's scheduled payment retries forward to now
+func (r *invoiceRepository) RetryOpenInvoices(userID uuid.UUID, now time.Time) error {
+	return r.db.Model(&models.Invoice{}).
+		Where("user_id = ? AND status = ? AND next_payment_at > ?", userID, models.InvoiceOpen, now).
+		Update("next_payment_at", now).Error
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"fmt"
+
+	"ironnode/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// System accounts on the other side of user postings
+const (
+	ledgerTopUpAccount = "system:topup"
+	ledgerGrantAccount = "system:grant"
+	ledgerUsageAccount = "system:usage"
+)
+
+type LedgerRepository interface {
+	GetTransaction(idempotencyKey string) (*models.LedgerTransaction, error)
+	// ListAccounts returns the user's own account and their API key accounts
+	ListAccounts(userID uuid.UUID) ([]*models.LedgerAccount, error)
+	// ListEntries returns the postings to the user's accounts, newest first;
+	// apiKeyID narrows them to that key's account
+	ListEntries(userID uuid.UUID, apiKeyID *uuid.UUID, limit int) ([]*models.LedgerEntry, error)
+	// Credit posts a grant or refund to the user's account, or to their key's account if APIKeyID is set
+	Credit(transaction *models.LedgerTransaction) error
+	// TopUp debits transaction.Cost from the user's prepaid balance and credits
+	// the bought credits; it reports false without changes if the balance is too low
+	TopUp(transaction *models.LedgerTransaction) (bool, error)
+}
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+func (r *ledgerRepository) GetTransaction(idempotencyKey string) (*models.LedgerTransaction, error) {
+	var transaction models.LedgerTransaction
+	err := r.db.Preload("Entries").Where("idempotency_key = ?", idempotencyKey).First(&transaction).Error
+	return &transaction, err
+}
+
+func (r *ledgerRepository) ListAccounts(userID uuid.UUID) ([]*models.LedgerAccount, error) {
+	var accounts []*models.LedgerAccount
+	err := r.db.Where("user_id = ?", userID).Order("created_at").Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *ledgerRepository) ListEntries(userID uuid.UUID, apiKeyID *uuid.UUID, limit int) ([]*models.LedgerEntry, error) {
+	query := r.db.Joins("Account").Joins("Transaction").Where("\"Account\".user_id = ?", userID)
+	if apiKeyID != nil {
+		query = query.Where("\"Account\".api_key_id = ?", *apiKeyID)
+	}
+
+	var entries []*models.LedgerEntry
+	err := query.Order("ledger_entries.created_at DESC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+func (r *ledgerRepository) Credit(transaction *models.LedgerTransaction) error {
+	source := ledgerGrantAccount
+	if transaction.Type == models.LedgerRefund {
+		source = ledgerUsageAccount
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return creditLedger(tx, transaction, source)
+	})
+}
+
+func (r *ledgerRepository) TopUp(transaction *models.LedgerTransaction) (bool, error) {
+	paid := false
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PrepaidBalance{}).
+			Where("user_id = ? AND balance >= ?", transaction.UserID, transaction.Cost).
+			Update("balance", gorm.Expr("balance - ?", transaction.Cost))
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		paid = true
+		return creditLedger(tx, transaction, ledgerTopUpAccount)
+	})
+
+	return paid, err
+}
+
+// ledgerPosting is one side of a transaction before it is written
+type ledgerPosting struct {
+	account *models.LedgerAccount
+	amount  int64
+}
+
+// creditLedger moves transaction.Amount from a system account to the user's (or key's) account
+func creditLedger(tx *gorm.DB, transaction *models.LedgerTransaction, source string) error {
+	account, err := lockAccount(tx, userAccount(transaction.UserID, transaction.APIKeyID))
+	if err != nil {
+		return err
+	}
+	system, err := systemAccount(tx, source)
+	if err != nil {
+		return err
+	}
+
+	return postLedger(tx, transaction, []ledgerPosting{
+		{account: system, amount: -transaction.Amount},
+		{account: account, amount: transaction.Amount},
+	})
+}
+
+// debitUsage spends up to credits on usage, from the key's account first and
+// then from the user's. Balances never go below zero, so fewer credits may be
+// debited; transaction.Amount is set to what was, and nothing is posted if it is 0.
+func debitUsage(tx *gorm.DB, transaction *models.LedgerTransaction, credits int64) error {
+	// Always the key's account before the user's, so concurrent debits lock in the same order
+	codes := []string{userAccount(transaction.UserID, nil).Code}
+	if transaction.APIKeyID != nil {
+		codes = append([]string{userAccount(transaction.UserID, transaction.APIKeyID).Code}, codes...)
+	}
+
+	var postings []ledgerPosting
+	remaining := credits
+	for _, code := range codes {
+		// Users who never had credits have no account to lock
+		var accounts []*models.LedgerAccount
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code = ?", code).
+			Limit(1).
+			Find(&accounts).Error
+		if err != nil {
+			return err
+		}
+		if len(accounts) == 0 {
+			continue
+		}
+
+		amount := accounts[0].Balance
+		if amount > remaining {
+			amount = remaining
+		}
+		if amount <= 0 {
+			continue
+		}
+		postings = append(postings, ledgerPosting{account: accounts[0], amount: -amount})
+		remaining -= amount
+	}
+
+	transaction.Amount = credits - remaining
+	if transaction.Amount == 0 {
+		return nil
+	}
+
+	system, err := systemAccount(tx, ledgerUsageAccount)
+	if err != nil {
+		return err
+	}
+	postings = append(postings, ledgerPosting{account: system, amount: transaction.Amount})
+
+	return postLedger(tx, transaction, postings)
+}
+
+// postLedger writes a balanced transaction and its entries and updates the running balances.
+// A transaction whose idempotency key was used before fails on the unique index.
+func postLedger(tx *gorm.DB, transaction *models.LedgerTransaction, postings []ledgerPosting) error {
+	var sum int64
+	for _, posting := range postings {
+		sum += posting.amount
+	}
+	if sum != 0 {
+		return fmt.Errorf("unbalanced ledger transaction %s: entries add up to %d", transaction.IdempotencyKey, sum)
+	}
+
+	if err := tx.Omit("Entries").Create(transaction).Error; err != nil {
+		return err
+	}
+
+	transaction.Entries = transaction.Entries[:0]
+	for _, posting := range postings {
+		account := posting.account
+		entry := models.LedgerEntry{
+			TransactionID: transaction.ID,
+			AccountID:     account.ID,
+			Amount:        posting.amount,
+		}
+
+		if account.Type != models.LedgerAccountSystem {
+			account.Balance += posting.amount
+			entry.BalanceAfter = account.Balance
+
+			err := tx.Model(&models.LedgerAccount{}).
+				Where("id = ?", account.ID).
+				Update("balance", account.Balance).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Omit("Transaction", "Account").Create(&entry).Error; err != nil {
+			return err
+		}
+		transaction.Entries = append(transaction.Entries, entry)
+	}
+
+	return nil
+}
+
+// userAccount describes the user's account, or their key's account if apiKeyID is set
+func userAccount(userID uuid.UUID, apiKeyID *uuid.UUID) *models.LedgerAccount {
+	if apiKeyID != nil {
+		return &models.LedgerAccount{
+			Code:     fmt.Sprintf("user:%s:key:%s", userID, *apiKeyID),
+			Type:     models.LedgerAccountAPIKey,
+			UserID:   &userID,
+			APIKeyID: apiKeyID,
+		}
+	}
+
+	return &models.LedgerAccount{
+		Code:   fmt.Sprintf("user:%s", userID),
+		Type:   models.LedgerAccountUser,
+		UserID: &userID,
+	}
+}
+
+// systemAccount returns a system account without locking it
+func systemAccount(tx *gorm.DB, code string) (*models.LedgerAccount, error) {
+	account := &models.LedgerAccount{}
+	err := tx.Where("code = ?", code).First(account).Error
+	if err == nil {
+		return account, nil
+	}
+
+	return lockAccount(tx, &models.LedgerAccount{Code: code, Type: models.LedgerAccountSystem})
+}
+
+// lockAccount creates the account on first use and reads it back locked for the rest of the transaction
+func lockAccount(tx *gorm.DB, account *models.LedgerAccount) (*models.LedgerAccount, error) {
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoNothing: true,
+	}).Create(account).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var locked models.LedgerAccount
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code = ?", account.Code).
+		First(&locked).Error
+	return &locked, err
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"ironnode/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PaymentRepository interface {
+	GetCustomer(userID uuid.UUID) (*models.PaymentCustomer, error)
+	SaveCustomer(customer *models.PaymentCustomer) error
+	GetPayment(id uuid.UUID) (*models.Payment, error)
+	GetPaymentByCharge(provider, chargeID string) (*models.Payment, error)
+	// GetPendingPayment returns the invoice's latest charge still waiting for its outcome
+	GetPendingPayment(invoiceID uuid.UUID) (*models.Payment, error)
+	UpdatePayment(payment *models.Payment) error
+	// RecordPayment stores a payment attempt together with the invoice's new payment state
+	RecordPayment(payment *models.Payment, invoice *models.Invoice) error
+	// PayFromBalance debits the payment amount from the user's prepaid balance and
+	// records it; it reports false without changes if the balance is too low
+	PayFromBalance(payment *models.Payment, invoice *models.Invoice) (bool, error)
+}
+
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) PaymentRepository {
+	return &paymentRepository{db: db}
+}
+
+func (r *paymentRepository) GetCustomer(userID uuid.UUID) (*models.PaymentCustomer, error) {
+	var customer models.PaymentCustomer
+	err := r.db.Where("user_id = ?", userID).First(&customer).Error
+	return &customer, err
+}
+
+func (r *paymentRepository) SaveCustomer(customer *models.PaymentCustomer) error {
+	return r.db.Save(customer).Error
+}
+
+func (r *paymentRepository) GetPayment(id uuid.UUID) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.db.Where("id = ?", id).First(&payment).Error
+	return &payment, err
+}
+
+func (r *paymentRepository) GetPaymentByCharge(provider, chargeID string) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.db.Where("provider = ? AND charge_id = ?", provider, chargeID).
+		Order("created_at DESC").
+		First(&payment).Error
+	return &payment, err
+}
+
+func (r *paymentRepository) GetPendingPayment(invoiceID uuid.UUID) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.db.Where("invoice_id = ? AND status = ?", invoiceID, models.PaymentPending).
+		Order("attempt DESC").
+		First(&payment).Error
+	return &payment, err
+}
+
+func (r *paymentRepository) UpdatePayment(payment *models.Payment) error {
+	return r.db.Save(payment).Error
+}
+
+func (r *paymentRepository) RecordPayment(payment *models.Payment, invoice *models.Invoice) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return recordPayment(tx, payment, invoice)
+	})
+}
+
+func (r *paymentRepository) PayFromBalance(payment *models.Payment, invoice *models.Invoice) (bool, error) {
+	paid := false
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PrepaidBalance{}).
+			Where("user_id = ? AND balance >= ?", payment.UserID, payment.Amount).
+			Update("balance", gorm.Expr("balance - ?", payment.Amount))
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		paid = true
+		return recordPayment(tx, payment, invoice)
+	})
+
+	return paid, err
+}
+
+func recordPayment(tx *gorm.DB, payment *models.Payment, invoice *models.Invoice) error {
+	if err := tx.Save(payment).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.Invoice{}).
+		Where("id = ?", invoice.ID).
+		Updates(map[string]interface{}{
+			"status":           invoice.Status,
+			"paid_at":          invoice.PaidAt,
+			"payment_attempts": invoice.PaymentAttempts,
+			"next_payment_at":  invoice.NextPaymentAt,
+		}).Error
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"math"
 	"time"
 
 	"ironnode/pkg/models"
@@ -10,46 +11,75 @@ import (
 	"github.com/google/uuid"
 )
 
+// Plan is the monthly allowance and price of a plan
+type Plan struct {
+	Requests     int
+	Price        float64
+	OveragePrice float64 // per 1,000 credits over the allowance
+}
+
+// Plans is the plan table used for new subscriptions, plan changes and invoices
+var Plans = map[models.PlanType]Plan{
+	models.FreePlan:         {Requests: 10000, Price: 0, OveragePrice: 0},
+	models.BasicPlan:        {Requests: 100000, Price: 29.99, OveragePrice: 0.50},
+	models.ProfessionalPlan: {Requests: 1000000, Price: 99.99, OveragePrice: 0.40},
+	models.EnterprisePlan:   {Requests: 10000000, Price: 499.99, OveragePrice: 0.30},
+}
+
+var (
+	ErrInvalidPlan        = errors.New("invalid plan type")
+	ErrSubscriptionClosed = errors.New("subscription is canceled or expired")
+)
+
 type BillingService interface {
 	CreateSubscription(userID uuid.UUID, planType models.PlanType) (*models.Subscription, error)
 	GetSubscription(userID uuid.UUID) (*models.Subscription, error)
-	UpdateSubscription(userID uuid.UUID, planType models.PlanType) error
-	CheckQuota(userID uuid.UUID) (bool, error)
-	IncrementUsage(userID uuid.UUID) error
+	UpdateSubscription(userID uuid.UUID, planType models.PlanType) (*models.Subscription, error)
+	CancelSubscription(userID uuid.UUID, atPeriodEnd bool) (*models.Subscription, error)
+	ListBillingPeriods(userID uuid.UUID, limit int) ([]*models.BillingPeriod, error)
+	// CheckQuota reports whether calls made with apiKeyID may go on, and the prepaid credits they can spend
+	CheckQuota(userID uuid.UUID, apiKeyID *uuid.UUID) (bool, int64, error)
+	// KeyUsage returns the credits spent with apiKeyID in the user's current period
+	KeyUsage(userID, apiKeyID uuid.UUID) (int64, error)
+	IncrementUsage(userID uuid.UUID, apiKeyID *uuid.UUID, credits int64, byChain map[string]int64, idempotencyKey string) error
 }
 
 type billingService struct {
-	repo repository.BillingRepository
+	repo        repository.BillingRepository
+	ledger      repository.LedgerRepository
+	trialPeriod time.Duration
 }
 
-func NewBillingService(repo repository.BillingRepository) BillingService {
-	return &billingService{repo: repo}
+// NewBillingService creates the service; paid plans start with a trial of trialPeriod unless it is 0
+func NewBillingService(repo repository.BillingRepository, ledger repository.LedgerRepository, trialPeriod time.Duration) BillingService {
+	return &billingService{repo: repo, ledger: ledger, trialPeriod: trialPeriod}
 }
 
 func (s *billingService) CreateSubscription(userID uuid.UUID, planType models.PlanType) (*models.Subscription, error) {
-	// Define plan limits
-	planLimits := map[models.PlanType]struct {
-		requests int
-		price    float64
-	}{
-		models.FreePlan:          {requests: 10000, price: 0},
-		models.BasicPlan:         {requests: 100000, price: 29.99},
-		models.ProfessionalPlan:  {requests: 1000000, price: 99.99},
-		models.EnterprisePlan:    {requests: 10000000, price: 499.99},
-	}
-
-	plan, exists := planLimits[planType]
+	plan, exists := Plans[planType]
 	if !exists {
-		return nil, errors.New("invalid plan type")
+		return nil, ErrInvalidPlan
 	}
 
+	now := time.Now()
 	subscription := &models.Subscription{
-		UserID:           userID,
-		PlanType:         planType,
-		RequestsPerMonth: plan.requests,
-		Price:            plan.price,
-		IsActive:         true,
-		StartsAt:         time.Now(),
+		UserID:             userID,
+		PlanType:           planType,
+		RequestsPerMonth:   plan.Requests,
+		Price:              plan.Price,
+		IsActive:           true,
+		Status:             models.StatusActive,
+		StartsAt:           now,
+		CurrentPeriodStart: now,
+		CurrentPeriodEnd:   models.AddMonths(now, 1),
+	}
+
+	// The trial is a period of its own; the first paid period starts when it ends
+	if s.trialPeriod > 0 && plan.Price > 0 {
+		trialEndsAt := now.Add(s.trialPeriod)
+		subscription.Status = models.StatusTrialing
+		subscription.TrialEndsAt = &trialEndsAt
+		subscription.CurrentPeriodEnd = trialEndsAt
 	}
 
 	if err := s.repo.CreateSubscription(subscription); err != nil {
@@ -63,32 +93,181 @@ func (s *billingService) GetSubscription(userID uuid.UUID) (*models.Subscription
 	return s.repo.GetSubscriptionByUser(userID)
 }
 
-func (s *billingService) UpdateSubscription(userID uuid.UUID, planType models.PlanType) error {
+// UpdateSubscription changes the plan mid-period. The rest of the period is
+// prorated: its allowance is blended between the plans and the price difference
+// for the remaining time is charged (or credited) on the next invoice.
+func (s *billingService) UpdateSubscription(userID uuid.UUID, planType models.PlanType) (*models.Subscription, error) {
+	plan, exists := Plans[planType]
+	if !exists {
+		return nil, ErrInvalidPlan
+	}
+
 	subscription, err := s.repo.GetSubscriptionByUser(userID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if !subscription.Status.IsLive() {
+		return nil, ErrSubscriptionClosed
+	}
+
+	if subscription.PlanType == planType {
+		return subscription, nil
+	}
+
+	now := time.Now()
+	periodStart, periodEnd := subscription.CurrentPeriod(now)
+	remaining := remainingFraction(periodStart, periodEnd, now)
+
+	// Trials are free, so only the allowance changes
+	if subscription.Status != models.StatusTrialing {
+		subscription.PendingProration += roundCents((plan.Price - subscription.Price) * remaining)
 	}
 
+	subscription.RequestsPerMonth = int(math.Round(
+		float64(subscription.RequestsPerMonth)*(1-remaining) + float64(plan.Requests)*remaining,
+	))
 	subscription.PlanType = planType
-	// Update limits based on new plan
-	// (similar logic as CreateSubscription)
+	subscription.Price = plan.Price
+
+	if err := s.repo.UpdateSubscription(subscription); err != nil {
+		return nil, err
+	}
 
-	return s.repo.UpdateSubscription(subscription)
+	return subscription, nil
 }
 
-func (s *billingService) CheckQuota(userID uuid.UUID) (bool, error) {
+// CancelSubscription cancels now, or at the end of the current period if atPeriodEnd is set.
+// An immediate cancellation closes the current period with the usage so far.
+func (s *billingService) CancelSubscription(userID uuid.UUID, atPeriodEnd bool) (*models.Subscription, error) {
 	subscription, err := s.repo.GetSubscriptionByUser(userID)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+
+	if !subscription.Status.IsLive() {
+		return nil, ErrSubscriptionClosed
 	}
 
-	if subscription.IsExpired() {
-		return false, errors.New("subscription expired")
+	if atPeriodEnd {
+		subscription.CancelAtPeriodEnd = true
+		if err := s.repo.UpdateSubscription(subscription); err != nil {
+			return nil, err
+		}
+		return subscription, nil
 	}
 
-	return subscription.HasRequestsAvailable(), nil
+	if err := cancelNow(s.repo, subscription, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (s *billingService) ListBillingPeriods(userID uuid.UUID, limit int) ([]*models.BillingPeriod, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 12
+	}
+	return s.repo.ListBillingPeriods(userID, limit)
+}
+
+// CheckQuota allows calls while the period's allowance lasts and after that
+// while the user, or the API key the calls are made with, has prepaid credits
+func (s *billingService) CheckQuota(userID uuid.UUID, apiKeyID *uuid.UUID) (bool, int64, error) {
+	subscription, err := s.repo.GetSubscriptionByUser(userID)
+	if err != nil {
+		return false, 0, err
+	}
+
+	if subscription.IsExpired() || !subscription.Status.IsLive() {
+		return false, 0, errors.New("subscription expired")
+	}
+
+	balance, err := creditBalance(s.ledger, userID)
+	if err != nil {
+		return false, 0, err
+	}
+	prepaid := balance.Available(apiKeyID)
+
+	return subscription.HasRequestsAvailable() || prepaid > 0, prepaid, nil
+}
+
+func (s *billingService) KeyUsage(userID, apiKeyID uuid.UUID) (int64, error) {
+	subscription, err := s.repo.GetSubscriptionByUser(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.repo.GetKeyUsage(subscription.ID, subscription.CurrentPeriodStart, apiKeyID)
+}
+
+// IncrementUsage deducts credits from the user's monthly allowance and, once
+// it is used up, from their prepaid credits. byChain optionally breaks the
+// credits down by blockchain for invoices. A call retried with the same
+// idempotencyKey is not counted twice.
+func (s *billingService) IncrementUsage(userID uuid.UUID, apiKeyID *uuid.UUID, credits int64, byChain map[string]int64, idempotencyKey string) error {
+	if credits < 1 {
+		credits = 1
+	}
+	if idempotencyKey == "" {
+		idempotencyKey = uuid.NewString()
+	}
+
+	debit := &models.LedgerTransaction{
+		IdempotencyKey: idempotencyKey,
+		Type:           models.LedgerUsage,
+		UserID:         userID,
+		APIKeyID:       apiKeyID,
+		Description:    "Usage over the plan allowance",
+	}
+
+	return s.repo.IncrementUsage(userID, credits, byChain, debit)
+}
+
+// cancelNow cancels the subscription and closes its current period with the usage so far
+func cancelNow(repo repository.BillingRepository, subscription *models.Subscription, now time.Time) error {
+	periodStart, _ := subscription.CurrentPeriod(now)
+	period := newBillingPeriod(subscription, periodStart, now)
+
+	if err := subscription.TransitionTo(models.StatusCanceled, now); err != nil {
+		return err
+	}
+	period.Status = subscription.Status
+	subscription.CurrentPeriodEnd = now
+
+	return repo.ClosePeriods(subscription, []*models.BillingPeriod{period})
+}
+
+// newBillingPeriod snapshots the subscription's usage for a period being closed
+func newBillingPeriod(subscription *models.Subscription, start, end time.Time) *models.BillingPeriod {
+	return &models.BillingPeriod{
+		SubscriptionID:   subscription.ID,
+		UserID:           subscription.UserID,
+		PlanType:         subscription.PlanType,
+		Status:           subscription.Status,
+		PeriodStart:      start,
+		PeriodEnd:        end,
+		RequestsPerMonth: subscription.RequestsPerMonth,
+		RequestsUsed:     subscription.RequestsUsed,
+		PrepaidCredits:   subscription.PrepaidCreditsUsed,
+		Price:            subscription.Price,
+		Proration:        subscription.PendingProration,
+		Trial:            subscription.Status == models.StatusTrialing,
+	}
+}
+
+// remainingFraction returns the share of the period still ahead of now, between 0 and 1
+func remainingFraction(start, end, now time.Time) float64 {
+	total := end.Sub(start)
+	if total <= 0 || !now.Before(end) {
+		return 0
+	}
+	if now.Before(start) {
+		return 1
+	}
+	return float64(end.Sub(now)) / float64(total)
 }
 
-func (s *billingService) IncrementUsage(userID uuid.UUID) error {
-	return s.repo.IncrementUsage(userID)
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
 }
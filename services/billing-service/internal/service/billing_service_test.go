@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"ironnode/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+func TestKeyUsageCountsCurrentPeriod(t *testing.T) {
+	apiKeyID := uuid.New()
+	previous := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	current := previous.AddDate(0, 1, 0)
+
+	repo := &fakeBillingRepo{
+		subscription: &models.Subscription{
+			ID:                 uuid.New(),
+			Status:             models.StatusActive,
+			CurrentPeriodStart: current,
+			CurrentPeriodEnd:   current.AddDate(0, 1, 0),
+		},
+		keyUsage: map[string]int64{
+			previous.Format(time.RFC3339) + "/" + apiKeyID.String(): 9000,
+			current.Format(time.RFC3339) + "/" + apiKeyID.String():  250,
+		},
+	}
+	billing := &billingService{repo: repo}
+
+	used, err := billing.KeyUsage(uuid.New(), apiKeyID)
+	if err != nil {
+		t.Fatalf("KeyUsage: %v", err)
+	}
+	if used != 250 {
+		t.Errorf("used = %d, want 250 from the current period only", used)
+	}
+
+	if used, _ := billing.KeyUsage(uuid.New(), uuid.New()); used != 0 {
+		t.Errorf("unused key used = %d, want 0", used)
+	}
+}
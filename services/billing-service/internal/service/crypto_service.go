@@ -0,0 +1,104 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"ironnode/pkg/crypto"
+	"ironnode/pkg/models"
+	"ironnode/services/billing-service/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// depositWalletPurpose tags the platform wallets that receive billing top-ups
+const depositWalletPurpose = "billing_deposit"
+
+// CryptoService manages USDT TRC20 top-ups of the prepaid balance
+type CryptoService interface {
+	// GetDepositAddress returns the user's deposit wallet, creating it on first use
+	GetDepositAddress(userID uuid.UUID) (*models.DepositWallet, error)
+	ListDeposits(userID uuid.UUID, limit int) ([]*models.CryptoDeposit, error)
+	// GetPrepaidBalance returns the user's balance; users who never topped up have 0
+	GetPrepaidBalance(userID uuid.UUID) (*models.PrepaidBalance, error)
+	// Confirmations is the number of blocks a deposit waits before it is credited
+	Confirmations() int
+}
+
+type cryptoService struct {
+	repo          repository.CryptoRepository
+	encryption    *crypto.EncryptionService
+	confirmations int
+}
+
+func NewCryptoService(repo repository.CryptoRepository, encryption *crypto.EncryptionService, confirmations int) CryptoService {
+	return &cryptoService{
+		repo:          repo,
+		encryption:    encryption,
+		confirmations: confirmations,
+	}
+}
+
+func (s *cryptoService) GetDepositAddress(userID uuid.UUID) (*models.DepositWallet, error) {
+	deposit, err := s.repo.GetDepositWallet(userID)
+	if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+		return deposit, err
+	}
+
+	walletData, err := crypto.GenerateTRC20Wallet()
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedKey, err := s.encryption.Encrypt(walletData.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt private key: %v", err)
+	}
+
+	wallet := &models.Wallet{
+		UserID:              userID,
+		ClientUserID:        userID.String(),
+		Address:             walletData.Address,
+		Network:             models.NetworkTRC20,
+		Purpose:             depositWalletPurpose,
+		PublicKey:           walletData.PublicKey,
+		HexAddress:          walletData.HexAddress,
+		PrivateKeyEncrypted: encryptedKey,
+		IsActive:            true,
+	}
+	deposit = &models.DepositWallet{
+		UserID:  userID,
+		Address: walletData.Address,
+		Network: models.NetworkTRC20,
+	}
+
+	if err := s.repo.CreateDepositWallet(wallet, deposit); err != nil {
+		// A concurrent request may have created it first
+		if existing, getErr := s.repo.GetDepositWallet(userID); getErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+
+	return deposit, nil
+}
+
+func (s *cryptoService) ListDeposits(userID uuid.UUID, limit int) ([]*models.CryptoDeposit, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return s.repo.ListDeposits(userID, limit)
+}
+
+func (s *cryptoService) GetPrepaidBalance(userID uuid.UUID) (*models.PrepaidBalance, error) {
+	balance, err := s.repo.GetPrepaidBalance(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.PrepaidBalance{UserID: userID, Currency: invoiceCurrency}, nil
+	}
+	return balance, err
+}
+
+func (s *cryptoService) Confirmations() int {
+	return s.confirmations
+}
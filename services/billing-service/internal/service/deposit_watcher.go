@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"ironnode/pkg/models"
+	"ironnode/pkg/tron"
+	"ironnode/services/billing-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// depositWalletBatchSize limits how many deposit wallets are loaded per query
+	depositWalletBatchSize = 100
+	// depositTransferBatchSize limits how many transfers to a wallet are read per check;
+	// the rest are read on the next one
+	depositTransferBatchSize = 200
+)
+
+// trc20TransferReader is the part of the Tron client the watcher needs
+type trc20TransferReader interface {
+	GetIncomingTRC20Transfers(address, contract string, minTimestamp int64, limit int) ([]tron.TRC20Transfer, error)
+	GetTransactionInfo(txID string) (*tron.TransactionInfo, error)
+	GetNowBlockNumber() (int64, error)
+}
+
+// DepositWatcher polls deposit wallets for incoming USDT. Every USDT transfer
+// to a wallet is recorded once, by transaction, as a pending deposit in the
+// transaction's block and credited to the prepaid balance once enough blocks
+// have been built on top of it. Outgoing transfers (sweeps) do not affect
+// deposits. Credited deposits make open invoices retry right away, so a
+// past_due subscription renews from the new balance.
+type DepositWatcher interface {
+	Start()
+	Stop()
+	// Scan checks every deposit wallet once and returns how many deposits were credited
+	Scan(now time.Time) (int, error)
+}
+
+type depositWatcher struct {
+	repo          repository.CryptoRepository
+	invoices      repository.InvoiceRepository
+	chain         trc20TransferReader
+	confirmations int64
+	interval      time.Duration
+
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewDepositWatcher(
+	repo repository.CryptoRepository,
+	invoices repository.InvoiceRepository,
+	chain trc20TransferReader,
+	confirmations int,
+	interval time.Duration,
+) DepositWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &depositWatcher{
+		repo:          repo,
+		invoices:      invoices,
+		chain:         chain,
+		confirmations: int64(confirmations),
+		interval:      interval,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// Start runs the polling loop in a background goroutine
+func (w *depositWatcher) Start() {
+	w.wg.Add(1)
+	go w.run()
+	log.Printf("[Deposits] Started (interval: %v, confirmations: %d)", w.interval, w.confirmations)
+}
+
+// Stop stops the polling loop and waits for it to finish
+func (w *depositWatcher) Stop() {
+	w.cancel()
+	w.wg.Wait()
+	log.Printf("[Deposits] Stopped")
+}
+
+func (w *depositWatcher) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.scanNow()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.scanNow()
+		}
+	}
+}
+
+func (w *depositWatcher) scanNow() {
+	credited, err := w.Scan(time.Now())
+	if err != nil {
+		log.Printf("[Deposits] Scan failed: %v", err)
+	}
+	if credited > 0 {
+		log.Printf("[Deposits] Credited %d deposits", credited)
+	}
+}
+
+func (w *depositWatcher) Scan(now time.Time) (int, error) {
+	block, err := w.chain.GetNowBlockNumber()
+	if err != nil {
+		return 0, err
+	}
+
+	credited := 0
+	after := uuid.Nil
+
+	for {
+		wallets, err := w.repo.ListDepositWallets(after, depositWalletBatchSize)
+		if err != nil {
+			return credited, err
+		}
+
+		for _, wallet := range wallets {
+			n, err := w.check(wallet, block, now)
+			if err != nil {
+				// Checked again on the next scan
+				log.Printf("[Deposits] Failed to check wallet %s: %v", wallet.Address, err)
+			}
+			credited += n
+		}
+
+		if len(wallets) < depositWalletBatchSize {
+			return credited, nil
+		}
+		after = wallets[len(wallets)-1].ID
+	}
+}
+
+// check records new transfers to the wallet and confirms pending deposits that are deep enough
+func (w *depositWatcher) check(wallet *models.DepositWallet, block int64, now time.Time) (int, error) {
+	if err := w.recordTransfers(wallet, now); err != nil {
+		return 0, err
+	}
+
+	pending, err := w.repo.ListPendingDeposits(wallet.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	credited := 0
+	for _, deposit := range pending {
+		if block-deposit.DetectedBlock < w.confirmations {
+			continue
+		}
+
+		moved, err := w.recheck(deposit)
+		if err != nil {
+			return credited, err
+		}
+		if moved {
+			continue
+		}
+
+		if err := w.repo.ConfirmDeposit(deposit, now); err != nil {
+			return credited, err
+		}
+		if deposit.Status != models.DepositConfirmed {
+			continue
+		}
+		credited++
+		log.Printf("[Deposits] Credited %.6f USDT to user %s (tx %s)", deposit.Amount, deposit.UserID, deposit.TxID)
+
+		if err := w.invoices.RetryOpenInvoices(deposit.UserID, now); err != nil {
+			log.Printf("[Deposits] Failed to reschedule open invoices of user %s: %v", deposit.UserID, err)
+		}
+	}
+
+	return credited, nil
+}
+
+// recheck looks the deposit's transaction up again, since a reorg may have dropped it
+// or moved it to another block. It reports whether the deposit is no longer due:
+// it was reverted, or its depth counts from the new block.
+func (w *depositWatcher) recheck(deposit *models.CryptoDeposit) (bool, error) {
+	info, err := w.chain.GetTransactionInfo(deposit.TxID)
+	if err != nil {
+		return false, err
+	}
+
+	if info == nil || !info.Succeeded() {
+		if err := w.repo.RevertDeposit(deposit); err != nil {
+			return false, err
+		}
+		log.Printf("[Deposits] Deposit of %.6f USDT to %s reverted (tx %s)", deposit.Amount, deposit.Address, deposit.TxID)
+		return true, nil
+	}
+
+	if info.BlockNumber != deposit.DetectedBlock {
+		deposit.DetectedBlock = info.BlockNumber
+		return true, w.repo.MoveDeposit(deposit)
+	}
+
+	return false, nil
+}
+
+// recordTransfers reads USDT transfers to the wallet since the newest one recorded and
+// records each transaction as a pending deposit in its block. Transfers are read from
+// the last one's block time on, so that one is read again and skipped as recorded.
+func (w *depositWatcher) recordTransfers(wallet *models.DepositWallet, now time.Time) error {
+	transfers, err := w.chain.GetIncomingTRC20Transfers(wallet.Address, tron.USDTContractAddress, wallet.LastTransferAt, depositTransferBatchSize)
+	if err != nil {
+		return err
+	}
+
+	var deposits []*models.CryptoDeposit
+	var infoErr error
+
+	for _, transfer := range transfers {
+		amount, err := strconv.ParseInt(transfer.Value, 10, 64)
+		if err != nil || amount <= 0 || transfer.To != wallet.Address {
+			wallet.LastTransferAt = transfer.BlockTimestamp
+			continue
+		}
+
+		info, err := w.chain.GetTransactionInfo(transfer.TxID)
+		if err != nil {
+			infoErr = err
+			break
+		}
+		if info == nil {
+			// Not in a block of the node yet; this and later transfers are read again next time
+			break
+		}
+		wallet.LastTransferAt = transfer.BlockTimestamp
+
+		if !info.Succeeded() {
+			continue
+		}
+
+		deposits = append(deposits, &models.CryptoDeposit{
+			ID:              uuid.New(),
+			UserID:          wallet.UserID,
+			DepositWalletID: wallet.ID,
+			Address:         wallet.Address,
+			TxID:            transfer.TxID,
+			RawAmount:       amount,
+			Amount:          float64(amount) / 1e6,
+			DetectedBlock:   info.BlockNumber,
+			Status:          models.DepositPending,
+		})
+	}
+
+	wallet.LastCheckedAt = &now
+	if err := w.repo.RecordTransfers(wallet, deposits); err != nil {
+		return err
+	}
+	return infoErr
+}
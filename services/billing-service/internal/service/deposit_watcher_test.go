@@ -0,0 +1,249 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"ironnode/pkg/models"
+	"ironnode/pkg/tron"
+	"ironnode/services/billing-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const testDepositAddress = "TXYZopYRdj2D9XRtbG411XZZ3kM5VkAeBf"
+
+// fakeTron serves a fixed transfer history and the blocks of the transactions in it
+type fakeTron struct {
+	transfers []tron.TRC20Transfer
+	blocks    map[string]int64 // by txid; missing for transactions not in a block
+	head      int64
+}
+
+func (f *fakeTron) GetIncomingTRC20Transfers(address, contract string, minTimestamp int64, limit int) ([]tron.TRC20Transfer, error) {
+	var transfers []tron.TRC20Transfer
+	for _, transfer := range f.transfers {
+		if transfer.To == address && transfer.BlockTimestamp >= minTimestamp && len(transfers) < limit {
+			transfers = append(transfers, transfer)
+		}
+	}
+	return transfers, nil
+}
+
+func (f *fakeTron) GetTransactionInfo(txID string) (*tron.TransactionInfo, error) {
+	block, ok := f.blocks[txID]
+	if !ok {
+		return nil, nil
+	}
+	info := &tron.TransactionInfo{ID: txID, BlockNumber: block}
+	info.Receipt.Result = "SUCCESS"
+	return info, nil
+}
+
+func (f *fakeTron) GetNowBlockNumber() (int64, error) {
+	return f.head, nil
+}
+
+// fakeCryptoRepo keeps one deposit wallet and its deposits in memory
+type fakeCryptoRepo struct {
+	repository.CryptoRepository
+	wallet   *models.DepositWallet
+	deposits []*models.CryptoDeposit
+}
+
+func (r *fakeCryptoRepo) ListDepositWallets(afterID uuid.UUID, limit int) ([]*models.DepositWallet, error) {
+	if afterID == r.wallet.ID {
+		return nil, nil
+	}
+	copied := *r.wallet
+	return []*models.DepositWallet{&copied}, nil
+}
+
+func (r *fakeCryptoRepo) RecordTransfers(wallet *models.DepositWallet, deposits []*models.CryptoDeposit) error {
+	for _, deposit := range deposits {
+		if r.find(deposit.TxID) == nil {
+			r.deposits = append(r.deposits, deposit)
+		}
+	}
+	r.wallet.LastTransferAt = wallet.LastTransferAt
+	r.wallet.LastCheckedAt = wallet.LastCheckedAt
+	return nil
+}
+
+func (r *fakeCryptoRepo) ListPendingDeposits(walletID uuid.UUID) ([]*models.CryptoDeposit, error) {
+	var pending []*models.CryptoDeposit
+	for _, deposit := range r.deposits {
+		if deposit.Status == models.DepositPending {
+			copied := *deposit
+			pending = append(pending, &copied)
+		}
+	}
+	return pending, nil
+}
+
+func (r *fakeCryptoRepo) MoveDeposit(deposit *models.CryptoDeposit) error {
+	r.find(deposit.TxID).DetectedBlock = deposit.DetectedBlock
+	return nil
+}
+
+func (r *fakeCryptoRepo) RevertDeposit(deposit *models.CryptoDeposit) error {
+	r.find(deposit.TxID).Status = models.DepositReverted
+	return nil
+}
+
+func (r *fakeCryptoRepo) ConfirmDeposit(deposit *models.CryptoDeposit, now time.Time) error {
+	r.find(deposit.TxID).Status = models.DepositConfirmed
+	deposit.Status = models.DepositConfirmed
+	return nil
+}
+
+func (r *fakeCryptoRepo) find(txID string) *models.CryptoDeposit {
+	for _, deposit := range r.deposits {
+		if deposit.TxID == txID {
+			return deposit
+		}
+	}
+	return nil
+}
+
+type fakeInvoiceRepo struct {
+	repository.InvoiceRepository
+	retried int
+}
+
+func (r *fakeInvoiceRepo) RetryOpenInvoices(userID uuid.UUID, now time.Time) error {
+	r.retried++
+	return nil
+}
+
+func transfer(txID string, value string, at int64) tron.TRC20Transfer {
+	return tron.TRC20Transfer{TxID: txID, From: "TSender", To: testDepositAddress, Value: value, BlockTimestamp: at}
+}
+
+func newTestWatcher(chain *fakeTron) (*depositWatcher, *fakeCryptoRepo, *fakeInvoiceRepo) {
+	repo := &fakeCryptoRepo{wallet: &models.DepositWallet{ID: uuid.New(), UserID: uuid.New(), Address: testDepositAddress}}
+	invoices := &fakeInvoiceRepo{}
+	return NewDepositWatcher(repo, invoices, chain, 19, time.Minute).(*depositWatcher), repo, invoices
+}
+
+func scan(t *testing.T, w *depositWatcher) int {
+	t.Helper()
+	credited, err := w.Scan(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return credited
+}
+
+func TestDepositWatcherRecordsTransfers(t *testing.T) {
+	chain := &fakeTron{
+		transfers: []tron.TRC20Transfer{
+			transfer("tx-1", "5000000", 1000),
+			transfer("tx-2", "2500000", 1000), // same block as tx-1
+			transfer("tx-bad", "not a number", 2000),
+		},
+		blocks: map[string]int64{"tx-1": 100, "tx-2": 100, "tx-bad": 101},
+		head:   105,
+	}
+	w, repo, _ := newTestWatcher(chain)
+
+	if credited := scan(t, w); credited != 0 {
+		t.Errorf("credited %d shallow deposits", credited)
+	}
+	if len(repo.deposits) != 2 {
+		t.Fatalf("recorded %d deposits, want one per transfer", len(repo.deposits))
+	}
+	for i, want := range []struct {
+		txID string
+		raw  int64
+	}{{"tx-1", 5000000}, {"tx-2", 2500000}} {
+		deposit := repo.deposits[i]
+		if deposit.TxID != want.txID || deposit.RawAmount != want.raw || deposit.DetectedBlock != 100 || deposit.Status != models.DepositPending {
+			t.Errorf("deposit %d = %s %d at %d (%s), want %s %d at 100 (pending)",
+				i, deposit.TxID, deposit.RawAmount, deposit.DetectedBlock, deposit.Status, want.txID, want.raw)
+		}
+	}
+	if repo.wallet.LastTransferAt != 2000 {
+		t.Errorf("cursor = %d, want 2000", repo.wallet.LastTransferAt)
+	}
+
+	// The transfer at the cursor is read again but not recorded twice
+	chain.transfers = append(chain.transfers, transfer("tx-3", "1000000", 3000))
+	chain.blocks["tx-3"] = 104
+	scan(t, w)
+	if len(repo.deposits) != 3 {
+		t.Errorf("recorded %d deposits, want 3", len(repo.deposits))
+	}
+}
+
+func TestDepositWatcherWaitsForBlock(t *testing.T) {
+	chain := &fakeTron{
+		transfers: []tron.TRC20Transfer{
+			transfer("tx-1", "1000000", 1000),
+			transfer("tx-2", "1000000", 2000),
+			transfer("tx-3", "1000000", 3000),
+		},
+		blocks: map[string]int64{"tx-1": 100, "tx-3": 102},
+		head:   105,
+	}
+	w, repo, _ := newTestWatcher(chain)
+
+	// tx-2 is not in a block of the node yet, so neither it nor anything after it is recorded
+	scan(t, w)
+	if len(repo.deposits) != 1 || repo.wallet.LastTransferAt != 1000 {
+		t.Fatalf("recorded %d deposits up to %d, want 1 up to 1000", len(repo.deposits), repo.wallet.LastTransferAt)
+	}
+
+	chain.blocks["tx-2"] = 101
+	scan(t, w)
+	if len(repo.deposits) != 3 || repo.wallet.LastTransferAt != 3000 {
+		t.Errorf("recorded %d deposits up to %d, want 3 up to 3000", len(repo.deposits), repo.wallet.LastTransferAt)
+	}
+}
+
+func TestDepositWatcherConfirms(t *testing.T) {
+	chain := &fakeTron{
+		transfers: []tron.TRC20Transfer{
+			transfer("tx-kept", "1000000", 1000),
+			transfer("tx-dropped", "2000000", 1000),
+			transfer("tx-moved", "3000000", 1000),
+		},
+		blocks: map[string]int64{"tx-kept": 100, "tx-dropped": 100, "tx-moved": 100},
+		head:   118,
+	}
+	w, repo, invoices := newTestWatcher(chain)
+
+	if credited := scan(t, w); credited != 0 {
+		t.Fatalf("credited %d deposits 18 blocks deep, want none", credited)
+	}
+
+	// A reorg drops one transaction and moves another to a later block
+	delete(chain.blocks, "tx-dropped")
+	chain.blocks["tx-moved"] = 110
+	chain.head = 119
+
+	if credited := scan(t, w); credited != 1 {
+		t.Errorf("credited %d deposits, want 1", credited)
+	}
+	want := map[string]models.DepositStatus{
+		"tx-kept":    models.DepositConfirmed,
+		"tx-dropped": models.DepositReverted,
+		"tx-moved":   models.DepositPending,
+	}
+	for txID, status := range want {
+		if deposit := repo.find(txID); deposit.Status != status {
+			t.Errorf("%s is %s, want %s", txID, deposit.Status, status)
+		}
+	}
+	if block := repo.find("tx-moved").DetectedBlock; block != 110 {
+		t.Errorf("moved deposit at block %d, want 110", block)
+	}
+	if invoices.retried != 1 {
+		t.Errorf("open invoices retried %d times, want 1", invoices.retried)
+	}
+
+	chain.head = 129
+	if credited := scan(t, w); credited != 1 || repo.find("tx-moved").Status != models.DepositConfirmed {
+		t.Errorf("moved deposit not credited once deep enough in its new block")
+	}
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"ironnode/pkg/models"
+	"ironnode/services/billing-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// invoiceDueAfter is the payment term of an invoice
+	invoiceDueAfter = 14 * 24 * time.Hour
+	// invoiceBatchSize limits how many closed periods are invoiced per query
+	invoiceBatchSize = 100
+	invoiceCurrency  = "USD"
+)
+
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
+// InvoiceService bills closed billing periods
+type InvoiceService interface {
+	// GenerateInvoices creates an invoice for every closed period that has none
+	GenerateInvoices(now time.Time) (int, error)
+	ListInvoices(userID uuid.UUID, limit int) ([]*models.Invoice, error)
+	GetInvoice(userID, invoiceID uuid.UUID) (*models.Invoice, error)
+}
+
+type invoiceService struct {
+	repo repository.InvoiceRepository
+}
+
+func NewInvoiceService(repo repository.InvoiceRepository) InvoiceService {
+	return &invoiceService{repo: repo}
+}
+
+func (s *invoiceService) GenerateInvoices(now time.Time) (int, error) {
+	generated := 0
+
+	for {
+		periods, err := s.repo.ListUninvoicedPeriods(invoiceBatchSize)
+		if err != nil {
+			return generated, err
+		}
+
+		failed := 0
+		for _, period := range periods {
+			invoice, err := s.buildInvoice(period, now)
+			if err == nil {
+				err = s.repo.CreateInvoice(invoice)
+			}
+			if err != nil {
+				// The period stays uninvoiced and is retried on the next run
+				log.Printf("[Invoices] Failed to invoice period %s: %v", period.ID, err)
+				failed++
+				continue
+			}
+			generated++
+		}
+
+		if len(periods) < invoiceBatchSize || failed > 0 {
+			return generated, nil
+		}
+	}
+}
+
+// buildInvoice prices a closed period: the plan base price, any proration
+// from plan changes, overage credits not paid from the credit ledger, and the
+// usage breakdown by chain
+func (s *invoiceService) buildInvoice(period *models.BillingPeriod, now time.Time) (*models.Invoice, error) {
+	invoice := &models.Invoice{
+		ID:              uuid.New(),
+		UserID:          period.UserID,
+		SubscriptionID:  period.SubscriptionID,
+		BillingPeriodID: period.ID,
+		PlanType:        period.PlanType,
+		PeriodStart:     period.PeriodStart,
+		PeriodEnd:       period.PeriodEnd,
+		Currency:        invoiceCurrency,
+		Status:          models.InvoiceOpen,
+		IssuedAt:        now,
+		DueAt:           now.Add(invoiceDueAfter),
+	}
+	invoice.Number = invoiceNumber(invoice)
+
+	plan, ok := Plans[period.PlanType]
+	if !ok {
+		log.Printf("[Invoices] Unknown plan %q for period %s, billing the recorded price", period.PlanType, period.ID)
+		plan = Plan{Requests: period.RequestsPerMonth, Price: period.Price}
+	}
+
+	basePrice := plan.Price
+	description := fmt.Sprintf("%s plan", planName(period.PlanType))
+	if period.Trial {
+		basePrice = 0
+		description += " (trial)"
+	}
+	invoice.LineItems = append(invoice.LineItems, models.InvoiceLineItem{
+		Kind:        models.LineItemPlan,
+		Description: description,
+		Quantity:    1,
+		UnitPrice:   basePrice,
+		Amount:      basePrice,
+	})
+
+	if period.Proration != 0 {
+		invoice.LineItems = append(invoice.LineItems, models.InvoiceLineItem{
+			Kind:        models.LineItemProration,
+			Description: "Plan change proration",
+			Quantity:    1,
+			UnitPrice:   period.Proration,
+			Amount:      period.Proration,
+		})
+	}
+
+	if period.PrepaidCredits > 0 {
+		invoice.LineItems = append(invoice.LineItems, models.InvoiceLineItem{
+			Kind:        models.LineItemPrepaid,
+			Description: "Credits paid from the prepaid credit balance",
+			Quantity:    int64(period.PrepaidCredits),
+		})
+	}
+
+	if overage := int64(period.RequestsUsed - period.RequestsPerMonth - period.PrepaidCredits); overage > 0 && plan.OveragePrice > 0 {
+		invoice.LineItems = append(invoice.LineItems, models.InvoiceLineItem{
+			Kind:        models.LineItemOverage,
+			Description: fmt.Sprintf("Overage credits over %d included", period.RequestsPerMonth),
+			Quantity:    overage,
+			UnitPrice:   plan.OveragePrice / 1000,
+			Amount:      roundCents(float64(overage) * plan.OveragePrice / 1000),
+		})
+	}
+
+	usage, err := s.repo.GetChainUsage(period.SubscriptionID, period.PeriodStart)
+	if err != nil {
+		return nil, err
+	}
+	for _, chain := range usage {
+		invoice.LineItems = append(invoice.LineItems, models.InvoiceLineItem{
+			Kind:        models.LineItemUsage,
+			Description: fmt.Sprintf("%s usage (credits)", chain.Blockchain),
+			Blockchain:  chain.Blockchain,
+			Quantity:    chain.Credits,
+		})
+	}
+
+	for i := range invoice.LineItems {
+		invoice.LineItems[i].Position = i
+		invoice.Total += invoice.LineItems[i].Amount
+	}
+	invoice.Total = math.Max(roundCents(invoice.Total), 0)
+
+	// Nothing to collect
+	if invoice.Total == 0 {
+		invoice.Status = models.InvoicePaid
+		invoice.PaidAt = &now
+	}
+
+	return invoice, nil
+}
+
+// invoiceNumber is human-readable and unique: INV-<year><month>-<id prefix>
+func invoiceNumber(invoice *models.Invoice) string {
+	return fmt.Sprintf("INV-%s-%s", invoice.PeriodEnd.Format("200601"),
+		strings.ToUpper(strings.ReplaceAll(invoice.ID.String(), "-", "")[:8]))
+}
+
+func (s *invoiceService) ListInvoices(userID uuid.UUID, limit int) ([]*models.Invoice, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 12
+	}
+	return s.repo.ListInvoices(userID, limit)
+}
+
+// GetInvoice returns an invoice with its line items if it belongs to the user
+func (s *invoiceService) GetInvoice(userID, invoiceID uuid.UUID) (*models.Invoice, error) {
+	invoice, err := s.repo.GetInvoice(invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.UserID != userID {
+		return nil, ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+// planName capitalizes a plan type for display
+func planName(planType models.PlanType) string {
+	name := string(planType)
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ironnode/pkg/models"
+	"ironnode/services/billing-service/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeInvoicingRepo serves closed periods until they are invoiced and keeps the invoices created
+type fakeInvoicingRepo struct {
+	repository.InvoiceRepository
+	periods    []*models.BillingPeriod
+	chainUsage map[uuid.UUID][]*models.ChainUsage // by subscription
+	invoices   map[uuid.UUID]*models.Invoice
+	failFor    uuid.UUID // period whose invoice fails to be stored
+}
+
+func newFakeInvoicingRepo(periods ...*models.BillingPeriod) *fakeInvoicingRepo {
+	return &fakeInvoicingRepo{
+		periods:    periods,
+		chainUsage: make(map[uuid.UUID][]*models.ChainUsage),
+		invoices:   make(map[uuid.UUID]*models.Invoice),
+	}
+}
+
+func (r *fakeInvoicingRepo) ListUninvoicedPeriods(limit int) ([]*models.BillingPeriod, error) {
+	var periods []*models.BillingPeriod
+	for _, period := range r.periods {
+		if r.invoiceFor(period.ID) == nil && len(periods) < limit {
+			periods = append(periods, period)
+		}
+	}
+	return periods, nil
+}
+
+func (r *fakeInvoicingRepo) GetChainUsage(subscriptionID uuid.UUID, periodStart time.Time) ([]*models.ChainUsage, error) {
+	return r.chainUsage[subscriptionID], nil
+}
+
+func (r *fakeInvoicingRepo) CreateInvoice(invoice *models.Invoice) error {
+	if invoice.BillingPeriodID == r.failFor {
+		return errors.New("database unavailable")
+	}
+	r.invoices[invoice.ID] = invoice
+	return nil
+}
+
+func (r *fakeInvoicingRepo) GetInvoice(id uuid.UUID) (*models.Invoice, error) {
+	invoice, ok := r.invoices[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return invoice, nil
+}
+
+func (r *fakeInvoicingRepo) invoiceFor(periodID uuid.UUID) *models.Invoice {
+	for _, invoice := range r.invoices {
+		if invoice.BillingPeriodID == periodID {
+			return invoice
+		}
+	}
+	return nil
+}
+
+func closedPeriod(planType models.PlanType, used, prepaid int) *models.BillingPeriod {
+	plan := Plans[planType]
+	return &models.BillingPeriod{
+		ID:               uuid.New(),
+		SubscriptionID:   uuid.New(),
+		UserID:           uuid.New(),
+		PlanType:         planType,
+		PeriodStart:      time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:        time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		RequestsPerMonth: plan.Requests,
+		RequestsUsed:     used,
+		PrepaidCredits:   prepaid,
+		Price:            plan.Price,
+	}
+}
+
+func TestGenerateInvoicesLineItems(t *testing.T) {
+	// 130k credits on a 100k plan, 10k of them paid from prepaid credits
+	period := closedPeriod(models.BasicPlan, 130000, 10000)
+	repo := newFakeInvoicingRepo(period)
+	repo.chainUsage[period.SubscriptionID] = []*models.ChainUsage{
+		{Blockchain: "ethereum", Credits: 100000},
+		{Blockchain: "polygon", Credits: 30000},
+	}
+	now := time.Date(2026, 10, 1, 1, 0, 0, 0, time.UTC)
+
+	generated, err := NewInvoiceService(repo).GenerateInvoices(now)
+	if err != nil || generated != 1 {
+		t.Fatalf("GenerateInvoices = %d, %v; want 1 invoice", generated, err)
+	}
+
+	invoice := repo.invoiceFor(period.ID)
+	want := []struct {
+		kind     models.LineItemKind
+		quantity int64
+		amount   float64
+	}{
+		{models.LineItemPlan, 1, 29.99},
+		{models.LineItemPrepaid, 10000, 0},
+		{models.LineItemOverage, 20000, 10.00},
+		{models.LineItemUsage, 100000, 0},
+		{models.LineItemUsage, 30000, 0},
+	}
+	if len(invoice.LineItems) != len(want) {
+		t.Fatalf("%d line items, want %d: %+v", len(invoice.LineItems), len(want), invoice.LineItems)
+	}
+	for i, item := range invoice.LineItems {
+		if item.Kind != want[i].kind || item.Quantity != want[i].quantity || item.Amount != want[i].amount || item.Position != i {
+			t.Errorf("line %d = %s x%d %.2f at %d, want %s x%d %.2f", i, item.Kind, item.Quantity, item.Amount, item.Position,
+				want[i].kind, want[i].quantity, want[i].amount)
+		}
+	}
+	if invoice.LineItems[3].Blockchain != "ethereum" || invoice.LineItems[4].Blockchain != "polygon" {
+		t.Errorf("usage lines for %s and %s", invoice.LineItems[3].Blockchain, invoice.LineItems[4].Blockchain)
+	}
+
+	if invoice.Total != 39.99 || invoice.Status != models.InvoiceOpen || invoice.Currency != "USD" {
+		t.Errorf("total %.2f %s, status %s; want 39.99 USD open", invoice.Total, invoice.Currency, invoice.Status)
+	}
+	if !invoice.DueAt.Equal(now.Add(invoiceDueAfter)) || invoice.UserID != period.UserID {
+		t.Errorf("due %v for user %s", invoice.DueAt, invoice.UserID)
+	}
+}
+
+func TestGenerateInvoicesTrialIsPaid(t *testing.T) {
+	period := closedPeriod(models.ProfessionalPlan, 5000, 0)
+	period.Trial = true
+	repo := newFakeInvoicingRepo(period)
+
+	if _, err := NewInvoiceService(repo).GenerateInvoices(time.Now()); err != nil {
+		t.Fatalf("GenerateInvoices: %v", err)
+	}
+
+	invoice := repo.invoiceFor(period.ID)
+	if invoice.Total != 0 || invoice.Status != models.InvoicePaid || invoice.PaidAt == nil {
+		t.Errorf("total %.2f, status %s; want nothing to collect", invoice.Total, invoice.Status)
+	}
+	if len(invoice.LineItems) != 1 || invoice.LineItems[0].Description != "Professional plan (trial)" {
+		t.Errorf("line items %+v, want only the trial plan", invoice.LineItems)
+	}
+}
+
+func TestGenerateInvoicesRetriesFailedPeriods(t *testing.T) {
+	failing, ok := closedPeriod(models.BasicPlan, 0, 0), closedPeriod(models.BasicPlan, 0, 0)
+	repo := newFakeInvoicingRepo(failing, ok)
+	repo.failFor = failing.ID
+	invoices := NewInvoiceService(repo)
+
+	if generated, err := invoices.GenerateInvoices(time.Now()); err != nil || generated != 1 {
+		t.Fatalf("GenerateInvoices = %d, %v; want the other period invoiced", generated, err)
+	}
+
+	// The failed period is picked up on the next run, without invoicing the other twice
+	repo.failFor = uuid.Nil
+	if generated, err := invoices.GenerateInvoices(time.Now()); err != nil || generated != 1 {
+		t.Fatalf("second GenerateInvoices = %d, %v; want only the failed period", generated, err)
+	}
+	if repo.invoiceFor(failing.ID) == nil || len(repo.invoices) != 2 {
+		t.Errorf("%d invoices, want one per period", len(repo.invoices))
+	}
+}
+
+func TestGetInvoiceOfAnotherUser(t *testing.T) {
+	period := closedPeriod(models.BasicPlan, 0, 0)
+	repo := newFakeInvoicingRepo(period)
+	invoices := NewInvoiceService(repo)
+	invoices.GenerateInvoices(time.Now())
+	invoice := repo.invoiceFor(period.ID)
+
+	if got, err := invoices.GetInvoice(period.UserID, invoice.ID); err != nil || got.ID != invoice.ID {
+		t.Errorf("owner's GetInvoice = %v", err)
+	}
+	if _, err := invoices.GetInvoice(uuid.New(), invoice.ID); !errors.Is(err, ErrInvoiceNotFound) {
+		t.Errorf("another user's GetInvoice = %v, want ErrInvoiceNotFound", err)
+	}
+}
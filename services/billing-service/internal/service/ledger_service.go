@@ -0,0 +1,200 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"ironnode/pkg/models"
+	"ironnode/services/billing-service/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCredits         = errors.New("credits must be positive")
+	ErrIdempotencyKeyRequired = errors.New("idempotency key is required")
+	ErrIdempotencyKeyReused   = errors.New("idempotency key was used for a different request")
+	ErrTopUpTooSmall          = errors.New("top-up costs less than the smallest charge")
+	ErrInsufficientBalance    = errors.New("prepaid balance is too low")
+)
+
+// CreditBalance is a user's prepaid credits: their own account and the credits earmarked for each API key
+type CreditBalance struct {
+	UserID  uuid.UUID
+	Balance int64
+	Keys    map[uuid.UUID]int64
+}
+
+// Total is every credit the user holds
+func (b *CreditBalance) Total() int64 {
+	total := b.Balance
+	for _, credits := range b.Keys {
+		total += credits
+	}
+	return total
+}
+
+// Available is what calls made with apiKeyID can spend: the key's credits and the user's own
+func (b *CreditBalance) Available(apiKeyID *uuid.UUID) int64 {
+	if apiKeyID == nil {
+		return b.Balance
+	}
+	return b.Balance + b.Keys[*apiKeyID]
+}
+
+// LedgerService manages prepaid credits. Credits are spent once a subscription's
+// allowance for the period is used up, so pay-as-you-go usage is not billed as overage.
+// Every change is a double-entry transaction with an idempotency key; posting
+// the same key again returns the first transaction instead of a second one.
+type LedgerService interface {
+	GetBalance(userID uuid.UUID) (*CreditBalance, error)
+	ListEntries(userID uuid.UUID, apiKeyID *uuid.UUID, limit int) ([]*models.LedgerEntry, error)
+	// TopUp buys credits with the prepaid balance; apiKeyID earmarks them for one key
+	TopUp(userID uuid.UUID, apiKeyID *uuid.UUID, credits int64, idempotencyKey string) (*models.LedgerTransaction, error)
+	// Grant adds promotional credits
+	Grant(userID uuid.UUID, apiKeyID *uuid.UUID, credits int64, idempotencyKey, description string) (*models.LedgerTransaction, error)
+	// Refund gives back credits spent on usage
+	Refund(userID uuid.UUID, apiKeyID *uuid.UUID, credits int64, idempotencyKey, description string) (*models.LedgerTransaction, error)
+}
+
+type ledgerService struct {
+	repo        repository.LedgerRepository
+	creditPrice float64
+}
+
+// NewLedgerService creates the service; top-ups cost creditPrice USD per 1,000 credits
+func NewLedgerService(repo repository.LedgerRepository, creditPrice float64) LedgerService {
+	return &ledgerService{repo: repo, creditPrice: creditPrice}
+}
+
+func (s *ledgerService) GetBalance(userID uuid.UUID) (*CreditBalance, error) {
+	return creditBalance(s.repo, userID)
+}
+
+func (s *ledgerService) ListEntries(userID uuid.UUID, apiKeyID *uuid.UUID, limit int) ([]*models.LedgerEntry, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return s.repo.ListEntries(userID, apiKeyID, limit)
+}
+
+func (s *ledgerService) TopUp(userID uuid.UUID, apiKeyID *uuid.UUID, credits int64, idempotencyKey string) (*models.LedgerTransaction, error) {
+	cost := roundCents(float64(credits) * s.creditPrice / 1000)
+	if credits > 0 && cost < 0.01 {
+		return nil, ErrTopUpTooSmall
+	}
+
+	// Clients choose top-up keys, so they are scoped to the user: the same key from
+	// another user, or an internal usage or grant key, is a different transaction
+	if idempotencyKey != "" {
+		idempotencyKey = fmt.Sprintf("topup:%s:%s", userID, idempotencyKey)
+	}
+
+	transaction := &models.LedgerTransaction{
+		IdempotencyKey: idempotencyKey,
+		Type:           models.LedgerTopUp,
+		UserID:         userID,
+		APIKeyID:       apiKeyID,
+		Amount:         credits,
+		Cost:           cost,
+		Description:    fmt.Sprintf("%d credits for %.2f %s", credits, cost, invoiceCurrency),
+	}
+
+	return s.post(transaction, func() error {
+		paid, err := s.repo.TopUp(transaction)
+		if err == nil && !paid {
+			return ErrInsufficientBalance
+		}
+		return err
+	})
+}
+
+func (s *ledgerService) Grant(userID uuid.UUID, apiKeyID *uuid.UUID, credits int64, idempotencyKey, description string) (*models.LedgerTransaction, error) {
+	if description == "" {
+		description = "Promotional credits"
+	}
+	return s.credit(models.LedgerGrant, userID, apiKeyID, credits, idempotencyKey, description)
+}
+
+func (s *ledgerService) Refund(userID uuid.UUID, apiKeyID *uuid.UUID, credits int64, idempotencyKey, description string) (*models.LedgerTransaction, error) {
+	if description == "" {
+		description = "Refunded credits"
+	}
+	return s.credit(models.LedgerRefund, userID, apiKeyID, credits, idempotencyKey, description)
+}
+
+func (s *ledgerService) credit(entryType models.LedgerEntryType, userID uuid.UUID, apiKeyID *uuid.UUID, credits int64, idempotencyKey, description string) (*models.LedgerTransaction, error) {
+	transaction := &models.LedgerTransaction{
+		IdempotencyKey: idempotencyKey,
+		Type:           entryType,
+		UserID:         userID,
+		APIKeyID:       apiKeyID,
+		Amount:         credits,
+		Description:    description,
+	}
+
+	return s.post(transaction, func() error {
+		return s.repo.Credit(transaction)
+	})
+}
+
+// post runs write unless the transaction's idempotency key was posted before,
+// in which case the earlier transaction is returned
+func (s *ledgerService) post(transaction *models.LedgerTransaction, write func() error) (*models.LedgerTransaction, error) {
+	if transaction.Amount <= 0 {
+		return nil, ErrInvalidCredits
+	}
+	if transaction.IdempotencyKey == "" {
+		return nil, ErrIdempotencyKeyRequired
+	}
+
+	existing, err := s.repo.GetTransaction(transaction.IdempotencyKey)
+	if err == nil {
+		return replayed(existing, transaction)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := write(); err != nil {
+		// A concurrent request with the same key may have posted first
+		if existing, getErr := s.repo.GetTransaction(transaction.IdempotencyKey); getErr == nil {
+			return replayed(existing, transaction)
+		}
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// replayed returns the earlier transaction if it was posted for the same request
+func replayed(existing, transaction *models.LedgerTransaction) (*models.LedgerTransaction, error) {
+	sameKey := (existing.APIKeyID == nil) == (transaction.APIKeyID == nil) &&
+		(existing.APIKeyID == nil || *existing.APIKeyID == *transaction.APIKeyID)
+
+	if existing.Type != transaction.Type || existing.UserID != transaction.UserID ||
+		existing.Amount != transaction.Amount || !sameKey {
+		return nil, ErrIdempotencyKeyReused
+	}
+	return existing, nil
+}
+
+// creditBalance sums up the user's ledger accounts; users who never had credits have 0
+func creditBalance(repo repository.LedgerRepository, userID uuid.UUID) (*CreditBalance, error) {
+	accounts, err := repo.ListAccounts(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &CreditBalance{UserID: userID, Keys: make(map[uuid.UUID]int64)}
+	for _, account := range accounts {
+		if account.APIKeyID != nil {
+			balance.Keys[*account.APIKeyID] = account.Balance
+			continue
+		}
+		balance.Balance = account.Balance
+	}
+
+	return balance, nil
+}
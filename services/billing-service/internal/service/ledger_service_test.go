@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+
+	"ironnode/pkg/models"
+	"ironnode/services/billing-service/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeLedgerRepo keeps posted transactions by idempotency key, like the unique index does
+type fakeLedgerRepo struct {
+	repository.LedgerRepository
+	transactions map[string]*models.LedgerTransaction
+}
+
+func (r *fakeLedgerRepo) GetTransaction(idempotencyKey string) (*models.LedgerTransaction, error) {
+	transaction, ok := r.transactions[idempotencyKey]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return transaction, nil
+}
+
+func (r *fakeLedgerRepo) TopUp(transaction *models.LedgerTransaction) (bool, error) {
+	if _, ok := r.transactions[transaction.IdempotencyKey]; ok {
+		return false, gorm.ErrDuplicatedKey
+	}
+	transaction.ID = uuid.New()
+	r.transactions[transaction.IdempotencyKey] = transaction
+	return true, nil
+}
+
+func TestTopUpScopesIdempotencyKeyToUser(t *testing.T) {
+	repo := &fakeLedgerRepo{transactions: make(map[string]*models.LedgerTransaction)}
+	ledger := NewLedgerService(repo, 1)
+	alice, bob := uuid.New(), uuid.New()
+
+	first, err := ledger.TopUp(alice, nil, 1000, "1")
+	if err != nil {
+		t.Fatalf("alice's top-up: %v", err)
+	}
+	second, err := ledger.TopUp(bob, nil, 2000, "1")
+	if err != nil {
+		t.Fatalf("bob's top-up with the same key: %v", err)
+	}
+	if first.ID == second.ID || second.UserID != bob {
+		t.Error("bob's top-up replayed alice's")
+	}
+
+	// The same user repeating the key still gets the first top-up back
+	replay, err := ledger.TopUp(alice, nil, 1000, "1")
+	if err != nil {
+		t.Fatalf("alice's retry: %v", err)
+	}
+	if replay.ID != first.ID {
+		t.Error("retry posted a second top-up")
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"ironnode/pkg/models"
+	"ironnode/services/billing-service/internal/repository"
+)
+
+const (
+	// rolloverBatchSize limits how many due subscriptions are loaded per query
+	rolloverBatchSize = 100
+	// maxClosedPeriods bounds catch-up after a long outage
+	maxClosedPeriods = 24
+	// usageIncrementRetention is how long applied usage increments are remembered;
+	// retries of an increment come within minutes
+	usageIncrementRetention = 7 * 24 * time.Hour
+)
+
+// LifecycleManager closes ended billing periods and moves subscriptions
+// through their lifecycle: trials convert to active, cancellations scheduled
+// for the period end take effect, and fixed-term subscriptions expire.
+// Closed periods are then invoiced and open invoices charged.
+type LifecycleManager interface {
+	Start()
+	Stop()
+	// Rollover closes every period that has ended by now
+	Rollover(now time.Time) (int, error)
+}
+
+type lifecycleManager struct {
+	repo     repository.BillingRepository
+	invoices InvoiceService
+	payments PaymentService
+	interval time.Duration
+
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewLifecycleManager(repo repository.BillingRepository, invoices InvoiceService, payments PaymentService, interval time.Duration) LifecycleManager {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &lifecycleManager{
+		repo:     repo,
+		invoices: invoices,
+		payments: payments,
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start runs the rollover loop in a background goroutine
+func (m *lifecycleManager) Start() {
+	m.wg.Add(1)
+	go m.run()
+	log.Printf("[Lifecycle] Started (interval: %v)", m.interval)
+}
+
+// Stop stops the rollover loop and waits for it to finish
+func (m *lifecycleManager) Stop() {
+	m.cancel()
+	m.wg.Wait()
+	log.Printf("[Lifecycle] Stopped")
+}
+
+func (m *lifecycleManager) run() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	m.rolloverNow()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			m.rolloverNow()
+		}
+	}
+}
+
+func (m *lifecycleManager) rolloverNow() {
+	now := time.Now()
+
+	closed, err := m.Rollover(now)
+	if err != nil {
+		log.Printf("[Lifecycle] Rollover failed: %v", err)
+	}
+	if closed > 0 {
+		log.Printf("[Lifecycle] Rolled over %d subscriptions", closed)
+	}
+
+	// Also picks up periods whose invoice failed on an earlier run
+	invoiced, err := m.invoices.GenerateInvoices(now)
+	if err != nil {
+		log.Printf("[Lifecycle] Invoicing failed: %v", err)
+	}
+	if invoiced > 0 {
+		log.Printf("[Lifecycle] Generated %d invoices", invoiced)
+	}
+
+	// New invoices and due dunning retries
+	attempted, err := m.payments.CollectPayments(now)
+	if err != nil {
+		log.Printf("[Lifecycle] Payment collection failed: %v", err)
+	}
+	if attempted > 0 {
+		log.Printf("[Lifecycle] Made %d payment attempts", attempted)
+	}
+
+	if _, err := m.repo.PruneUsageIncrements(now.Add(-usageIncrementRetention)); err != nil {
+		log.Printf("[Lifecycle] Failed to prune usage increments: %v", err)
+	}
+}
+
+func (m *lifecycleManager) Rollover(now time.Time) (int, error) {
+	rolled := 0
+
+	for {
+		subscriptions, err := m.repo.ListDueSubscriptions(now, rolloverBatchSize)
+		if err != nil {
+			return rolled, err
+		}
+
+		failed := 0
+		for _, subscription := range subscriptions {
+			if err := m.rollover(subscription, now); err != nil {
+				// Skip it; it stays due and is retried on the next tick
+				log.Printf("[Lifecycle] Failed to roll over subscription %s: %v", subscription.ID, err)
+				failed++
+				continue
+			}
+			rolled++
+		}
+
+		// Failed subscriptions are still due, so stop instead of fetching them again
+		if len(subscriptions) < rolloverBatchSize || failed > 0 {
+			return rolled, nil
+		}
+	}
+}
+
+// rollover closes the subscription's ended periods and applies the
+// transition due at the end of each one
+func (m *lifecycleManager) rollover(subscription *models.Subscription, now time.Time) error {
+	// Subscriptions created before periods were stored get them set without closing anything
+	if subscription.CurrentPeriodEnd.IsZero() {
+		subscription.CurrentPeriodStart, subscription.CurrentPeriodEnd = subscription.CurrentPeriod(now)
+		return m.repo.UpdateSubscription(subscription)
+	}
+
+	var periods []*models.BillingPeriod
+
+	for !subscription.CurrentPeriodEnd.After(now) && subscription.Status.IsLive() && len(periods) < maxClosedPeriods {
+		periodEnd := subscription.CurrentPeriodEnd
+		period := newBillingPeriod(subscription, subscription.CurrentPeriodStart, periodEnd)
+		periods = append(periods, period)
+
+		// Only the first closed period has usage; later ones were missed while the job was down
+		subscription.RequestsUsed = 0
+		subscription.PrepaidCreditsUsed = 0
+		subscription.PendingProration = 0
+
+		if err := subscription.TransitionTo(nextStatus(subscription, periodEnd), periodEnd); err != nil {
+			return err
+		}
+		period.Status = subscription.Status
+
+		if !subscription.Status.IsLive() {
+			break
+		}
+
+		// A new period starts with the plan's full allowance
+		if plan, ok := Plans[subscription.PlanType]; ok {
+			subscription.RequestsPerMonth = plan.Requests
+		}
+		subscription.CurrentPeriodStart = periodEnd
+		subscription.CurrentPeriodEnd = subscription.NextPeriodEnd(periodEnd)
+	}
+
+	return m.repo.ClosePeriods(subscription, periods)
+}
+
+// nextStatus returns the state a subscription enters when a period ends at periodEnd
+func nextStatus(subscription *models.Subscription, periodEnd time.Time) models.SubscriptionStatus {
+	switch {
+	case subscription.CancelAtPeriodEnd:
+		return models.StatusCanceled
+	case subscription.EndsAt != nil && !subscription.EndsAt.After(periodEnd):
+		return models.StatusExpired
+	case subscription.Status == models.StatusTrialing:
+		return models.StatusActive
+	default:
+		return subscription.Status
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"ironnode/pkg/models"
+	"ironnode/services/billing-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// fakeBillingRepo keeps one subscription and records the periods closed for it
+type fakeBillingRepo struct {
+	repository.BillingRepository
+	subscription *models.Subscription
+	closed       []*models.BillingPeriod
+	keyUsage     map[string]int64 // by period start and API key
+}
+
+func (r *fakeBillingRepo) GetSubscriptionByID(id uuid.UUID) (*models.Subscription, error) {
+	copied := *r.subscription
+	return &copied, nil
+}
+
+func (r *fakeBillingRepo) GetSubscriptionByUser(userID uuid.UUID) (*models.Subscription, error) {
+	copied := *r.subscription
+	return &copied, nil
+}
+
+func (r *fakeBillingRepo) GetKeyUsage(subscriptionID uuid.UUID, periodStart time.Time, apiKeyID uuid.UUID) (int64, error) {
+	return r.keyUsage[periodStart.Format(time.RFC3339)+"/"+apiKeyID.String()], nil
+}
+
+func (r *fakeBillingRepo) UpdateSubscription(subscription *models.Subscription) error {
+	copied := *subscription
+	r.subscription = &copied
+	return nil
+}
+
+func (r *fakeBillingRepo) ClosePeriods(subscription *models.Subscription, periods []*models.BillingPeriod) error {
+	copied := *subscription
+	r.subscription = &copied
+	r.closed = append(r.closed, periods...)
+	return nil
+}
+
+func TestRolloverCarriesUsageOnlyIntoFirstMissedPeriod(t *testing.T) {
+	repo := &fakeBillingRepo{}
+	manager := &lifecycleManager{repo: repo}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	subscription := &models.Subscription{
+		ID:                 uuid.New(),
+		UserID:             uuid.New(),
+		PlanType:           models.BasicPlan,
+		Status:             models.StatusActive,
+		RequestsUsed:       1500,
+		PrepaidCreditsUsed: 300,
+		StartsAt:           start,
+		CurrentPeriodStart: start,
+		CurrentPeriodEnd:   start.AddDate(0, 1, 0),
+	}
+
+	// Three periods ended while the job was down
+	if err := manager.rollover(subscription, start.AddDate(0, 3, 1)); err != nil {
+		t.Fatalf("rollover: %v", err)
+	}
+
+	if len(repo.closed) != 3 {
+		t.Fatalf("closed %d periods, want 3", len(repo.closed))
+	}
+	if first := repo.closed[0]; first.RequestsUsed != 1500 || first.PrepaidCredits != 300 {
+		t.Errorf("first period usage = %d, prepaid %d; want 1500, prepaid 300", first.RequestsUsed, first.PrepaidCredits)
+	}
+	for i, period := range repo.closed[1:] {
+		if period.RequestsUsed != 0 || period.PrepaidCredits != 0 {
+			t.Errorf("missed period %d usage = %d, prepaid %d; want none", i+1, period.RequestsUsed, period.PrepaidCredits)
+		}
+	}
+	if !subscription.CurrentPeriodStart.Equal(start.AddDate(0, 3, 0)) {
+		t.Errorf("current period starts %v, want %v", subscription.CurrentPeriodStart, start.AddDate(0, 3, 0))
+	}
+}
+
+func TestRolloverKeepsPeriodEndsOnTheAnchorDay(t *testing.T) {
+	repo := &fakeBillingRepo{}
+	manager := &lifecycleManager{repo: repo}
+
+	start := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)
+	subscription := &models.Subscription{
+		ID:                 uuid.New(),
+		UserID:             uuid.New(),
+		PlanType:           models.BasicPlan,
+		Status:             models.StatusActive,
+		StartsAt:           start,
+		CurrentPeriodStart: start,
+		CurrentPeriodEnd:   models.AddMonths(start, 1),
+	}
+
+	if err := manager.rollover(subscription, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("rollover: %v", err)
+	}
+
+	want := []time.Time{
+		time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC),
+		time.Date(2026, 4, 30, 12, 0, 0, 0, time.UTC),
+	}
+	if len(repo.closed) != len(want) {
+		t.Fatalf("closed %d periods, want %d", len(repo.closed), len(want))
+	}
+	for i, period := range repo.closed {
+		if !period.PeriodEnd.Equal(want[i]) {
+			t.Errorf("period %d ends %v, want %v", i, period.PeriodEnd, want[i])
+		}
+	}
+	if end := time.Date(2026, 5, 31, 12, 0, 0, 0, time.UTC); !subscription.CurrentPeriodEnd.Equal(end) {
+		t.Errorf("current period ends %v, want %v", subscription.CurrentPeriodEnd, end)
+	}
+}
+
+func TestRolloverCountsPaidPeriodsFromTrialEnd(t *testing.T) {
+	repo := &fakeBillingRepo{}
+	manager := &lifecycleManager{repo: repo}
+
+	start := time.Date(2026, 1, 17, 0, 0, 0, 0, time.UTC)
+	trialEndsAt := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	subscription := &models.Subscription{
+		ID:                 uuid.New(),
+		UserID:             uuid.New(),
+		PlanType:           models.BasicPlan,
+		Status:             models.StatusTrialing,
+		StartsAt:           start,
+		TrialEndsAt:        &trialEndsAt,
+		CurrentPeriodStart: start,
+		CurrentPeriodEnd:   trialEndsAt,
+	}
+
+	if err := manager.rollover(subscription, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("rollover: %v", err)
+	}
+
+	if subscription.Status != models.StatusActive {
+		t.Errorf("status = %s, want %s", subscription.Status, models.StatusActive)
+	}
+	if end := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC); !subscription.CurrentPeriodEnd.Equal(end) {
+		t.Errorf("current period ends %v, want %v", subscription.CurrentPeriodEnd, end)
+	}
+}
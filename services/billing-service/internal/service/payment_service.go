@@ -0,0 +1,460 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"ironnode/pkg/models"
+	"ironnode/services/billing-service/internal/payment"
+	"ironnode/services/billing-service/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// paymentBatchSize limits how many invoices are charged per query
+	paymentBatchSize = 100
+	providerTimeout  = 30 * time.Second
+
+	// Failure codes of attempts that never reached the provider
+	failureNoPaymentMethod  = "no_payment_method"
+	failureProviderMismatch = "provider_changed"
+
+	// prepaidProvider marks payments taken from the prepaid balance
+	prepaidProvider = "balance"
+)
+
+var (
+	ErrNoPaymentMethod      = errors.New("no payment method on file")
+	ErrProviderMismatch     = errors.New("payment method belongs to another provider")
+	ErrUnknownProvider      = errors.New("unknown payment provider")
+	ErrPaymentNotRefundable = errors.New("payment cannot be refunded")
+)
+
+// PaymentService collects open invoices, from the prepaid balance if it covers
+// the total and otherwise through the payment provider. A failed charge puts
+// the subscription past_due and is retried every dunning interval; once the
+// retries are used up the subscription is canceled.
+type PaymentService interface {
+	// AttachPaymentMethod makes the card behind token the user's default payment method
+	AttachPaymentMethod(userID uuid.UUID, token string) (*models.PaymentCustomer, error)
+	// CollectPayments charges every open invoice due for a payment attempt
+	CollectPayments(now time.Time) (int, error)
+	// RefundPayment refunds amount of a collected payment; 0 refunds what is left
+	RefundPayment(paymentID uuid.UUID, amount float64) (*models.Payment, error)
+	HandleWebhook(provider string, payload []byte, signature string) error
+}
+
+type paymentService struct {
+	provider        payment.PaymentProvider
+	payments        repository.PaymentRepository
+	invoices        repository.InvoiceRepository
+	billing         repository.BillingRepository
+	maxAttempts     int
+	dunningInterval time.Duration
+}
+
+// NewPaymentService creates the service; a failed invoice is retried dunningRetries
+// times, dunningInterval apart
+func NewPaymentService(
+	provider payment.PaymentProvider,
+	payments repository.PaymentRepository,
+	invoices repository.InvoiceRepository,
+	billing repository.BillingRepository,
+	dunningRetries int,
+	dunningInterval time.Duration,
+) PaymentService {
+	if dunningRetries < 0 {
+		dunningRetries = 0
+	}
+
+	return &paymentService{
+		provider:        provider,
+		payments:        payments,
+		invoices:        invoices,
+		billing:         billing,
+		maxAttempts:     dunningRetries + 1,
+		dunningInterval: dunningInterval,
+	}
+}
+
+func (s *paymentService) AttachPaymentMethod(userID uuid.UUID, token string) (*models.PaymentCustomer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	customer, err := s.payments.GetCustomer(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		customer = &models.PaymentCustomer{UserID: userID}
+	} else if err != nil {
+		return nil, err
+	}
+
+	// New users, and users of a previous provider, get a customer at the current one
+	if customer.CustomerID == "" || customer.Provider != s.provider.Name() {
+		created, err := s.provider.CreateCustomer(ctx, userID.String())
+		if err != nil {
+			return nil, err
+		}
+		customer.Provider = s.provider.Name()
+		customer.CustomerID = created.ID
+	}
+
+	method, err := s.provider.AttachPaymentMethod(ctx, customer.CustomerID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	customer.PaymentMethodID = method.ID
+	customer.CardBrand = method.Brand
+	customer.CardLast4 = method.Last4
+
+	if err := s.payments.SaveCustomer(customer); err != nil {
+		return nil, err
+	}
+
+	// Retry outstanding invoices with the new card instead of waiting for the next dunning attempt
+	if err := s.invoices.RetryOpenInvoices(userID, time.Now()); err != nil {
+		log.Printf("[Payments] Failed to reschedule open invoices of user %s: %v", userID, err)
+	}
+
+	return customer, nil
+}
+
+func (s *paymentService) CollectPayments(now time.Time) (int, error) {
+	attempted := 0
+
+	for {
+		invoices, err := s.invoices.ListCollectibleInvoices(now, s.maxAttempts, paymentBatchSize)
+		if err != nil {
+			return attempted, err
+		}
+
+		failed := 0
+		for _, invoice := range invoices {
+			if err := s.collect(invoice, now); err != nil {
+				// Not counted as an attempt; the invoice is picked up again on the next run
+				log.Printf("[Payments] Failed to charge invoice %s: %v", invoice.Number, err)
+				failed++
+				continue
+			}
+			attempted++
+		}
+
+		if len(invoices) < paymentBatchSize || failed > 0 {
+			return attempted, nil
+		}
+	}
+}
+
+// collect makes one payment attempt for the invoice. Declines count as an
+// attempt; errors reaching the provider do not.
+func (s *paymentService) collect(invoice *models.Invoice, now time.Time) error {
+	// A charge still waiting for its outcome is asked for again under its own
+	// idempotency key instead of starting a new one, so it is never paid twice
+	pending, err := s.payments.GetPendingPayment(invoice.ID)
+	if err == nil {
+		return s.recheck(invoice, pending, now)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	attempt := invoice.PaymentAttempts + 1
+
+	paid, err := s.payFromBalance(invoice, attempt, now)
+	if err != nil || paid {
+		return err
+	}
+
+	record := &models.Payment{
+		UserID:    invoice.UserID,
+		InvoiceID: invoice.ID,
+		Provider:  s.provider.Name(),
+		Attempt:   attempt,
+		Amount:    invoice.Total,
+		Currency:  invoice.Currency,
+		Status:    models.PaymentFailed,
+	}
+
+	customer, err := s.payments.GetCustomer(invoice.UserID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && customer.PaymentMethodID == ""):
+		record.FailureCode = failureNoPaymentMethod
+		record.FailureMessage = ErrNoPaymentMethod.Error()
+
+	case err != nil:
+		return err
+
+	case customer.Provider != s.provider.Name():
+		record.FailureCode = failureProviderMismatch
+		record.FailureMessage = ErrProviderMismatch.Error()
+
+	default:
+		charge, err := s.charge(customer, invoice, record.Attempt)
+		if err != nil {
+			return err
+		}
+		applyCharge(record, charge)
+	}
+
+	invoice.PaymentAttempts = attempt
+	return s.settle(invoice, record, now)
+}
+
+// recheck repeats a pending charge under the idempotency key of its attempt, which
+// returns the original charge, and settles it once its outcome is known.
+// It is not a new attempt.
+func (s *paymentService) recheck(invoice *models.Invoice, record *models.Payment, now time.Time) error {
+	customer, err := s.payments.GetCustomer(invoice.UserID)
+	if err != nil {
+		return err
+	}
+
+	charge, err := s.charge(customer, invoice, record.Attempt)
+	if err != nil {
+		return err
+	}
+	applyCharge(record, charge)
+
+	return s.settle(invoice, record, now)
+}
+
+// charge charges the invoice total to the customer's payment method; the
+// idempotency key is the invoice and attempt, so repeating an attempt never charges twice
+func (s *paymentService) charge(customer *models.PaymentCustomer, invoice *models.Invoice, attempt int) (*payment.Charge, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	return s.provider.Charge(ctx, payment.ChargeRequest{
+		CustomerID:      customer.CustomerID,
+		PaymentMethodID: customer.PaymentMethodID,
+		Amount:          invoice.Total,
+		Currency:        invoice.Currency,
+		Description:     fmt.Sprintf("Invoice %s", invoice.Number),
+		IdempotencyKey:  fmt.Sprintf("%s-%d", invoice.ID, attempt),
+	})
+}
+
+// payFromBalance pays the invoice from the user's prepaid balance if it covers the total
+func (s *paymentService) payFromBalance(invoice *models.Invoice, attempt int, now time.Time) (bool, error) {
+	record := &models.Payment{
+		UserID:    invoice.UserID,
+		InvoiceID: invoice.ID,
+		Provider:  prepaidProvider,
+		Attempt:   attempt,
+		Amount:    invoice.Total,
+		Currency:  invoice.Currency,
+		Status:    models.PaymentSucceeded,
+	}
+
+	paidInvoice := *invoice
+	paidInvoice.PaymentAttempts = attempt
+	s.applyOutcome(&paidInvoice, record, now)
+
+	paid, err := s.payments.PayFromBalance(record, &paidInvoice)
+	if err != nil || !paid {
+		return false, err
+	}
+
+	*invoice = paidInvoice
+	log.Printf("[Payments] Invoice %s paid from prepaid balance", invoice.Number)
+
+	return true, s.updateSubscription(invoice, record.Status, now)
+}
+
+// settle stores the outcome of a payment attempt on the invoice and the subscription
+func (s *paymentService) settle(invoice *models.Invoice, record *models.Payment, now time.Time) error {
+	s.applyOutcome(invoice, record, now)
+
+	if err := s.payments.RecordPayment(record, invoice); err != nil {
+		return err
+	}
+
+	if record.Status == models.PaymentFailed {
+		log.Printf("[Payments] Invoice %s attempt %d/%d failed: %s",
+			invoice.Number, record.Attempt, s.maxAttempts, record.FailureCode)
+	}
+
+	return s.updateSubscription(invoice, record.Status, now)
+}
+
+// applyOutcome sets the invoice's payment state after an attempt
+func (s *paymentService) applyOutcome(invoice *models.Invoice, record *models.Payment, now time.Time) {
+	switch record.Status {
+	case models.PaymentSucceeded:
+		invoice.Status = models.InvoicePaid
+		invoice.PaidAt = &now
+		invoice.NextPaymentAt = nil
+
+	case models.PaymentFailed, models.PaymentPending:
+		// Pending charges are checked again if their webhook never arrives
+		invoice.NextPaymentAt = nil
+		if invoice.PaymentAttempts < s.maxAttempts {
+			next := now.Add(s.dunningInterval)
+			invoice.NextPaymentAt = &next
+		}
+	}
+}
+
+// updateSubscription applies dunning: a failed charge makes the subscription
+// past_due, a successful one makes it active again, and a failure on the last
+// attempt cancels it
+func (s *paymentService) updateSubscription(invoice *models.Invoice, status models.PaymentStatus, now time.Time) error {
+	subscription, err := s.billing.GetSubscriptionByID(invoice.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case status == models.PaymentSucceeded && subscription.Status == models.StatusPastDue:
+		if err := subscription.TransitionTo(models.StatusActive, now); err != nil {
+			return err
+		}
+		return s.billing.UpdateSubscription(subscription)
+
+	case status != models.PaymentFailed || !subscription.Status.IsLive():
+		return nil
+
+	case invoice.PaymentAttempts >= s.maxAttempts:
+		log.Printf("[Payments] Retries for invoice %s exhausted, canceling subscription %s", invoice.Number, subscription.ID)
+		return cancelNow(s.billing, subscription, now)
+
+	case subscription.Status != models.StatusPastDue:
+		if err := subscription.TransitionTo(models.StatusPastDue, now); err != nil {
+			return err
+		}
+		return s.billing.UpdateSubscription(subscription)
+	}
+
+	return nil
+}
+
+func (s *paymentService) RefundPayment(paymentID uuid.UUID, amount float64) (*models.Payment, error) {
+	record, err := s.payments.GetPayment(paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.Status != models.PaymentSucceeded || record.ChargeID == "" {
+		return nil, ErrPaymentNotRefundable
+	}
+	if record.Provider != s.provider.Name() {
+		return nil, ErrUnknownProvider
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	refund, err := s.provider.Refund(ctx, record.ChargeID, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	setRefunded(record, roundCents(record.AmountRefunded+refund.Amount))
+
+	if err := s.payments.UpdatePayment(record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// HandleWebhook applies a provider event to the payment it refers to.
+// Deliveries may repeat, so events for settled payments are ignored.
+func (s *paymentService) HandleWebhook(provider string, payload []byte, signature string) error {
+	if provider != s.provider.Name() {
+		return ErrUnknownProvider
+	}
+
+	event, err := s.provider.HandleWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	record, err := s.payments.GetPaymentByCharge(provider, event.Charge.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("[Payments] Ignoring %s event %s for unknown charge %s", event.Type, event.ID, event.Charge.ID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	switch event.Type {
+	case payment.EventChargeSucceeded, payment.EventChargeFailed:
+		if record.Status != models.PaymentPending {
+			return nil
+		}
+
+		invoice, err := s.invoices.GetInvoice(record.InvoiceID)
+		if err != nil {
+			return err
+		}
+
+		applyCharge(record, &event.Charge)
+
+		// Settled another way in the meantime: the charge is not owed, so it is given back
+		if invoice.Status != models.InvoiceOpen {
+			if record.Status == models.PaymentSucceeded {
+				s.refundUnowed(record)
+			}
+			return s.payments.UpdatePayment(record)
+		}
+
+		return s.settle(invoice, record, time.Now())
+
+	case payment.EventChargeRefunded:
+		if event.Charge.AmountRefunded <= record.AmountRefunded {
+			return nil
+		}
+		setRefunded(record, event.Charge.AmountRefunded)
+		return s.payments.UpdatePayment(record)
+
+	default:
+		log.Printf("[Payments] Ignoring unsupported event type %s", event.Type)
+		return nil
+	}
+}
+
+// refundUnowed refunds a charge that succeeded for an invoice settled otherwise
+func (s *paymentService) refundUnowed(record *models.Payment) {
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	refund, err := s.provider.Refund(ctx, record.ChargeID, 0)
+	if err != nil {
+		log.Printf("[Payments] Failed to refund charge %s of settled invoice %s: %v", record.ChargeID, record.InvoiceID, err)
+		return
+	}
+
+	log.Printf("[Payments] Refunded charge %s of settled invoice %s", record.ChargeID, record.InvoiceID)
+	setRefunded(record, roundCents(record.AmountRefunded+refund.Amount))
+}
+
+// applyCharge copies a provider charge onto the payment record
+func applyCharge(record *models.Payment, charge *payment.Charge) {
+	record.ChargeID = charge.ID
+	record.FailureCode = charge.FailureCode
+	record.FailureMessage = charge.FailureMessage
+
+	switch charge.Status {
+	case payment.ChargeSucceeded:
+		record.Status = models.PaymentSucceeded
+	case payment.ChargePending:
+		record.Status = models.PaymentPending
+	default:
+		record.Status = models.PaymentFailed
+	}
+}
+
+// setRefunded records the refunded total; a fully refunded payment becomes refunded
+func setRefunded(record *models.Payment, amountRefunded float64) {
+	record.AmountRefunded = amountRefunded
+	if record.AmountRefunded >= record.Amount {
+		record.Status = models.PaymentRefunded
+	}
+}
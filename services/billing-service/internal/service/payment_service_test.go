@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ironnode/pkg/models"
+	"ironnode/services/billing-service/internal/payment"
+	"ironnode/services/billing-service/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const testDunningInterval = 24 * time.Hour
+
+// fakeProvider answers new charges with status and repeats charges by idempotency key
+type fakeProvider struct {
+	payment.PaymentProvider
+	status  payment.ChargeStatus
+	charges map[string]*payment.Charge // by idempotency key
+	calls   int
+}
+
+func (p *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *fakeProvider) Charge(ctx context.Context, req payment.ChargeRequest) (*payment.Charge, error) {
+	p.calls++
+	charge, ok := p.charges[req.IdempotencyKey]
+	if !ok {
+		charge = &payment.Charge{ID: "ch_" + req.IdempotencyKey, Amount: req.Amount, Status: p.status}
+		if p.status == payment.ChargeFailed {
+			charge.FailureCode = "card_declined"
+		}
+		p.charges[req.IdempotencyKey] = charge
+	}
+	copied := *charge
+	return &copied, nil
+}
+
+// fakePaymentRepo keeps one customer, their invoice and its payments in memory
+type fakePaymentRepo struct {
+	repository.PaymentRepository
+	customer *models.PaymentCustomer
+	invoice  *models.Invoice
+	payments []*models.Payment
+}
+
+func (r *fakePaymentRepo) GetCustomer(userID uuid.UUID) (*models.PaymentCustomer, error) {
+	return r.customer, nil
+}
+
+func (r *fakePaymentRepo) GetPendingPayment(invoiceID uuid.UUID) (*models.Payment, error) {
+	for i := len(r.payments) - 1; i >= 0; i-- {
+		if r.payments[i].InvoiceID == invoiceID && r.payments[i].Status == models.PaymentPending {
+			copied := *r.payments[i]
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakePaymentRepo) PayFromBalance(payment *models.Payment, invoice *models.Invoice) (bool, error) {
+	return false, nil
+}
+
+func (r *fakePaymentRepo) RecordPayment(payment *models.Payment, invoice *models.Invoice) error {
+	copied := *payment
+	if copied.ID == uuid.Nil {
+		copied.ID = uuid.New()
+		payment.ID = copied.ID
+		r.payments = append(r.payments, &copied)
+	} else {
+		for i := range r.payments {
+			if r.payments[i].ID == copied.ID {
+				r.payments[i] = &copied
+			}
+		}
+	}
+
+	r.invoice.Status = invoice.Status
+	r.invoice.PaidAt = invoice.PaidAt
+	r.invoice.PaymentAttempts = invoice.PaymentAttempts
+	r.invoice.NextPaymentAt = invoice.NextPaymentAt
+	return nil
+}
+
+// fakeCollectibleInvoices serves the payment repo's invoice
+type fakeCollectibleInvoices struct {
+	repository.InvoiceRepository
+	payments *fakePaymentRepo
+}
+
+func (r *fakeCollectibleInvoices) ListCollectibleInvoices(now time.Time, maxAttempts, limit int) ([]*models.Invoice, error) {
+	invoice := r.payments.invoice
+	if invoice.Status != models.InvoiceOpen || invoice.PaymentAttempts >= maxAttempts ||
+		(invoice.NextPaymentAt != nil && invoice.NextPaymentAt.After(now)) {
+		return nil, nil
+	}
+	copied := *invoice
+	return []*models.Invoice{&copied}, nil
+}
+
+type paymentFixture struct {
+	service  PaymentService
+	provider *fakeProvider
+	payments *fakePaymentRepo
+	billing  *fakeBillingRepo
+	start    time.Time
+}
+
+// newPaymentFixture sets up an active subscription with an open invoice and
+// dunningRetries retries dunningInterval apart
+func newPaymentFixture(status payment.ChargeStatus, dunningRetries int) *paymentFixture {
+	userID := uuid.New()
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	subscription := &models.Subscription{
+		ID:                 uuid.New(),
+		UserID:             userID,
+		PlanType:           models.BasicPlan,
+		Status:             models.StatusActive,
+		CurrentPeriodStart: start,
+		CurrentPeriodEnd:   start.AddDate(0, 1, 0),
+	}
+	payments := &fakePaymentRepo{
+		customer: &models.PaymentCustomer{UserID: userID, Provider: "fake", CustomerID: "cus_1", PaymentMethodID: "pm_1"},
+		invoice: &models.Invoice{
+			ID:             uuid.New(),
+			Number:         "INV-1",
+			UserID:         userID,
+			SubscriptionID: subscription.ID,
+			Total:          49,
+			Currency:       "USD",
+			Status:         models.InvoiceOpen,
+		},
+	}
+	provider := &fakeProvider{status: status, charges: make(map[string]*payment.Charge)}
+	billing := &fakeBillingRepo{subscription: subscription}
+
+	return &paymentFixture{
+		service: NewPaymentService(provider, payments, &fakeCollectibleInvoices{payments: payments}, billing,
+			dunningRetries, testDunningInterval),
+		provider: provider,
+		payments: payments,
+		billing:  billing,
+		start:    start,
+	}
+}
+
+func (f *paymentFixture) collect(t *testing.T, now time.Time) int {
+	t.Helper()
+	attempted, err := f.service.CollectPayments(now)
+	if err != nil {
+		t.Fatalf("collect payments: %v", err)
+	}
+	return attempted
+}
+
+func TestCollectPaymentsDunningCancels(t *testing.T) {
+	f := newPaymentFixture(payment.ChargeFailed, 1)
+
+	f.collect(t, f.start)
+	if status := f.billing.subscription.Status; status != models.StatusPastDue {
+		t.Fatalf("after a decline subscription is %s, want past_due", status)
+	}
+	next := f.payments.invoice.NextPaymentAt
+	if next == nil || !next.Equal(f.start.Add(testDunningInterval)) {
+		t.Fatalf("next payment at %v, want one dunning interval later", next)
+	}
+
+	// Nothing is retried before the dunning interval passes
+	if attempted := f.collect(t, f.start.Add(time.Hour)); attempted != 0 {
+		t.Errorf("retried %d invoices before the dunning interval", attempted)
+	}
+
+	f.collect(t, f.start.Add(testDunningInterval))
+	if attempts := f.payments.invoice.PaymentAttempts; attempts != 2 {
+		t.Errorf("payment attempts = %d, want 2", attempts)
+	}
+	if status := f.billing.subscription.Status; status != models.StatusCanceled {
+		t.Errorf("after the last retry failed subscription is %s, want canceled", status)
+	}
+	if f.payments.invoice.NextPaymentAt != nil {
+		t.Error("a retry is scheduled after the last attempt")
+	}
+}
+
+func TestCollectPaymentsRetrySucceeds(t *testing.T) {
+	f := newPaymentFixture(payment.ChargeFailed, 3)
+
+	f.collect(t, f.start)
+	if status := f.billing.subscription.Status; status != models.StatusPastDue {
+		t.Fatalf("after a decline subscription is %s, want past_due", status)
+	}
+
+	f.provider.status = payment.ChargeSucceeded
+	f.collect(t, f.start.Add(testDunningInterval))
+
+	if status := f.payments.invoice.Status; status != models.InvoicePaid {
+		t.Errorf("invoice is %s, want paid", status)
+	}
+	if status := f.billing.subscription.Status; status != models.StatusActive {
+		t.Errorf("after a successful retry subscription is %s, want active", status)
+	}
+}
+
+func TestCollectPaymentsPendingChargeIsNotRepeated(t *testing.T) {
+	f := newPaymentFixture(payment.ChargePending, 3)
+
+	f.collect(t, f.start)
+	if status := f.billing.subscription.Status; status != models.StatusActive {
+		t.Errorf("a pending charge made the subscription %s", status)
+	}
+
+	// The webhook never came: the same charge is asked for again, not a new one
+	f.collect(t, f.start.Add(testDunningInterval))
+	if len(f.provider.charges) != 1 {
+		t.Fatalf("%d charges created, want 1", len(f.provider.charges))
+	}
+	if len(f.payments.payments) != 1 || f.payments.invoice.PaymentAttempts != 1 {
+		t.Fatalf("%d payments, %d attempts; want the single pending attempt",
+			len(f.payments.payments), f.payments.invoice.PaymentAttempts)
+	}
+
+	// The charge went through in the meantime
+	for _, charge := range f.provider.charges {
+		charge.Status = payment.ChargeSucceeded
+	}
+	f.collect(t, f.start.Add(2*testDunningInterval))
+
+	if len(f.provider.charges) != 1 {
+		t.Errorf("%d charges created, want 1", len(f.provider.charges))
+	}
+	if status := f.payments.invoice.Status; status != models.InvoicePaid {
+		t.Errorf("invoice is %s, want paid", status)
+	}
+	if status := f.payments.payments[0].Status; status != models.PaymentSucceeded {
+		t.Errorf("payment is %s, want succeeded", status)
+	}
+}
+
+func TestCollectPaymentsWithoutPaymentMethod(t *testing.T) {
+	f := newPaymentFixture(payment.ChargeSucceeded, 1)
+	f.payments.customer.PaymentMethodID = ""
+
+	f.collect(t, f.start)
+
+	if f.provider.calls != 0 {
+		t.Error("charged without a payment method")
+	}
+	if len(f.payments.payments) != 1 || f.payments.payments[0].FailureCode != failureNoPaymentMethod {
+		t.Fatalf("payments = %v, want one failed for the missing payment method", f.payments.payments)
+	}
+	if f.billing.subscription.Status != models.StatusPastDue {
+		t.Errorf("subscription is %s, want past_due", f.billing.subscription.Status)
+	}
+}
@@ -118,16 +118,22 @@ func (x *GetSubscriptionRequest) GetUserId() string {
 }
 
 type SubscriptionResponse struct {
-	state            protoimpl.MessageState `protogen:"open.v1"`
-	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	UserId           string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	PlanType         string                 `protobuf:"bytes,3,opt,name=plan_type,json=planType,proto3" json:"plan_type,omitempty"`
-	RequestsPerMonth int32                  `protobuf:"varint,4,opt,name=requests_per_month,json=requestsPerMonth,proto3" json:"requests_per_month,omitempty"`
-	RequestsUsed     int32                  `protobuf:"varint,5,opt,name=requests_used,json=requestsUsed,proto3" json:"requests_used,omitempty"`
-	Price            float64                `protobuf:"fixed64,6,opt,name=price,proto3" json:"price,omitempty"`
-	IsActive         bool                   `protobuf:"varint,7,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId            string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	PlanType          string                 `protobuf:"bytes,3,opt,name=plan_type,json=planType,proto3" json:"plan_type,omitempty"`
+	RequestsPerMonth  int32                  `protobuf:"varint,4,opt,name=requests_per_month,json=requestsPerMonth,proto3" json:"requests_per_month,omitempty"`
+	RequestsUsed      int32                  `protobuf:"varint,5,opt,name=requests_used,json=requestsUsed,proto3" json:"requests_used,omitempty"`
+	Price             float64                `protobuf:"fixed64,6,opt,name=price,proto3" json:"price,omitempty"`
+	IsActive          bool                   `protobuf:"varint,7,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	PeriodStart       int64                  `protobuf:"varint,8,opt,name=period_start,json=periodStart,proto3" json:"period_start,omitempty"` // current billing period, unix seconds
+	PeriodEnd         int64                  `protobuf:"varint,9,opt,name=period_end,json=periodEnd,proto3" json:"period_end,omitempty"`
+	Status            string                 `protobuf:"bytes,10,opt,name=status,proto3" json:"status,omitempty"` // trialing, active, past_due, canceled, expired
+	CancelAtPeriodEnd bool                   `protobuf:"varint,11,opt,name=cancel_at_period_end,json=cancelAtPeriodEnd,proto3" json:"cancel_at_period_end,omitempty"`
+	TrialEndsAt       int64                  `protobuf:"varint,12,opt,name=trial_ends_at,json=trialEndsAt,proto3" json:"trial_ends_at,omitempty"`               // unix seconds, 0 if not trialing
+	PendingProration  float64                `protobuf:"fixed64,13,opt,name=pending_proration,json=pendingProration,proto3" json:"pending_proration,omitempty"` // added to the next invoice; negative is a credit
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *SubscriptionResponse) Reset() {
@@ -209,9 +215,52 @@ func (x *SubscriptionResponse) GetIsActive() bool {
 	return false
 }
 
+func (x *SubscriptionResponse) GetPeriodStart() int64 {
+	if x != nil {
+		return x.PeriodStart
+	}
+	return 0
+}
+
+func (x *SubscriptionResponse) GetPeriodEnd() int64 {
+	if x != nil {
+		return x.PeriodEnd
+	}
+	return 0
+}
+
+func (x *SubscriptionResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SubscriptionResponse) GetCancelAtPeriodEnd() bool {
+	if x != nil {
+		return x.CancelAtPeriodEnd
+	}
+	return false
+}
+
+func (x *SubscriptionResponse) GetTrialEndsAt() int64 {
+	if x != nil {
+		return x.TrialEndsAt
+	}
+	return 0
+}
+
+func (x *SubscriptionResponse) GetPendingProration() float64 {
+	if x != nil {
+		return x.PendingProration
+	}
+	return 0
+}
+
 type CheckQuotaRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ApiKeyId      string                 `protobuf:"bytes,2,opt,name=api_key_id,json=apiKeyId,proto3" json:"api_key_id,omitempty"` // optional; credits earmarked for this key count too
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -253,12 +302,21 @@ func (x *CheckQuotaRequest) GetUserId() string {
 	return ""
 }
 
+func (x *CheckQuotaRequest) GetApiKeyId() string {
+	if x != nil {
+		return x.ApiKeyId
+	}
+	return ""
+}
+
 type CheckQuotaResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	HasQuota      bool                   `protobuf:"varint,1,opt,name=has_quota,json=hasQuota,proto3" json:"has_quota,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	HasQuota       bool                   `protobuf:"varint,1,opt,name=has_quota,json=hasQuota,proto3" json:"has_quota,omitempty"`
+	Message        string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	PrepaidCredits int64                  `protobuf:"varint,3,opt,name=prepaid_credits,json=prepaidCredits,proto3" json:"prepaid_credits,omitempty"` // ledger credits usable once the plan allowance is spent
+	KeyUsed        int64                  `protobuf:"varint,4,opt,name=key_used,json=keyUsed,proto3" json:"key_used,omitempty"`                      // credits spent with api_key_id in the current period
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *CheckQuotaResponse) Reset() {
@@ -305,11 +363,29 @@ func (x *CheckQuotaResponse) GetMessage() string {
 	return ""
 }
 
+func (x *CheckQuotaResponse) GetPrepaidCredits() int64 {
+	if x != nil {
+		return x.PrepaidCredits
+	}
+	return 0
+}
+
+func (x *CheckQuotaResponse) GetKeyUsed() int64 {
+	if x != nil {
+		return x.KeyUsed
+	}
+	return 0
+}
+
 type IncrementUsageRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	UserId            string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Credits           int64                  `protobuf:"varint,2,opt,name=credits,proto3" json:"credits,omitempty"`                                                                                                                        // credits consumed by the request(s); 0 counts as 1
+	BlockchainCredits map[string]int64       `protobuf:"bytes,3,rep,name=blockchain_credits,json=blockchainCredits,proto3" json:"blockchain_credits,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // breakdown of credits by blockchain
+	ApiKeyId          string                 `protobuf:"bytes,4,opt,name=api_key_id,json=apiKeyId,proto3" json:"api_key_id,omitempty"`                                                                                                     // optional; prepaid credits of this key are spent first
+	IdempotencyKey    string                 `protobuf:"bytes,5,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`                                                                                     // optional; a retry with the same key is not charged twice
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *IncrementUsageRequest) Reset() {
@@ -349,6 +425,34 @@ func (x *IncrementUsageRequest) GetUserId() string {
 	return ""
 }
 
+func (x *IncrementUsageRequest) GetCredits() int64 {
+	if x != nil {
+		return x.Credits
+	}
+	return 0
+}
+
+func (x *IncrementUsageRequest) GetBlockchainCredits() map[string]int64 {
+	if x != nil {
+		return x.BlockchainCredits
+	}
+	return nil
+}
+
+func (x *IncrementUsageRequest) GetApiKeyId() string {
+	if x != nil {
+		return x.ApiKeyId
+	}
+	return ""
+}
+
+func (x *IncrementUsageRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
 type IncrementUsageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -393,39 +497,2301 @@ func (x *IncrementUsageResponse) GetSuccess() bool {
 	return false
 }
 
-var File_services_billing_service_proto_billing_proto protoreflect.FileDescriptor
+type UpdateSubscriptionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	PlanType      string                 `protobuf:"bytes,2,opt,name=plan_type,json=planType,proto3" json:"plan_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_services_billing_service_proto_billing_proto_rawDesc = "" +
-	"\n" +
-	",services/billing-service/proto/billing.proto\x12\abilling\"Q\n" +
-	"\x19CreateSubscriptionRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
-	"\tplan_type\x18\x02 \x01(\tR\bplanType\"1\n" +
-	"\x16GetSubscriptionRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\"\xe2\x01\n" +
-	"\x14SubscriptionResponse\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x1b\n" +
-	"\tplan_type\x18\x03 \x01(\tR\bplanType\x12,\n" +
-	"\x12requests_per_month\x18\x04 \x01(\x05R\x10requestsPerMonth\x12#\n" +
-	"\rrequests_used\x18\x05 \x01(\x05R\frequestsUsed\x12\x14\n" +
-	"\x05price\x18\x06 \x01(\x01R\x05price\x12\x1b\n" +
-	"\tis_active\x18\a \x01(\bR\bisActive\",\n" +
-	"\x11CheckQuotaRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\"K\n" +
-	"\x12CheckQuotaResponse\x12\x1b\n" +
-	"\thas_quota\x18\x01 \x01(\bR\bhasQuota\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"0\n" +
-	"\x15IncrementUsageRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\"2\n" +
-	"\x16IncrementUsageResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess2\xd6\x02\n" +
-	"\x0eBillingService\x12W\n" +
-	"\x12CreateSubscription\x12\".billing.CreateSubscriptionRequest\x1a\x1d.billing.SubscriptionResponse\x12Q\n" +
-	"\x0fGetSubscription\x12\x1f.billing.GetSubscriptionRequest\x1a\x1d.billing.SubscriptionResponse\x12E\n" +
-	"\n" +
-	"CheckQuota\x12\x1a.billing.CheckQuotaRequest\x1a\x1b.billing.CheckQuotaResponse\x12Q\n" +
-	"\x0eIncrementUsage\x12\x1e.billing.IncrementUsageRequest\x1a\x1f.billing.IncrementUsageResponseB0Z.quicknode-clone/services/billing-service/protob\x06proto3"
+func (x *UpdateSubscriptionRequest) Reset() {
+	*x = UpdateSubscriptionRequest{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSubscriptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSubscriptionRequest) ProtoMessage() {}
+
+func (x *UpdateSubscriptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSubscriptionRequest.ProtoReflect.Descriptor instead.
+func (*UpdateSubscriptionRequest) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateSubscriptionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UpdateSubscriptionRequest) GetPlanType() string {
+	if x != nil {
+		return x.PlanType
+	}
+	return ""
+}
+
+type CancelSubscriptionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	AtPeriodEnd   bool                   `protobuf:"varint,2,opt,name=at_period_end,json=atPeriodEnd,proto3" json:"at_period_end,omitempty"` // keep the subscription until the current period ends
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelSubscriptionRequest) Reset() {
+	*x = CancelSubscriptionRequest{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelSubscriptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelSubscriptionRequest) ProtoMessage() {}
+
+func (x *CancelSubscriptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelSubscriptionRequest.ProtoReflect.Descriptor instead.
+func (*CancelSubscriptionRequest) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CancelSubscriptionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CancelSubscriptionRequest) GetAtPeriodEnd() bool {
+	if x != nil {
+		return x.AtPeriodEnd
+	}
+	return false
+}
+
+type ListBillingPeriodsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBillingPeriodsRequest) Reset() {
+	*x = ListBillingPeriodsRequest{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBillingPeriodsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBillingPeriodsRequest) ProtoMessage() {}
+
+func (x *ListBillingPeriodsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBillingPeriodsRequest.ProtoReflect.Descriptor instead.
+func (*ListBillingPeriodsRequest) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListBillingPeriodsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListBillingPeriodsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type BillingPeriod struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	PlanType         string                 `protobuf:"bytes,2,opt,name=plan_type,json=planType,proto3" json:"plan_type,omitempty"`
+	Status           string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	PeriodStart      int64                  `protobuf:"varint,4,opt,name=period_start,json=periodStart,proto3" json:"period_start,omitempty"`
+	PeriodEnd        int64                  `protobuf:"varint,5,opt,name=period_end,json=periodEnd,proto3" json:"period_end,omitempty"`
+	RequestsPerMonth int32                  `protobuf:"varint,6,opt,name=requests_per_month,json=requestsPerMonth,proto3" json:"requests_per_month,omitempty"`
+	RequestsUsed     int32                  `protobuf:"varint,7,opt,name=requests_used,json=requestsUsed,proto3" json:"requests_used,omitempty"`
+	Price            float64                `protobuf:"fixed64,8,opt,name=price,proto3" json:"price,omitempty"`
+	Proration        float64                `protobuf:"fixed64,9,opt,name=proration,proto3" json:"proration,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *BillingPeriod) Reset() {
+	*x = BillingPeriod{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BillingPeriod) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BillingPeriod) ProtoMessage() {}
+
+func (x *BillingPeriod) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BillingPeriod.ProtoReflect.Descriptor instead.
+func (*BillingPeriod) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BillingPeriod) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *BillingPeriod) GetPlanType() string {
+	if x != nil {
+		return x.PlanType
+	}
+	return ""
+}
+
+func (x *BillingPeriod) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *BillingPeriod) GetPeriodStart() int64 {
+	if x != nil {
+		return x.PeriodStart
+	}
+	return 0
+}
+
+func (x *BillingPeriod) GetPeriodEnd() int64 {
+	if x != nil {
+		return x.PeriodEnd
+	}
+	return 0
+}
+
+func (x *BillingPeriod) GetRequestsPerMonth() int32 {
+	if x != nil {
+		return x.RequestsPerMonth
+	}
+	return 0
+}
+
+func (x *BillingPeriod) GetRequestsUsed() int32 {
+	if x != nil {
+		return x.RequestsUsed
+	}
+	return 0
+}
+
+func (x *BillingPeriod) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *BillingPeriod) GetProration() float64 {
+	if x != nil {
+		return x.Proration
+	}
+	return 0
+}
+
+type ListBillingPeriodsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Periods       []*BillingPeriod       `protobuf:"bytes,1,rep,name=periods,proto3" json:"periods,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBillingPeriodsResponse) Reset() {
+	*x = ListBillingPeriodsResponse{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBillingPeriodsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBillingPeriodsResponse) ProtoMessage() {}
+
+func (x *ListBillingPeriodsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBillingPeriodsResponse.ProtoReflect.Descriptor instead.
+func (*ListBillingPeriodsResponse) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListBillingPeriodsResponse) GetPeriods() []*BillingPeriod {
+	if x != nil {
+		return x.Periods
+	}
+	return nil
+}
+
+type ListInvoicesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListInvoicesRequest) Reset() {
+	*x = ListInvoicesRequest{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListInvoicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListInvoicesRequest) ProtoMessage() {}
+
+func (x *ListInvoicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListInvoicesRequest.ProtoReflect.Descriptor instead.
+func (*ListInvoicesRequest) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListInvoicesRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListInvoicesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListInvoicesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Invoices      []*Invoice             `protobuf:"bytes,1,rep,name=invoices,proto3" json:"invoices,omitempty"` // without line items
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListInvoicesResponse) Reset() {
+	*x = ListInvoicesResponse{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListInvoicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListInvoicesResponse) ProtoMessage() {}
+
+func (x *ListInvoicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListInvoicesResponse.ProtoReflect.Descriptor instead.
+func (*ListInvoicesResponse) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListInvoicesResponse) GetInvoices() []*Invoice {
+	if x != nil {
+		return x.Invoices
+	}
+	return nil
+}
+
+type GetInvoiceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	InvoiceId     string                 `protobuf:"bytes,2,opt,name=invoice_id,json=invoiceId,proto3" json:"invoice_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInvoiceRequest) Reset() {
+	*x = GetInvoiceRequest{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInvoiceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInvoiceRequest) ProtoMessage() {}
+
+func (x *GetInvoiceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInvoiceRequest.ProtoReflect.Descriptor instead.
+func (*GetInvoiceRequest) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetInvoiceRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetInvoiceRequest) GetInvoiceId() string {
+	if x != nil {
+		return x.InvoiceId
+	}
+	return ""
+}
+
+type Invoice struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Number          string                 `protobuf:"bytes,2,opt,name=number,proto3" json:"number,omitempty"`
+	UserId          string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	PlanType        string                 `protobuf:"bytes,4,opt,name=plan_type,json=planType,proto3" json:"plan_type,omitempty"`
+	PeriodStart     int64                  `protobuf:"varint,5,opt,name=period_start,json=periodStart,proto3" json:"period_start,omitempty"` // unix seconds
+	PeriodEnd       int64                  `protobuf:"varint,6,opt,name=period_end,json=periodEnd,proto3" json:"period_end,omitempty"`
+	Currency        string                 `protobuf:"bytes,7,opt,name=currency,proto3" json:"currency,omitempty"`
+	Total           float64                `protobuf:"fixed64,8,opt,name=total,proto3" json:"total,omitempty"`
+	Status          string                 `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"` // open, paid, void
+	IssuedAt        int64                  `protobuf:"varint,10,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	DueAt           int64                  `protobuf:"varint,11,opt,name=due_at,json=dueAt,proto3" json:"due_at,omitempty"`
+	PaidAt          int64                  `protobuf:"varint,12,opt,name=paid_at,json=paidAt,proto3" json:"paid_at,omitempty"` // 0 if unpaid
+	LineItems       []*InvoiceLineItem     `protobuf:"bytes,13,rep,name=line_items,json=lineItems,proto3" json:"line_items,omitempty"`
+	PaymentAttempts int32                  `protobuf:"varint,14,opt,name=payment_attempts,json=paymentAttempts,proto3" json:"payment_attempts,omitempty"`
+	NextPaymentAt   int64                  `protobuf:"varint,15,opt,name=next_payment_at,json=nextPaymentAt,proto3" json:"next_payment_at,omitempty"` // next dunning retry, 0 if none is scheduled
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Invoice) Reset() {
+	*x = Invoice{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Invoice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Invoice) ProtoMessage() {}
+
+func (x *Invoice) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Invoice.ProtoReflect.Descriptor instead.
+func (*Invoice) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Invoice) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Invoice) GetNumber() string {
+	if x != nil {
+		return x.Number
+	}
+	return ""
+}
+
+func (x *Invoice) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Invoice) GetPlanType() string {
+	if x != nil {
+		return x.PlanType
+	}
+	return ""
+}
+
+func (x *Invoice) GetPeriodStart() int64 {
+	if x != nil {
+		return x.PeriodStart
+	}
+	return 0
+}
+
+func (x *Invoice) GetPeriodEnd() int64 {
+	if x != nil {
+		return x.PeriodEnd
+	}
+	return 0
+}
+
+func (x *Invoice) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Invoice) GetTotal() float64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *Invoice) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Invoice) GetIssuedAt() int64 {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return 0
+}
+
+func (x *Invoice) GetDueAt() int64 {
+	if x != nil {
+		return x.DueAt
+	}
+	return 0
+}
+
+func (x *Invoice) GetPaidAt() int64 {
+	if x != nil {
+		return x.PaidAt
+	}
+	return 0
+}
+
+func (x *Invoice) GetLineItems() []*InvoiceLineItem {
+	if x != nil {
+		return x.LineItems
+	}
+	return nil
+}
+
+func (x *Invoice) GetPaymentAttempts() int32 {
+	if x != nil {
+		return x.PaymentAttempts
+	}
+	return 0
+}
+
+func (x *Invoice) GetNextPaymentAt() int64 {
+	if x != nil {
+		return x.NextPaymentAt
+	}
+	return 0
+}
+
+type InvoiceLineItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"` // plan, proration, prepaid, overage, usage
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Blockchain    string                 `protobuf:"bytes,3,opt,name=blockchain,proto3" json:"blockchain,omitempty"`
+	Quantity      int64                  `protobuf:"varint,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	UnitPrice     float64                `protobuf:"fixed64,5,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
+	Amount        float64                `protobuf:"fixed64,6,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InvoiceLineItem) Reset() {
+	*x = InvoiceLineItem{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvoiceLineItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvoiceLineItem) ProtoMessage() {}
+
+func (x *InvoiceLineItem) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvoiceLineItem.ProtoReflect.Descriptor instead.
+func (*InvoiceLineItem) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *InvoiceLineItem) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *InvoiceLineItem) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *InvoiceLineItem) GetBlockchain() string {
+	if x != nil {
+		return x.Blockchain
+	}
+	return ""
+}
+
+func (x *InvoiceLineItem) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *InvoiceLineItem) GetUnitPrice() float64 {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return 0
+}
+
+func (x *InvoiceLineItem) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type AttachPaymentMethodRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"` // payment method token issued by the provider
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachPaymentMethodRequest) Reset() {
+	*x = AttachPaymentMethodRequest{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachPaymentMethodRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachPaymentMethodRequest) ProtoMessage() {}
+
+func (x *AttachPaymentMethodRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachPaymentMethodRequest.ProtoReflect.Descriptor instead.
+func (*AttachPaymentMethodRequest) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AttachPaymentMethodRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AttachPaymentMethodRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type PaymentMethodResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Provider        string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	CustomerId      string                 `protobuf:"bytes,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	PaymentMethodId string                 `protobuf:"bytes,3,opt,name=payment_method_id,json=paymentMethodId,proto3" json:"payment_method_id,omitempty"`
+	CardBrand       string                 `protobuf:"bytes,4,opt,name=card_brand,json=cardBrand,proto3" json:"card_brand,omitempty"`
+	CardLast4       string                 `protobuf:"bytes,5,opt,name=card_last4,json=cardLast4,proto3" json:"card_last4,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PaymentMethodResponse) Reset() {
+	*x = PaymentMethodResponse{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentMethodResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentMethodResponse) ProtoMessage() {}
+
+func (x *PaymentMethodResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentMethodResponse.ProtoReflect.Descriptor instead.
+func (*PaymentMethodResponse) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *PaymentMethodResponse) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *PaymentMethodResponse) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *PaymentMethodResponse) GetPaymentMethodId() string {
+	if x != nil {
+		return x.PaymentMethodId
+	}
+	return ""
+}
+
+func (x *PaymentMethodResponse) GetCardBrand() string {
+	if x != nil {
+		return x.CardBrand
+	}
+	return ""
+}
+
+func (x *PaymentMethodResponse) GetCardLast4() string {
+	if x != nil {
+		return x.CardLast4
+	}
+	return ""
+}
+
+type RefundPaymentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId     string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	Amount        float64                `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"` // 0 refunds the whole remaining amount
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefundPaymentRequest) Reset() {
+	*x = RefundPaymentRequest{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundPaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundPaymentRequest) ProtoMessage() {}
+
+func (x *RefundPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundPaymentRequest.ProtoReflect.Descriptor instead.
+func (*RefundPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RefundPaymentRequest) GetPaymentId() string {
+	if x != nil {
+		return x.PaymentId
+	}
+	return ""
+}
+
+func (x *RefundPaymentRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type Payment struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	InvoiceId      string                 `protobuf:"bytes,2,opt,name=invoice_id,json=invoiceId,proto3" json:"invoice_id,omitempty"`
+	Provider       string                 `protobuf:"bytes,3,opt,name=provider,proto3" json:"provider,omitempty"`
+	ChargeId       string                 `protobuf:"bytes,4,opt,name=charge_id,json=chargeId,proto3" json:"charge_id,omitempty"`
+	Attempt        int32                  `protobuf:"varint,5,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	Amount         float64                `protobuf:"fixed64,6,opt,name=amount,proto3" json:"amount,omitempty"`
+	AmountRefunded float64                `protobuf:"fixed64,7,opt,name=amount_refunded,json=amountRefunded,proto3" json:"amount_refunded,omitempty"`
+	Currency       string                 `protobuf:"bytes,8,opt,name=currency,proto3" json:"currency,omitempty"`
+	Status         string                 `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"` // pending, succeeded, failed, refunded
+	FailureCode    string                 `protobuf:"bytes,10,opt,name=failure_code,json=failureCode,proto3" json:"failure_code,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Payment) Reset() {
+	*x = Payment{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Payment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Payment) ProtoMessage() {}
+
+func (x *Payment) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Payment.ProtoReflect.Descriptor instead.
+func (*Payment) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Payment) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Payment) GetInvoiceId() string {
+	if x != nil {
+		return x.InvoiceId
+	}
+	return ""
+}
+
+func (x *Payment) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *Payment) GetChargeId() string {
+	if x != nil {
+		return x.ChargeId
+	}
+	return ""
+}
+
+func (x *Payment) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *Payment) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Payment) GetAmountRefunded() float64 {
+	if x != nil {
+		return x.AmountRefunded
+	}
+	return 0
+}
+
+func (x *Payment) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Payment) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Payment) GetFailureCode() string {
+	if x != nil {
+		return x.FailureCode
+	}
+	return ""
+}
+
+type HandleWebhookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Provider      string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	Payload       []byte                 `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"` // raw request body, as signed
+	Signature     string                 `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HandleWebhookRequest) Reset() {
+	*x = HandleWebhookRequest{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandleWebhookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandleWebhookRequest) ProtoMessage() {}
+
+func (x *HandleWebhookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandleWebhookRequest.ProtoReflect.Descriptor instead.
+func (*HandleWebhookRequest) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *HandleWebhookRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *HandleWebhookRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *HandleWebhookRequest) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+type HandleWebhookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HandleWebhookResponse) Reset() {
+	*x = HandleWebhookResponse{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandleWebhookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandleWebhookResponse) ProtoMessage() {}
+
+func (x *HandleWebhookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandleWebhookResponse.ProtoReflect.Descriptor instead.
+func (*HandleWebhookResponse) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *HandleWebhookResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type GetDepositAddressRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDepositAddressRequest) Reset() {
+	*x = GetDepositAddressRequest{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDepositAddressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDepositAddressRequest) ProtoMessage() {}
+
+func (x *GetDepositAddressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDepositAddressRequest.ProtoReflect.Descriptor instead.
+func (*GetDepositAddressRequest) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetDepositAddressRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type DepositAddressResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Network       string                 `protobuf:"bytes,2,opt,name=network,proto3" json:"network,omitempty"`              // TRC20
+	Token         string                 `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"`                  // USDT
+	Confirmations int32                  `protobuf:"varint,4,opt,name=confirmations,proto3" json:"confirmations,omitempty"` // blocks before a deposit is credited
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DepositAddressResponse) Reset() {
+	*x = DepositAddressResponse{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DepositAddressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DepositAddressResponse) ProtoMessage() {}
+
+func (x *DepositAddressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DepositAddressResponse.ProtoReflect.Descriptor instead.
+func (*DepositAddressResponse) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *DepositAddressResponse) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *DepositAddressResponse) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *DepositAddressResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *DepositAddressResponse) GetConfirmations() int32 {
+	if x != nil {
+		return x.Confirmations
+	}
+	return 0
+}
+
+type ListDepositsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDepositsRequest) Reset() {
+	*x = ListDepositsRequest{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDepositsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDepositsRequest) ProtoMessage() {}
+
+func (x *ListDepositsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDepositsRequest.ProtoReflect.Descriptor instead.
+func (*ListDepositsRequest) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ListDepositsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListDepositsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type Deposit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Amount        float64                `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"` // USDT
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`   // pending, confirmed, reverted
+	DetectedBlock int64                  `protobuf:"varint,5,opt,name=detected_block,json=detectedBlock,proto3" json:"detected_block,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`       // unix seconds
+	ConfirmedAt   int64                  `protobuf:"varint,7,opt,name=confirmed_at,json=confirmedAt,proto3" json:"confirmed_at,omitempty"` // 0 while pending
+	TxId          string                 `protobuf:"bytes,8,opt,name=tx_id,json=txId,proto3" json:"tx_id,omitempty"`                       // transaction of the transfer
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Deposit) Reset() {
+	*x = Deposit{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Deposit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Deposit) ProtoMessage() {}
+
+func (x *Deposit) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Deposit.ProtoReflect.Descriptor instead.
+func (*Deposit) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *Deposit) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Deposit) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Deposit) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Deposit) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Deposit) GetDetectedBlock() int64 {
+	if x != nil {
+		return x.DetectedBlock
+	}
+	return 0
+}
+
+func (x *Deposit) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Deposit) GetConfirmedAt() int64 {
+	if x != nil {
+		return x.ConfirmedAt
+	}
+	return 0
+}
+
+func (x *Deposit) GetTxId() string {
+	if x != nil {
+		return x.TxId
+	}
+	return ""
+}
+
+type ListDepositsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deposits      []*Deposit             `protobuf:"bytes,1,rep,name=deposits,proto3" json:"deposits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDepositsResponse) Reset() {
+	*x = ListDepositsResponse{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDepositsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDepositsResponse) ProtoMessage() {}
+
+func (x *ListDepositsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDepositsResponse.ProtoReflect.Descriptor instead.
+func (*ListDepositsResponse) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ListDepositsResponse) GetDeposits() []*Deposit {
+	if x != nil {
+		return x.Deposits
+	}
+	return nil
+}
+
+type GetPrepaidBalanceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPrepaidBalanceRequest) Reset() {
+	*x = GetPrepaidBalanceRequest{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPrepaidBalanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPrepaidBalanceRequest) ProtoMessage() {}
+
+func (x *GetPrepaidBalanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPrepaidBalanceRequest.ProtoReflect.Descriptor instead.
+func (*GetPrepaidBalanceRequest) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetPrepaidBalanceRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type PrepaidBalanceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Balance       float64                `protobuf:"fixed64,1,opt,name=balance,proto3" json:"balance,omitempty"`
+	Currency      string                 `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PrepaidBalanceResponse) Reset() {
+	*x = PrepaidBalanceResponse{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrepaidBalanceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrepaidBalanceResponse) ProtoMessage() {}
+
+func (x *PrepaidBalanceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrepaidBalanceResponse.ProtoReflect.Descriptor instead.
+func (*PrepaidBalanceResponse) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *PrepaidBalanceResponse) GetBalance() float64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *PrepaidBalanceResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type GetBalanceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ApiKeyId      string                 `protobuf:"bytes,2,opt,name=api_key_id,json=apiKeyId,proto3" json:"api_key_id,omitempty"` // optional; sets available for calls made with this key
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalanceRequest) Reset() {
+	*x = GetBalanceRequest{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceRequest) ProtoMessage() {}
+
+func (x *GetBalanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceRequest.ProtoReflect.Descriptor instead.
+func (*GetBalanceRequest) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GetBalanceRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetBalanceRequest) GetApiKeyId() string {
+	if x != nil {
+		return x.ApiKeyId
+	}
+	return ""
+}
+
+type KeyBalance struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ApiKeyId      string                 `protobuf:"bytes,1,opt,name=api_key_id,json=apiKeyId,proto3" json:"api_key_id,omitempty"`
+	Balance       int64                  `protobuf:"varint,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeyBalance) Reset() {
+	*x = KeyBalance{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeyBalance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyBalance) ProtoMessage() {}
+
+func (x *KeyBalance) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyBalance.ProtoReflect.Descriptor instead.
+func (*KeyBalance) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *KeyBalance) GetApiKeyId() string {
+	if x != nil {
+		return x.ApiKeyId
+	}
+	return ""
+}
+
+func (x *KeyBalance) GetBalance() int64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+type BalanceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Balance       int64                  `protobuf:"varint,1,opt,name=balance,proto3" json:"balance,omitempty"` // credits any of the user's keys can spend
+	Keys          []*KeyBalance          `protobuf:"bytes,2,rep,name=keys,proto3" json:"keys,omitempty"`        // credits earmarked for single keys
+	Total         int64                  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	Available     int64                  `protobuf:"varint,4,opt,name=available,proto3" json:"available,omitempty"` // balance plus the credits of api_key_id
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BalanceResponse) Reset() {
+	*x = BalanceResponse{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BalanceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BalanceResponse) ProtoMessage() {}
+
+func (x *BalanceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BalanceResponse.ProtoReflect.Descriptor instead.
+func (*BalanceResponse) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *BalanceResponse) GetBalance() int64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *BalanceResponse) GetKeys() []*KeyBalance {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *BalanceResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *BalanceResponse) GetAvailable() int64 {
+	if x != nil {
+		return x.Available
+	}
+	return 0
+}
+
+type ListLedgerEntriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ApiKeyId      string                 `protobuf:"bytes,2,opt,name=api_key_id,json=apiKeyId,proto3" json:"api_key_id,omitempty"` // optional; only entries of this key's account
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLedgerEntriesRequest) Reset() {
+	*x = ListLedgerEntriesRequest{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLedgerEntriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLedgerEntriesRequest) ProtoMessage() {}
+
+func (x *ListLedgerEntriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLedgerEntriesRequest.ProtoReflect.Descriptor instead.
+func (*ListLedgerEntriesRequest) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ListLedgerEntriesRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListLedgerEntriesRequest) GetApiKeyId() string {
+	if x != nil {
+		return x.ApiKeyId
+	}
+	return ""
+}
+
+func (x *ListLedgerEntriesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type LedgerEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TransactionId string                 `protobuf:"bytes,2,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`                           // topup, usage, refund, grant
+	ApiKeyId      string                 `protobuf:"bytes,4,opt,name=api_key_id,json=apiKeyId,proto3" json:"api_key_id,omitempty"` // empty for the user's own account
+	Amount        int64                  `protobuf:"varint,5,opt,name=amount,proto3" json:"amount,omitempty"`                      // credits; negative is a debit
+	BalanceAfter  int64                  `protobuf:"varint,6,opt,name=balance_after,json=balanceAfter,proto3" json:"balance_after,omitempty"`
+	Description   string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // unix seconds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LedgerEntry) Reset() {
+	*x = LedgerEntry{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LedgerEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LedgerEntry) ProtoMessage() {}
+
+func (x *LedgerEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LedgerEntry.ProtoReflect.Descriptor instead.
+func (*LedgerEntry) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *LedgerEntry) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *LedgerEntry) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *LedgerEntry) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *LedgerEntry) GetApiKeyId() string {
+	if x != nil {
+		return x.ApiKeyId
+	}
+	return ""
+}
+
+func (x *LedgerEntry) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *LedgerEntry) GetBalanceAfter() int64 {
+	if x != nil {
+		return x.BalanceAfter
+	}
+	return 0
+}
+
+func (x *LedgerEntry) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *LedgerEntry) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type ListLedgerEntriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*LedgerEntry         `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLedgerEntriesResponse) Reset() {
+	*x = ListLedgerEntriesResponse{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLedgerEntriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLedgerEntriesResponse) ProtoMessage() {}
+
+func (x *ListLedgerEntriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLedgerEntriesResponse.ProtoReflect.Descriptor instead.
+func (*ListLedgerEntriesResponse) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ListLedgerEntriesResponse) GetEntries() []*LedgerEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type CreditRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	UserId         string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ApiKeyId       string                 `protobuf:"bytes,2,opt,name=api_key_id,json=apiKeyId,proto3" json:"api_key_id,omitempty"` // optional; earmarks the credits for this key
+	Credits        int64                  `protobuf:"varint,3,opt,name=credits,proto3" json:"credits,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"` // required; a replay returns the first transaction
+	Description    string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`                             // grants and refunds only
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreditRequest) Reset() {
+	*x = CreditRequest{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreditRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreditRequest) ProtoMessage() {}
+
+func (x *CreditRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreditRequest.ProtoReflect.Descriptor instead.
+func (*CreditRequest) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *CreditRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreditRequest) GetApiKeyId() string {
+	if x != nil {
+		return x.ApiKeyId
+	}
+	return ""
+}
+
+func (x *CreditRequest) GetCredits() int64 {
+	if x != nil {
+		return x.Credits
+	}
+	return 0
+}
+
+func (x *CreditRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *CreditRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type LedgerTransaction struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,2,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	Type           string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	ApiKeyId       string                 `protobuf:"bytes,4,opt,name=api_key_id,json=apiKeyId,proto3" json:"api_key_id,omitempty"`
+	Amount         int64                  `protobuf:"varint,5,opt,name=amount,proto3" json:"amount,omitempty"` // credits
+	Cost           float64                `protobuf:"fixed64,6,opt,name=cost,proto3" json:"cost,omitempty"`    // USD taken from the prepaid balance by a top-up
+	Description    string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`
+	CreatedAt      int64                  `protobuf:"varint,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // unix seconds
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *LedgerTransaction) Reset() {
+	*x = LedgerTransaction{}
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LedgerTransaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LedgerTransaction) ProtoMessage() {}
+
+func (x *LedgerTransaction) ProtoReflect() protoreflect.Message {
+	mi := &file_services_billing_service_proto_billing_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LedgerTransaction.ProtoReflect.Descriptor instead.
+func (*LedgerTransaction) Descriptor() ([]byte, []int) {
+	return file_services_billing_service_proto_billing_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *LedgerTransaction) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *LedgerTransaction) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *LedgerTransaction) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *LedgerTransaction) GetApiKeyId() string {
+	if x != nil {
+		return x.ApiKeyId
+	}
+	return ""
+}
+
+func (x *LedgerTransaction) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *LedgerTransaction) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *LedgerTransaction) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *LedgerTransaction) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+var File_services_billing_service_proto_billing_proto protoreflect.FileDescriptor
+
+const file_services_billing_service_proto_billing_proto_rawDesc = "" +
+	"\n" +
+	",services/billing-service/proto/billing.proto\x12\abilling\"Q\n" +
+	"\x19CreateSubscriptionRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tplan_type\x18\x02 \x01(\tR\bplanType\"1\n" +
+	"\x16GetSubscriptionRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"\xbe\x03\n" +
+	"\x14SubscriptionResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tplan_type\x18\x03 \x01(\tR\bplanType\x12,\n" +
+	"\x12requests_per_month\x18\x04 \x01(\x05R\x10requestsPerMonth\x12#\n" +
+	"\rrequests_used\x18\x05 \x01(\x05R\frequestsUsed\x12\x14\n" +
+	"\x05price\x18\x06 \x01(\x01R\x05price\x12\x1b\n" +
+	"\tis_active\x18\a \x01(\bR\bisActive\x12!\n" +
+	"\fperiod_start\x18\b \x01(\x03R\vperiodStart\x12\x1d\n" +
+	"\n" +
+	"period_end\x18\t \x01(\x03R\tperiodEnd\x12\x16\n" +
+	"\x06status\x18\n" +
+	" \x01(\tR\x06status\x12/\n" +
+	"\x14cancel_at_period_end\x18\v \x01(\bR\x11cancelAtPeriodEnd\x12\"\n" +
+	"\rtrial_ends_at\x18\f \x01(\x03R\vtrialEndsAt\x12+\n" +
+	"\x11pending_proration\x18\r \x01(\x01R\x10pendingProration\"J\n" +
+	"\x11CheckQuotaRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1c\n" +
+	"\n" +
+	"api_key_id\x18\x02 \x01(\tR\bapiKeyId\"\x8f\x01\n" +
+	"\x12CheckQuotaResponse\x12\x1b\n" +
+	"\thas_quota\x18\x01 \x01(\bR\bhasQuota\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12'\n" +
+	"\x0fprepaid_credits\x18\x03 \x01(\x03R\x0eprepaidCredits\x12\x19\n" +
+	"\bkey_used\x18\x04 \x01(\x03R\akeyUsed\"\xbd\x02\n" +
+	"\x15IncrementUsageRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x18\n" +
+	"\acredits\x18\x02 \x01(\x03R\acredits\x12d\n" +
+	"\x12blockchain_credits\x18\x03 \x03(\v25.billing.IncrementUsageRequest.BlockchainCreditsEntryR\x11blockchainCredits\x12\x1c\n" +
+	"\n" +
+	"api_key_id\x18\x04 \x01(\tR\bapiKeyId\x12'\n" +
+	"\x0fidempotency_key\x18\x05 \x01(\tR\x0eidempotencyKey\x1aD\n" +
+	"\x16BlockchainCreditsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"2\n" +
+	"\x16IncrementUsageResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"Q\n" +
+	"\x19UpdateSubscriptionRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tplan_type\x18\x02 \x01(\tR\bplanType\"X\n" +
+	"\x19CancelSubscriptionRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\"\n" +
+	"\rat_period_end\x18\x02 \x01(\bR\vatPeriodEnd\"J\n" +
+	"\x19ListBillingPeriodsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"\x9d\x02\n" +
+	"\rBillingPeriod\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tplan_type\x18\x02 \x01(\tR\bplanType\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12!\n" +
+	"\fperiod_start\x18\x04 \x01(\x03R\vperiodStart\x12\x1d\n" +
+	"\n" +
+	"period_end\x18\x05 \x01(\x03R\tperiodEnd\x12,\n" +
+	"\x12requests_per_month\x18\x06 \x01(\x05R\x10requestsPerMonth\x12#\n" +
+	"\rrequests_used\x18\a \x01(\x05R\frequestsUsed\x12\x14\n" +
+	"\x05price\x18\b \x01(\x01R\x05price\x12\x1c\n" +
+	"\tproration\x18\t \x01(\x01R\tproration\"N\n" +
+	"\x1aListBillingPeriodsResponse\x120\n" +
+	"\aperiods\x18\x01 \x03(\v2\x16.billing.BillingPeriodR\aperiods\"D\n" +
+	"\x13ListInvoicesRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"D\n" +
+	"\x14ListInvoicesResponse\x12,\n" +
+	"\binvoices\x18\x01 \x03(\v2\x10.billing.InvoiceR\binvoices\"K\n" +
+	"\x11GetInvoiceRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1d\n" +
+	"\n" +
+	"invoice_id\x18\x02 \x01(\tR\tinvoiceId\"\xcc\x03\n" +
+	"\aInvoice\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06number\x18\x02 \x01(\tR\x06number\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tplan_type\x18\x04 \x01(\tR\bplanType\x12!\n" +
+	"\fperiod_start\x18\x05 \x01(\x03R\vperiodStart\x12\x1d\n" +
+	"\n" +
+	"period_end\x18\x06 \x01(\x03R\tperiodEnd\x12\x1a\n" +
+	"\bcurrency\x18\a \x01(\tR\bcurrency\x12\x14\n" +
+	"\x05total\x18\b \x01(\x01R\x05total\x12\x16\n" +
+	"\x06status\x18\t \x01(\tR\x06status\x12\x1b\n" +
+	"\tissued_at\x18\n" +
+	" \x01(\x03R\bissuedAt\x12\x15\n" +
+	"\x06due_at\x18\v \x01(\x03R\x05dueAt\x12\x17\n" +
+	"\apaid_at\x18\f \x01(\x03R\x06paidAt\x127\n" +
+	"\n" +
+	"line_items\x18\r \x03(\v2\x18.billing.InvoiceLineItemR\tlineItems\x12)\n" +
+	"\x10payment_attempts\x18\x0e \x01(\x05R\x0fpaymentAttempts\x12&\n" +
+	"\x0fnext_payment_at\x18\x0f \x01(\x03R\rnextPaymentAt\"\xba\x01\n" +
+	"\x0fInvoiceLineItem\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1e\n" +
+	"\n" +
+	"blockchain\x18\x03 \x01(\tR\n" +
+	"blockchain\x12\x1a\n" +
+	"\bquantity\x18\x04 \x01(\x03R\bquantity\x12\x1d\n" +
+	"\n" +
+	"unit_price\x18\x05 \x01(\x01R\tunitPrice\x12\x16\n" +
+	"\x06amount\x18\x06 \x01(\x01R\x06amount\"K\n" +
+	"\x1aAttachPaymentMethodRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\"\xbe\x01\n" +
+	"\x15PaymentMethodResponse\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x1f\n" +
+	"\vcustomer_id\x18\x02 \x01(\tR\n" +
+	"customerId\x12*\n" +
+	"\x11payment_method_id\x18\x03 \x01(\tR\x0fpaymentMethodId\x12\x1d\n" +
+	"\n" +
+	"card_brand\x18\x04 \x01(\tR\tcardBrand\x12\x1d\n" +
+	"\n" +
+	"card_last4\x18\x05 \x01(\tR\tcardLast4\"M\n" +
+	"\x14RefundPaymentRequest\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x01R\x06amount\"\xa3\x02\n" +
+	"\aPayment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"invoice_id\x18\x02 \x01(\tR\tinvoiceId\x12\x1a\n" +
+	"\bprovider\x18\x03 \x01(\tR\bprovider\x12\x1b\n" +
+	"\tcharge_id\x18\x04 \x01(\tR\bchargeId\x12\x18\n" +
+	"\aattempt\x18\x05 \x01(\x05R\aattempt\x12\x16\n" +
+	"\x06amount\x18\x06 \x01(\x01R\x06amount\x12'\n" +
+	"\x0famount_refunded\x18\a \x01(\x01R\x0eamountRefunded\x12\x1a\n" +
+	"\bcurrency\x18\b \x01(\tR\bcurrency\x12\x16\n" +
+	"\x06status\x18\t \x01(\tR\x06status\x12!\n" +
+	"\ffailure_code\x18\n" +
+	" \x01(\tR\vfailureCode\"j\n" +
+	"\x14HandleWebhookRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x18\n" +
+	"\apayload\x18\x02 \x01(\fR\apayload\x12\x1c\n" +
+	"\tsignature\x18\x03 \x01(\tR\tsignature\"1\n" +
+	"\x15HandleWebhookResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"3\n" +
+	"\x18GetDepositAddressRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"\x88\x01\n" +
+	"\x16DepositAddressResponse\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x18\n" +
+	"\anetwork\x18\x02 \x01(\tR\anetwork\x12\x14\n" +
+	"\x05token\x18\x03 \x01(\tR\x05token\x12$\n" +
+	"\rconfirmations\x18\x04 \x01(\x05R\rconfirmations\"D\n" +
+	"\x13ListDepositsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"\xe1\x01\n" +
+	"\aDeposit\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x01R\x06amount\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12%\n" +
+	"\x0edetected_block\x18\x05 \x01(\x03R\rdetectedBlock\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x03R\tcreatedAt\x12!\n" +
+	"\fconfirmed_at\x18\a \x01(\x03R\vconfirmedAt\x12\x13\n" +
+	"\x05tx_id\x18\b \x01(\tR\x04txId\"D\n" +
+	"\x14ListDepositsResponse\x12,\n" +
+	"\bdeposits\x18\x01 \x03(\v2\x10.billing.DepositR\bdeposits\"3\n" +
+	"\x18GetPrepaidBalanceRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"N\n" +
+	"\x16PrepaidBalanceResponse\x12\x18\n" +
+	"\abalance\x18\x01 \x01(\x01R\abalance\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\"J\n" +
+	"\x11GetBalanceRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1c\n" +
+	"\n" +
+	"api_key_id\x18\x02 \x01(\tR\bapiKeyId\"D\n" +
+	"\n" +
+	"KeyBalance\x12\x1c\n" +
+	"\n" +
+	"api_key_id\x18\x01 \x01(\tR\bapiKeyId\x12\x18\n" +
+	"\abalance\x18\x02 \x01(\x03R\abalance\"\x88\x01\n" +
+	"\x0fBalanceResponse\x12\x18\n" +
+	"\abalance\x18\x01 \x01(\x03R\abalance\x12'\n" +
+	"\x04keys\x18\x02 \x03(\v2\x13.billing.KeyBalanceR\x04keys\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x03R\x05total\x12\x1c\n" +
+	"\tavailable\x18\x04 \x01(\x03R\tavailable\"g\n" +
+	"\x18ListLedgerEntriesRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1c\n" +
+	"\n" +
+	"api_key_id\x18\x02 \x01(\tR\bapiKeyId\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"\xf4\x01\n" +
+	"\vLedgerEntry\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12%\n" +
+	"\x0etransaction_id\x18\x02 \x01(\tR\rtransactionId\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x1c\n" +
+	"\n" +
+	"api_key_id\x18\x04 \x01(\tR\bapiKeyId\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\x03R\x06amount\x12#\n" +
+	"\rbalance_after\x18\x06 \x01(\x03R\fbalanceAfter\x12 \n" +
+	"\vdescription\x18\a \x01(\tR\vdescription\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\b \x01(\x03R\tcreatedAt\"K\n" +
+	"\x19ListLedgerEntriesResponse\x12.\n" +
+	"\aentries\x18\x01 \x03(\v2\x14.billing.LedgerEntryR\aentries\"\xab\x01\n" +
+	"\rCreditRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1c\n" +
+	"\n" +
+	"api_key_id\x18\x02 \x01(\tR\bapiKeyId\x12\x18\n" +
+	"\acredits\x18\x03 \x01(\x03R\acredits\x12'\n" +
+	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\"\xeb\x01\n" +
+	"\x11LedgerTransaction\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12'\n" +
+	"\x0fidempotency_key\x18\x02 \x01(\tR\x0eidempotencyKey\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x1c\n" +
+	"\n" +
+	"api_key_id\x18\x04 \x01(\tR\bapiKeyId\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\x03R\x06amount\x12\x12\n" +
+	"\x04cost\x18\x06 \x01(\x01R\x04cost\x12 \n" +
+	"\vdescription\x18\a \x01(\tR\vdescription\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\b \x01(\x03R\tcreatedAt2\xca\f\n" +
+	"\x0eBillingService\x12W\n" +
+	"\x12CreateSubscription\x12\".billing.CreateSubscriptionRequest\x1a\x1d.billing.SubscriptionResponse\x12Q\n" +
+	"\x0fGetSubscription\x12\x1f.billing.GetSubscriptionRequest\x1a\x1d.billing.SubscriptionResponse\x12E\n" +
+	"\n" +
+	"CheckQuota\x12\x1a.billing.CheckQuotaRequest\x1a\x1b.billing.CheckQuotaResponse\x12Q\n" +
+	"\x0eIncrementUsage\x12\x1e.billing.IncrementUsageRequest\x1a\x1f.billing.IncrementUsageResponse\x12W\n" +
+	"\x12UpdateSubscription\x12\".billing.UpdateSubscriptionRequest\x1a\x1d.billing.SubscriptionResponse\x12W\n" +
+	"\x12CancelSubscription\x12\".billing.CancelSubscriptionRequest\x1a\x1d.billing.SubscriptionResponse\x12]\n" +
+	"\x12ListBillingPeriods\x12\".billing.ListBillingPeriodsRequest\x1a#.billing.ListBillingPeriodsResponse\x12K\n" +
+	"\fListInvoices\x12\x1c.billing.ListInvoicesRequest\x1a\x1d.billing.ListInvoicesResponse\x12:\n" +
+	"\n" +
+	"GetInvoice\x12\x1a.billing.GetInvoiceRequest\x1a\x10.billing.Invoice\x12Z\n" +
+	"\x13AttachPaymentMethod\x12#.billing.AttachPaymentMethodRequest\x1a\x1e.billing.PaymentMethodResponse\x12@\n" +
+	"\rRefundPayment\x12\x1d.billing.RefundPaymentRequest\x1a\x10.billing.Payment\x12N\n" +
+	"\rHandleWebhook\x12\x1d.billing.HandleWebhookRequest\x1a\x1e.billing.HandleWebhookResponse\x12W\n" +
+	"\x11GetDepositAddress\x12!.billing.GetDepositAddressRequest\x1a\x1f.billing.DepositAddressResponse\x12K\n" +
+	"\fListDeposits\x12\x1c.billing.ListDepositsRequest\x1a\x1d.billing.ListDepositsResponse\x12W\n" +
+	"\x11GetPrepaidBalance\x12!.billing.GetPrepaidBalanceRequest\x1a\x1f.billing.PrepaidBalanceResponse\x12B\n" +
+	"\n" +
+	"GetBalance\x12\x1a.billing.GetBalanceRequest\x1a\x18.billing.BalanceResponse\x12Z\n" +
+	"\x11ListLedgerEntries\x12!.billing.ListLedgerEntriesRequest\x1a\".billing.ListLedgerEntriesResponse\x12B\n" +
+	"\fTopUpCredits\x12\x16.billing.CreditRequest\x1a\x1a.billing.LedgerTransaction\x12B\n" +
+	"\fGrantCredits\x12\x16.billing.CreditRequest\x1a\x1a.billing.LedgerTransaction\x12C\n" +
+	"\rRefundCredits\x12\x16.billing.CreditRequest\x1a\x1a.billing.LedgerTransactionB0Z.quicknode-clone/services/billing-service/protob\x06proto3"
 
 var (
 	file_services_billing_service_proto_billing_proto_rawDescOnce sync.Once
@@ -439,30 +2805,101 @@ func file_services_billing_service_proto_billing_proto_rawDescGZIP() []byte {
 	return file_services_billing_service_proto_billing_proto_rawDescData
 }
 
-var file_services_billing_service_proto_billing_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_services_billing_service_proto_billing_proto_msgTypes = make([]protoimpl.MessageInfo, 39)
 var file_services_billing_service_proto_billing_proto_goTypes = []any{
-	(*CreateSubscriptionRequest)(nil), // 0: billing.CreateSubscriptionRequest
-	(*GetSubscriptionRequest)(nil),    // 1: billing.GetSubscriptionRequest
-	(*SubscriptionResponse)(nil),      // 2: billing.SubscriptionResponse
-	(*CheckQuotaRequest)(nil),         // 3: billing.CheckQuotaRequest
-	(*CheckQuotaResponse)(nil),        // 4: billing.CheckQuotaResponse
-	(*IncrementUsageRequest)(nil),     // 5: billing.IncrementUsageRequest
-	(*IncrementUsageResponse)(nil),    // 6: billing.IncrementUsageResponse
+	(*CreateSubscriptionRequest)(nil),  // 0: billing.CreateSubscriptionRequest
+	(*GetSubscriptionRequest)(nil),     // 1: billing.GetSubscriptionRequest
+	(*SubscriptionResponse)(nil),       // 2: billing.SubscriptionResponse
+	(*CheckQuotaRequest)(nil),          // 3: billing.CheckQuotaRequest
+	(*CheckQuotaResponse)(nil),         // 4: billing.CheckQuotaResponse
+	(*IncrementUsageRequest)(nil),      // 5: billing.IncrementUsageRequest
+	(*IncrementUsageResponse)(nil),     // 6: billing.IncrementUsageResponse
+	(*UpdateSubscriptionRequest)(nil),  // 7: billing.UpdateSubscriptionRequest
+	(*CancelSubscriptionRequest)(nil),  // 8: billing.CancelSubscriptionRequest
+	(*ListBillingPeriodsRequest)(nil),  // 9: billing.ListBillingPeriodsRequest
+	(*BillingPeriod)(nil),              // 10: billing.BillingPeriod
+	(*ListBillingPeriodsResponse)(nil), // 11: billing.ListBillingPeriodsResponse
+	(*ListInvoicesRequest)(nil),        // 12: billing.ListInvoicesRequest
+	(*ListInvoicesResponse)(nil),       // 13: billing.ListInvoicesResponse
+	(*GetInvoiceRequest)(nil),          // 14: billing.GetInvoiceRequest
+	(*Invoice)(nil),                    // 15: billing.Invoice
+	(*InvoiceLineItem)(nil),            // 16: billing.InvoiceLineItem
+	(*AttachPaymentMethodRequest)(nil), // 17: billing.AttachPaymentMethodRequest
+	(*PaymentMethodResponse)(nil),      // 18: billing.PaymentMethodResponse
+	(*RefundPaymentRequest)(nil),       // 19: billing.RefundPaymentRequest
+	(*Payment)(nil),                    // 20: billing.Payment
+	(*HandleWebhookRequest)(nil),       // 21: billing.HandleWebhookRequest
+	(*HandleWebhookResponse)(nil),      // 22: billing.HandleWebhookResponse
+	(*GetDepositAddressRequest)(nil),   // 23: billing.GetDepositAddressRequest
+	(*DepositAddressResponse)(nil),     // 24: billing.DepositAddressResponse
+	(*ListDepositsRequest)(nil),        // 25: billing.ListDepositsRequest
+	(*Deposit)(nil),                    // 26: billing.Deposit
+	(*ListDepositsResponse)(nil),       // 27: billing.ListDepositsResponse
+	(*GetPrepaidBalanceRequest)(nil),   // 28: billing.GetPrepaidBalanceRequest
+	(*PrepaidBalanceResponse)(nil),     // 29: billing.PrepaidBalanceResponse
+	(*GetBalanceRequest)(nil),          // 30: billing.GetBalanceRequest
+	(*KeyBalance)(nil),                 // 31: billing.KeyBalance
+	(*BalanceResponse)(nil),            // 32: billing.BalanceResponse
+	(*ListLedgerEntriesRequest)(nil),   // 33: billing.ListLedgerEntriesRequest
+	(*LedgerEntry)(nil),                // 34: billing.LedgerEntry
+	(*ListLedgerEntriesResponse)(nil),  // 35: billing.ListLedgerEntriesResponse
+	(*CreditRequest)(nil),              // 36: billing.CreditRequest
+	(*LedgerTransaction)(nil),          // 37: billing.LedgerTransaction
+	nil,                                // 38: billing.IncrementUsageRequest.BlockchainCreditsEntry
 }
 var file_services_billing_service_proto_billing_proto_depIdxs = []int32{
-	0, // 0: billing.BillingService.CreateSubscription:input_type -> billing.CreateSubscriptionRequest
-	1, // 1: billing.BillingService.GetSubscription:input_type -> billing.GetSubscriptionRequest
-	3, // 2: billing.BillingService.CheckQuota:input_type -> billing.CheckQuotaRequest
-	5, // 3: billing.BillingService.IncrementUsage:input_type -> billing.IncrementUsageRequest
-	2, // 4: billing.BillingService.CreateSubscription:output_type -> billing.SubscriptionResponse
-	2, // 5: billing.BillingService.GetSubscription:output_type -> billing.SubscriptionResponse
-	4, // 6: billing.BillingService.CheckQuota:output_type -> billing.CheckQuotaResponse
-	6, // 7: billing.BillingService.IncrementUsage:output_type -> billing.IncrementUsageResponse
-	4, // [4:8] is the sub-list for method output_type
-	0, // [0:4] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	38, // 0: billing.IncrementUsageRequest.blockchain_credits:type_name -> billing.IncrementUsageRequest.BlockchainCreditsEntry
+	10, // 1: billing.ListBillingPeriodsResponse.periods:type_name -> billing.BillingPeriod
+	15, // 2: billing.ListInvoicesResponse.invoices:type_name -> billing.Invoice
+	16, // 3: billing.Invoice.line_items:type_name -> billing.InvoiceLineItem
+	26, // 4: billing.ListDepositsResponse.deposits:type_name -> billing.Deposit
+	31, // 5: billing.BalanceResponse.keys:type_name -> billing.KeyBalance
+	34, // 6: billing.ListLedgerEntriesResponse.entries:type_name -> billing.LedgerEntry
+	0,  // 7: billing.BillingService.CreateSubscription:input_type -> billing.CreateSubscriptionRequest
+	1,  // 8: billing.BillingService.GetSubscription:input_type -> billing.GetSubscriptionRequest
+	3,  // 9: billing.BillingService.CheckQuota:input_type -> billing.CheckQuotaRequest
+	5,  // 10: billing.BillingService.IncrementUsage:input_type -> billing.IncrementUsageRequest
+	7,  // 11: billing.BillingService.UpdateSubscription:input_type -> billing.UpdateSubscriptionRequest
+	8,  // 12: billing.BillingService.CancelSubscription:input_type -> billing.CancelSubscriptionRequest
+	9,  // 13: billing.BillingService.ListBillingPeriods:input_type -> billing.ListBillingPeriodsRequest
+	12, // 14: billing.BillingService.ListInvoices:input_type -> billing.ListInvoicesRequest
+	14, // 15: billing.BillingService.GetInvoice:input_type -> billing.GetInvoiceRequest
+	17, // 16: billing.BillingService.AttachPaymentMethod:input_type -> billing.AttachPaymentMethodRequest
+	19, // 17: billing.BillingService.RefundPayment:input_type -> billing.RefundPaymentRequest
+	21, // 18: billing.BillingService.HandleWebhook:input_type -> billing.HandleWebhookRequest
+	23, // 19: billing.BillingService.GetDepositAddress:input_type -> billing.GetDepositAddressRequest
+	25, // 20: billing.BillingService.ListDeposits:input_type -> billing.ListDepositsRequest
+	28, // 21: billing.BillingService.GetPrepaidBalance:input_type -> billing.GetPrepaidBalanceRequest
+	30, // 22: billing.BillingService.GetBalance:input_type -> billing.GetBalanceRequest
+	33, // 23: billing.BillingService.ListLedgerEntries:input_type -> billing.ListLedgerEntriesRequest
+	36, // 24: billing.BillingService.TopUpCredits:input_type -> billing.CreditRequest
+	36, // 25: billing.BillingService.GrantCredits:input_type -> billing.CreditRequest
+	36, // 26: billing.BillingService.RefundCredits:input_type -> billing.CreditRequest
+	2,  // 27: billing.BillingService.CreateSubscription:output_type -> billing.SubscriptionResponse
+	2,  // 28: billing.BillingService.GetSubscription:output_type -> billing.SubscriptionResponse
+	4,  // 29: billing.BillingService.CheckQuota:output_type -> billing.CheckQuotaResponse
+	6,  // 30: billing.BillingService.IncrementUsage:output_type -> billing.IncrementUsageResponse
+	2,  // 31: billing.BillingService.UpdateSubscription:output_type -> billing.SubscriptionResponse
+	2,  // 32: billing.BillingService.CancelSubscription:output_type -> billing.SubscriptionResponse
+	11, // 33: billing.BillingService.ListBillingPeriods:output_type -> billing.ListBillingPeriodsResponse
+	13, // 34: billing.BillingService.ListInvoices:output_type -> billing.ListInvoicesResponse
+	15, // 35: billing.BillingService.GetInvoice:output_type -> billing.Invoice
+	18, // 36: billing.BillingService.AttachPaymentMethod:output_type -> billing.PaymentMethodResponse
+	20, // 37: billing.BillingService.RefundPayment:output_type -> billing.Payment
+	22, // 38: billing.BillingService.HandleWebhook:output_type -> billing.HandleWebhookResponse
+	24, // 39: billing.BillingService.GetDepositAddress:output_type -> billing.DepositAddressResponse
+	27, // 40: billing.BillingService.ListDeposits:output_type -> billing.ListDepositsResponse
+	29, // 41: billing.BillingService.GetPrepaidBalance:output_type -> billing.PrepaidBalanceResponse
+	32, // 42: billing.BillingService.GetBalance:output_type -> billing.BalanceResponse
+	35, // 43: billing.BillingService.ListLedgerEntries:output_type -> billing.ListLedgerEntriesResponse
+	37, // 44: billing.BillingService.TopUpCredits:output_type -> billing.LedgerTransaction
+	37, // 45: billing.BillingService.GrantCredits:output_type -> billing.LedgerTransaction
+	37, // 46: billing.BillingService.RefundCredits:output_type -> billing.LedgerTransaction
+	27, // [27:47] is the sub-list for method output_type
+	7,  // [7:27] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_services_billing_service_proto_billing_proto_init() }
@@ -476,7 +2913,7 @@ func file_services_billing_service_proto_billing_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_services_billing_service_proto_billing_proto_rawDesc), len(file_services_billing_service_proto_billing_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   39,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
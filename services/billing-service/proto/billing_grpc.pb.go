@@ -19,10 +19,26 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	BillingService_CreateSubscription_FullMethodName = "/billing.BillingService/CreateSubscription"
-	BillingService_GetSubscription_FullMethodName    = "/billing.BillingService/GetSubscription"
-	BillingService_CheckQuota_FullMethodName         = "/billing.BillingService/CheckQuota"
-	BillingService_IncrementUsage_FullMethodName     = "/billing.BillingService/IncrementUsage"
+	BillingService_CreateSubscription_FullMethodName  = "/billing.BillingService/CreateSubscription"
+	BillingService_GetSubscription_FullMethodName     = "/billing.BillingService/GetSubscription"
+	BillingService_CheckQuota_FullMethodName          = "/billing.BillingService/CheckQuota"
+	BillingService_IncrementUsage_FullMethodName      = "/billing.BillingService/IncrementUsage"
+	BillingService_UpdateSubscription_FullMethodName  = "/billing.BillingService/UpdateSubscription"
+	BillingService_CancelSubscription_FullMethodName  = "/billing.BillingService/CancelSubscription"
+	BillingService_ListBillingPeriods_FullMethodName  = "/billing.BillingService/ListBillingPeriods"
+	BillingService_ListInvoices_FullMethodName        = "/billing.BillingService/ListInvoices"
+	BillingService_GetInvoice_FullMethodName          = "/billing.BillingService/GetInvoice"
+	BillingService_AttachPaymentMethod_FullMethodName = "/billing.BillingService/AttachPaymentMethod"
+	BillingService_RefundPayment_FullMethodName       = "/billing.BillingService/RefundPayment"
+	BillingService_HandleWebhook_FullMethodName       = "/billing.BillingService/HandleWebhook"
+	BillingService_GetDepositAddress_FullMethodName   = "/billing.BillingService/GetDepositAddress"
+	BillingService_ListDeposits_FullMethodName        = "/billing.BillingService/ListDeposits"
+	BillingService_GetPrepaidBalance_FullMethodName   = "/billing.BillingService/GetPrepaidBalance"
+	BillingService_GetBalance_FullMethodName          = "/billing.BillingService/GetBalance"
+	BillingService_ListLedgerEntries_FullMethodName   = "/billing.BillingService/ListLedgerEntries"
+	BillingService_TopUpCredits_FullMethodName        = "/billing.BillingService/TopUpCredits"
+	BillingService_GrantCredits_FullMethodName        = "/billing.BillingService/GrantCredits"
+	BillingService_RefundCredits_FullMethodName       = "/billing.BillingService/RefundCredits"
 )
 
 // BillingServiceClient is the client API for BillingService service.
@@ -33,6 +49,22 @@ type BillingServiceClient interface {
 	GetSubscription(ctx context.Context, in *GetSubscriptionRequest, opts ...grpc.CallOption) (*SubscriptionResponse, error)
 	CheckQuota(ctx context.Context, in *CheckQuotaRequest, opts ...grpc.CallOption) (*CheckQuotaResponse, error)
 	IncrementUsage(ctx context.Context, in *IncrementUsageRequest, opts ...grpc.CallOption) (*IncrementUsageResponse, error)
+	UpdateSubscription(ctx context.Context, in *UpdateSubscriptionRequest, opts ...grpc.CallOption) (*SubscriptionResponse, error)
+	CancelSubscription(ctx context.Context, in *CancelSubscriptionRequest, opts ...grpc.CallOption) (*SubscriptionResponse, error)
+	ListBillingPeriods(ctx context.Context, in *ListBillingPeriodsRequest, opts ...grpc.CallOption) (*ListBillingPeriodsResponse, error)
+	ListInvoices(ctx context.Context, in *ListInvoicesRequest, opts ...grpc.CallOption) (*ListInvoicesResponse, error)
+	GetInvoice(ctx context.Context, in *GetInvoiceRequest, opts ...grpc.CallOption) (*Invoice, error)
+	AttachPaymentMethod(ctx context.Context, in *AttachPaymentMethodRequest, opts ...grpc.CallOption) (*PaymentMethodResponse, error)
+	RefundPayment(ctx context.Context, in *RefundPaymentRequest, opts ...grpc.CallOption) (*Payment, error)
+	HandleWebhook(ctx context.Context, in *HandleWebhookRequest, opts ...grpc.CallOption) (*HandleWebhookResponse, error)
+	GetDepositAddress(ctx context.Context, in *GetDepositAddressRequest, opts ...grpc.CallOption) (*DepositAddressResponse, error)
+	ListDeposits(ctx context.Context, in *ListDepositsRequest, opts ...grpc.CallOption) (*ListDepositsResponse, error)
+	GetPrepaidBalance(ctx context.Context, in *GetPrepaidBalanceRequest, opts ...grpc.CallOption) (*PrepaidBalanceResponse, error)
+	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error)
+	ListLedgerEntries(ctx context.Context, in *ListLedgerEntriesRequest, opts ...grpc.CallOption) (*ListLedgerEntriesResponse, error)
+	TopUpCredits(ctx context.Context, in *CreditRequest, opts ...grpc.CallOption) (*LedgerTransaction, error)
+	GrantCredits(ctx context.Context, in *CreditRequest, opts ...grpc.CallOption) (*LedgerTransaction, error)
+	RefundCredits(ctx context.Context, in *CreditRequest, opts ...grpc.CallOption) (*LedgerTransaction, error)
 }
 
 type billingServiceClient struct {
@@ -79,6 +111,150 @@ func (c *billingServiceClient) IncrementUsage(ctx context.Context, in *Increment
 	return out, nil
 }
 
+func (c *billingServiceClient) UpdateSubscription(ctx context.Context, in *UpdateSubscriptionRequest, opts ...grpc.CallOption) (*SubscriptionResponse, error) {
+	out := new(SubscriptionResponse)
+	err := c.cc.Invoke(ctx, BillingService_UpdateSubscription_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) CancelSubscription(ctx context.Context, in *CancelSubscriptionRequest, opts ...grpc.CallOption) (*SubscriptionResponse, error) {
+	out := new(SubscriptionResponse)
+	err := c.cc.Invoke(ctx, BillingService_CancelSubscription_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) ListBillingPeriods(ctx context.Context, in *ListBillingPeriodsRequest, opts ...grpc.CallOption) (*ListBillingPeriodsResponse, error) {
+	out := new(ListBillingPeriodsResponse)
+	err := c.cc.Invoke(ctx, BillingService_ListBillingPeriods_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) ListInvoices(ctx context.Context, in *ListInvoicesRequest, opts ...grpc.CallOption) (*ListInvoicesResponse, error) {
+	out := new(ListInvoicesResponse)
+	err := c.cc.Invoke(ctx, BillingService_ListInvoices_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) GetInvoice(ctx context.Context, in *GetInvoiceRequest, opts ...grpc.CallOption) (*Invoice, error) {
+	out := new(Invoice)
+	err := c.cc.Invoke(ctx, BillingService_GetInvoice_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) AttachPaymentMethod(ctx context.Context, in *AttachPaymentMethodRequest, opts ...grpc.CallOption) (*PaymentMethodResponse, error) {
+	out := new(PaymentMethodResponse)
+	err := c.cc.Invoke(ctx, BillingService_AttachPaymentMethod_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) RefundPayment(ctx context.Context, in *RefundPaymentRequest, opts ...grpc.CallOption) (*Payment, error) {
+	out := new(Payment)
+	err := c.cc.Invoke(ctx, BillingService_RefundPayment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) HandleWebhook(ctx context.Context, in *HandleWebhookRequest, opts ...grpc.CallOption) (*HandleWebhookResponse, error) {
+	out := new(HandleWebhookResponse)
+	err := c.cc.Invoke(ctx, BillingService_HandleWebhook_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) GetDepositAddress(ctx context.Context, in *GetDepositAddressRequest, opts ...grpc.CallOption) (*DepositAddressResponse, error) {
+	out := new(DepositAddressResponse)
+	err := c.cc.Invoke(ctx, BillingService_GetDepositAddress_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) ListDeposits(ctx context.Context, in *ListDepositsRequest, opts ...grpc.CallOption) (*ListDepositsResponse, error) {
+	out := new(ListDepositsResponse)
+	err := c.cc.Invoke(ctx, BillingService_ListDeposits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) GetPrepaidBalance(ctx context.Context, in *GetPrepaidBalanceRequest, opts ...grpc.CallOption) (*PrepaidBalanceResponse, error) {
+	out := new(PrepaidBalanceResponse)
+	err := c.cc.Invoke(ctx, BillingService_GetPrepaidBalance_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error) {
+	out := new(BalanceResponse)
+	err := c.cc.Invoke(ctx, BillingService_GetBalance_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) ListLedgerEntries(ctx context.Context, in *ListLedgerEntriesRequest, opts ...grpc.CallOption) (*ListLedgerEntriesResponse, error) {
+	out := new(ListLedgerEntriesResponse)
+	err := c.cc.Invoke(ctx, BillingService_ListLedgerEntries_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) TopUpCredits(ctx context.Context, in *CreditRequest, opts ...grpc.CallOption) (*LedgerTransaction, error) {
+	out := new(LedgerTransaction)
+	err := c.cc.Invoke(ctx, BillingService_TopUpCredits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) GrantCredits(ctx context.Context, in *CreditRequest, opts ...grpc.CallOption) (*LedgerTransaction, error) {
+	out := new(LedgerTransaction)
+	err := c.cc.Invoke(ctx, BillingService_GrantCredits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) RefundCredits(ctx context.Context, in *CreditRequest, opts ...grpc.CallOption) (*LedgerTransaction, error) {
+	out := new(LedgerTransaction)
+	err := c.cc.Invoke(ctx, BillingService_RefundCredits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BillingServiceServer is the server API for BillingService service.
 // All implementations must embed UnimplementedBillingServiceServer
 // for forward compatibility
@@ -87,6 +263,22 @@ type BillingServiceServer interface {
 	GetSubscription(context.Context, *GetSubscriptionRequest) (*SubscriptionResponse, error)
 	CheckQuota(context.Context, *CheckQuotaRequest) (*CheckQuotaResponse, error)
 	IncrementUsage(context.Context, *IncrementUsageRequest) (*IncrementUsageResponse, error)
+	UpdateSubscription(context.Context, *UpdateSubscriptionRequest) (*SubscriptionResponse, error)
+	CancelSubscription(context.Context, *CancelSubscriptionRequest) (*SubscriptionResponse, error)
+	ListBillingPeriods(context.Context, *ListBillingPeriodsRequest) (*ListBillingPeriodsResponse, error)
+	ListInvoices(context.Context, *ListInvoicesRequest) (*ListInvoicesResponse, error)
+	GetInvoice(context.Context, *GetInvoiceRequest) (*Invoice, error)
+	AttachPaymentMethod(context.Context, *AttachPaymentMethodRequest) (*PaymentMethodResponse, error)
+	RefundPayment(context.Context, *RefundPaymentRequest) (*Payment, error)
+	HandleWebhook(context.Context, *HandleWebhookRequest) (*HandleWebhookResponse, error)
+	GetDepositAddress(context.Context, *GetDepositAddressRequest) (*DepositAddressResponse, error)
+	ListDeposits(context.Context, *ListDepositsRequest) (*ListDepositsResponse, error)
+	GetPrepaidBalance(context.Context, *GetPrepaidBalanceRequest) (*PrepaidBalanceResponse, error)
+	GetBalance(context.Context, *GetBalanceRequest) (*BalanceResponse, error)
+	ListLedgerEntries(context.Context, *ListLedgerEntriesRequest) (*ListLedgerEntriesResponse, error)
+	TopUpCredits(context.Context, *CreditRequest) (*LedgerTransaction, error)
+	GrantCredits(context.Context, *CreditRequest) (*LedgerTransaction, error)
+	RefundCredits(context.Context, *CreditRequest) (*LedgerTransaction, error)
 	mustEmbedUnimplementedBillingServiceServer()
 }
 
@@ -106,6 +298,54 @@ func (UnimplementedBillingServiceServer) CheckQuota(context.Context, *CheckQuota
 func (UnimplementedBillingServiceServer) IncrementUsage(context.Context, *IncrementUsageRequest) (*IncrementUsageResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method IncrementUsage not implemented")
 }
+func (UnimplementedBillingServiceServer) UpdateSubscription(context.Context, *UpdateSubscriptionRequest) (*SubscriptionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSubscription not implemented")
+}
+func (UnimplementedBillingServiceServer) CancelSubscription(context.Context, *CancelSubscriptionRequest) (*SubscriptionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelSubscription not implemented")
+}
+func (UnimplementedBillingServiceServer) ListBillingPeriods(context.Context, *ListBillingPeriodsRequest) (*ListBillingPeriodsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBillingPeriods not implemented")
+}
+func (UnimplementedBillingServiceServer) ListInvoices(context.Context, *ListInvoicesRequest) (*ListInvoicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListInvoices not implemented")
+}
+func (UnimplementedBillingServiceServer) GetInvoice(context.Context, *GetInvoiceRequest) (*Invoice, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInvoice not implemented")
+}
+func (UnimplementedBillingServiceServer) AttachPaymentMethod(context.Context, *AttachPaymentMethodRequest) (*PaymentMethodResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AttachPaymentMethod not implemented")
+}
+func (UnimplementedBillingServiceServer) RefundPayment(context.Context, *RefundPaymentRequest) (*Payment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefundPayment not implemented")
+}
+func (UnimplementedBillingServiceServer) HandleWebhook(context.Context, *HandleWebhookRequest) (*HandleWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HandleWebhook not implemented")
+}
+func (UnimplementedBillingServiceServer) GetDepositAddress(context.Context, *GetDepositAddressRequest) (*DepositAddressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDepositAddress not implemented")
+}
+func (UnimplementedBillingServiceServer) ListDeposits(context.Context, *ListDepositsRequest) (*ListDepositsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeposits not implemented")
+}
+func (UnimplementedBillingServiceServer) GetPrepaidBalance(context.Context, *GetPrepaidBalanceRequest) (*PrepaidBalanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPrepaidBalance not implemented")
+}
+func (UnimplementedBillingServiceServer) GetBalance(context.Context, *GetBalanceRequest) (*BalanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (UnimplementedBillingServiceServer) ListLedgerEntries(context.Context, *ListLedgerEntriesRequest) (*ListLedgerEntriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLedgerEntries not implemented")
+}
+func (UnimplementedBillingServiceServer) TopUpCredits(context.Context, *CreditRequest) (*LedgerTransaction, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TopUpCredits not implemented")
+}
+func (UnimplementedBillingServiceServer) GrantCredits(context.Context, *CreditRequest) (*LedgerTransaction, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GrantCredits not implemented")
+}
+func (UnimplementedBillingServiceServer) RefundCredits(context.Context, *CreditRequest) (*LedgerTransaction, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefundCredits not implemented")
+}
 func (UnimplementedBillingServiceServer) mustEmbedUnimplementedBillingServiceServer() {}
 
 // UnsafeBillingServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -191,6 +431,294 @@ func _BillingService_IncrementUsage_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BillingService_UpdateSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).UpdateSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_UpdateSubscription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).UpdateSubscription(ctx, req.(*UpdateSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_CancelSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).CancelSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_CancelSubscription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).CancelSubscription(ctx, req.(*CancelSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_ListBillingPeriods_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBillingPeriodsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).ListBillingPeriods(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_ListBillingPeriods_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).ListBillingPeriods(ctx, req.(*ListBillingPeriodsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_ListInvoices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListInvoicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).ListInvoices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_ListInvoices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).ListInvoices(ctx, req.(*ListInvoicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_GetInvoice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInvoiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).GetInvoice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_GetInvoice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).GetInvoice(ctx, req.(*GetInvoiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_AttachPaymentMethod_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachPaymentMethodRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).AttachPaymentMethod(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_AttachPaymentMethod_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).AttachPaymentMethod(ctx, req.(*AttachPaymentMethodRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_RefundPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefundPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).RefundPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_RefundPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).RefundPayment(ctx, req.(*RefundPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_HandleWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandleWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).HandleWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_HandleWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).HandleWebhook(ctx, req.(*HandleWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_GetDepositAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDepositAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).GetDepositAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_GetDepositAddress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).GetDepositAddress(ctx, req.(*GetDepositAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_ListDeposits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDepositsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).ListDeposits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_ListDeposits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).ListDeposits(ctx, req.(*ListDepositsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_GetPrepaidBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPrepaidBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).GetPrepaidBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_GetPrepaidBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).GetPrepaidBalance(ctx, req.(*GetPrepaidBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_GetBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).GetBalance(ctx, req.(*GetBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_ListLedgerEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLedgerEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).ListLedgerEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_ListLedgerEntries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).ListLedgerEntries(ctx, req.(*ListLedgerEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_TopUpCredits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreditRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).TopUpCredits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_TopUpCredits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).TopUpCredits(ctx, req.(*CreditRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_GrantCredits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreditRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).GrantCredits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_GrantCredits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).GrantCredits(ctx, req.(*CreditRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_RefundCredits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreditRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).RefundCredits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillingService_RefundCredits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).RefundCredits(ctx, req.(*CreditRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BillingService_ServiceDesc is the grpc.ServiceDesc for BillingService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -214,6 +742,70 @@ var BillingService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "IncrementUsage",
 			Handler:    _BillingService_IncrementUsage_Handler,
 		},
+		{
+			MethodName: "UpdateSubscription",
+			Handler:    _BillingService_UpdateSubscription_Handler,
+		},
+		{
+			MethodName: "CancelSubscription",
+			Handler:    _BillingService_CancelSubscription_Handler,
+		},
+		{
+			MethodName: "ListBillingPeriods",
+			Handler:    _BillingService_ListBillingPeriods_Handler,
+		},
+		{
+			MethodName: "ListInvoices",
+			Handler:    _BillingService_ListInvoices_Handler,
+		},
+		{
+			MethodName: "GetInvoice",
+			Handler:    _BillingService_GetInvoice_Handler,
+		},
+		{
+			MethodName: "AttachPaymentMethod",
+			Handler:    _BillingService_AttachPaymentMethod_Handler,
+		},
+		{
+			MethodName: "RefundPayment",
+			Handler:    _BillingService_RefundPayment_Handler,
+		},
+		{
+			MethodName: "HandleWebhook",
+			Handler:    _BillingService_HandleWebhook_Handler,
+		},
+		{
+			MethodName: "GetDepositAddress",
+			Handler:    _BillingService_GetDepositAddress_Handler,
+		},
+		{
+			MethodName: "ListDeposits",
+			Handler:    _BillingService_ListDeposits_Handler,
+		},
+		{
+			MethodName: "GetPrepaidBalance",
+			Handler:    _BillingService_GetPrepaidBalance_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _BillingService_GetBalance_Handler,
+		},
+		{
+			MethodName: "ListLedgerEntries",
+			Handler:    _BillingService_ListLedgerEntries_Handler,
+		},
+		{
+			MethodName: "TopUpCredits",
+			Handler:    _BillingService_TopUpCredits_Handler,
+		},
+		{
+			MethodName: "GrantCredits",
+			Handler:    _BillingService_GrantCredits_Handler,
+		},
+		{
+			MethodName: "RefundCredits",
+			Handler:    _BillingService_RefundCredits_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "services/billing-service/proto/billing.proto",
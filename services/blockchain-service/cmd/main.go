@@ -38,9 +38,19 @@ func main() {
 
 	// Initialize repository, service, and handler
 	nodeRepo := repository.NewNodeRepository(db)
-	nodeService := service.NewNodeService(nodeRepo)
+	healthChecker := service.NewHealthChecker(
+		nodeRepo,
+		cfg.HealthCheck.Interval,
+		cfg.HealthCheck.Timeout,
+		cfg.HealthCheck.FailureThreshold,
+	)
+	nodeService := service.NewNodeService(nodeRepo, healthChecker, cfg.NodeSelection)
 	nodeHandler := handler.NewNodeHandler(nodeService)
 
+	// Start probing nodes in the background
+	healthChecker.Start()
+	defer healthChecker.Stop()
+
 	// Create gRPC server
 	grpcServer := grpc.NewServer()
 	pb.RegisterBlockchainServiceServer(grpcServer, nodeHandler)
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"time"
 
 	"ironnode/pkg/models"
 	"ironnode/services/blockchain-service/internal/service"
@@ -24,7 +25,7 @@ func NewNodeHandler(nodeService service.NodeService) *NodeHandler {
 func (h *NodeHandler) CreateNode(ctx context.Context, req *pb.CreateNodeRequest) (*pb.NodeResponse, error) {
 	blockchainType := models.BlockchainType(req.Type)
 
-	node, err := h.nodeService.CreateNode(req.Name, blockchainType, req.Network, req.Url)
+	node, err := h.nodeService.CreateNode(req.Name, blockchainType, req.Network, req.Url, req.WsUrl)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create node: %v", err)
 	}
@@ -35,6 +36,7 @@ func (h *NodeHandler) CreateNode(ctx context.Context, req *pb.CreateNodeRequest)
 		Type:     string(node.Type),
 		Network:  node.Network,
 		Url:      node.URL,
+		WsUrl:    node.WSURL,
 		IsActive: node.IsActive,
 		Priority: int32(node.Priority),
 	}, nil
@@ -57,6 +59,7 @@ func (h *NodeHandler) GetNode(ctx context.Context, req *pb.GetNodeRequest) (*pb.
 		Type:     string(node.Type),
 		Network:  node.Network,
 		Url:      node.URL,
+		WsUrl:    node.WSURL,
 		IsActive: node.IsActive,
 		Priority: int32(node.Priority),
 	}, nil
@@ -76,6 +79,7 @@ func (h *NodeHandler) ListNodes(ctx context.Context, req *pb.ListNodesRequest) (
 			Type:     string(node.Type),
 			Network:  node.Network,
 			Url:      node.URL,
+			WsUrl:    node.WSURL,
 			IsActive: node.IsActive,
 			Priority: int32(node.Priority),
 		})
@@ -102,6 +106,7 @@ func (h *NodeHandler) GetNodesByType(ctx context.Context, req *pb.GetNodesByType
 			Type:     string(node.Type),
 			Network:  node.Network,
 			Url:      node.URL,
+			WsUrl:    node.WSURL,
 			IsActive: node.IsActive,
 			Priority: int32(node.Priority),
 		})
@@ -111,3 +116,91 @@ func (h *NodeHandler) GetNodesByType(ctx context.Context, req *pb.GetNodesByType
 		Nodes: pbNodes,
 	}, nil
 }
+
+func (h *NodeHandler) GetNodesByNetwork(ctx context.Context, req *pb.GetNodesByNetworkRequest) (*pb.ListNodesResponse, error) {
+	blockchainType := models.BlockchainType(req.Type)
+
+	nodes, err := h.nodeService.GetNodesByNetwork(blockchainType, req.Network)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get nodes by network: %v", err)
+	}
+
+	var pbNodes []*pb.NodeResponse
+	for _, node := range nodes {
+		pbNodes = append(pbNodes, &pb.NodeResponse{
+			Id:       node.ID.String(),
+			Name:     node.Name,
+			Type:     string(node.Type),
+			Network:  node.Network,
+			Url:      node.URL,
+			WsUrl:    node.WSURL,
+			IsActive: node.IsActive,
+			Priority: int32(node.Priority),
+		})
+	}
+
+	return &pb.ListNodesResponse{
+		Nodes: pbNodes,
+	}, nil
+}
+
+func (h *NodeHandler) SelectNodes(ctx context.Context, req *pb.SelectNodesRequest) (*pb.ListNodesResponse, error) {
+	blockchainType := models.BlockchainType(req.Type)
+
+	nodes, err := h.nodeService.SelectNodes(blockchainType, req.Network, req.BlockTag)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to select nodes: %v", err)
+	}
+
+	var pbNodes []*pb.NodeResponse
+	for _, node := range nodes {
+		pbNodes = append(pbNodes, &pb.NodeResponse{
+			Id:       node.ID.String(),
+			Name:     node.Name,
+			Type:     string(node.Type),
+			Network:  node.Network,
+			Url:      node.URL,
+			WsUrl:    node.WSURL,
+			IsActive: node.IsActive,
+			Priority: int32(node.Priority),
+		})
+	}
+
+	return &pb.ListNodesResponse{
+		Nodes: pbNodes,
+	}, nil
+}
+
+func (h *NodeHandler) GetNodeHealth(ctx context.Context, req *pb.GetNodeHealthRequest) (*pb.GetNodeHealthResponse, error) {
+	var nodeID *uuid.UUID
+	if req.Id != "" {
+		id, err := uuid.Parse(req.Id)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid node ID: %v", err)
+		}
+		nodeID = &id
+	}
+
+	states := h.nodeService.GetNodeHealth(nodeID, models.BlockchainType(req.Type), req.Network)
+
+	var pbStates []*pb.NodeHealth
+	for _, state := range states {
+		pbStates = append(pbStates, &pb.NodeHealth{
+			NodeId:              state.NodeID.String(),
+			Name:                state.Name,
+			Type:                string(state.Type),
+			Network:             state.Network,
+			Healthy:             state.Healthy,
+			LatencyMs:           state.LatencyMs,
+			BlockHeight:         state.BlockHeight,
+			BlockLag:            state.BlockLag,
+			ConsecutiveFailures: int32(state.ConsecutiveFailures),
+			LastError:           state.LastError,
+			LastCheckedAt:       state.LastCheckedAt.Format(time.RFC3339),
+		})
+	}
+
+	return &pb.GetNodeHealthResponse{
+		Nodes: pbStates,
+	}, nil
+}
@@ -11,6 +11,7 @@ type NodeRepository interface {
 	CreateNode(node *models.BlockchainNode) error
 	GetNodeByID(id uuid.UUID) (*models.BlockchainNode, error)
 	GetNodesByType(blockchainType models.BlockchainType) ([]*models.BlockchainNode, error)
+	GetNodesByNetwork(blockchainType models.BlockchainType, network string) ([]*models.BlockchainNode, error)
 	GetActiveNodes() ([]*models.BlockchainNode, error)
 	UpdateNode(node *models.BlockchainNode) error
 	DeleteNode(id uuid.UUID) error
@@ -42,6 +43,14 @@ func (r *nodeRepository) GetNodesByType(blockchainType models.BlockchainType) ([
 	return nodes, err
 }
 
+func (r *nodeRepository) GetNodesByNetwork(blockchainType models.BlockchainType, network string) ([]*models.BlockchainNode, error) {
+	var nodes []*models.BlockchainNode
+	err := r.db.Where("type = ? AND network = ? AND is_active = ?", blockchainType, network, true).
+		Order("priority DESC").
+		Find(&nodes).Error
+	return nodes, err
+}
+
 func (r *nodeRepository) GetActiveNodes() ([]*models.BlockchainNode, error) {
 	var nodes []*models.BlockchainNode
 	err := r.db.Where("is_active = ?", true).Order("priority DESC").Find(&nodes).Error
@@ -0,0 +1,359 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ironnode/pkg/models"
+	"ironnode/services/blockchain-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// NodeHealth holds the latest probe results for a node
+type NodeHealth struct {
+	NodeID              uuid.UUID
+	Name                string
+	Type                models.BlockchainType
+	Network             string
+	Healthy             bool
+	LatencyMs           int64
+	BlockHeight         uint64
+	BlockLag            uint64 // blocks behind the best node of the same chain and network
+	ConsecutiveFailures int
+	LastError           string
+	LastCheckedAt       time.Time
+}
+
+type HealthChecker interface {
+	Start()
+	Stop()
+	GetNodeHealth(nodeID uuid.UUID) (*NodeHealth, bool)
+	GetAllHealth() []*NodeHealth
+}
+
+type healthChecker struct {
+	repo             repository.NodeRepository
+	httpClient       *http.Client
+	interval         time.Duration
+	failureThreshold int
+
+	mu     sync.RWMutex
+	health map[uuid.UUID]*NodeHealth
+
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewHealthChecker creates a checker that probes every active node each interval.
+// A node is marked unhealthy after failureThreshold consecutive failed probes.
+func NewHealthChecker(repo repository.NodeRepository, interval, timeout time.Duration, failureThreshold int) HealthChecker {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+
+	return &healthChecker{
+		repo: repo,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+		interval:         interval,
+		failureThreshold: failureThreshold,
+		health:           make(map[uuid.UUID]*NodeHealth),
+		ctx:              ctx,
+		cancel:           cancel,
+	}
+}
+
+// Start runs the check loop in a background goroutine
+func (h *healthChecker) Start() {
+	h.wg.Add(1)
+	go h.run()
+	log.Printf("[HealthChecker] Started (interval: %v, failure threshold: %d)", h.interval, h.failureThreshold)
+}
+
+// Stop stops the check loop and waits for it to finish
+func (h *healthChecker) Stop() {
+	h.cancel()
+	h.wg.Wait()
+	log.Printf("[HealthChecker] Stopped")
+}
+
+func (h *healthChecker) run() {
+	defer h.wg.Done()
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	h.checkAll()
+
+	for {
+		select {
+		case <-h.ctx.Done():
+			return
+		case <-ticker.C:
+			h.checkAll()
+		}
+	}
+}
+
+// checkAll probes all active nodes in parallel and recomputes block lag
+func (h *healthChecker) checkAll() {
+	nodes, err := h.repo.GetActiveNodes()
+	if err != nil {
+		log.Printf("[HealthChecker] Failed to load nodes: %v", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(node *models.BlockchainNode) {
+			defer wg.Done()
+			h.checkNode(node)
+		}(node)
+	}
+	wg.Wait()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Forget nodes that were removed or deactivated
+	active := make(map[uuid.UUID]bool, len(nodes))
+	for _, node := range nodes {
+		active[node.ID] = true
+	}
+	for id := range h.health {
+		if !active[id] {
+			delete(h.health, id)
+		}
+	}
+
+	h.updateLag()
+}
+
+// checkNode probes a single node and records the result
+func (h *healthChecker) checkNode(node *models.BlockchainNode) {
+	ctx, cancel := context.WithTimeout(h.ctx, h.httpClient.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	height, err := h.probe(ctx, node)
+	latency := time.Since(start).Milliseconds()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	state, exists := h.health[node.ID]
+	if !exists {
+		// New nodes are trusted until they fail
+		state = &NodeHealth{NodeID: node.ID, Healthy: true}
+		h.health[node.ID] = state
+	}
+
+	state.Name = node.Name
+	state.Type = node.Type
+	state.Network = node.Network
+	state.LatencyMs = latency
+	state.LastCheckedAt = time.Now()
+
+	if err != nil {
+		state.ConsecutiveFailures++
+		state.LastError = err.Error()
+		if state.Healthy && state.ConsecutiveFailures >= h.failureThreshold {
+			state.Healthy = false
+			log.Printf("[HealthChecker] Node %s (%s) marked unhealthy: %v", node.Name, node.URL, err)
+		}
+		return
+	}
+
+	if !state.Healthy {
+		log.Printf("[HealthChecker] Node %s (%s) recovered", node.Name, node.URL)
+	}
+
+	state.Healthy = true
+	state.ConsecutiveFailures = 0
+	state.LastError = ""
+	state.BlockHeight = height
+}
+
+// updateLag computes how far each node is behind the best node of its chain.
+// Must be called with h.mu held.
+func (h *healthChecker) updateLag() {
+	best := make(map[string]uint64)
+	for _, state := range h.health {
+		key := string(state.Type) + "/" + state.Network
+		if state.Healthy && state.BlockHeight > best[key] {
+			best[key] = state.BlockHeight
+		}
+	}
+
+	for _, state := range h.health {
+		head := best[string(state.Type)+"/"+state.Network]
+		if head > state.BlockHeight {
+			state.BlockLag = head - state.BlockHeight
+		} else {
+			state.BlockLag = 0
+		}
+	}
+}
+
+// GetNodeHealth returns a copy of the node's health state
+func (h *healthChecker) GetNodeHealth(nodeID uuid.UUID) (*NodeHealth, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	state, exists := h.health[nodeID]
+	if !exists {
+		return nil, false
+	}
+
+	copied := *state
+	return &copied, true
+}
+
+// GetAllHealth returns a copy of every known node's health state
+func (h *healthChecker) GetAllHealth() []*NodeHealth {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	result := make([]*NodeHealth, 0, len(h.health))
+	for _, state := range h.health {
+		copied := *state
+		result = append(result, &copied)
+	}
+
+	return result
+}
+
+// probe runs the chain-appropriate check and returns the node's block height
+func (h *healthChecker) probe(ctx context.Context, node *models.BlockchainNode) (uint64, error) {
+	switch {
+	case node.Type.IsEVM():
+		var result string
+		if err := h.callJSONRPC(ctx, node.URL, "eth_blockNumber", &result); err != nil {
+			return 0, err
+		}
+		return strconv.ParseUint(strings.TrimPrefix(result, "0x"), 16, 64)
+
+	case node.Type == models.Solana:
+		var health string
+		if err := h.callJSONRPC(ctx, node.URL, "getHealth", &health); err != nil {
+			return 0, err
+		}
+		if health != "ok" {
+			return 0, fmt.Errorf("node reports health %q", health)
+		}
+		var slot uint64
+		if err := h.callJSONRPC(ctx, node.URL, "getSlot", &slot); err != nil {
+			return 0, err
+		}
+		return slot, nil
+
+	case node.Type == models.Bitcoin:
+		var count uint64
+		if err := h.callJSONRPC(ctx, node.URL, "getblockcount", &count); err != nil {
+			return 0, err
+		}
+		return count, nil
+
+	case node.Type == models.Tron:
+		return h.probeTron(ctx, node.URL)
+
+	default:
+		return 0, fmt.Errorf("no health probe for blockchain type %q", node.Type)
+	}
+}
+
+// callJSONRPC makes a parameterless JSON-RPC call and decodes its result
+func (h *healthChecker) callJSONRPC(ctx context.Context, url, method string, result interface{}) error {
+	body := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":%q,"params":[]}`, method)
+
+	data, err := h.post(ctx, url, []byte(body))
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("%s: invalid response: %v", method, err)
+	}
+
+	if resp.Error != nil {
+		return fmt.Errorf("%s: %s (code %d)", method, resp.Error.Message, resp.Error.Code)
+	}
+
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("%s: unexpected result: %v", method, err)
+	}
+
+	return nil
+}
+
+// probeTron reads the current block number from a Tron full node
+func (h *healthChecker) probeTron(ctx context.Context, url string) (uint64, error) {
+	data, err := h.post(ctx, strings.TrimRight(url, "/")+"/wallet/getnowblock", []byte("{}"))
+	if err != nil {
+		return 0, err
+	}
+
+	var block struct {
+		BlockHeader struct {
+			RawData struct {
+				Number uint64 `json:"number"`
+			} `json:"raw_data"`
+		} `json:"block_header"`
+	}
+	if err := json.Unmarshal(data, &block); err != nil {
+		return 0, fmt.Errorf("getnowblock: invalid response: %v", err)
+	}
+
+	if block.BlockHeader.RawData.Number == 0 {
+		return 0, fmt.Errorf("getnowblock: missing block number")
+	}
+
+	return block.BlockHeader.RawData.Number, nil
+}
+
+func (h *healthChecker) post(ctx context.Context, url string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("node returned status %d", resp.StatusCode)
+	}
+
+	return data, nil
+}
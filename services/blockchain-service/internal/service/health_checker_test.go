@@ -0,0 +1,193 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ironnode/pkg/models"
+	"ironnode/services/blockchain-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// fakeNodeRepo serves a fixed set of active nodes
+type fakeNodeRepo struct {
+	repository.NodeRepository
+	nodes []*models.BlockchainNode
+}
+
+func (r *fakeNodeRepo) GetActiveNodes() ([]*models.BlockchainNode, error) {
+	return r.nodes, nil
+}
+
+func (r *fakeNodeRepo) GetNodesByNetwork(blockchainType models.BlockchainType, network string) ([]*models.BlockchainNode, error) {
+	var nodes []*models.BlockchainNode
+	for _, node := range r.nodes {
+		if node.Type == blockchainType && node.Network == network {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+// rpcNode answers JSON-RPC calls from results keyed by method
+func rpcNode(t *testing.T, results map[string]interface{}) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+
+		result, ok := results[req.Method]
+		if !ok {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"jsonrpc": "2.0", "id": 1,
+				"error": map[string]interface{}{"code": -32601, "message": "method not found"},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": result})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newNode(blockchainType models.BlockchainType, url string) *models.BlockchainNode {
+	return &models.BlockchainNode{ID: uuid.New(), Name: string(blockchainType), Type: blockchainType, Network: "mainnet", URL: url}
+}
+
+func newTestHealthChecker(repo repository.NodeRepository, failureThreshold int) *healthChecker {
+	return NewHealthChecker(repo, time.Minute, time.Second, failureThreshold).(*healthChecker)
+}
+
+func TestHealthCheckerProbes(t *testing.T) {
+	tron := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wallet/getnowblock" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"block_header":{"raw_data":{"number":5000}}}`))
+	}))
+	defer tron.Close()
+
+	tests := []struct {
+		name   string
+		node   *models.BlockchainNode
+		height uint64
+	}{
+		{"evm", newNode(models.Ethereum, rpcNode(t, map[string]interface{}{"eth_blockNumber": "0x10"}).URL), 16},
+		{"solana", newNode(models.Solana, rpcNode(t, map[string]interface{}{"getHealth": "ok", "getSlot": 250}).URL), 250},
+		{"bitcoin", newNode(models.Bitcoin, rpcNode(t, map[string]interface{}{"getblockcount": 800000}).URL), 800000},
+		{"tron", newNode(models.Tron, tron.URL+"/"), 5000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := newTestHealthChecker(&fakeNodeRepo{nodes: []*models.BlockchainNode{tt.node}}, 1)
+			checker.checkAll()
+
+			health, ok := checker.GetNodeHealth(tt.node.ID)
+			if !ok {
+				t.Fatal("node has no health state")
+			}
+			if !health.Healthy || health.BlockHeight != tt.height {
+				t.Errorf("healthy = %v, height = %d (%s); want healthy at %d", health.Healthy, health.BlockHeight, health.LastError, tt.height)
+			}
+		})
+	}
+}
+
+func TestHealthCheckerProbeFailures(t *testing.T) {
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+
+	tests := []struct {
+		name string
+		node *models.BlockchainNode
+	}{
+		{"error status", newNode(models.Ethereum, unavailable.URL)},
+		{"rpc error", newNode(models.Ethereum, rpcNode(t, map[string]interface{}{}).URL)},
+		{"solana behind", newNode(models.Solana, rpcNode(t, map[string]interface{}{"getHealth": "behind", "getSlot": 1}).URL)},
+		{"tron without block", newNode(models.Tron, rpcNode(t, map[string]interface{}{}).URL)},
+		{"unknown chain", newNode(models.BlockchainType("cardano"), unavailable.URL)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := newTestHealthChecker(&fakeNodeRepo{nodes: []*models.BlockchainNode{tt.node}}, 1)
+			checker.checkAll()
+
+			health, _ := checker.GetNodeHealth(tt.node.ID)
+			if health.Healthy || health.LastError == "" {
+				t.Errorf("healthy = %v, last error %q; want unhealthy with an error", health.Healthy, health.LastError)
+			}
+		})
+	}
+}
+
+func TestHealthCheckerFailureThreshold(t *testing.T) {
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x64"}`))
+	}))
+	defer server.Close()
+
+	node := newNode(models.Ethereum, server.URL)
+	checker := newTestHealthChecker(&fakeNodeRepo{nodes: []*models.BlockchainNode{node}}, 3)
+
+	checker.checkAll()
+	failing.Store(true)
+
+	for i := 1; i <= 3; i++ {
+		checker.checkAll()
+		health, _ := checker.GetNodeHealth(node.ID)
+		if health.ConsecutiveFailures != i {
+			t.Errorf("after %d failed probes ConsecutiveFailures = %d", i, health.ConsecutiveFailures)
+		}
+		if want := i < 3; health.Healthy != want {
+			t.Errorf("after %d failed probes healthy = %v, want %v", i, health.Healthy, want)
+		}
+	}
+
+	// One good probe brings the node back
+	failing.Store(false)
+	checker.checkAll()
+	if health, _ := checker.GetNodeHealth(node.ID); !health.Healthy || health.ConsecutiveFailures != 0 {
+		t.Errorf("after recovery healthy = %v, failures = %d; want healthy with none", health.Healthy, health.ConsecutiveFailures)
+	}
+}
+
+func TestHealthCheckerBlockLag(t *testing.T) {
+	ahead := newNode(models.Ethereum, rpcNode(t, map[string]interface{}{"eth_blockNumber": "0x6e"}).URL)
+	behind := newNode(models.Ethereum, rpcNode(t, map[string]interface{}{"eth_blockNumber": "0x64"}).URL)
+	other := newNode(models.Polygon, rpcNode(t, map[string]interface{}{"eth_blockNumber": "0x1"}).URL)
+
+	repo := &fakeNodeRepo{nodes: []*models.BlockchainNode{ahead, behind, other}}
+	checker := newTestHealthChecker(repo, 1)
+	checker.checkAll()
+
+	for node, lag := range map[*models.BlockchainNode]uint64{ahead: 0, behind: 10, other: 0} {
+		if health, _ := checker.GetNodeHealth(node.ID); health.BlockLag != lag {
+			t.Errorf("%s at %d: lag = %d, want %d", node.Type, health.BlockHeight, health.BlockLag, lag)
+		}
+	}
+
+	// Deactivated nodes are forgotten
+	repo.nodes = repo.nodes[:1]
+	checker.checkAll()
+	if _, ok := checker.GetNodeHealth(behind.ID); ok {
+		t.Error("removed node still has health state")
+	}
+}
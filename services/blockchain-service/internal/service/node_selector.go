@@ -0,0 +1,105 @@
+package service
+
+import (
+	"sort"
+	"sync"
+
+	"ironnode/pkg/models"
+)
+
+// Selector strategy names, used in NODE_SELECTOR / NODE_SELECTORS
+const (
+	PriorityStrategy     = "priority"
+	RoundRobinStrategy   = "round-robin"
+	LeastLatencyStrategy = "least-latency"
+	FreshestHeadStrategy = "freshest-head"
+)
+
+// Candidate is a node together with its latest health state (nil if not probed yet)
+type Candidate struct {
+	Node   *models.BlockchainNode
+	Health *NodeHealth
+}
+
+// NodeSelector orders candidate nodes; the first node is tried first.
+// Candidates arrive sorted by priority (highest first).
+type NodeSelector interface {
+	Select(candidates []*Candidate) []*Candidate
+}
+
+// NewNodeSelector returns the selector for a strategy name, falling back to priority
+func NewNodeSelector(strategy string) NodeSelector {
+	switch strategy {
+	case RoundRobinStrategy:
+		return &roundRobinSelector{}
+	case LeastLatencyStrategy:
+		return leastLatencySelector{}
+	case FreshestHeadStrategy:
+		return freshestHeadSelector{}
+	default:
+		return prioritySelector{}
+	}
+}
+
+// prioritySelector keeps the configured priority order
+type prioritySelector struct{}
+
+func (prioritySelector) Select(candidates []*Candidate) []*Candidate {
+	return candidates
+}
+
+// roundRobinSelector rotates the starting node on every call
+type roundRobinSelector struct {
+	mu   sync.Mutex
+	next int
+}
+
+func (s *roundRobinSelector) Select(candidates []*Candidate) []*Candidate {
+	if len(candidates) == 0 {
+		return candidates
+	}
+
+	s.mu.Lock()
+	start := s.next % len(candidates)
+	s.next++
+	s.mu.Unlock()
+
+	rotated := make([]*Candidate, 0, len(candidates))
+	rotated = append(rotated, candidates[start:]...)
+	rotated = append(rotated, candidates[:start]...)
+	return rotated
+}
+
+// leastLatencySelector prefers nodes with the lowest probe latency
+type leastLatencySelector struct{}
+
+func (leastLatencySelector) Select(candidates []*Candidate) []*Candidate {
+	sorted := append([]*Candidate(nil), candidates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].Health, sorted[j].Health
+		if a == nil || b == nil {
+			// Unprobed nodes go last
+			return a != nil && b == nil
+		}
+		return a.LatencyMs < b.LatencyMs
+	})
+	return sorted
+}
+
+// freshestHeadSelector prefers nodes with the highest block
+type freshestHeadSelector struct{}
+
+func (freshestHeadSelector) Select(candidates []*Candidate) []*Candidate {
+	sorted := append([]*Candidate(nil), candidates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return blockHeight(sorted[i]) > blockHeight(sorted[j])
+	})
+	return sorted
+}
+
+func blockHeight(c *Candidate) uint64 {
+	if c.Health == nil {
+		return 0
+	}
+	return c.Health.BlockHeight
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"testing"
+
+	"ironnode/pkg/models"
+)
+
+// candidates builds named candidates; a nil health means the node was not probed yet
+func candidates(health map[string]*NodeHealth, names ...string) []*Candidate {
+	result := make([]*Candidate, 0, len(names))
+	for _, name := range names {
+		result = append(result, &Candidate{Node: &models.BlockchainNode{Name: name}, Health: health[name]})
+	}
+	return result
+}
+
+func names(candidates []*Candidate) []string {
+	result := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		result = append(result, candidate.Node.Name)
+	}
+	return result
+}
+
+func assertOrder(t *testing.T, got []*Candidate, want ...string) {
+	t.Helper()
+	if order := names(got); len(order) != len(want) {
+		t.Errorf("order = %v, want %v", order, want)
+	} else {
+		for i := range want {
+			if order[i] != want[i] {
+				t.Errorf("order = %v, want %v", order, want)
+				return
+			}
+		}
+	}
+}
+
+func TestPrioritySelectorKeepsOrder(t *testing.T) {
+	assertOrder(t, NewNodeSelector(PriorityStrategy).Select(candidates(nil, "a", "b", "c")), "a", "b", "c")
+}
+
+func TestUnknownStrategyFallsBackToPriority(t *testing.T) {
+	assertOrder(t, NewNodeSelector("fastest").Select(candidates(nil, "a", "b")), "a", "b")
+}
+
+func TestRoundRobinSelectorRotates(t *testing.T) {
+	selector := NewNodeSelector(RoundRobinStrategy)
+	nodes := candidates(nil, "a", "b", "c")
+
+	assertOrder(t, selector.Select(nodes), "a", "b", "c")
+	assertOrder(t, selector.Select(nodes), "b", "c", "a")
+	assertOrder(t, selector.Select(nodes), "c", "a", "b")
+	assertOrder(t, selector.Select(nodes), "a", "b", "c")
+
+	if got := selector.Select(nil); len(got) != 0 {
+		t.Errorf("selecting from no candidates returned %v", names(got))
+	}
+}
+
+func TestLeastLatencySelector(t *testing.T) {
+	health := map[string]*NodeHealth{
+		"slow": {LatencyMs: 300},
+		"fast": {LatencyMs: 20},
+		"mid":  {LatencyMs: 100},
+	}
+
+	got := NewNodeSelector(LeastLatencyStrategy).Select(candidates(health, "unprobed", "slow", "fast", "mid"))
+	assertOrder(t, got, "fast", "mid", "slow", "unprobed")
+}
+
+func TestFreshestHeadSelector(t *testing.T) {
+	health := map[string]*NodeHealth{
+		"behind":  {BlockHeight: 90},
+		"head":    {BlockHeight: 100},
+		"head-lo": {BlockHeight: 100},
+	}
+
+	// Nodes at the same height keep their priority order
+	got := NewNodeSelector(FreshestHeadStrategy).Select(candidates(health, "behind", "unprobed", "head", "head-lo"))
+	assertOrder(t, got, "head", "head-lo", "behind", "unprobed")
+}
+
+func TestFilterByLag(t *testing.T) {
+	health := map[string]*NodeHealth{
+		"head":   {BlockLag: 0},
+		"near":   {BlockLag: 3},
+		"behind": {BlockLag: 50},
+	}
+	nodes := candidates(health, "behind", "near", "head", "unprobed")
+
+	assertOrder(t, filterByLag(nodes, 5), "near", "head", "unprobed")
+	assertOrder(t, filterByLag(nodes, 0), "head", "unprobed")
+
+	// With every node lagging there is still something to try
+	lagging := candidates(health, "behind", "near")
+	assertOrder(t, filterByLag(lagging, 0), "behind", "near")
+}
@@ -1,6 +1,10 @@
 package service
 
 import (
+	"strings"
+	"sync"
+
+	"ironnode/pkg/config"
 	"ironnode/pkg/models"
 	"ironnode/services/blockchain-service/internal/repository"
 
@@ -8,28 +12,42 @@ import (
 )
 
 type NodeService interface {
-	CreateNode(name string, blockchainType models.BlockchainType, network, url string) (*models.BlockchainNode, error)
+	CreateNode(name string, blockchainType models.BlockchainType, network, url, wsURL string) (*models.BlockchainNode, error)
 	GetNodeByID(id uuid.UUID) (*models.BlockchainNode, error)
 	GetNodesByType(blockchainType models.BlockchainType) ([]*models.BlockchainNode, error)
+	GetNodesByNetwork(blockchainType models.BlockchainType, network string) ([]*models.BlockchainNode, error)
 	GetActiveNodes() ([]*models.BlockchainNode, error)
+	GetNodeHealth(nodeID *uuid.UUID, blockchainType models.BlockchainType, network string) []*NodeHealth
+	SelectNodes(blockchainType models.BlockchainType, network, blockTag string) ([]*models.BlockchainNode, error)
 	UpdateNode(node *models.BlockchainNode) error
 	DeleteNode(id uuid.UUID) error
 }
 
 type nodeService struct {
-	repo repository.NodeRepository
+	repo          repository.NodeRepository
+	healthChecker HealthChecker
+	selection     config.NodeSelectionConfig
+
+	mu        sync.Mutex
+	selectors map[string]NodeSelector // keyed by "type/network"
 }
 
-func NewNodeService(repo repository.NodeRepository) NodeService {
-	return &nodeService{repo: repo}
+func NewNodeService(repo repository.NodeRepository, healthChecker HealthChecker, selection config.NodeSelectionConfig) NodeService {
+	return &nodeService{
+		repo:          repo,
+		healthChecker: healthChecker,
+		selection:     selection,
+		selectors:     make(map[string]NodeSelector),
+	}
 }
 
-func (s *nodeService) CreateNode(name string, blockchainType models.BlockchainType, network, url string) (*models.BlockchainNode, error) {
+func (s *nodeService) CreateNode(name string, blockchainType models.BlockchainType, network, url, wsURL string) (*models.BlockchainNode, error) {
 	node := &models.BlockchainNode{
 		Name:     name,
 		Type:     blockchainType,
 		Network:  network,
 		URL:      url,
+		WSURL:    wsURL,
 		IsActive: true,
 		Priority: 0,
 	}
@@ -49,10 +67,145 @@ func (s *nodeService) GetNodesByType(blockchainType models.BlockchainType) ([]*m
 	return s.repo.GetNodesByType(blockchainType)
 }
 
+// GetNodesByNetwork returns active nodes of the chain, skipping nodes the health
+// checker has marked unhealthy. If every node is unhealthy, all are returned so
+// callers still have something to try.
+func (s *nodeService) GetNodesByNetwork(blockchainType models.BlockchainType, network string) ([]*models.BlockchainNode, error) {
+	nodes, err := s.repo.GetNodesByNetwork(blockchainType, network)
+	if err != nil {
+		return nil, err
+	}
+
+	healthy := make([]*models.BlockchainNode, 0, len(nodes))
+	for _, node := range nodes {
+		if state, exists := s.healthChecker.GetNodeHealth(node.ID); exists && !state.Healthy {
+			continue
+		}
+		healthy = append(healthy, node)
+	}
+
+	if len(healthy) == 0 {
+		return nodes, nil
+	}
+
+	return healthy, nil
+}
+
 func (s *nodeService) GetActiveNodes() ([]*models.BlockchainNode, error) {
 	return s.repo.GetActiveNodes()
 }
 
+// GetNodeHealth returns health states filtered by node ID, type and network (all optional)
+func (s *nodeService) GetNodeHealth(nodeID *uuid.UUID, blockchainType models.BlockchainType, network string) []*NodeHealth {
+	if nodeID != nil {
+		state, exists := s.healthChecker.GetNodeHealth(*nodeID)
+		if !exists {
+			return nil
+		}
+		return []*NodeHealth{state}
+	}
+
+	var result []*NodeHealth
+	for _, state := range s.healthChecker.GetAllHealth() {
+		if blockchainType != "" && state.Type != blockchainType {
+			continue
+		}
+		if network != "" && state.Network != network {
+			continue
+		}
+		result = append(result, state)
+	}
+
+	return result
+}
+
+// SelectNodes returns healthy nodes of the chain in the order they should be tried.
+// Queries for the chain head ("latest", "pending") are pinned to the freshest nodes,
+// queries for a specific historical block may use lagging nodes, and everything
+// else skips nodes more than MaxBlockLag blocks behind.
+func (s *nodeService) SelectNodes(blockchainType models.BlockchainType, network, blockTag string) ([]*models.BlockchainNode, error) {
+	nodes, err := s.GetNodesByNetwork(blockchainType, network)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*Candidate, 0, len(nodes))
+	for _, node := range nodes {
+		health, _ := s.healthChecker.GetNodeHealth(node.ID)
+		candidates = append(candidates, &Candidate{Node: node, Health: health})
+	}
+
+	switch classifyBlockTag(blockTag) {
+	case headQuery:
+		candidates = filterByLag(candidates, 0)
+	case recentQuery:
+		candidates = filterByLag(candidates, s.selection.MaxBlockLag)
+	}
+
+	candidates = s.selector(blockchainType, network).Select(candidates)
+
+	selected := make([]*models.BlockchainNode, 0, len(candidates))
+	for _, candidate := range candidates {
+		selected = append(selected, candidate.Node)
+	}
+
+	return selected, nil
+}
+
+// selector returns the per-chain selector, creating it on first use so that
+// stateful strategies (round-robin) keep their state between calls
+func (s *nodeService) selector(blockchainType models.BlockchainType, network string) NodeSelector {
+	key := string(blockchainType) + "/" + network
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	selector, exists := s.selectors[key]
+	if !exists {
+		selector = NewNodeSelector(s.selection.StrategyFor(string(blockchainType)))
+		s.selectors[key] = selector
+	}
+
+	return selector
+}
+
+type blockQuery int
+
+const (
+	recentQuery   blockQuery = iota // no block context, or safe/finalized
+	headQuery                       // latest / pending
+	archivalQuery                   // a specific block number or hash
+)
+
+func classifyBlockTag(blockTag string) blockQuery {
+	switch strings.ToLower(blockTag) {
+	case "", "safe", "finalized":
+		return recentQuery
+	case "latest", "pending":
+		return headQuery
+	default:
+		return archivalQuery
+	}
+}
+
+// filterByLag drops probed nodes lagging more than maxLag blocks behind the head.
+// Unprobed nodes are kept; if nothing is left the input is returned unchanged.
+func filterByLag(candidates []*Candidate, maxLag uint64) []*Candidate {
+	filtered := make([]*Candidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.Health != nil && candidate.Health.BlockLag > maxLag {
+			continue
+		}
+		filtered = append(filtered, candidate)
+	}
+
+	if len(filtered) == 0 {
+		return candidates
+	}
+
+	return filtered
+}
+
 func (s *nodeService) UpdateNode(node *models.BlockchainNode) error {
 	return s.repo.UpdateNode(node)
 }
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"testing"
+
+	"ironnode/pkg/config"
+	"ironnode/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+// fakeHealthChecker reports fixed health states
+type fakeHealthChecker struct {
+	HealthChecker
+	health map[uuid.UUID]*NodeHealth
+}
+
+func (h *fakeHealthChecker) GetNodeHealth(nodeID uuid.UUID) (*NodeHealth, bool) {
+	state, ok := h.health[nodeID]
+	return state, ok
+}
+
+func TestSelectNodesFiltersByLag(t *testing.T) {
+	head := newNode(models.Ethereum, "http://head")
+	near := newNode(models.Ethereum, "http://near")
+	behind := newNode(models.Ethereum, "http://behind")
+	down := newNode(models.Ethereum, "http://down")
+	head.Name, near.Name, behind.Name, down.Name = "head", "near", "behind", "down"
+
+	checker := &fakeHealthChecker{health: map[uuid.UUID]*NodeHealth{
+		head.ID:   {Healthy: true, BlockLag: 0},
+		near.ID:   {Healthy: true, BlockLag: 3},
+		behind.ID: {Healthy: true, BlockLag: 40},
+		down.ID:   {Healthy: false},
+	}}
+	repo := &fakeNodeRepo{nodes: []*models.BlockchainNode{behind, near, head, down}}
+	service := NewNodeService(repo, checker, config.NodeSelectionConfig{MaxBlockLag: 5, DefaultStrategy: PriorityStrategy})
+
+	tests := []struct {
+		blockTag string
+		want     []string
+	}{
+		{"latest", []string{"head"}},
+		{"PENDING", []string{"head"}},
+		{"", []string{"near", "head"}},
+		{"finalized", []string{"near", "head"}},
+		{"0x10", []string{"behind", "near", "head"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.blockTag, func(t *testing.T) {
+			nodes, err := service.SelectNodes(models.Ethereum, "mainnet", tt.blockTag)
+			if err != nil {
+				t.Fatalf("SelectNodes: %v", err)
+			}
+
+			got := make([]*Candidate, 0, len(nodes))
+			for _, node := range nodes {
+				got = append(got, &Candidate{Node: node})
+			}
+			assertOrder(t, got, tt.want...)
+		})
+	}
+}
+
+func TestSelectNodesUsesStrategyPerChain(t *testing.T) {
+	fast := newNode(models.Solana, "http://fast")
+	slow := newNode(models.Solana, "http://slow")
+	fast.Name, slow.Name = "fast", "slow"
+
+	checker := &fakeHealthChecker{health: map[uuid.UUID]*NodeHealth{
+		fast.ID: {Healthy: true, LatencyMs: 10},
+		slow.ID: {Healthy: true, LatencyMs: 90},
+	}}
+	repo := &fakeNodeRepo{nodes: []*models.BlockchainNode{slow, fast}}
+	service := NewNodeService(repo, checker, config.NodeSelectionConfig{
+		DefaultStrategy: PriorityStrategy,
+		Strategies:      map[string]string{"solana": LeastLatencyStrategy},
+	})
+
+	nodes, err := service.SelectNodes(models.Solana, "mainnet", "")
+	if err != nil {
+		t.Fatalf("SelectNodes: %v", err)
+	}
+	if len(nodes) != 2 || nodes[0] != fast {
+		t.Errorf("first node = %s, want fast", nodes[0].Name)
+	}
+}
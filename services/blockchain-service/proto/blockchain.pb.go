@@ -27,6 +27,7 @@ type CreateNodeRequest struct {
 	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
 	Network       string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
 	Url           string                 `protobuf:"bytes,4,opt,name=url,proto3" json:"url,omitempty"`
+	WsUrl         string                 `protobuf:"bytes,5,opt,name=ws_url,json=wsUrl,proto3" json:"ws_url,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -89,6 +90,13 @@ func (x *CreateNodeRequest) GetUrl() string {
 	return ""
 }
 
+func (x *CreateNodeRequest) GetWsUrl() string {
+	if x != nil {
+		return x.WsUrl
+	}
+	return ""
+}
+
 type GetNodeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -213,6 +221,118 @@ func (x *GetNodesByTypeRequest) GetType() string {
 	return ""
 }
 
+type GetNodesByNetworkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Network       string                 `protobuf:"bytes,2,opt,name=network,proto3" json:"network,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNodesByNetworkRequest) Reset() {
+	*x = GetNodesByNetworkRequest{}
+	mi := &file_services_blockchain_service_proto_blockchain_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNodesByNetworkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNodesByNetworkRequest) ProtoMessage() {}
+
+func (x *GetNodesByNetworkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_blockchain_service_proto_blockchain_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNodesByNetworkRequest.ProtoReflect.Descriptor instead.
+func (*GetNodesByNetworkRequest) Descriptor() ([]byte, []int) {
+	return file_services_blockchain_service_proto_blockchain_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetNodesByNetworkRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *GetNodesByNetworkRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+type SelectNodesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Network       string                 `protobuf:"bytes,2,opt,name=network,proto3" json:"network,omitempty"`
+	BlockTag      string                 `protobuf:"bytes,3,opt,name=block_tag,json=blockTag,proto3" json:"block_tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SelectNodesRequest) Reset() {
+	*x = SelectNodesRequest{}
+	mi := &file_services_blockchain_service_proto_blockchain_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SelectNodesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SelectNodesRequest) ProtoMessage() {}
+
+func (x *SelectNodesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_blockchain_service_proto_blockchain_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SelectNodesRequest.ProtoReflect.Descriptor instead.
+func (*SelectNodesRequest) Descriptor() ([]byte, []int) {
+	return file_services_blockchain_service_proto_blockchain_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SelectNodesRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SelectNodesRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *SelectNodesRequest) GetBlockTag() string {
+	if x != nil {
+		return x.BlockTag
+	}
+	return ""
+}
+
 type NodeResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -222,13 +342,14 @@ type NodeResponse struct {
 	Url           string                 `protobuf:"bytes,5,opt,name=url,proto3" json:"url,omitempty"`
 	IsActive      bool                   `protobuf:"varint,6,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
 	Priority      int32                  `protobuf:"varint,7,opt,name=priority,proto3" json:"priority,omitempty"`
+	WsUrl         string                 `protobuf:"bytes,8,opt,name=ws_url,json=wsUrl,proto3" json:"ws_url,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *NodeResponse) Reset() {
 	*x = NodeResponse{}
-	mi := &file_services_blockchain_service_proto_blockchain_proto_msgTypes[4]
+	mi := &file_services_blockchain_service_proto_blockchain_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -240,7 +361,7 @@ func (x *NodeResponse) String() string {
 func (*NodeResponse) ProtoMessage() {}
 
 func (x *NodeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_services_blockchain_service_proto_blockchain_proto_msgTypes[4]
+	mi := &file_services_blockchain_service_proto_blockchain_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -253,7 +374,7 @@ func (x *NodeResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodeResponse.ProtoReflect.Descriptor instead.
 func (*NodeResponse) Descriptor() ([]byte, []int) {
-	return file_services_blockchain_service_proto_blockchain_proto_rawDescGZIP(), []int{4}
+	return file_services_blockchain_service_proto_blockchain_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *NodeResponse) GetId() string {
@@ -305,6 +426,13 @@ func (x *NodeResponse) GetPriority() int32 {
 	return 0
 }
 
+func (x *NodeResponse) GetWsUrl() string {
+	if x != nil {
+		return x.WsUrl
+	}
+	return ""
+}
+
 type ListNodesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Nodes         []*NodeResponse        `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
@@ -314,7 +442,7 @@ type ListNodesResponse struct {
 
 func (x *ListNodesResponse) Reset() {
 	*x = ListNodesResponse{}
-	mi := &file_services_blockchain_service_proto_blockchain_proto_msgTypes[5]
+	mi := &file_services_blockchain_service_proto_blockchain_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -326,7 +454,7 @@ func (x *ListNodesResponse) String() string {
 func (*ListNodesResponse) ProtoMessage() {}
 
 func (x *ListNodesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_services_blockchain_service_proto_blockchain_proto_msgTypes[5]
+	mi := &file_services_blockchain_service_proto_blockchain_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -339,7 +467,7 @@ func (x *ListNodesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListNodesResponse.ProtoReflect.Descriptor instead.
 func (*ListNodesResponse) Descriptor() ([]byte, []int) {
-	return file_services_blockchain_service_proto_blockchain_proto_rawDescGZIP(), []int{5}
+	return file_services_blockchain_service_proto_blockchain_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ListNodesResponse) GetNodes() []*NodeResponse {
@@ -349,22 +477,258 @@ func (x *ListNodesResponse) GetNodes() []*NodeResponse {
 	return nil
 }
 
+type GetNodeHealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Network       string                 `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNodeHealthRequest) Reset() {
+	*x = GetNodeHealthRequest{}
+	mi := &file_services_blockchain_service_proto_blockchain_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNodeHealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNodeHealthRequest) ProtoMessage() {}
+
+func (x *GetNodeHealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_blockchain_service_proto_blockchain_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNodeHealthRequest.ProtoReflect.Descriptor instead.
+func (*GetNodeHealthRequest) Descriptor() ([]byte, []int) {
+	return file_services_blockchain_service_proto_blockchain_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetNodeHealthRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetNodeHealthRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *GetNodeHealthRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+type NodeHealth struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	NodeId              string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Name                string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Type                string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Network             string                 `protobuf:"bytes,4,opt,name=network,proto3" json:"network,omitempty"`
+	Healthy             bool                   `protobuf:"varint,5,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	LatencyMs           int64                  `protobuf:"varint,6,opt,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`
+	BlockHeight         uint64                 `protobuf:"varint,7,opt,name=block_height,json=blockHeight,proto3" json:"block_height,omitempty"`
+	BlockLag            uint64                 `protobuf:"varint,8,opt,name=block_lag,json=blockLag,proto3" json:"block_lag,omitempty"`
+	ConsecutiveFailures int32                  `protobuf:"varint,9,opt,name=consecutive_failures,json=consecutiveFailures,proto3" json:"consecutive_failures,omitempty"`
+	LastError           string                 `protobuf:"bytes,10,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	LastCheckedAt       string                 `protobuf:"bytes,11,opt,name=last_checked_at,json=lastCheckedAt,proto3" json:"last_checked_at,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *NodeHealth) Reset() {
+	*x = NodeHealth{}
+	mi := &file_services_blockchain_service_proto_blockchain_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeHealth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeHealth) ProtoMessage() {}
+
+func (x *NodeHealth) ProtoReflect() protoreflect.Message {
+	mi := &file_services_blockchain_service_proto_blockchain_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeHealth.ProtoReflect.Descriptor instead.
+func (*NodeHealth) Descriptor() ([]byte, []int) {
+	return file_services_blockchain_service_proto_blockchain_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *NodeHealth) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *NodeHealth) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *NodeHealth) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *NodeHealth) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *NodeHealth) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *NodeHealth) GetLatencyMs() int64 {
+	if x != nil {
+		return x.LatencyMs
+	}
+	return 0
+}
+
+func (x *NodeHealth) GetBlockHeight() uint64 {
+	if x != nil {
+		return x.BlockHeight
+	}
+	return 0
+}
+
+func (x *NodeHealth) GetBlockLag() uint64 {
+	if x != nil {
+		return x.BlockLag
+	}
+	return 0
+}
+
+func (x *NodeHealth) GetConsecutiveFailures() int32 {
+	if x != nil {
+		return x.ConsecutiveFailures
+	}
+	return 0
+}
+
+func (x *NodeHealth) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *NodeHealth) GetLastCheckedAt() string {
+	if x != nil {
+		return x.LastCheckedAt
+	}
+	return ""
+}
+
+type GetNodeHealthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         []*NodeHealth          `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNodeHealthResponse) Reset() {
+	*x = GetNodeHealthResponse{}
+	mi := &file_services_blockchain_service_proto_blockchain_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNodeHealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNodeHealthResponse) ProtoMessage() {}
+
+func (x *GetNodeHealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_services_blockchain_service_proto_blockchain_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNodeHealthResponse.ProtoReflect.Descriptor instead.
+func (*GetNodeHealthResponse) Descriptor() ([]byte, []int) {
+	return file_services_blockchain_service_proto_blockchain_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetNodeHealthResponse) GetNodes() []*NodeHealth {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
 var File_services_blockchain_service_proto_blockchain_proto protoreflect.FileDescriptor
 
 const file_services_blockchain_service_proto_blockchain_proto_rawDesc = "" +
 	"\n" +
 	"2services/blockchain-service/proto/blockchain.proto\x12\n" +
-	"blockchain\"g\n" +
+	"blockchain\"~\n" +
 	"\x11CreateNodeRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
 	"\x04type\x18\x02 \x01(\tR\x04type\x12\x18\n" +
 	"\anetwork\x18\x03 \x01(\tR\anetwork\x12\x10\n" +
-	"\x03url\x18\x04 \x01(\tR\x03url\" \n" +
+	"\x03url\x18\x04 \x01(\tR\x03url\x12\x15\n" +
+	"\x06ws_url\x18\x05 \x01(\tR\x05wsUrl\" \n" +
 	"\x0eGetNodeRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"\x12\n" +
 	"\x10ListNodesRequest\"+\n" +
 	"\x15GetNodesByTypeRequest\x12\x12\n" +
-	"\x04type\x18\x01 \x01(\tR\x04type\"\xab\x01\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\"H\n" +
+	"\x18GetNodesByNetworkRequest\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x18\n" +
+	"\anetwork\x18\x02 \x01(\tR\anetwork\"_\n" +
+	"\x12SelectNodesRequest\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x18\n" +
+	"\anetwork\x18\x02 \x01(\tR\anetwork\x12\x1b\n" +
+	"\tblock_tag\x18\x03 \x01(\tR\bblockTag\"\xc2\x01\n" +
 	"\fNodeResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
@@ -372,15 +736,41 @@ const file_services_blockchain_service_proto_blockchain_proto_rawDesc = "" +
 	"\anetwork\x18\x04 \x01(\tR\anetwork\x12\x10\n" +
 	"\x03url\x18\x05 \x01(\tR\x03url\x12\x1b\n" +
 	"\tis_active\x18\x06 \x01(\bR\bisActive\x12\x1a\n" +
-	"\bpriority\x18\a \x01(\x05R\bpriority\"C\n" +
+	"\bpriority\x18\a \x01(\x05R\bpriority\x12\x15\n" +
+	"\x06ws_url\x18\b \x01(\tR\x05wsUrl\"C\n" +
 	"\x11ListNodesResponse\x12.\n" +
-	"\x05nodes\x18\x01 \x03(\v2\x18.blockchain.NodeResponseR\x05nodes2\xb9\x02\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x18.blockchain.NodeResponseR\x05nodes\"T\n" +
+	"\x14GetNodeHealthRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x18\n" +
+	"\anetwork\x18\x03 \x01(\tR\anetwork\"\xda\x02\n" +
+	"\n" +
+	"NodeHealth\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x18\n" +
+	"\anetwork\x18\x04 \x01(\tR\anetwork\x12\x18\n" +
+	"\ahealthy\x18\x05 \x01(\bR\ahealthy\x12\x1d\n" +
+	"\n" +
+	"latency_ms\x18\x06 \x01(\x03R\tlatencyMs\x12!\n" +
+	"\fblock_height\x18\a \x01(\x04R\vblockHeight\x12\x1b\n" +
+	"\tblock_lag\x18\b \x01(\x04R\bblockLag\x121\n" +
+	"\x14consecutive_failures\x18\t \x01(\x05R\x13consecutiveFailures\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\n" +
+	" \x01(\tR\tlastError\x12&\n" +
+	"\x0flast_checked_at\x18\v \x01(\tR\rlastCheckedAt\"E\n" +
+	"\x15GetNodeHealthResponse\x12,\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x16.blockchain.NodeHealthR\x05nodes2\xb7\x04\n" +
 	"\x11BlockchainService\x12E\n" +
 	"\n" +
 	"CreateNode\x12\x1d.blockchain.CreateNodeRequest\x1a\x18.blockchain.NodeResponse\x12?\n" +
 	"\aGetNode\x12\x1a.blockchain.GetNodeRequest\x1a\x18.blockchain.NodeResponse\x12H\n" +
 	"\tListNodes\x12\x1c.blockchain.ListNodesRequest\x1a\x1d.blockchain.ListNodesResponse\x12R\n" +
-	"\x0eGetNodesByType\x12!.blockchain.GetNodesByTypeRequest\x1a\x1d.blockchain.ListNodesResponseB3Z1quicknode-clone/services/blockchain-service/protob\x06proto3"
+	"\x0eGetNodesByType\x12!.blockchain.GetNodesByTypeRequest\x1a\x1d.blockchain.ListNodesResponse\x12X\n" +
+	"\x11GetNodesByNetwork\x12$.blockchain.GetNodesByNetworkRequest\x1a\x1d.blockchain.ListNodesResponse\x12T\n" +
+	"\rGetNodeHealth\x12 .blockchain.GetNodeHealthRequest\x1a!.blockchain.GetNodeHealthResponse\x12L\n" +
+	"\vSelectNodes\x12\x1e.blockchain.SelectNodesRequest\x1a\x1d.blockchain.ListNodesResponseB3Z1quicknode-clone/services/blockchain-service/protob\x06proto3"
 
 var (
 	file_services_blockchain_service_proto_blockchain_proto_rawDescOnce sync.Once
@@ -394,30 +784,42 @@ func file_services_blockchain_service_proto_blockchain_proto_rawDescGZIP() []byt
 	return file_services_blockchain_service_proto_blockchain_proto_rawDescData
 }
 
-var file_services_blockchain_service_proto_blockchain_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_services_blockchain_service_proto_blockchain_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_services_blockchain_service_proto_blockchain_proto_goTypes = []any{
-	(*CreateNodeRequest)(nil),     // 0: blockchain.CreateNodeRequest
-	(*GetNodeRequest)(nil),        // 1: blockchain.GetNodeRequest
-	(*ListNodesRequest)(nil),      // 2: blockchain.ListNodesRequest
-	(*GetNodesByTypeRequest)(nil), // 3: blockchain.GetNodesByTypeRequest
-	(*NodeResponse)(nil),          // 4: blockchain.NodeResponse
-	(*ListNodesResponse)(nil),     // 5: blockchain.ListNodesResponse
+	(*CreateNodeRequest)(nil),        // 0: blockchain.CreateNodeRequest
+	(*GetNodeRequest)(nil),           // 1: blockchain.GetNodeRequest
+	(*ListNodesRequest)(nil),         // 2: blockchain.ListNodesRequest
+	(*GetNodesByTypeRequest)(nil),    // 3: blockchain.GetNodesByTypeRequest
+	(*GetNodesByNetworkRequest)(nil), // 4: blockchain.GetNodesByNetworkRequest
+	(*SelectNodesRequest)(nil),       // 5: blockchain.SelectNodesRequest
+	(*NodeResponse)(nil),             // 6: blockchain.NodeResponse
+	(*ListNodesResponse)(nil),        // 7: blockchain.ListNodesResponse
+	(*GetNodeHealthRequest)(nil),     // 8: blockchain.GetNodeHealthRequest
+	(*NodeHealth)(nil),               // 9: blockchain.NodeHealth
+	(*GetNodeHealthResponse)(nil),    // 10: blockchain.GetNodeHealthResponse
 }
 var file_services_blockchain_service_proto_blockchain_proto_depIdxs = []int32{
-	4, // 0: blockchain.ListNodesResponse.nodes:type_name -> blockchain.NodeResponse
-	0, // 1: blockchain.BlockchainService.CreateNode:input_type -> blockchain.CreateNodeRequest
-	1, // 2: blockchain.BlockchainService.GetNode:input_type -> blockchain.GetNodeRequest
-	2, // 3: blockchain.BlockchainService.ListNodes:input_type -> blockchain.ListNodesRequest
-	3, // 4: blockchain.BlockchainService.GetNodesByType:input_type -> blockchain.GetNodesByTypeRequest
-	4, // 5: blockchain.BlockchainService.CreateNode:output_type -> blockchain.NodeResponse
-	4, // 6: blockchain.BlockchainService.GetNode:output_type -> blockchain.NodeResponse
-	5, // 7: blockchain.BlockchainService.ListNodes:output_type -> blockchain.ListNodesResponse
-	5, // 8: blockchain.BlockchainService.GetNodesByType:output_type -> blockchain.ListNodesResponse
-	5, // [5:9] is the sub-list for method output_type
-	1, // [1:5] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	6,  // 0: blockchain.ListNodesResponse.nodes:type_name -> blockchain.NodeResponse
+	9,  // 1: blockchain.GetNodeHealthResponse.nodes:type_name -> blockchain.NodeHealth
+	0,  // 2: blockchain.BlockchainService.CreateNode:input_type -> blockchain.CreateNodeRequest
+	1,  // 3: blockchain.BlockchainService.GetNode:input_type -> blockchain.GetNodeRequest
+	2,  // 4: blockchain.BlockchainService.ListNodes:input_type -> blockchain.ListNodesRequest
+	3,  // 5: blockchain.BlockchainService.GetNodesByType:input_type -> blockchain.GetNodesByTypeRequest
+	4,  // 6: blockchain.BlockchainService.GetNodesByNetwork:input_type -> blockchain.GetNodesByNetworkRequest
+	8,  // 7: blockchain.BlockchainService.GetNodeHealth:input_type -> blockchain.GetNodeHealthRequest
+	5,  // 8: blockchain.BlockchainService.SelectNodes:input_type -> blockchain.SelectNodesRequest
+	6,  // 9: blockchain.BlockchainService.CreateNode:output_type -> blockchain.NodeResponse
+	6,  // 10: blockchain.BlockchainService.GetNode:output_type -> blockchain.NodeResponse
+	7,  // 11: blockchain.BlockchainService.ListNodes:output_type -> blockchain.ListNodesResponse
+	7,  // 12: blockchain.BlockchainService.GetNodesByType:output_type -> blockchain.ListNodesResponse
+	7,  // 13: blockchain.BlockchainService.GetNodesByNetwork:output_type -> blockchain.ListNodesResponse
+	10, // 14: blockchain.BlockchainService.GetNodeHealth:output_type -> blockchain.GetNodeHealthResponse
+	7,  // 15: blockchain.BlockchainService.SelectNodes:output_type -> blockchain.ListNodesResponse
+	9,  // [9:16] is the sub-list for method output_type
+	2,  // [2:9] is the sub-list for method input_type
+	2,  // [2:2] is the sub-list for extension type_name
+	2,  // [2:2] is the sub-list for extension extendee
+	0,  // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_services_blockchain_service_proto_blockchain_proto_init() }
@@ -431,7 +833,7 @@ func file_services_blockchain_service_proto_blockchain_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_services_blockchain_service_proto_blockchain_proto_rawDesc), len(file_services_blockchain_service_proto_blockchain_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
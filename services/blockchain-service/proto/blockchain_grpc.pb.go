@@ -19,10 +19,13 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	BlockchainService_CreateNode_FullMethodName     = "/blockchain.BlockchainService/CreateNode"
-	BlockchainService_GetNode_FullMethodName        = "/blockchain.BlockchainService/GetNode"
-	BlockchainService_ListNodes_FullMethodName      = "/blockchain.BlockchainService/ListNodes"
-	BlockchainService_GetNodesByType_FullMethodName = "/blockchain.BlockchainService/GetNodesByType"
+	BlockchainService_CreateNode_FullMethodName        = "/blockchain.BlockchainService/CreateNode"
+	BlockchainService_GetNode_FullMethodName           = "/blockchain.BlockchainService/GetNode"
+	BlockchainService_ListNodes_FullMethodName         = "/blockchain.BlockchainService/ListNodes"
+	BlockchainService_GetNodesByType_FullMethodName    = "/blockchain.BlockchainService/GetNodesByType"
+	BlockchainService_GetNodesByNetwork_FullMethodName = "/blockchain.BlockchainService/GetNodesByNetwork"
+	BlockchainService_GetNodeHealth_FullMethodName     = "/blockchain.BlockchainService/GetNodeHealth"
+	BlockchainService_SelectNodes_FullMethodName       = "/blockchain.BlockchainService/SelectNodes"
 )
 
 // BlockchainServiceClient is the client API for BlockchainService service.
@@ -33,6 +36,9 @@ type BlockchainServiceClient interface {
 	GetNode(ctx context.Context, in *GetNodeRequest, opts ...grpc.CallOption) (*NodeResponse, error)
 	ListNodes(ctx context.Context, in *ListNodesRequest, opts ...grpc.CallOption) (*ListNodesResponse, error)
 	GetNodesByType(ctx context.Context, in *GetNodesByTypeRequest, opts ...grpc.CallOption) (*ListNodesResponse, error)
+	GetNodesByNetwork(ctx context.Context, in *GetNodesByNetworkRequest, opts ...grpc.CallOption) (*ListNodesResponse, error)
+	GetNodeHealth(ctx context.Context, in *GetNodeHealthRequest, opts ...grpc.CallOption) (*GetNodeHealthResponse, error)
+	SelectNodes(ctx context.Context, in *SelectNodesRequest, opts ...grpc.CallOption) (*ListNodesResponse, error)
 }
 
 type blockchainServiceClient struct {
@@ -79,6 +85,33 @@ func (c *blockchainServiceClient) GetNodesByType(ctx context.Context, in *GetNod
 	return out, nil
 }
 
+func (c *blockchainServiceClient) GetNodesByNetwork(ctx context.Context, in *GetNodesByNetworkRequest, opts ...grpc.CallOption) (*ListNodesResponse, error) {
+	out := new(ListNodesResponse)
+	err := c.cc.Invoke(ctx, BlockchainService_GetNodesByNetwork_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockchainServiceClient) GetNodeHealth(ctx context.Context, in *GetNodeHealthRequest, opts ...grpc.CallOption) (*GetNodeHealthResponse, error) {
+	out := new(GetNodeHealthResponse)
+	err := c.cc.Invoke(ctx, BlockchainService_GetNodeHealth_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockchainServiceClient) SelectNodes(ctx context.Context, in *SelectNodesRequest, opts ...grpc.CallOption) (*ListNodesResponse, error) {
+	out := new(ListNodesResponse)
+	err := c.cc.Invoke(ctx, BlockchainService_SelectNodes_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BlockchainServiceServer is the server API for BlockchainService service.
 // All implementations must embed UnimplementedBlockchainServiceServer
 // for forward compatibility
@@ -87,6 +120,9 @@ type BlockchainServiceServer interface {
 	GetNode(context.Context, *GetNodeRequest) (*NodeResponse, error)
 	ListNodes(context.Context, *ListNodesRequest) (*ListNodesResponse, error)
 	GetNodesByType(context.Context, *GetNodesByTypeRequest) (*ListNodesResponse, error)
+	GetNodesByNetwork(context.Context, *GetNodesByNetworkRequest) (*ListNodesResponse, error)
+	GetNodeHealth(context.Context, *GetNodeHealthRequest) (*GetNodeHealthResponse, error)
+	SelectNodes(context.Context, *SelectNodesRequest) (*ListNodesResponse, error)
 	mustEmbedUnimplementedBlockchainServiceServer()
 }
 
@@ -106,6 +142,15 @@ func (UnimplementedBlockchainServiceServer) ListNodes(context.Context, *ListNode
 func (UnimplementedBlockchainServiceServer) GetNodesByType(context.Context, *GetNodesByTypeRequest) (*ListNodesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetNodesByType not implemented")
 }
+func (UnimplementedBlockchainServiceServer) GetNodesByNetwork(context.Context, *GetNodesByNetworkRequest) (*ListNodesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNodesByNetwork not implemented")
+}
+func (UnimplementedBlockchainServiceServer) GetNodeHealth(context.Context, *GetNodeHealthRequest) (*GetNodeHealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNodeHealth not implemented")
+}
+func (UnimplementedBlockchainServiceServer) SelectNodes(context.Context, *SelectNodesRequest) (*ListNodesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SelectNodes not implemented")
+}
 func (UnimplementedBlockchainServiceServer) mustEmbedUnimplementedBlockchainServiceServer() {}
 
 // UnsafeBlockchainServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -191,6 +236,60 @@ func _BlockchainService_GetNodesByType_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BlockchainService_GetNodesByNetwork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNodesByNetworkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockchainServiceServer).GetNodesByNetwork(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlockchainService_GetNodesByNetwork_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockchainServiceServer).GetNodesByNetwork(ctx, req.(*GetNodesByNetworkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlockchainService_GetNodeHealth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNodeHealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockchainServiceServer).GetNodeHealth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlockchainService_GetNodeHealth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockchainServiceServer).GetNodeHealth(ctx, req.(*GetNodeHealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlockchainService_SelectNodes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SelectNodesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockchainServiceServer).SelectNodes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlockchainService_SelectNodes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockchainServiceServer).SelectNodes(ctx, req.(*SelectNodesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BlockchainService_ServiceDesc is the grpc.ServiceDesc for BlockchainService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -214,6 +313,18 @@ var BlockchainService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetNodesByType",
 			Handler:    _BlockchainService_GetNodesByType_Handler,
 		},
+		{
+			MethodName: "GetNodesByNetwork",
+			Handler:    _BlockchainService_GetNodesByNetwork_Handler,
+		},
+		{
+			MethodName: "GetNodeHealth",
+			Handler:    _BlockchainService_GetNodeHealth_Handler,
+		},
+		{
+			MethodName: "SelectNodes",
+			Handler:    _BlockchainService_SelectNodes_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "services/blockchain-service/proto/blockchain.proto",
@@ -38,7 +38,7 @@ func main() {
 
 	// Initialize repository, service, and handler
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, cfg.APIKeys.RotationGrace, cfg.APIKeys.MaxRotationGrace)
 	userHandler := handler.NewUserHandler(userService)
 
 	// Create gRPC server
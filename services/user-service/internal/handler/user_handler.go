@@ -2,7 +2,10 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"ironnode/pkg/models"
 	"ironnode/services/user-service/internal/service"
 	pb "ironnode/services/user-service/proto"
 
@@ -26,19 +29,15 @@ func (h *UserHandler) CreateAPIKey(ctx context.Context, req *pb.CreateAPIKeyRequ
 		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
 	}
 
-	apiKey, err := h.userService.CreateAPIKey(userID, req.Name, req.Description)
+	apiKey, err := h.userService.CreateAPIKey(userID, req.Name, req.Description, req.MonthlyLimit, req.RequestsPerSecond, restrictionsFromProto(req.Restrictions))
+	if errors.Is(err, service.ErrInvalidLimit) || errors.Is(err, service.ErrInvalidRestrictions) {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to create API key: %v", err)
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create API key: %v", err)
 	}
 
-	return &pb.APIKeyResponse{
-		Id:          apiKey.ID.String(),
-		UserId:      apiKey.UserID.String(),
-		Key:         apiKey.Key,
-		Name:        apiKey.Name,
-		Description: apiKey.Description,
-		IsActive:    apiKey.IsActive,
-	}, nil
+	return apiKeyToProto(apiKey), nil
 }
 
 func (h *UserHandler) GetAPIKeys(ctx context.Context, req *pb.GetAPIKeysRequest) (*pb.GetAPIKeysResponse, error) {
@@ -54,14 +53,7 @@ func (h *UserHandler) GetAPIKeys(ctx context.Context, req *pb.GetAPIKeysRequest)
 
 	var pbKeys []*pb.APIKeyResponse
 	for _, key := range keys {
-		pbKeys = append(pbKeys, &pb.APIKeyResponse{
-			Id:          key.ID.String(),
-			UserId:      key.UserID.String(),
-			Key:         key.Key,
-			Name:        key.Name,
-			Description: key.Description,
-			IsActive:    key.IsActive,
-		})
+		pbKeys = append(pbKeys, apiKeyToProto(key))
 	}
 
 	return &pb.GetAPIKeysResponse{
@@ -78,18 +70,97 @@ func (h *UserHandler) ValidateAPIKey(ctx context.Context, req *pb.ValidateAPIKey
 	}
 
 	return &pb.ValidateAPIKeyResponse{
-		Valid:  true,
-		UserId: apiKey.UserID.String(),
+		Valid:             true,
+		UserId:            apiKey.UserID.String(),
+		ApiKeyId:          apiKey.ID.String(),
+		MonthlyLimit:      apiKey.MonthlyLimit,
+		RequestsPerSecond: apiKey.RequestsPerSecond,
+		Restrictions:      restrictionsToProto(apiKey.Restrictions),
+		Deprecated:        apiKey.IsDeprecated(),
+		ReplacedById:      optionalIDString(apiKey.ReplacedByID),
+		ExpiresAt:         optionalTimeString(apiKey.ExpiresAt),
 	}, nil
 }
 
+func (h *UserHandler) UpdateAPIKey(ctx context.Context, req *pb.UpdateAPIKeyRequest) (*pb.APIKeyResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+	keyID, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid API key ID: %v", err)
+	}
+
+	update := service.APIKeyUpdate{
+		Name:         req.Name,
+		Description:  req.Description,
+		IsActive:     req.IsActive,
+		UpdateExpiry: req.ExpiresAt != nil,
+	}
+	if req.GetExpiresAt() != "" {
+		expiresAt, err := time.Parse(time.RFC3339, req.GetExpiresAt())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid expiry: %v", err)
+		}
+		update.ExpiresAt = &expiresAt
+	}
+
+	apiKey, err := h.userService.UpdateAPIKey(userID, keyID, update)
+	switch {
+	case errors.Is(err, service.ErrInvalidName), errors.Is(err, service.ErrInvalidExpiry):
+		return nil, status.Errorf(codes.InvalidArgument, "failed to update API key: %v", err)
+	case errors.Is(err, service.ErrAPIKeyNotFound):
+		return nil, status.Errorf(codes.NotFound, "failed to update API key: %v", err)
+	case errors.Is(err, service.ErrAPIKeyRotated):
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to update API key: %v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "failed to update API key: %v", err)
+	}
+
+	return apiKeyToProto(apiKey), nil
+}
+
+func (h *UserHandler) RotateAPIKey(ctx context.Context, req *pb.RotateAPIKeyRequest) (*pb.APIKeyResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+	keyID, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid API key ID: %v", err)
+	}
+
+	apiKey, err := h.userService.RotateAPIKey(userID, keyID, time.Duration(req.GracePeriodSeconds)*time.Second)
+	switch {
+	case errors.Is(err, service.ErrInvalidGracePeriod):
+		return nil, status.Errorf(codes.InvalidArgument, "failed to rotate API key: %v", err)
+	case errors.Is(err, service.ErrAPIKeyNotFound):
+		return nil, status.Errorf(codes.NotFound, "failed to rotate API key: %v", err)
+	case errors.Is(err, service.ErrAPIKeyInactive), errors.Is(err, service.ErrAPIKeyRotated):
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to rotate API key: %v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "failed to rotate API key: %v", err)
+	}
+
+	return apiKeyToProto(apiKey), nil
+}
+
 func (h *UserHandler) DeleteAPIKey(ctx context.Context, req *pb.DeleteAPIKeyRequest) (*pb.DeleteAPIKeyResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
 	keyID, err := uuid.Parse(req.Id)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid API key ID: %v", err)
 	}
 
-	if err := h.userService.DeleteAPIKey(keyID); err != nil {
+	err = h.userService.DeleteAPIKey(userID, keyID)
+	if errors.Is(err, service.ErrAPIKeyNotFound) {
+		return nil, status.Errorf(codes.NotFound, "failed to delete API key: %v", err)
+	}
+	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to delete API key: %v", err)
 	}
 
@@ -97,3 +168,55 @@ func (h *UserHandler) DeleteAPIKey(ctx context.Context, req *pb.DeleteAPIKeyRequ
 		Success: true,
 	}, nil
 }
+
+// apiKeyToProto converts a key; only a key just issued carries the key itself
+func apiKeyToProto(apiKey *models.APIKey) *pb.APIKeyResponse {
+	return &pb.APIKeyResponse{
+		Id:                apiKey.ID.String(),
+		UserId:            apiKey.UserID.String(),
+		Key:               apiKey.Key,
+		KeyPrefix:         apiKey.KeyPrefix,
+		Name:              apiKey.Name,
+		Description:       apiKey.Description,
+		IsActive:          apiKey.IsActive,
+		MonthlyLimit:      apiKey.MonthlyLimit,
+		RequestsPerSecond: apiKey.RequestsPerSecond,
+		Restrictions:      restrictionsToProto(apiKey.Restrictions),
+		ExpiresAt:         optionalTimeString(apiKey.ExpiresAt),
+		RotatedFromId:     optionalIDString(apiKey.RotatedFromID),
+		ReplacedById:      optionalIDString(apiKey.ReplacedByID),
+		CreatedAt:         apiKey.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func optionalIDString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+func optionalTimeString(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func restrictionsFromProto(restrictions *pb.APIKeyRestrictions) models.APIKeyRestrictions {
+	return models.APIKeyRestrictions{
+		AllowedChains:  restrictions.GetAllowedChains(),
+		AllowedMethods: restrictions.GetAllowedMethods(),
+		AllowedIPs:     restrictions.GetAllowedIps(),
+		AllowedOrigins: restrictions.GetAllowedOrigins(),
+	}
+}
+
+func restrictionsToProto(restrictions models.APIKeyRestrictions) *pb.APIKeyRestrictions {
+	return &pb.APIKeyRestrictions{
+		AllowedChains:  restrictions.AllowedChains,
+		AllowedMethods: restrictions.AllowedMethods,
+		AllowedIps:     restrictions.AllowedIPs,
+		AllowedOrigins: restrictions.AllowedOrigins,
+	}
+}
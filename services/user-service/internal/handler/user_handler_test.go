@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ironnode/pkg/models"
+	"ironnode/services/user-service/internal/service"
+	pb "ironnode/services/user-service/proto"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeUserService owns one key and reports every other key as not found
+type fakeUserService struct {
+	service.UserService
+	owner uuid.UUID
+	key   *models.APIKey
+}
+
+func (s *fakeUserService) owned(userID, id uuid.UUID) (*models.APIKey, error) {
+	if userID != s.owner || id != s.key.ID {
+		return nil, service.ErrAPIKeyNotFound
+	}
+	return s.key, nil
+}
+
+func (s *fakeUserService) UpdateAPIKey(userID, id uuid.UUID, update service.APIKeyUpdate) (*models.APIKey, error) {
+	return s.owned(userID, id)
+}
+
+func (s *fakeUserService) RotateAPIKey(userID, id uuid.UUID, gracePeriod time.Duration) (*models.APIKey, error) {
+	return s.owned(userID, id)
+}
+
+func (s *fakeUserService) DeleteAPIKey(userID, id uuid.UUID) error {
+	_, err := s.owned(userID, id)
+	return err
+}
+
+func TestAPIKeyOperationsOnAnotherUsersKeyReturnNotFound(t *testing.T) {
+	owner := uuid.New()
+	key := &models.APIKey{ID: uuid.New(), UserID: owner, Name: "frontend"}
+	h := NewUserHandler(&fakeUserService{owner: owner, key: key})
+	ctx := context.Background()
+	name := "taken over"
+
+	operations := []struct {
+		name string
+		call func(userID, id string) error
+	}{
+		{"update", func(userID, id string) error {
+			_, err := h.UpdateAPIKey(ctx, &pb.UpdateAPIKeyRequest{UserId: userID, Id: id, Name: &name})
+			return err
+		}},
+		{"rotate", func(userID, id string) error {
+			_, err := h.RotateAPIKey(ctx, &pb.RotateAPIKeyRequest{UserId: userID, Id: id})
+			return err
+		}},
+		{"delete", func(userID, id string) error {
+			_, err := h.DeleteAPIKey(ctx, &pb.DeleteAPIKeyRequest{UserId: userID, Id: id})
+			return err
+		}},
+	}
+
+	for _, op := range operations {
+		t.Run(op.name, func(t *testing.T) {
+			if err := op.call(uuid.NewString(), key.ID.String()); status.Code(err) != codes.NotFound {
+				t.Errorf("on another user's key: code = %v, want %v", status.Code(err), codes.NotFound)
+			}
+			if err := op.call(owner.String(), key.ID.String()); err != nil {
+				t.Errorf("on the owner's key: %v", err)
+			}
+			if err := op.call(owner.String(), "not-a-uuid"); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("with a malformed ID: code = %v, want %v", status.Code(err), codes.InvalidArgument)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"ironnode/pkg/models"
 
 	"github.com/google/uuid"
@@ -9,9 +11,17 @@ import (
 
 type UserRepository interface {
 	CreateAPIKey(apiKey *models.APIKey) error
+	// GetAPIKeysByUser returns the user's keys, inactive ones included, oldest first
 	GetAPIKeysByUser(userID uuid.UUID) ([]*models.APIKey, error)
-	GetAPIKeyByKey(key string) (*models.APIKey, error)
-	DeleteAPIKey(id uuid.UUID) error
+	GetAPIKey(id uuid.UUID) (*models.APIKey, error)
+	GetAPIKeyByHash(keyHash string) (*models.APIKey, error)
+	// UpdateAPIKey saves the given columns of apiKey
+	UpdateAPIKey(apiKey *models.APIKey, columns []string) error
+	// RotateAPIKey creates replacement for the active key it was rotated from, which
+	// then only works until graceUntil; false if that key was rotated meanwhile
+	RotateAPIKey(replacement *models.APIKey, graceUntil time.Time) (bool, error)
+	// DeleteAPIKey deletes the user's key id; false if the user has no such key
+	DeleteAPIKey(userID, id uuid.UUID) (bool, error)
 }
 
 type userRepository struct {
@@ -28,16 +38,48 @@ func (r *userRepository) CreateAPIKey(apiKey *models.APIKey) error {
 
 func (r *userRepository) GetAPIKeysByUser(userID uuid.UUID) ([]*models.APIKey, error) {
 	var keys []*models.APIKey
-	err := r.db.Where("user_id = ? AND is_active = ?", userID, true).Find(&keys).Error
+	err := r.db.Where("user_id = ?", userID).Order("created_at").Find(&keys).Error
 	return keys, err
 }
 
-func (r *userRepository) GetAPIKeyByKey(key string) (*models.APIKey, error) {
+func (r *userRepository) GetAPIKey(id uuid.UUID) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	err := r.db.Where("id = ?", id).First(&apiKey).Error
+	return &apiKey, err
+}
+
+func (r *userRepository) GetAPIKeyByHash(keyHash string) (*models.APIKey, error) {
 	var apiKey models.APIKey
-	err := r.db.Where("key = ? AND is_active = ?", key, true).First(&apiKey).Error
+	err := r.db.Where("key_hash = ? AND is_active = ?", keyHash, true).First(&apiKey).Error
 	return &apiKey, err
 }
 
-func (r *userRepository) DeleteAPIKey(id uuid.UUID) error {
-	return r.db.Delete(&models.APIKey{}, id).Error
+func (r *userRepository) UpdateAPIKey(apiKey *models.APIKey, columns []string) error {
+	return r.db.Model(apiKey).Select(columns).Updates(apiKey).Error
+}
+
+func (r *userRepository) RotateAPIKey(replacement *models.APIKey, graceUntil time.Time) (bool, error) {
+	rotated := false
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.APIKey{}).
+			Where("id = ? AND is_active = ? AND replaced_by_id IS NULL", replacement.RotatedFromID, true).
+			Updates(map[string]interface{}{
+				"replaced_by_id": replacement.ID,
+				"expires_at":     graceUntil,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		rotated = true
+		return tx.Create(replacement).Error
+	})
+
+	return rotated, err
+}
+
+func (r *userRepository) DeleteAPIKey(userID, id uuid.UUID) (bool, error) {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.APIKey{})
+	return result.RowsAffected > 0, result.Error
 }
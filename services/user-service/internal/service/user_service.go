@@ -3,39 +3,91 @@ package service
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"time"
 
 	"ironnode/pkg/models"
 	"ironnode/services/user-service/internal/repository"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidLimit        = errors.New("API key limits must not be negative")
+	ErrInvalidRestrictions = errors.New("invalid API key restrictions")
+	ErrInvalidGracePeriod  = errors.New("invalid grace period")
+	ErrInvalidName         = errors.New("API key name must not be empty")
+	ErrInvalidExpiry       = errors.New("API key expiry must be in the future")
+	ErrAPIKeyNotFound      = errors.New("API key not found")
+	ErrAPIKeyInactive      = errors.New("API key is inactive or expired")
+	ErrAPIKeyRotated       = errors.New("API key was already rotated")
 )
 
 type UserService interface {
-	CreateAPIKey(userID uuid.UUID, name, description string) (*models.APIKey, error)
+	// CreateAPIKey issues a key; limits of 0 and empty restrictions mean no limit beyond the user's plan.
+	// Only the returned APIKey carries the key itself; just its hash is stored.
+	CreateAPIKey(userID uuid.UUID, name, description string, monthlyLimit, requestsPerSecond int64, restrictions models.APIKeyRestrictions) (*models.APIKey, error)
 	GetAPIKeys(userID uuid.UUID) ([]*models.APIKey, error)
+	// ValidateAPIKey returns the key's settings; a rotated key is valid, but deprecated, until its grace period ends
 	ValidateAPIKey(key string) (*models.APIKey, error)
-	DeleteAPIKey(id uuid.UUID) error
+	// UpdateAPIKey applies update to the user's key id
+	UpdateAPIKey(userID, id uuid.UUID, update APIKeyUpdate) (*models.APIKey, error)
+	// RotateAPIKey issues a key with the settings of the user's key id, which keeps working
+	// for gracePeriod (0 for the default) alongside the returned one
+	RotateAPIKey(userID, id uuid.UUID, gracePeriod time.Duration) (*models.APIKey, error)
+	// DeleteAPIKey deletes the user's key id; other users' keys are not found
+	DeleteAPIKey(userID, id uuid.UUID) error
+}
+
+// APIKeyUpdate holds the changes UpdateAPIKey makes; nil fields are left unchanged
+type APIKeyUpdate struct {
+	Name         *string
+	Description  *string
+	IsActive     *bool
+	UpdateExpiry bool       // set ExpiresAt
+	ExpiresAt    *time.Time // nil for a key that never expires
 }
 
 type userService struct {
-	repo repository.UserRepository
+	repo             repository.UserRepository
+	rotationGrace    time.Duration
+	maxRotationGrace time.Duration
 }
 
-func NewUserService(repo repository.UserRepository) UserService {
-	return &userService{repo: repo}
+// NewUserService creates the service; rotated keys keep working for rotationGrace
+// unless a rotation asks for another grace period, of at most maxRotationGrace
+func NewUserService(repo repository.UserRepository, rotationGrace, maxRotationGrace time.Duration) UserService {
+	return &userService{
+		repo:             repo,
+		rotationGrace:    rotationGrace,
+		maxRotationGrace: maxRotationGrace,
+	}
 }
 
-func (s *userService) CreateAPIKey(userID uuid.UUID, name, description string) (*models.APIKey, error) {
+func (s *userService) CreateAPIKey(userID uuid.UUID, name, description string, monthlyLimit, requestsPerSecond int64, restrictions models.APIKeyRestrictions) (*models.APIKey, error) {
+	if monthlyLimit < 0 || requestsPerSecond < 0 {
+		return nil, ErrInvalidLimit
+	}
+	if err := restrictions.Normalize(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRestrictions, err)
+	}
+
 	// Generate random API key
 	key := generateAPIKey()
 
 	apiKey := &models.APIKey{
-		UserID:      userID,
-		Key:         key,
-		Name:        name,
-		Description: description,
-		IsActive:    true,
+		UserID:            userID,
+		Key:               key,
+		KeyHash:           models.HashAPIKey(key),
+		KeyPrefix:         models.APIKeyPrefix(key),
+		Name:              name,
+		Description:       description,
+		IsActive:          true,
+		MonthlyLimit:      monthlyLimit,
+		RequestsPerSecond: requestsPerSecond,
+		Restrictions:      restrictions,
 	}
 
 	if err := s.repo.CreateAPIKey(apiKey); err != nil {
@@ -50,11 +102,15 @@ func (s *userService) GetAPIKeys(userID uuid.UUID) ([]*models.APIKey, error) {
 }
 
 func (s *userService) ValidateAPIKey(key string) (*models.APIKey, error) {
-	apiKey, err := s.repo.GetAPIKeyByKey(key)
+	apiKey, err := s.repo.GetAPIKeyByHash(models.HashAPIKey(key))
 	if err != nil {
 		return nil, err
 	}
 
+	if !apiKey.IsActive {
+		return nil, fmt.Errorf("API key is inactive")
+	}
+
 	if apiKey.IsExpired() {
 		return nil, fmt.Errorf("API key has expired")
 	}
@@ -62,8 +118,125 @@ func (s *userService) ValidateAPIKey(key string) (*models.APIKey, error) {
 	return apiKey, nil
 }
 
-func (s *userService) DeleteAPIKey(id uuid.UUID) error {
-	return s.repo.DeleteAPIKey(id)
+func (s *userService) UpdateAPIKey(userID, id uuid.UUID, update APIKeyUpdate) (*models.APIKey, error) {
+	apiKey, err := s.getOwnedAPIKey(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []string
+	if update.Name != nil {
+		if *update.Name == "" {
+			return nil, ErrInvalidName
+		}
+		apiKey.Name = *update.Name
+		columns = append(columns, "name")
+	}
+	if update.Description != nil {
+		apiKey.Description = *update.Description
+		columns = append(columns, "description")
+	}
+	if update.IsActive != nil {
+		apiKey.IsActive = *update.IsActive
+		columns = append(columns, "is_active")
+	}
+	if update.UpdateExpiry {
+		// A rotated key expires when its grace period ends
+		if apiKey.IsDeprecated() {
+			return nil, ErrAPIKeyRotated
+		}
+		if update.ExpiresAt != nil && !update.ExpiresAt.After(time.Now()) {
+			return nil, ErrInvalidExpiry
+		}
+		apiKey.ExpiresAt = update.ExpiresAt
+		columns = append(columns, "expires_at")
+	}
+
+	if len(columns) == 0 {
+		return apiKey, nil
+	}
+	if err := s.repo.UpdateAPIKey(apiKey, columns); err != nil {
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+func (s *userService) RotateAPIKey(userID, id uuid.UUID, gracePeriod time.Duration) (*models.APIKey, error) {
+	if gracePeriod == 0 {
+		gracePeriod = s.rotationGrace
+	}
+	if gracePeriod < 0 || gracePeriod > s.maxRotationGrace {
+		return nil, fmt.Errorf("%w: must be between 0 and %v", ErrInvalidGracePeriod, s.maxRotationGrace)
+	}
+
+	old, err := s.getOwnedAPIKey(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if old.IsDeprecated() {
+		return nil, ErrAPIKeyRotated
+	}
+	if !old.IsActive || old.IsExpired() {
+		return nil, ErrAPIKeyInactive
+	}
+
+	// The old key never outlives its own expiry
+	graceUntil := time.Now().Add(gracePeriod)
+	if old.ExpiresAt != nil && old.ExpiresAt.Before(graceUntil) {
+		graceUntil = *old.ExpiresAt
+	}
+
+	key := generateAPIKey()
+
+	replacement := &models.APIKey{
+		ID:                uuid.New(),
+		UserID:            old.UserID,
+		Key:               key,
+		KeyHash:           models.HashAPIKey(key),
+		KeyPrefix:         models.APIKeyPrefix(key),
+		Name:              old.Name,
+		Description:       old.Description,
+		IsActive:          true,
+		ExpiresAt:         old.ExpiresAt,
+		MonthlyLimit:      old.MonthlyLimit,
+		RequestsPerSecond: old.RequestsPerSecond,
+		Restrictions:      old.Restrictions,
+		RotatedFromID:     &old.ID,
+	}
+
+	rotated, err := s.repo.RotateAPIKey(replacement, graceUntil)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		return nil, ErrAPIKeyRotated
+	}
+
+	return replacement, nil
+}
+
+func (s *userService) DeleteAPIKey(userID, id uuid.UUID) error {
+	deleted, err := s.repo.DeleteAPIKey(userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// getOwnedAPIKey returns the key id if it belongs to the user
+func (s *userService) getOwnedAPIKey(userID, id uuid.UUID) (*models.APIKey, error) {
+	apiKey, err := s.repo.GetAPIKey(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && apiKey.UserID != userID) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return apiKey, nil
 }
 
 func generateAPIKey() string {
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"ironnode/pkg/models"
+	"ironnode/services/user-service/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	testRotationGrace    = 24 * time.Hour
+	testMaxRotationGrace = 7 * 24 * time.Hour
+)
+
+// fakeUserRepo keeps API keys in memory as the database would store them
+type fakeUserRepo struct {
+	repository.UserRepository
+	keys map[uuid.UUID]*models.APIKey
+}
+
+func newFakeUserRepo() *fakeUserRepo {
+	return &fakeUserRepo{keys: make(map[uuid.UUID]*models.APIKey)}
+}
+
+// store saves a copy of apiKey; the key itself is not a column
+func (r *fakeUserRepo) store(apiKey *models.APIKey) {
+	copied := *apiKey
+	copied.Key = ""
+	r.keys[copied.ID] = &copied
+}
+
+func (r *fakeUserRepo) CreateAPIKey(apiKey *models.APIKey) error {
+	if apiKey.ID == uuid.Nil {
+		apiKey.ID = uuid.New()
+	}
+	r.store(apiKey)
+	return nil
+}
+
+func (r *fakeUserRepo) GetAPIKey(id uuid.UUID) (*models.APIKey, error) {
+	apiKey, ok := r.keys[id]
+	if !ok {
+		return &models.APIKey{}, gorm.ErrRecordNotFound
+	}
+	copied := *apiKey
+	return &copied, nil
+}
+
+func (r *fakeUserRepo) GetAPIKeyByHash(keyHash string) (*models.APIKey, error) {
+	for _, apiKey := range r.keys {
+		if apiKey.KeyHash == keyHash && apiKey.IsActive {
+			copied := *apiKey
+			return &copied, nil
+		}
+	}
+	return &models.APIKey{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) GetAPIKeysByUser(userID uuid.UUID) ([]*models.APIKey, error) {
+	var keys []*models.APIKey
+	for _, apiKey := range r.keys {
+		if apiKey.UserID == userID {
+			copied := *apiKey
+			keys = append(keys, &copied)
+		}
+	}
+	return keys, nil
+}
+
+func (r *fakeUserRepo) UpdateAPIKey(apiKey *models.APIKey, columns []string) error {
+	r.store(apiKey)
+	return nil
+}
+
+func (r *fakeUserRepo) RotateAPIKey(replacement *models.APIKey, graceUntil time.Time) (bool, error) {
+	old, ok := r.keys[*replacement.RotatedFromID]
+	if !ok || !old.IsActive || old.ReplacedByID != nil {
+		return false, nil
+	}
+
+	old.ReplacedByID = &replacement.ID
+	old.ExpiresAt = &graceUntil
+	r.store(replacement)
+	return true, nil
+}
+
+func (r *fakeUserRepo) DeleteAPIKey(userID, id uuid.UUID) (bool, error) {
+	apiKey, ok := r.keys[id]
+	if !ok || apiKey.UserID != userID {
+		return false, nil
+	}
+	delete(r.keys, id)
+	return true, nil
+}
+
+func newTestUserService() (*fakeUserRepo, UserService) {
+	repo := newFakeUserRepo()
+	return repo, NewUserService(repo, testRotationGrace, testMaxRotationGrace)
+}
+
+func createTestKey(t *testing.T, users UserService, userID uuid.UUID) *models.APIKey {
+	t.Helper()
+	apiKey, err := users.CreateAPIKey(userID, "frontend", "dapp key", 5000, 10, models.APIKeyRestrictions{
+		AllowedChains:  []string{"ethereum"},
+		AllowedOrigins: []string{"app.example.com"},
+	})
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	return apiKey
+}
+
+func TestRotateAPIKeyKeepsOldKeyForGracePeriod(t *testing.T) {
+	repo, users := newTestUserService()
+	userID := uuid.New()
+	old := createTestKey(t, users, userID)
+
+	before := time.Now()
+	replacement, err := users.RotateAPIKey(userID, old.ID, 0)
+	if err != nil {
+		t.Fatalf("RotateAPIKey: %v", err)
+	}
+
+	if replacement.Key == "" || replacement.Key == old.Key || replacement.ID == old.ID {
+		t.Fatalf("replacement key %q (%s) is not a new key", replacement.Key, replacement.ID)
+	}
+	if replacement.RotatedFromID == nil || *replacement.RotatedFromID != old.ID ||
+		replacement.MonthlyLimit != 5000 || replacement.RequestsPerSecond != 10 ||
+		len(replacement.Restrictions.AllowedOrigins) != 1 || replacement.ExpiresAt != nil {
+		t.Errorf("replacement does not carry the old key's settings: %+v", replacement)
+	}
+
+	// Both keys work during the grace period; the old one is flagged and expires with it
+	deprecated, err := users.ValidateAPIKey(old.Key)
+	if err != nil {
+		t.Fatalf("old key rejected during its grace period: %v", err)
+	}
+	if !deprecated.IsDeprecated() || *deprecated.ReplacedByID != replacement.ID {
+		t.Errorf("old key not marked as replaced by %s", replacement.ID)
+	}
+	if deprecated.ExpiresAt == nil || deprecated.ExpiresAt.Before(before.Add(testRotationGrace)) ||
+		deprecated.ExpiresAt.After(time.Now().Add(testRotationGrace)) {
+		t.Errorf("old key expires at %v, want %v after rotation", deprecated.ExpiresAt, testRotationGrace)
+	}
+	if current, err := users.ValidateAPIKey(replacement.Key); err != nil || current.IsDeprecated() {
+		t.Errorf("replacement key: deprecated = %v, err = %v", current != nil && current.IsDeprecated(), err)
+	}
+
+	// After the grace period only the replacement works
+	expired := time.Now().Add(-time.Second)
+	repo.keys[old.ID].ExpiresAt = &expired
+	if _, err := users.ValidateAPIKey(old.Key); err == nil {
+		t.Error("old key still valid after its grace period")
+	}
+	if _, err := users.ValidateAPIKey(replacement.Key); err != nil {
+		t.Errorf("replacement key rejected: %v", err)
+	}
+}
+
+func TestRotateAPIKeyGracePeriod(t *testing.T) {
+	soon := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		grace     time.Duration
+		expiresAt *time.Time
+		want      time.Duration // from now, if no error
+		wantErr   error
+	}{
+		{name: "default", grace: 0, want: testRotationGrace},
+		{name: "requested", grace: 2 * time.Hour, want: 2 * time.Hour},
+		{name: "longest allowed", grace: testMaxRotationGrace, want: testMaxRotationGrace},
+		{name: "beyond the maximum", grace: testMaxRotationGrace + time.Second, wantErr: ErrInvalidGracePeriod},
+		{name: "negative", grace: -time.Hour, wantErr: ErrInvalidGracePeriod},
+		{name: "capped at the key's own expiry", grace: 0, expiresAt: &soon, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, users := newTestUserService()
+			userID := uuid.New()
+			old := createTestKey(t, users, userID)
+			repo.keys[old.ID].ExpiresAt = tt.expiresAt
+
+			_, err := users.RotateAPIKey(userID, old.ID, tt.grace)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("RotateAPIKey error = %v, want %v", err, tt.wantErr)
+				}
+				if repo.keys[old.ID].IsDeprecated() {
+					t.Error("refused rotation still deprecated the key")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RotateAPIKey: %v", err)
+			}
+
+			graceUntil := repo.keys[old.ID].ExpiresAt
+			if graceUntil == nil || time.Until(*graceUntil) > tt.want || time.Until(*graceUntil) < tt.want-time.Minute {
+				t.Errorf("old key works until %v, want about %v from now", graceUntil, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotateAPIKeyRefusals(t *testing.T) {
+	repo, users := newTestUserService()
+	userID := uuid.New()
+
+	rotated := createTestKey(t, users, userID)
+	if _, err := users.RotateAPIKey(userID, rotated.ID, 0); err != nil {
+		t.Fatalf("RotateAPIKey: %v", err)
+	}
+	if _, err := users.RotateAPIKey(userID, rotated.ID, 0); !errors.Is(err, ErrAPIKeyRotated) {
+		t.Errorf("rotating a rotated key: error = %v, want %v", err, ErrAPIKeyRotated)
+	}
+
+	// A rotated key's expiry is its grace period and cannot be moved
+	later := time.Now().Add(30 * 24 * time.Hour)
+	if _, err := users.UpdateAPIKey(userID, rotated.ID, APIKeyUpdate{UpdateExpiry: true, ExpiresAt: &later}); !errors.Is(err, ErrAPIKeyRotated) {
+		t.Errorf("extending a rotated key: error = %v, want %v", err, ErrAPIKeyRotated)
+	}
+
+	inactive := createTestKey(t, users, userID)
+	repo.keys[inactive.ID].IsActive = false
+	if _, err := users.RotateAPIKey(userID, inactive.ID, 0); !errors.Is(err, ErrAPIKeyInactive) {
+		t.Errorf("rotating an inactive key: error = %v, want %v", err, ErrAPIKeyInactive)
+	}
+}
+
+func TestAPIKeyOperationsOnAnotherUsersKey(t *testing.T) {
+	repo, users := newTestUserService()
+	owner, other := uuid.New(), uuid.New()
+	apiKey := createTestKey(t, users, owner)
+	name := "taken over"
+
+	operations := []struct {
+		name string
+		call func(userID, id uuid.UUID) error
+	}{
+		{"update", func(userID, id uuid.UUID) error {
+			_, err := users.UpdateAPIKey(userID, id, APIKeyUpdate{Name: &name})
+			return err
+		}},
+		{"rotate", func(userID, id uuid.UUID) error {
+			_, err := users.RotateAPIKey(userID, id, 0)
+			return err
+		}},
+		{"delete", func(userID, id uuid.UUID) error {
+			return users.DeleteAPIKey(userID, id)
+		}},
+	}
+
+	for _, op := range operations {
+		t.Run(op.name, func(t *testing.T) {
+			if err := op.call(other, apiKey.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+				t.Errorf("on another user's key: error = %v, want %v", err, ErrAPIKeyNotFound)
+			}
+			if err := op.call(owner, uuid.New()); !errors.Is(err, ErrAPIKeyNotFound) {
+				t.Errorf("on a missing key: error = %v, want %v", err, ErrAPIKeyNotFound)
+			}
+
+			stored, ok := repo.keys[apiKey.ID]
+			if !ok || stored.Name != "frontend" || stored.IsDeprecated() {
+				t.Errorf("another user's call changed the key: %+v", stored)
+			}
+		})
+	}
+
+	if keys, _ := users.GetAPIKeys(other); len(keys) != 0 {
+		t.Errorf("another user lists %d keys, want none", len(keys))
+	}
+}
+
+func TestCreateAPIKeyStoresOnlyTheHash(t *testing.T) {
+	repo, users := newTestUserService()
+	userID := uuid.New()
+
+	created := createTestKey(t, users, userID)
+	if !strings.HasPrefix(created.Key, "qn_") || len(created.Key) != 67 {
+		t.Fatalf("created key %q is not a full qn_ key", created.Key)
+	}
+
+	stored := repo.keys[created.ID]
+	if stored.KeyHash != models.HashAPIKey(created.Key) || stored.KeyPrefix != created.Key[:models.APIKeyPrefixLength] {
+		t.Errorf("stored hash %q, prefix %q do not match the created key", stored.KeyHash, stored.KeyPrefix)
+	}
+
+	// Only the response to the creation carries the key
+	keys, err := users.GetAPIKeys(userID)
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("GetAPIKeys = %d keys, %v", len(keys), err)
+	}
+	if keys[0].Key != "" {
+		t.Errorf("listed key carries the plaintext key")
+	}
+	validated, err := users.ValidateAPIKey(created.Key)
+	if err != nil {
+		t.Fatalf("ValidateAPIKey: %v", err)
+	}
+	if validated.Key != "" {
+		t.Errorf("validated key carries the plaintext key")
+	}
+}
+
+func TestValidateAPIKeyMigratedByHash(t *testing.T) {
+	repo, users := newTestUserService()
+
+	// A key issued before hashing, as the migration leaves it: hash and prefix only
+	plaintext := "qn_" + strings.Repeat("ab", 32)
+	migrated := &models.APIKey{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		KeyHash:   models.HashAPIKey(plaintext),
+		KeyPrefix: models.APIKeyPrefix(plaintext),
+		IsActive:  true,
+	}
+	repo.store(migrated)
+
+	apiKey, err := users.ValidateAPIKey(plaintext)
+	if err != nil {
+		t.Fatalf("migrated key rejected: %v", err)
+	}
+	if apiKey.ID != migrated.ID {
+		t.Errorf("validated key %s, want %s", apiKey.ID, migrated.ID)
+	}
+
+	for _, wrong := range []string{plaintext[:len(plaintext)-1] + "c", models.HashAPIKey(plaintext), migrated.KeyPrefix} {
+		if _, err := users.ValidateAPIKey(wrong); err == nil {
+			t.Errorf("ValidateAPIKey(%q) accepted", wrong)
+		}
+	}
+}
@@ -21,13 +21,17 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Limits of 0 and empty restrictions mean no limit
 type CreateAPIKeyRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	UserId            string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Name              string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description       string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	MonthlyLimit      int64                  `protobuf:"varint,4,opt,name=monthly_limit,json=monthlyLimit,proto3" json:"monthly_limit,omitempty"`
+	RequestsPerSecond int64                  `protobuf:"varint,5,opt,name=requests_per_second,json=requestsPerSecond,proto3" json:"requests_per_second,omitempty"`
+	Restrictions      *APIKeyRestrictions    `protobuf:"bytes,6,opt,name=restrictions,proto3" json:"restrictions,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *CreateAPIKeyRequest) Reset() {
@@ -81,6 +85,96 @@ func (x *CreateAPIKeyRequest) GetDescription() string {
 	return ""
 }
 
+func (x *CreateAPIKeyRequest) GetMonthlyLimit() int64 {
+	if x != nil {
+		return x.MonthlyLimit
+	}
+	return 0
+}
+
+func (x *CreateAPIKeyRequest) GetRequestsPerSecond() int64 {
+	if x != nil {
+		return x.RequestsPerSecond
+	}
+	return 0
+}
+
+func (x *CreateAPIKeyRequest) GetRestrictions() *APIKeyRestrictions {
+	if x != nil {
+		return x.Restrictions
+	}
+	return nil
+}
+
+// Where and how a key may be used; empty lists allow everything
+type APIKeyRestrictions struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AllowedChains  []string               `protobuf:"bytes,1,rep,name=allowed_chains,json=allowedChains,proto3" json:"allowed_chains,omitempty"`    // "ethereum" or "ethereum/mainnet"
+	AllowedMethods []string               `protobuf:"bytes,2,rep,name=allowed_methods,json=allowedMethods,proto3" json:"allowed_methods,omitempty"` // "eth_*" or a method name
+	AllowedIps     []string               `protobuf:"bytes,3,rep,name=allowed_ips,json=allowedIps,proto3" json:"allowed_ips,omitempty"`             // CIDRs or single IPs
+	AllowedOrigins []string               `protobuf:"bytes,4,rep,name=allowed_origins,json=allowedOrigins,proto3" json:"allowed_origins,omitempty"` // "app.example.com" or "*.example.com"
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *APIKeyRestrictions) Reset() {
+	*x = APIKeyRestrictions{}
+	mi := &file_services_user_service_proto_user_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *APIKeyRestrictions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*APIKeyRestrictions) ProtoMessage() {}
+
+func (x *APIKeyRestrictions) ProtoReflect() protoreflect.Message {
+	mi := &file_services_user_service_proto_user_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use APIKeyRestrictions.ProtoReflect.Descriptor instead.
+func (*APIKeyRestrictions) Descriptor() ([]byte, []int) {
+	return file_services_user_service_proto_user_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *APIKeyRestrictions) GetAllowedChains() []string {
+	if x != nil {
+		return x.AllowedChains
+	}
+	return nil
+}
+
+func (x *APIKeyRestrictions) GetAllowedMethods() []string {
+	if x != nil {
+		return x.AllowedMethods
+	}
+	return nil
+}
+
+func (x *APIKeyRestrictions) GetAllowedIps() []string {
+	if x != nil {
+		return x.AllowedIps
+	}
+	return nil
+}
+
+func (x *APIKeyRestrictions) GetAllowedOrigins() []string {
+	if x != nil {
+		return x.AllowedOrigins
+	}
+	return nil
+}
+
 type GetAPIKeysRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
@@ -90,7 +184,7 @@ type GetAPIKeysRequest struct {
 
 func (x *GetAPIKeysRequest) Reset() {
 	*x = GetAPIKeysRequest{}
-	mi := &file_services_user_service_proto_user_proto_msgTypes[1]
+	mi := &file_services_user_service_proto_user_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -102,7 +196,7 @@ func (x *GetAPIKeysRequest) String() string {
 func (*GetAPIKeysRequest) ProtoMessage() {}
 
 func (x *GetAPIKeysRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_services_user_service_proto_user_proto_msgTypes[1]
+	mi := &file_services_user_service_proto_user_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -115,7 +209,7 @@ func (x *GetAPIKeysRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetAPIKeysRequest.ProtoReflect.Descriptor instead.
 func (*GetAPIKeysRequest) Descriptor() ([]byte, []int) {
-	return file_services_user_service_proto_user_proto_rawDescGZIP(), []int{1}
+	return file_services_user_service_proto_user_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *GetAPIKeysRequest) GetUserId() string {
@@ -126,20 +220,28 @@ func (x *GetAPIKeysRequest) GetUserId() string {
 }
 
 type APIKeyResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Key           string                 `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
-	Name          string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
-	Description   string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
-	IsActive      bool                   `protobuf:"varint,6,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId            string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Key               string                 `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"` // only in the CreateAPIKey response; keys are stored hashed
+	Name              string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Description       string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	IsActive          bool                   `protobuf:"varint,6,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	MonthlyLimit      int64                  `protobuf:"varint,7,opt,name=monthly_limit,json=monthlyLimit,proto3" json:"monthly_limit,omitempty"`
+	RequestsPerSecond int64                  `protobuf:"varint,8,opt,name=requests_per_second,json=requestsPerSecond,proto3" json:"requests_per_second,omitempty"`
+	Restrictions      *APIKeyRestrictions    `protobuf:"bytes,9,opt,name=restrictions,proto3" json:"restrictions,omitempty"`
+	KeyPrefix         string                 `protobuf:"bytes,10,opt,name=key_prefix,json=keyPrefix,proto3" json:"key_prefix,omitempty"`
+	ExpiresAt         string                 `protobuf:"bytes,11,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`               // RFC3339; empty if the key never expires
+	RotatedFromId     string                 `protobuf:"bytes,12,opt,name=rotated_from_id,json=rotatedFromId,proto3" json:"rotated_from_id,omitempty"` // key this one replaced
+	ReplacedById      string                 `protobuf:"bytes,13,opt,name=replaced_by_id,json=replacedById,proto3" json:"replaced_by_id,omitempty"`    // set once rotated; the key works until expires_at
+	CreatedAt         string                 `protobuf:"bytes,14,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`               // RFC3339
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *APIKeyResponse) Reset() {
 	*x = APIKeyResponse{}
-	mi := &file_services_user_service_proto_user_proto_msgTypes[2]
+	mi := &file_services_user_service_proto_user_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -151,7 +253,7 @@ func (x *APIKeyResponse) String() string {
 func (*APIKeyResponse) ProtoMessage() {}
 
 func (x *APIKeyResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_services_user_service_proto_user_proto_msgTypes[2]
+	mi := &file_services_user_service_proto_user_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -164,7 +266,7 @@ func (x *APIKeyResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use APIKeyResponse.ProtoReflect.Descriptor instead.
 func (*APIKeyResponse) Descriptor() ([]byte, []int) {
-	return file_services_user_service_proto_user_proto_rawDescGZIP(), []int{2}
+	return file_services_user_service_proto_user_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *APIKeyResponse) GetId() string {
@@ -209,6 +311,62 @@ func (x *APIKeyResponse) GetIsActive() bool {
 	return false
 }
 
+func (x *APIKeyResponse) GetMonthlyLimit() int64 {
+	if x != nil {
+		return x.MonthlyLimit
+	}
+	return 0
+}
+
+func (x *APIKeyResponse) GetRequestsPerSecond() int64 {
+	if x != nil {
+		return x.RequestsPerSecond
+	}
+	return 0
+}
+
+func (x *APIKeyResponse) GetRestrictions() *APIKeyRestrictions {
+	if x != nil {
+		return x.Restrictions
+	}
+	return nil
+}
+
+func (x *APIKeyResponse) GetKeyPrefix() string {
+	if x != nil {
+		return x.KeyPrefix
+	}
+	return ""
+}
+
+func (x *APIKeyResponse) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+func (x *APIKeyResponse) GetRotatedFromId() string {
+	if x != nil {
+		return x.RotatedFromId
+	}
+	return ""
+}
+
+func (x *APIKeyResponse) GetReplacedById() string {
+	if x != nil {
+		return x.ReplacedById
+	}
+	return ""
+}
+
+func (x *APIKeyResponse) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
 type GetAPIKeysResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ApiKeys       []*APIKeyResponse      `protobuf:"bytes,1,rep,name=api_keys,json=apiKeys,proto3" json:"api_keys,omitempty"`
@@ -218,7 +376,7 @@ type GetAPIKeysResponse struct {
 
 func (x *GetAPIKeysResponse) Reset() {
 	*x = GetAPIKeysResponse{}
-	mi := &file_services_user_service_proto_user_proto_msgTypes[3]
+	mi := &file_services_user_service_proto_user_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -230,7 +388,7 @@ func (x *GetAPIKeysResponse) String() string {
 func (*GetAPIKeysResponse) ProtoMessage() {}
 
 func (x *GetAPIKeysResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_services_user_service_proto_user_proto_msgTypes[3]
+	mi := &file_services_user_service_proto_user_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -243,7 +401,7 @@ func (x *GetAPIKeysResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetAPIKeysResponse.ProtoReflect.Descriptor instead.
 func (*GetAPIKeysResponse) Descriptor() ([]byte, []int) {
-	return file_services_user_service_proto_user_proto_rawDescGZIP(), []int{3}
+	return file_services_user_service_proto_user_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *GetAPIKeysResponse) GetApiKeys() []*APIKeyResponse {
@@ -262,7 +420,7 @@ type ValidateAPIKeyRequest struct {
 
 func (x *ValidateAPIKeyRequest) Reset() {
 	*x = ValidateAPIKeyRequest{}
-	mi := &file_services_user_service_proto_user_proto_msgTypes[4]
+	mi := &file_services_user_service_proto_user_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -274,7 +432,7 @@ func (x *ValidateAPIKeyRequest) String() string {
 func (*ValidateAPIKeyRequest) ProtoMessage() {}
 
 func (x *ValidateAPIKeyRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_services_user_service_proto_user_proto_msgTypes[4]
+	mi := &file_services_user_service_proto_user_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -287,7 +445,7 @@ func (x *ValidateAPIKeyRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ValidateAPIKeyRequest.ProtoReflect.Descriptor instead.
 func (*ValidateAPIKeyRequest) Descriptor() ([]byte, []int) {
-	return file_services_user_service_proto_user_proto_rawDescGZIP(), []int{4}
+	return file_services_user_service_proto_user_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *ValidateAPIKeyRequest) GetKey() string {
@@ -298,16 +456,23 @@ func (x *ValidateAPIKeyRequest) GetKey() string {
 }
 
 type ValidateAPIKeyResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Valid         bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
-	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Valid             bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	UserId            string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ApiKeyId          string                 `protobuf:"bytes,3,opt,name=api_key_id,json=apiKeyId,proto3" json:"api_key_id,omitempty"`
+	MonthlyLimit      int64                  `protobuf:"varint,4,opt,name=monthly_limit,json=monthlyLimit,proto3" json:"monthly_limit,omitempty"`
+	RequestsPerSecond int64                  `protobuf:"varint,5,opt,name=requests_per_second,json=requestsPerSecond,proto3" json:"requests_per_second,omitempty"`
+	Restrictions      *APIKeyRestrictions    `protobuf:"bytes,6,opt,name=restrictions,proto3" json:"restrictions,omitempty"`
+	Deprecated        bool                   `protobuf:"varint,7,opt,name=deprecated,proto3" json:"deprecated,omitempty"` // rotated; works until expires_at
+	ReplacedById      string                 `protobuf:"bytes,8,opt,name=replaced_by_id,json=replacedById,proto3" json:"replaced_by_id,omitempty"`
+	ExpiresAt         string                 `protobuf:"bytes,9,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // RFC3339; empty if the key never expires
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *ValidateAPIKeyResponse) Reset() {
 	*x = ValidateAPIKeyResponse{}
-	mi := &file_services_user_service_proto_user_proto_msgTypes[5]
+	mi := &file_services_user_service_proto_user_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -319,7 +484,7 @@ func (x *ValidateAPIKeyResponse) String() string {
 func (*ValidateAPIKeyResponse) ProtoMessage() {}
 
 func (x *ValidateAPIKeyResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_services_user_service_proto_user_proto_msgTypes[5]
+	mi := &file_services_user_service_proto_user_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -332,7 +497,7 @@ func (x *ValidateAPIKeyResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ValidateAPIKeyResponse.ProtoReflect.Descriptor instead.
 func (*ValidateAPIKeyResponse) Descriptor() ([]byte, []int) {
-	return file_services_user_service_proto_user_proto_rawDescGZIP(), []int{5}
+	return file_services_user_service_proto_user_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *ValidateAPIKeyResponse) GetValid() bool {
@@ -349,16 +514,212 @@ func (x *ValidateAPIKeyResponse) GetUserId() string {
 	return ""
 }
 
+func (x *ValidateAPIKeyResponse) GetApiKeyId() string {
+	if x != nil {
+		return x.ApiKeyId
+	}
+	return ""
+}
+
+func (x *ValidateAPIKeyResponse) GetMonthlyLimit() int64 {
+	if x != nil {
+		return x.MonthlyLimit
+	}
+	return 0
+}
+
+func (x *ValidateAPIKeyResponse) GetRequestsPerSecond() int64 {
+	if x != nil {
+		return x.RequestsPerSecond
+	}
+	return 0
+}
+
+func (x *ValidateAPIKeyResponse) GetRestrictions() *APIKeyRestrictions {
+	if x != nil {
+		return x.Restrictions
+	}
+	return nil
+}
+
+func (x *ValidateAPIKeyResponse) GetDeprecated() bool {
+	if x != nil {
+		return x.Deprecated
+	}
+	return false
+}
+
+func (x *ValidateAPIKeyResponse) GetReplacedById() string {
+	if x != nil {
+		return x.ReplacedById
+	}
+	return ""
+}
+
+func (x *ValidateAPIKeyResponse) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+// Fields left unset are not changed
+type UpdateAPIKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Name          *string                `protobuf:"bytes,3,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Description   *string                `protobuf:"bytes,4,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	IsActive      *bool                  `protobuf:"varint,5,opt,name=is_active,json=isActive,proto3,oneof" json:"is_active,omitempty"`
+	ExpiresAt     *string                `protobuf:"bytes,6,opt,name=expires_at,json=expiresAt,proto3,oneof" json:"expires_at,omitempty"` // RFC3339; empty for a key that never expires
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateAPIKeyRequest) Reset() {
+	*x = UpdateAPIKeyRequest{}
+	mi := &file_services_user_service_proto_user_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateAPIKeyRequest) ProtoMessage() {}
+
+func (x *UpdateAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_user_service_proto_user_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*UpdateAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_services_user_service_proto_user_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateAPIKeyRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateAPIKeyRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UpdateAPIKeyRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *UpdateAPIKeyRequest) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *UpdateAPIKeyRequest) GetIsActive() bool {
+	if x != nil && x.IsActive != nil {
+		return *x.IsActive
+	}
+	return false
+}
+
+func (x *UpdateAPIKeyRequest) GetExpiresAt() string {
+	if x != nil && x.ExpiresAt != nil {
+		return *x.ExpiresAt
+	}
+	return ""
+}
+
+// The rotated key keeps working for the grace period alongside the new one
+type RotateAPIKeyRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId             string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	GracePeriodSeconds int64                  `protobuf:"varint,3,opt,name=grace_period_seconds,json=gracePeriodSeconds,proto3" json:"grace_period_seconds,omitempty"` // 0 for the default
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *RotateAPIKeyRequest) Reset() {
+	*x = RotateAPIKeyRequest{}
+	mi := &file_services_user_service_proto_user_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RotateAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateAPIKeyRequest) ProtoMessage() {}
+
+func (x *RotateAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_user_service_proto_user_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*RotateAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_services_user_service_proto_user_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RotateAPIKeyRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RotateAPIKeyRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RotateAPIKeyRequest) GetGracePeriodSeconds() int64 {
+	if x != nil {
+		return x.GracePeriodSeconds
+	}
+	return 0
+}
+
 type DeleteAPIKeyRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DeleteAPIKeyRequest) Reset() {
 	*x = DeleteAPIKeyRequest{}
-	mi := &file_services_user_service_proto_user_proto_msgTypes[6]
+	mi := &file_services_user_service_proto_user_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -370,7 +731,7 @@ func (x *DeleteAPIKeyRequest) String() string {
 func (*DeleteAPIKeyRequest) ProtoMessage() {}
 
 func (x *DeleteAPIKeyRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_services_user_service_proto_user_proto_msgTypes[6]
+	mi := &file_services_user_service_proto_user_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -383,7 +744,7 @@ func (x *DeleteAPIKeyRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteAPIKeyRequest.ProtoReflect.Descriptor instead.
 func (*DeleteAPIKeyRequest) Descriptor() ([]byte, []int) {
-	return file_services_user_service_proto_user_proto_rawDescGZIP(), []int{6}
+	return file_services_user_service_proto_user_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *DeleteAPIKeyRequest) GetId() string {
@@ -393,6 +754,13 @@ func (x *DeleteAPIKeyRequest) GetId() string {
 	return ""
 }
 
+func (x *DeleteAPIKeyRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
 type DeleteAPIKeyResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -402,7 +770,7 @@ type DeleteAPIKeyResponse struct {
 
 func (x *DeleteAPIKeyResponse) Reset() {
 	*x = DeleteAPIKeyResponse{}
-	mi := &file_services_user_service_proto_user_proto_msgTypes[7]
+	mi := &file_services_user_service_proto_user_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -414,7 +782,7 @@ func (x *DeleteAPIKeyResponse) String() string {
 func (*DeleteAPIKeyResponse) ProtoMessage() {}
 
 func (x *DeleteAPIKeyResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_services_user_service_proto_user_proto_msgTypes[7]
+	mi := &file_services_user_service_proto_user_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -427,7 +795,7 @@ func (x *DeleteAPIKeyResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteAPIKeyResponse.ProtoReflect.Descriptor instead.
 func (*DeleteAPIKeyResponse) Descriptor() ([]byte, []int) {
-	return file_services_user_service_proto_user_proto_rawDescGZIP(), []int{7}
+	return file_services_user_service_proto_user_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *DeleteAPIKeyResponse) GetSuccess() bool {
@@ -441,36 +809,88 @@ var File_services_user_service_proto_user_proto protoreflect.FileDescriptor
 
 const file_services_user_service_proto_user_proto_rawDesc = "" +
 	"\n" +
-	"&services/user-service/proto/user.proto\x12\x04user\"d\n" +
+	"&services/user-service/proto/user.proto\x12\x04user\"\xf7\x01\n" +
 	"\x13CreateAPIKeyRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
-	"\vdescription\x18\x03 \x01(\tR\vdescription\",\n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12#\n" +
+	"\rmonthly_limit\x18\x04 \x01(\x03R\fmonthlyLimit\x12.\n" +
+	"\x13requests_per_second\x18\x05 \x01(\x03R\x11requestsPerSecond\x12<\n" +
+	"\frestrictions\x18\x06 \x01(\v2\x18.user.APIKeyRestrictionsR\frestrictions\"\xae\x01\n" +
+	"\x12APIKeyRestrictions\x12%\n" +
+	"\x0eallowed_chains\x18\x01 \x03(\tR\rallowedChains\x12'\n" +
+	"\x0fallowed_methods\x18\x02 \x03(\tR\x0eallowedMethods\x12\x1f\n" +
+	"\vallowed_ips\x18\x03 \x03(\tR\n" +
+	"allowedIps\x12'\n" +
+	"\x0fallowed_origins\x18\x04 \x03(\tR\x0eallowedOrigins\",\n" +
 	"\x11GetAPIKeysRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\"\x9e\x01\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"\xdc\x03\n" +
 	"\x0eAPIKeyResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x10\n" +
 	"\x03key\x18\x03 \x01(\tR\x03key\x12\x12\n" +
 	"\x04name\x18\x04 \x01(\tR\x04name\x12 \n" +
 	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x1b\n" +
-	"\tis_active\x18\x06 \x01(\bR\bisActive\"E\n" +
+	"\tis_active\x18\x06 \x01(\bR\bisActive\x12#\n" +
+	"\rmonthly_limit\x18\a \x01(\x03R\fmonthlyLimit\x12.\n" +
+	"\x13requests_per_second\x18\b \x01(\x03R\x11requestsPerSecond\x12<\n" +
+	"\frestrictions\x18\t \x01(\v2\x18.user.APIKeyRestrictionsR\frestrictions\x12\x1d\n" +
+	"\n" +
+	"key_prefix\x18\n" +
+	" \x01(\tR\tkeyPrefix\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\v \x01(\tR\texpiresAt\x12&\n" +
+	"\x0frotated_from_id\x18\f \x01(\tR\rrotatedFromId\x12$\n" +
+	"\x0ereplaced_by_id\x18\r \x01(\tR\freplacedById\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x0e \x01(\tR\tcreatedAt\"E\n" +
 	"\x12GetAPIKeysResponse\x12/\n" +
 	"\bapi_keys\x18\x01 \x03(\v2\x14.user.APIKeyResponseR\aapiKeys\")\n" +
 	"\x15ValidateAPIKeyRequest\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\"G\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"\xdd\x02\n" +
 	"\x16ValidateAPIKeyResponse\x12\x14\n" +
 	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\"%\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x1c\n" +
+	"\n" +
+	"api_key_id\x18\x03 \x01(\tR\bapiKeyId\x12#\n" +
+	"\rmonthly_limit\x18\x04 \x01(\x03R\fmonthlyLimit\x12.\n" +
+	"\x13requests_per_second\x18\x05 \x01(\x03R\x11requestsPerSecond\x12<\n" +
+	"\frestrictions\x18\x06 \x01(\v2\x18.user.APIKeyRestrictionsR\frestrictions\x12\x1e\n" +
+	"\n" +
+	"deprecated\x18\a \x01(\bR\n" +
+	"deprecated\x12$\n" +
+	"\x0ereplaced_by_id\x18\b \x01(\tR\freplacedById\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\t \x01(\tR\texpiresAt\"\xfa\x01\n" +
+	"\x13UpdateAPIKeyRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x17\n" +
+	"\x04name\x18\x03 \x01(\tH\x00R\x04name\x88\x01\x01\x12%\n" +
+	"\vdescription\x18\x04 \x01(\tH\x01R\vdescription\x88\x01\x01\x12 \n" +
+	"\tis_active\x18\x05 \x01(\bH\x02R\bisActive\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"expires_at\x18\x06 \x01(\tH\x03R\texpiresAt\x88\x01\x01B\a\n" +
+	"\x05_nameB\x0e\n" +
+	"\f_descriptionB\f\n" +
+	"\n" +
+	"_is_activeB\r\n" +
+	"\v_expires_at\"p\n" +
+	"\x13RotateAPIKeyRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x120\n" +
+	"\x14grace_period_seconds\x18\x03 \x01(\x03R\x12gracePeriodSeconds\">\n" +
 	"\x13DeleteAPIKeyRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"0\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"0\n" +
 	"\x14DeleteAPIKeyResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess2\xa3\x02\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess2\xa5\x03\n" +
 	"\vUserService\x12?\n" +
 	"\fCreateAPIKey\x12\x19.user.CreateAPIKeyRequest\x1a\x14.user.APIKeyResponse\x12?\n" +
 	"\n" +
 	"GetAPIKeys\x12\x17.user.GetAPIKeysRequest\x1a\x18.user.GetAPIKeysResponse\x12K\n" +
-	"\x0eValidateAPIKey\x12\x1b.user.ValidateAPIKeyRequest\x1a\x1c.user.ValidateAPIKeyResponse\x12E\n" +
+	"\x0eValidateAPIKey\x12\x1b.user.ValidateAPIKeyRequest\x1a\x1c.user.ValidateAPIKeyResponse\x12?\n" +
+	"\fUpdateAPIKey\x12\x19.user.UpdateAPIKeyRequest\x1a\x14.user.APIKeyResponse\x12?\n" +
+	"\fRotateAPIKey\x12\x19.user.RotateAPIKeyRequest\x1a\x14.user.APIKeyResponse\x12E\n" +
 	"\fDeleteAPIKey\x12\x19.user.DeleteAPIKeyRequest\x1a\x1a.user.DeleteAPIKeyResponseB-Z+quicknode-clone/services/user-service/protob\x06proto3"
 
 var (
@@ -485,32 +905,42 @@ func file_services_user_service_proto_user_proto_rawDescGZIP() []byte {
 	return file_services_user_service_proto_user_proto_rawDescData
 }
 
-var file_services_user_service_proto_user_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_services_user_service_proto_user_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_services_user_service_proto_user_proto_goTypes = []any{
 	(*CreateAPIKeyRequest)(nil),    // 0: user.CreateAPIKeyRequest
-	(*GetAPIKeysRequest)(nil),      // 1: user.GetAPIKeysRequest
-	(*APIKeyResponse)(nil),         // 2: user.APIKeyResponse
-	(*GetAPIKeysResponse)(nil),     // 3: user.GetAPIKeysResponse
-	(*ValidateAPIKeyRequest)(nil),  // 4: user.ValidateAPIKeyRequest
-	(*ValidateAPIKeyResponse)(nil), // 5: user.ValidateAPIKeyResponse
-	(*DeleteAPIKeyRequest)(nil),    // 6: user.DeleteAPIKeyRequest
-	(*DeleteAPIKeyResponse)(nil),   // 7: user.DeleteAPIKeyResponse
+	(*APIKeyRestrictions)(nil),     // 1: user.APIKeyRestrictions
+	(*GetAPIKeysRequest)(nil),      // 2: user.GetAPIKeysRequest
+	(*APIKeyResponse)(nil),         // 3: user.APIKeyResponse
+	(*GetAPIKeysResponse)(nil),     // 4: user.GetAPIKeysResponse
+	(*ValidateAPIKeyRequest)(nil),  // 5: user.ValidateAPIKeyRequest
+	(*ValidateAPIKeyResponse)(nil), // 6: user.ValidateAPIKeyResponse
+	(*UpdateAPIKeyRequest)(nil),    // 7: user.UpdateAPIKeyRequest
+	(*RotateAPIKeyRequest)(nil),    // 8: user.RotateAPIKeyRequest
+	(*DeleteAPIKeyRequest)(nil),    // 9: user.DeleteAPIKeyRequest
+	(*DeleteAPIKeyResponse)(nil),   // 10: user.DeleteAPIKeyResponse
 }
 var file_services_user_service_proto_user_proto_depIdxs = []int32{
-	2, // 0: user.GetAPIKeysResponse.api_keys:type_name -> user.APIKeyResponse
-	0, // 1: user.UserService.CreateAPIKey:input_type -> user.CreateAPIKeyRequest
-	1, // 2: user.UserService.GetAPIKeys:input_type -> user.GetAPIKeysRequest
-	4, // 3: user.UserService.ValidateAPIKey:input_type -> user.ValidateAPIKeyRequest
-	6, // 4: user.UserService.DeleteAPIKey:input_type -> user.DeleteAPIKeyRequest
-	2, // 5: user.UserService.CreateAPIKey:output_type -> user.APIKeyResponse
-	3, // 6: user.UserService.GetAPIKeys:output_type -> user.GetAPIKeysResponse
-	5, // 7: user.UserService.ValidateAPIKey:output_type -> user.ValidateAPIKeyResponse
-	7, // 8: user.UserService.DeleteAPIKey:output_type -> user.DeleteAPIKeyResponse
-	5, // [5:9] is the sub-list for method output_type
-	1, // [1:5] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	1,  // 0: user.CreateAPIKeyRequest.restrictions:type_name -> user.APIKeyRestrictions
+	1,  // 1: user.APIKeyResponse.restrictions:type_name -> user.APIKeyRestrictions
+	3,  // 2: user.GetAPIKeysResponse.api_keys:type_name -> user.APIKeyResponse
+	1,  // 3: user.ValidateAPIKeyResponse.restrictions:type_name -> user.APIKeyRestrictions
+	0,  // 4: user.UserService.CreateAPIKey:input_type -> user.CreateAPIKeyRequest
+	2,  // 5: user.UserService.GetAPIKeys:input_type -> user.GetAPIKeysRequest
+	5,  // 6: user.UserService.ValidateAPIKey:input_type -> user.ValidateAPIKeyRequest
+	7,  // 7: user.UserService.UpdateAPIKey:input_type -> user.UpdateAPIKeyRequest
+	8,  // 8: user.UserService.RotateAPIKey:input_type -> user.RotateAPIKeyRequest
+	9,  // 9: user.UserService.DeleteAPIKey:input_type -> user.DeleteAPIKeyRequest
+	3,  // 10: user.UserService.CreateAPIKey:output_type -> user.APIKeyResponse
+	4,  // 11: user.UserService.GetAPIKeys:output_type -> user.GetAPIKeysResponse
+	6,  // 12: user.UserService.ValidateAPIKey:output_type -> user.ValidateAPIKeyResponse
+	3,  // 13: user.UserService.UpdateAPIKey:output_type -> user.APIKeyResponse
+	3,  // 14: user.UserService.RotateAPIKey:output_type -> user.APIKeyResponse
+	10, // 15: user.UserService.DeleteAPIKey:output_type -> user.DeleteAPIKeyResponse
+	10, // [10:16] is the sub-list for method output_type
+	4,  // [4:10] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_services_user_service_proto_user_proto_init() }
@@ -518,13 +948,14 @@ func file_services_user_service_proto_user_proto_init() {
 	if File_services_user_service_proto_user_proto != nil {
 		return
 	}
+	file_services_user_service_proto_user_proto_msgTypes[7].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_services_user_service_proto_user_proto_rawDesc), len(file_services_user_service_proto_user_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -22,6 +22,8 @@ const (
 	UserService_CreateAPIKey_FullMethodName   = "/user.UserService/CreateAPIKey"
 	UserService_GetAPIKeys_FullMethodName     = "/user.UserService/GetAPIKeys"
 	UserService_ValidateAPIKey_FullMethodName = "/user.UserService/ValidateAPIKey"
+	UserService_UpdateAPIKey_FullMethodName   = "/user.UserService/UpdateAPIKey"
+	UserService_RotateAPIKey_FullMethodName   = "/user.UserService/RotateAPIKey"
 	UserService_DeleteAPIKey_FullMethodName   = "/user.UserService/DeleteAPIKey"
 )
 
@@ -32,6 +34,8 @@ type UserServiceClient interface {
 	CreateAPIKey(ctx context.Context, in *CreateAPIKeyRequest, opts ...grpc.CallOption) (*APIKeyResponse, error)
 	GetAPIKeys(ctx context.Context, in *GetAPIKeysRequest, opts ...grpc.CallOption) (*GetAPIKeysResponse, error)
 	ValidateAPIKey(ctx context.Context, in *ValidateAPIKeyRequest, opts ...grpc.CallOption) (*ValidateAPIKeyResponse, error)
+	UpdateAPIKey(ctx context.Context, in *UpdateAPIKeyRequest, opts ...grpc.CallOption) (*APIKeyResponse, error)
+	RotateAPIKey(ctx context.Context, in *RotateAPIKeyRequest, opts ...grpc.CallOption) (*APIKeyResponse, error)
 	DeleteAPIKey(ctx context.Context, in *DeleteAPIKeyRequest, opts ...grpc.CallOption) (*DeleteAPIKeyResponse, error)
 }
 
@@ -70,6 +74,24 @@ func (c *userServiceClient) ValidateAPIKey(ctx context.Context, in *ValidateAPIK
 	return out, nil
 }
 
+func (c *userServiceClient) UpdateAPIKey(ctx context.Context, in *UpdateAPIKeyRequest, opts ...grpc.CallOption) (*APIKeyResponse, error) {
+	out := new(APIKeyResponse)
+	err := c.cc.Invoke(ctx, UserService_UpdateAPIKey_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) RotateAPIKey(ctx context.Context, in *RotateAPIKeyRequest, opts ...grpc.CallOption) (*APIKeyResponse, error) {
+	out := new(APIKeyResponse)
+	err := c.cc.Invoke(ctx, UserService_RotateAPIKey_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *userServiceClient) DeleteAPIKey(ctx context.Context, in *DeleteAPIKeyRequest, opts ...grpc.CallOption) (*DeleteAPIKeyResponse, error) {
 	out := new(DeleteAPIKeyResponse)
 	err := c.cc.Invoke(ctx, UserService_DeleteAPIKey_FullMethodName, in, out, opts...)
@@ -86,6 +108,8 @@ type UserServiceServer interface {
 	CreateAPIKey(context.Context, *CreateAPIKeyRequest) (*APIKeyResponse, error)
 	GetAPIKeys(context.Context, *GetAPIKeysRequest) (*GetAPIKeysResponse, error)
 	ValidateAPIKey(context.Context, *ValidateAPIKeyRequest) (*ValidateAPIKeyResponse, error)
+	UpdateAPIKey(context.Context, *UpdateAPIKeyRequest) (*APIKeyResponse, error)
+	RotateAPIKey(context.Context, *RotateAPIKeyRequest) (*APIKeyResponse, error)
 	DeleteAPIKey(context.Context, *DeleteAPIKeyRequest) (*DeleteAPIKeyResponse, error)
 	mustEmbedUnimplementedUserServiceServer()
 }
@@ -103,6 +127,12 @@ func (UnimplementedUserServiceServer) GetAPIKeys(context.Context, *GetAPIKeysReq
 func (UnimplementedUserServiceServer) ValidateAPIKey(context.Context, *ValidateAPIKeyRequest) (*ValidateAPIKeyResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ValidateAPIKey not implemented")
 }
+func (UnimplementedUserServiceServer) UpdateAPIKey(context.Context, *UpdateAPIKeyRequest) (*APIKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateAPIKey not implemented")
+}
+func (UnimplementedUserServiceServer) RotateAPIKey(context.Context, *RotateAPIKeyRequest) (*APIKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateAPIKey not implemented")
+}
 func (UnimplementedUserServiceServer) DeleteAPIKey(context.Context, *DeleteAPIKeyRequest) (*DeleteAPIKeyResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteAPIKey not implemented")
 }
@@ -173,6 +203,42 @@ func _UserService_ValidateAPIKey_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserService_UpdateAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).UpdateAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_UpdateAPIKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).UpdateAPIKey(ctx, req.(*UpdateAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_RotateAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).RotateAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_RotateAPIKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).RotateAPIKey(ctx, req.(*RotateAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _UserService_DeleteAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteAPIKeyRequest)
 	if err := dec(in); err != nil {
@@ -210,6 +276,14 @@ var UserService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ValidateAPIKey",
 			Handler:    _UserService_ValidateAPIKey_Handler,
 		},
+		{
+			MethodName: "UpdateAPIKey",
+			Handler:    _UserService_UpdateAPIKey_Handler,
+		},
+		{
+			MethodName: "RotateAPIKey",
+			Handler:    _UserService_RotateAPIKey_Handler,
+		},
 		{
 			MethodName: "DeleteAPIKey",
 			Handler:    _UserService_DeleteAPIKey_Handler,